@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+)
+
+// Exit codes. Without --ci every failure exits with exitError.
+const (
+	exitError      = 1
+	exitMerge      = 2
+	exitValidation = 3
+	exitConflicts  = 4
+	exitLint       = 5
+	exitWarnings   = 6
+)
+
+// gate collects the failures of the checks run after merging, so every
+// problem is reported before exiting
+type gate struct {
+	ci       bool
+	failures []gateFailure
+}
+
+// gateFailure is a failed check with its --ci exit code
+type gateFailure struct {
+	code    int
+	message string
+}
+
+// fail records a failed check
+func (g *gate) fail(code int, message string) {
+	g.failures = append(g.failures, gateFailure{code: code, message: message})
+}
+
+// exitCode returns the exit code for code: code itself with --ci, or
+// exitError
+func (g *gate) exitCode(code int) int {
+	if g.ci {
+		return code
+	}
+	return exitError
+}
+
+// check reports the failures and exits with the code of the first one
+func (g *gate) check(logger *consoleLogger) {
+	if len(g.failures) == 0 {
+		return
+	}
+	for _, failure := range g.failures {
+		logger.Error(failure.message)
+	}
+	logger.exit(g.exitCode(g.failures[0].code))
+}
+
+// mergeExitCode returns the --ci exit code of a merge error: invalid
+// inputs are validation errors
+func mergeExitCode(err error) int {
+	var inputErrs merger.InputErrors
+	var inputErr *merger.InputError
+	if errors.As(err, &inputErrs) || errors.As(err, &inputErr) {
+		return exitValidation
+	}
+	return exitMerge
+}
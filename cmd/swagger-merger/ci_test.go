@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+)
+
+func TestMergeExitCode(t *testing.T) {
+	invalid := &merger.InputError{Source: "users.yaml", Err: errors.New("invalid")}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"merge error", errors.New("output path is required"), exitMerge},
+		{"input error", invalid, exitValidation},
+		{"input errors", merger.InputErrors{invalid}, exitValidation},
+		{"wrapped input error", fmt.Errorf("merge failed: %w", invalid), exitValidation},
+	}
+	for _, tt := range tests {
+		if got := mergeExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: mergeExitCode() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if code := (&gate{}).exitCode(exitLint); code != exitError {
+		t.Errorf("Expected exit code %d without --ci, got %d", exitError, code)
+	}
+	if code := (&gate{ci: true}).exitCode(exitLint); code != exitLint {
+		t.Errorf("Expected exit code %d with --ci, got %d", exitLint, code)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+)
+
+// flagGiven reports whether a flag was set on the command line
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) { given = given || f.Name == name })
+	return given
+}
+
+// stringList is a flag that can be given multiple times
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseHeader parses a "Name: Value" header flag
+func parseHeader(header string) (string, string, error) {
+	name, value, found := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return "", "", fmt.Errorf("invalid header %q (format: 'Name: Value')", header)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// buildHTTPConfig builds the remote fetch configuration from the CLI flags.
+// The bearer token falls back to the SWAGGER_MERGER_TOKEN environment variable.
+func buildHTTPConfig(headers, urlHeaders []string, bearerToken, basicAuth string) (merger.HTTPConfig, error) {
+	config := merger.HTTPConfig{BearerToken: bearerToken}
+	if config.BearerToken == "" {
+		config.BearerToken = os.Getenv("SWAGGER_MERGER_TOKEN")
+	}
+
+	if basicAuth != "" {
+		user, password, found := strings.Cut(basicAuth, ":")
+		if !found {
+			return config, fmt.Errorf("invalid --basic-auth (format: user:password)")
+		}
+		config.Username, config.Password = user, password
+	}
+
+	for _, header := range headers {
+		name, value, err := parseHeader(header)
+		if err != nil {
+			return config, err
+		}
+		if config.Headers == nil {
+			config.Headers = make(map[string]string)
+		}
+		config.Headers[name] = value
+	}
+
+	for _, urlHeader := range urlHeaders {
+		prefix, header, found := strings.Cut(urlHeader, "=")
+		if !found || prefix == "" {
+			return config, fmt.Errorf("invalid --url-header %q (format: url-prefix=Name: Value)", urlHeader)
+		}
+		name, value, err := parseHeader(header)
+		if err != nil {
+			return config, err
+		}
+		if config.URLHeaders == nil {
+			config.URLHeaders = make(map[string]map[string]string)
+		}
+		if config.URLHeaders[prefix] == nil {
+			config.URLHeaders[prefix] = make(map[string]string)
+		}
+		config.URLHeaders[prefix][name] = value
+	}
+
+	return config, nil
+}
+
+// splitList splits a comma-separated flag value, keeping commas inside
+// {a,b} glob alternatives. Items are trimmed and empty ones dropped.
+func splitList(value string) []string {
+	var items []string
+	add := func(item string) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	depth, start := 0, 0
+	for i, c := range value {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				add(value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(value[start:])
+	return items
+}
+
+// parseBackends parses "[/path-prefix=]url" --proxy-backend flags into
+// backend URLs by path prefix, "/" when none is given
+func parseBackends(values []string) (map[string]string, error) {
+	backends := make(map[string]string, len(values))
+	for _, value := range values {
+		prefix, backend, found := strings.Cut(value, "=")
+		if !found || !strings.HasPrefix(prefix, "/") {
+			prefix, backend = "/", value
+		}
+		if backend == "" {
+			return nil, fmt.Errorf("invalid --proxy-backend %q (format: [/path-prefix=]url)", value)
+		}
+		backends[prefix] = backend
+	}
+	return backends, nil
+}
+
+// compressedPath appends .gz to an output path, before the query of object
+// storage URIs, unless it already has it
+func compressedPath(output string) string {
+	name, query, hasQuery := strings.Cut(output, "?")
+	if !strings.Contains(name, "://") {
+		name, hasQuery = output, false
+	}
+	if !strings.EqualFold(path.Ext(name), ".gz") {
+		name += ".gz"
+	}
+	if hasQuery {
+		return name + "?" + query
+	}
+	return name
+}
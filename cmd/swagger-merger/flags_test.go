@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBackends(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "none",
+			values: nil,
+			want:   map[string]string{},
+		},
+		{
+			name:   "default prefix",
+			values: []string{"http://localhost:8080"},
+			want:   map[string]string{"/": "http://localhost:8080"},
+		},
+		{
+			name:   "prefixes",
+			values: []string{"/users=http://users:8080", "/orders=http://orders:8080", "http://gateway"},
+			want:   map[string]string{"/users": "http://users:8080", "/orders": "http://orders:8080", "/": "http://gateway"},
+		},
+		{
+			name:   "equals sign in the URL",
+			values: []string{"http://backend/?tenant=a"},
+			want:   map[string]string{"/": "http://backend/?tenant=a"},
+		},
+		{
+			name:   "last backend of a prefix wins",
+			values: []string{"/api=http://a", "/api=http://b"},
+			want:   map[string]string{"/api": "http://b"},
+		},
+		{
+			name:    "empty",
+			values:  []string{""},
+			wantErr: true,
+		},
+		{
+			name:    "prefix without URL",
+			values:  []string{"/users="},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBackends(tt.values)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBackends() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressedPath(t *testing.T) {
+	tests := []struct {
+		output, want string
+	}{
+		{"merged.yaml", "merged.yaml.gz"},
+		{"merged.yaml.gz", "merged.yaml.gz"},
+		{"merged.yaml.GZ", "merged.yaml.GZ"},
+		{"out/what?.json", "out/what?.json.gz"},
+		{"s3://bucket/merged.yaml", "s3://bucket/merged.yaml.gz"},
+		{"s3://bucket/merged.yaml?region=eu-west-1", "s3://bucket/merged.yaml.gz?region=eu-west-1"},
+		{"gs://bucket/merged.json.gz?x=1", "gs://bucket/merged.json.gz?x=1"},
+	}
+	for _, tt := range tests {
+		if got := compressedPath(tt.output); got != tt.want {
+			t.Errorf("compressedPath(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"a.yaml", []string{"a.yaml"}},
+		{" a.yaml , ,b.yaml ", []string{"a.yaml", "b.yaml"}},
+		{"specs/*.{yaml,json},extra.yaml", []string{"specs/*.{yaml,json}", "extra.yaml"}},
+	}
+	for _, tt := range tests {
+		if got := splitList(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitList(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+)
+
+// annotator prints diagnostics as GitHub Actions workflow commands, so
+// they show up inline on pull requests
+type annotator struct {
+	out io.Writer
+	// files caches the content of local inputs, nil for unreadable ones
+	files map[string][]byte
+}
+
+// newAnnotator creates an annotator writing to stdout for the
+// --output-format value, or returns nil for text output
+func newAnnotator(format string) (*annotator, error) {
+	switch format {
+	case "text":
+		return nil, nil
+	case "github":
+		return &annotator{out: os.Stdout, files: make(map[string][]byte)}, nil
+	}
+	return nil, fmt.Errorf("invalid --output-format %q (expected text or github)", format)
+}
+
+// annotate prints a workflow command of level (error, warning or notice)
+// for a source, pointing at the line of keys when the source is a
+// readable file
+func (a *annotator) annotate(level, source string, keys []string, title, message string) {
+	if a == nil {
+		return
+	}
+	var props []string
+	if source != "" {
+		props = append(props, "file="+escapeProperty(source))
+		if line := a.line(source, keys); line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", line))
+		}
+	}
+	props = append(props, "title="+escapeProperty(title))
+	fmt.Fprintf(a.out, "::%s %s::%s\n", level, strings.Join(props, ","), escapeData(message))
+}
+
+// line returns the line of keys in a local source, or 0
+func (a *annotator) line(source string, keys []string) int {
+	if len(keys) == 0 {
+		return 0
+	}
+	data, ok := a.files[source]
+	if !ok {
+		data, _ = os.ReadFile(source)
+		a.files[source] = data
+	}
+	if data == nil {
+		return 0
+	}
+	return merger.LineOf(data, keys...)
+}
+
+// conflicts annotates the definitions that conflicting inputs redefine
+func (a *annotator) conflicts(conflicts []merger.Conflict) {
+	for _, c := range conflicts {
+		source := c.Sources[len(c.Sources)-1]
+		a.annotate("warning", source, c.Keys(), "Merge conflict", c.String())
+	}
+}
+
+// inputErrors annotates invalid inputs
+func (a *annotator) inputErrors(err error) {
+	var inputErrs merger.InputErrors
+	var inputErr *merger.InputError
+	switch {
+	case errors.As(err, &inputErrs):
+		for _, e := range inputErrs {
+			a.annotate("error", e.Source, nil, "Invalid input", e.Err.Error())
+		}
+	case errors.As(err, &inputErr):
+		a.annotate("error", inputErr.Source, nil, "Invalid input", inputErr.Err.Error())
+	default:
+		a.annotate("error", "", nil, "Merge failed", err.Error())
+	}
+}
+
+// exampleIssues annotates examples not matching their schemas
+func (a *annotator) exampleIssues(issues []merger.ExampleIssue) {
+	for _, issue := range issues {
+		a.annotate("error", issue.Source, []string{"paths", issue.Path}, "Invalid example", issue.String())
+	}
+}
+
+// envelopeDeviations annotates responses not using the envelope
+func (a *annotator) envelopeDeviations(deviations []merger.EnvelopeDeviation) {
+	for _, deviation := range deviations {
+		a.annotate("warning", deviation.Source, []string{"paths", deviation.Path}, "Response without envelope", deviation.String())
+	}
+}
+
+// lintFindings annotates lint findings, by severity
+func (a *annotator) lintFindings(findings []merger.LintFinding) {
+	for _, finding := range findings {
+		level := "notice"
+		switch finding.Severity {
+		case merger.SeverityError:
+			level = "error"
+		case merger.SeverityWarn:
+			level = "warning"
+		}
+		var keys []string
+		switch {
+		case finding.Path != "":
+			keys = []string{"paths", finding.Path}
+		case finding.Schema != "":
+			keys = []string{"components", "schemas", finding.Schema}
+		}
+		a.annotate(level, finding.Source, keys, "Lint: "+finding.Rule, finding.Location+": "+finding.Message)
+	}
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
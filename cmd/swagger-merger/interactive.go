@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+	"gopkg.in/yaml.v3"
+)
+
+// diffContext is the number of unchanged lines shown around changes
+const diffContext = 3
+
+// resolutionPrompt asks the user to resolve the collisions of a merge the
+// saved resolutions do not decide
+type resolutionPrompt struct {
+	in  *bufio.Reader
+	out io.Writer
+	// saved resolutions, applied without asking
+	saved []merger.Resolution
+	// decisions taken interactively
+	decisions []merger.Resolution
+	// skipAll is set once the user skips the remaining collisions or the
+	// input ends
+	skipAll bool
+	width   int
+}
+
+// newResolutionPrompt returns a prompt reading answers from in and
+// writing collisions to out
+func newResolutionPrompt(in io.Reader, out io.Writer, saved []merger.Resolution) *resolutionPrompt {
+	width := 120
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns >= 40 {
+		width = columns
+	}
+	return &resolutionPrompt{in: bufio.NewReader(in), out: out, saved: saved, width: width}
+}
+
+// readResolutions reads a resolution file; a missing file has no
+// resolutions when allowMissing is set
+func readResolutions(file string, allowMissing bool) ([]merger.Resolution, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) && allowMissing {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return merger.ParseResolutions(data)
+}
+
+// resolve applies the saved resolution of a collision, or asks the user
+func (p *resolutionPrompt) resolve(c merger.Collision) (merger.Resolution, error) {
+	if r, err := merger.SavedResolutions(p.saved)(c); err != nil || r.Keep != "" || r.Rename != "" {
+		return r, err
+	}
+	if p.skipAll {
+		return merger.Resolution{}, nil
+	}
+
+	fmt.Fprintf(p.out, "\n⚔️  %s %s is defined differently by %s and %s\n\n", c.Kind, c.Name, c.Sources[0], c.Sources[1])
+	if err := p.printDiff(c); err != nil {
+		return merger.Resolution{}, err
+	}
+	options := fmt.Sprintf("[1] keep %s, [2] keep %s", c.Sources[0], c.Sources[1])
+	if c.Kind == "schema" {
+		options += fmt.Sprintf(", [r] rename the schema of %s", c.Sources[1])
+	}
+	options += ", [s] skip, [q] skip all"
+
+	for {
+		answer, ok := p.ask(fmt.Sprintf("\n%s\n> ", options))
+		if !ok {
+			return merger.Resolution{}, nil
+		}
+		r := merger.Resolution{Kind: c.Kind, Name: c.Name}
+		switch strings.ToLower(answer) {
+		case "1", "2":
+			index := int(answer[0] - '1')
+			r.Keep = keepName(c.Sources, index)
+		case "r":
+			if c.Kind != "schema" {
+				continue
+			}
+			name, ok := p.ask(fmt.Sprintf("New name for the %s schema of %s: ", c.Name, c.Sources[1]))
+			if !ok {
+				return merger.Resolution{}, nil
+			}
+			if name == "" || name == c.Name {
+				continue
+			}
+			r.Rename = name
+		case "s", "":
+			return r, nil
+		case "q":
+			p.skipAll = true
+			return r, nil
+		default:
+			continue
+		}
+		p.decisions = append(p.decisions, r)
+		return r, nil
+	}
+}
+
+// ask prints a question and reads the answer; false means the input
+// ended, which skips the remaining collisions
+func (p *resolutionPrompt) ask(question string) (string, bool) {
+	fmt.Fprint(p.out, question)
+	line, err := p.in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(p.out)
+		p.skipAll = true
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// keepName returns how resolution files refer to the index-th source of
+// a collision: its base name, unless both sources share it
+func keepName(sources []string, index int) string {
+	name := path.Base(sources[index])
+	if path.Base(sources[1-index]) == name {
+		return sources[index]
+	}
+	return name
+}
+
+// printDiff prints both definitions of a collision side by side
+func (p *resolutionPrompt) printDiff(c merger.Collision) error {
+	var texts [2][]string
+	for i, v := range []any{c.Existing, c.Incoming} {
+		text, err := yamlLines(v)
+		if err != nil {
+			return fmt.Errorf("failed to render %s %s: %v", c.Kind, c.Name, err)
+		}
+		texts[i] = text
+	}
+	column := (p.width - 3) / 2
+	fmt.Fprintf(p.out, "%s   %s\n", pad(c.Sources[0], column), c.Sources[1])
+	fmt.Fprintf(p.out, "%s   %s\n", strings.Repeat("─", column), strings.Repeat("─", column))
+	for _, row := range sideBySide(texts[0], texts[1]) {
+		if row.marker == '⋮' {
+			fmt.Fprintf(p.out, "%s ⋮\n", pad("", column))
+			continue
+		}
+		fmt.Fprintf(p.out, "%s %c %s\n", pad(row.left, column), row.marker, truncate(row.right, column))
+	}
+	return nil
+}
+
+// yamlLines renders a value as YAML lines, through its JSON encoding so
+// OpenAPI types render as in documents
+func yamlLines(v any) ([]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(generic)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(out), "\n"), "\n"), nil
+}
+
+// diffRow is a row of a side-by-side diff; marker is ' ' for equal lines,
+// '|' for changed ones, '<' and '>' for lines of one side only and '⋮'
+// for elided equal lines
+type diffRow struct {
+	left, right string
+	marker      rune
+}
+
+// sideBySide aligns two texts on their longest common subsequence of
+// lines, pairing the lines changed between equal ones and eliding the
+// equal lines far from changes
+func sideBySide(a, b []string) []diffRow {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var rows []diffRow
+	var removed, added []string
+	flush := func() {
+		for k := 0; k < max(len(removed), len(added)); k++ {
+			row := diffRow{marker: '|'}
+			if k < len(removed) {
+				row.left = removed[k]
+			} else {
+				row.marker = '>'
+			}
+			if k < len(added) {
+				row.right = added[k]
+			} else {
+				row.marker = '<'
+			}
+			rows = append(rows, row)
+		}
+		removed, added = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			rows = append(rows, diffRow{left: a[i], right: b[j], marker: ' '})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	flush()
+
+	// Keep the equal lines within diffContext rows of a change
+	keep := make([]bool, len(rows))
+	for k, row := range rows {
+		if row.marker != ' ' {
+			for n := max(0, k-diffContext); n <= min(len(rows)-1, k+diffContext); n++ {
+				keep[n] = true
+			}
+		}
+	}
+	var shown []diffRow
+	for k, row := range rows {
+		switch {
+		case keep[k]:
+			shown = append(shown, row)
+		case len(shown) == 0 || shown[len(shown)-1].marker != '⋮':
+			shown = append(shown, diffRow{marker: '⋮'})
+		}
+	}
+	return shown
+}
+
+// pad truncates or pads s to width runes
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+// truncate shortens s to width runes, ending it with an ellipsis
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// saveResolutions writes the saved resolutions updated with the decisions
+// of the prompt to a resolution file
+func (p *resolutionPrompt) saveResolutions(file string) (int, error) {
+	if len(p.decisions) == 0 {
+		return 0, nil
+	}
+	resolutions := slices.Clone(p.saved)
+	for _, d := range p.decisions {
+		i := slices.IndexFunc(resolutions, func(r merger.Resolution) bool { return r.Kind == d.Kind && r.Name == d.Name })
+		if i >= 0 {
+			resolutions[i] = d
+		} else {
+			resolutions = append(resolutions, d)
+		}
+	}
+	data, err := merger.MarshalResolutions(resolutions)
+	if err != nil {
+		return 0, err
+	}
+	return len(p.decisions), os.WriteFile(file, data, 0644)
+}
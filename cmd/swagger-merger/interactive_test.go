@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+)
+
+func TestSideBySide(t *testing.T) {
+	// lines returns "line 1" to "line n"
+	lines := func(n int, last ...string) []string {
+		var out []string
+		for i := 1; i <= n; i++ {
+			out = append(out, fmt.Sprintf("line %d", i))
+		}
+		return append(out, last...)
+	}
+
+	tests := []struct {
+		name string
+		a, b []string
+		want []diffRow
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "equal",
+			a:    []string{"type: object", "title: User"},
+			b:    []string{"type: object", "title: User"},
+			// Equal lines far from changes are elided
+			want: []diffRow{{marker: '⋮'}},
+		},
+		{
+			name: "changed",
+			a:    []string{"type: object", "title: User"},
+			b:    []string{"type: object", "title: Account"},
+			want: []diffRow{
+				{left: "type: object", right: "type: object", marker: ' '},
+				{left: "title: User", right: "title: Account", marker: '|'},
+			},
+		},
+		{
+			name: "added",
+			a:    []string{"type: object"},
+			b:    []string{"type: object", "title: User"},
+			want: []diffRow{
+				{left: "type: object", right: "type: object", marker: ' '},
+				{right: "title: User", marker: '>'},
+			},
+		},
+		{
+			name: "removed",
+			a:    []string{"type: object", "title: User"},
+			b:    []string{"type: object"},
+			want: []diffRow{
+				{left: "type: object", right: "type: object", marker: ' '},
+				{left: "title: User", marker: '<'},
+			},
+		},
+		{
+			name: "more removed than added",
+			a:    []string{"a: 1", "b: 2", "c: 3"},
+			b:    []string{"d: 4"},
+			want: []diffRow{
+				{left: "a: 1", right: "d: 4", marker: '|'},
+				{left: "b: 2", marker: '<'},
+				{left: "c: 3", marker: '<'},
+			},
+		},
+		{
+			name: "elided context",
+			a:    lines(10, "old"),
+			b:    lines(10, "new"),
+			want: []diffRow{
+				{marker: '⋮'},
+				{left: "line 8", right: "line 8", marker: ' '},
+				{left: "line 9", right: "line 9", marker: ' '},
+				{left: "line 10", right: "line 10", marker: ' '},
+				{left: "old", right: "new", marker: '|'},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sideBySide(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sideBySide() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolutionPrompt(t *testing.T) {
+	schema := merger.Collision{
+		Kind:     "schema",
+		Name:     "User",
+		Sources:  []string{"specs/users.yaml", "specs/accounts.yaml"},
+		Existing: map[string]any{"type": "object"},
+		Incoming: map[string]any{"type": "string"},
+	}
+	path := merger.Collision{
+		Kind:     "path",
+		Name:     "/health",
+		Sources:  []string{"users/api.yaml", "orders/api.yaml"},
+		Existing: map[string]any{"summary": "Users health"},
+		Incoming: map[string]any{"summary": "Orders health"},
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		saved     []merger.Resolution
+		collision merger.Collision
+		want      merger.Resolution
+		decisions int
+	}{
+		{
+			name:      "keep first",
+			input:     "1\n",
+			collision: schema,
+			want:      merger.Resolution{Kind: "schema", Name: "User", Keep: "users.yaml"},
+			decisions: 1,
+		},
+		{
+			name:      "keep second",
+			input:     "2\n",
+			collision: schema,
+			want:      merger.Resolution{Kind: "schema", Name: "User", Keep: "accounts.yaml"},
+			decisions: 1,
+		},
+		{
+			name:      "same base names keep the full source",
+			input:     "2\n",
+			collision: path,
+			want:      merger.Resolution{Kind: "path", Name: "/health", Keep: "orders/api.yaml"},
+			decisions: 1,
+		},
+		{
+			name:      "rename",
+			input:     "r\nUser\nr\nAccount\n",
+			collision: schema,
+			want:      merger.Resolution{Kind: "schema", Name: "User", Rename: "Account"},
+			decisions: 1,
+		},
+		{
+			name:      "paths cannot be renamed",
+			input:     "r\nx\n1\n",
+			collision: path,
+			want:      merger.Resolution{Kind: "path", Name: "/health", Keep: "users/api.yaml"},
+			decisions: 1,
+		},
+		{
+			name:      "skip",
+			input:     "s\n",
+			collision: schema,
+			want:      merger.Resolution{Kind: "schema", Name: "User"},
+		},
+		{
+			name:      "input ends",
+			collision: schema,
+		},
+		{
+			name:      "saved",
+			saved:     []merger.Resolution{{Kind: "schema", Name: "User", Keep: "accounts.yaml"}},
+			collision: schema,
+			want:      merger.Resolution{Kind: "schema", Name: "User", Keep: "accounts.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := newResolutionPrompt(strings.NewReader(tt.input), &out, tt.saved)
+			got, err := p.resolve(tt.collision)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+			if len(p.decisions) != tt.decisions {
+				t.Errorf("Expected %d decisions, got %v", tt.decisions, p.decisions)
+			}
+			if tt.saved != nil && out.Len() > 0 {
+				t.Errorf("Expected saved resolutions to be applied silently, got:\n%s", out.String())
+			}
+		})
+	}
+
+	// Skipping all leaves the next collisions unresolved without asking
+	var out bytes.Buffer
+	p := newResolutionPrompt(strings.NewReader("q\n1\n"), &out, nil)
+	for _, c := range []merger.Collision{schema, path} {
+		if r, err := p.resolve(c); err != nil || r.Keep != "" || r.Rename != "" {
+			t.Errorf("Expected %s %s to be skipped, got %+v, %v", c.Kind, c.Name, r, err)
+		}
+	}
+	if strings.Contains(out.String(), "/health") {
+		t.Errorf("Expected no question after skipping all, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+)
+
+// consoleLogger prints merger output for humans, or as JSON events with
+// --log-format json. Messages below its level are dropped.
+type consoleLogger struct {
+	out   io.Writer
+	err   io.Writer
+	level slog.Level
+	// json receives every message in JSON mode
+	json *slog.Logger
+}
+
+// newConsoleLogger creates a logger of level writing text to stdout and
+// stderr, or JSON lines to stderr for the json format
+func newConsoleLogger(level slog.Level, format string) (*consoleLogger, error) {
+	l := &consoleLogger{out: os.Stdout, err: os.Stderr, level: level}
+	switch format {
+	case "text":
+	case "json":
+		l.json = slog.New(slog.NewJSONHandler(l.err, &slog.HandlerOptions{Level: level}))
+	default:
+		return l, fmt.Errorf("invalid --log-format %q (expected text or json)", format)
+	}
+	return l, nil
+}
+
+// parseLogLevel returns the level of the --log-level, --verbose and
+// --quiet flags. --verbose is an alias of --log-level debug, --quiet of
+// --log-level error.
+func parseLogLevel(name string, verbose, quiet bool) (slog.Level, error) {
+	switch {
+	case verbose && quiet:
+		return slog.LevelInfo, fmt.Errorf("--verbose and --quiet cannot be combined")
+	case verbose:
+		return slog.LevelDebug, nil
+	case quiet:
+		return slog.LevelError, nil
+	}
+	switch name {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid --log-level %q (expected debug, info, warn or error)", name)
+}
+
+func (l *consoleLogger) Debug(msg string, args ...any) {
+	if l.json != nil {
+		l.json.Debug(msg, args...)
+	} else if l.level <= slog.LevelDebug {
+		fmt.Fprintln(l.out, formatMessage(msg, args))
+	}
+}
+
+func (l *consoleLogger) Info(msg string, args ...any) {
+	if l.json != nil {
+		l.json.Info(msg, args...)
+	} else if l.level <= slog.LevelInfo {
+		fmt.Fprintln(l.out, formatMessage(msg, args))
+	}
+}
+
+func (l *consoleLogger) Warn(msg string, args ...any) {
+	if l.json != nil {
+		l.json.Warn(msg, args...)
+	} else if l.level <= slog.LevelWarn {
+		fmt.Fprintln(l.err, "⚠️  Warning: "+formatMessage(msg, args))
+	}
+}
+
+func (l *consoleLogger) Error(msg string, args ...any) {
+	if l.json != nil {
+		l.json.Error(msg, args...)
+		return
+	}
+	fmt.Fprintln(l.err, "❌ Error: "+formatMessage(msg, args))
+}
+
+// fatal logs an error and exits
+func (l *consoleLogger) fatal(msg string, args ...any) {
+	l.Error(msg, args...)
+	l.exit(exitError)
+}
+
+// exit ends the process with an exit code
+func (l *consoleLogger) exit(code int) {
+	os.Exit(code)
+}
+
+// library returns the logger given to the merger. Its info events, such as
+// every processed input, are details in text mode, only shown at the
+// debug level.
+func (l *consoleLogger) library() merger.Logger {
+	if l.json != nil {
+		return l
+	}
+	return textLibraryLogger{l}
+}
+
+// textLibraryLogger shows the info events of the library as debug messages
+type textLibraryLogger struct {
+	*consoleLogger
+}
+
+func (l textLibraryLogger) Info(msg string, args ...any) {
+	l.Debug(msg, args...)
+}
+
+// formatMessage appends key=value pairs to msg
+func formatMessage(msg string, args []any) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", args[i])
+		}
+	}
+	return b.String()
+}
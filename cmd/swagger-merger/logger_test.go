@@ -0,0 +1,30 @@
+package main
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name           string
+		verbose, quiet bool
+		want           slog.Level
+		wantErr        bool
+	}{
+		{name: "info", want: slog.LevelInfo},
+		{name: "debug", want: slog.LevelDebug},
+		{name: "warn", want: slog.LevelWarn},
+		{name: "error", want: slog.LevelError},
+		{name: "info", verbose: true, want: slog.LevelDebug},
+		{name: "info", quiet: true, want: slog.LevelError},
+		{name: "info", verbose: true, quiet: true, wantErr: true},
+		{name: "trace", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseLogLevel(tt.name, tt.verbose, tt.quiet)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("parseLogLevel(%q, %v, %v) = %v, %v", tt.name, tt.verbose, tt.quiet, got, err)
+		}
+	}
+}
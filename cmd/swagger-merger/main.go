@@ -1,34 +1,177 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"log"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/JackBee2912/swagger-merger/pkg/merger"
 )
 
+// serveCommands serve the merged spec rather than producing it, so they
+// only write outputs and publish the spec when --output is given
+var serveCommands = map[string]bool{"mock": true, "proxy": true, "preview": true}
+
 func main() {
 	var (
-		inputPaths = flag.String("input", "", "Comma-separated list of input swagger files or directories")
-		outputPath = flag.String("output", "merged_swagger.yaml", "Output file path")
-		pattern    = flag.String("pattern", "*.yaml", "File pattern for directory scanning (supports comma-separated patterns)")
-		servers    = flag.String("servers", "", "Comma-separated list of server URLs (format: url:description)")
-		version    = flag.Bool("version", false, "Show version information")
-		help       = flag.Bool("help", false, "Show help information")
-		verbose    = flag.Bool("verbose", false, "Enable verbose output")
-		stats      = flag.Bool("stats", false, "Show statistics after merging")
+		inputPaths    = flag.String("input", "", "Comma-separated list of input swagger files or directories")
+		pattern       = flag.String("pattern", "*.yaml", "File pattern for directory and archive scanning (supports comma-separated patterns)")
+		ignoreFile    = flag.String("ignore-file", ".swaggerignore", "Name of the gitignore-style files excluding paths from directory scanning (- to disable)")
+		noRecursive   = flag.Bool("no-recursive", false, "Only scan the top level of directory inputs")
+		maxDepth      = flag.Int("max-depth", 0, "Maximum directory depth scanned, 1 being the top level (0 = no limit)")
+		symlinks      = flag.String("symlinks", "files", "Symlink handling for directory inputs: files, follow (with loop detection) or skip")
+		order         = flag.String("order", "as-given", "Merge order of inputs, which decides who wins conflicts: as-given, alpha or mtime")
+		schemaMode    = flag.String("schema-strategy", "overwrite", "How schemas defined by several inputs are combined: overwrite (last wins), union (of properties), strict (must be equal) or rename (with a content hash suffix)")
+		schemaDocs    = flag.Bool("schema-ignore-docs", false, "Ignore titles, descriptions and examples when comparing schemas with --schema-strategy strict")
+		enumUnion     = flag.Bool("enum-union", false, "Union the values of schemas several inputs define as enums of the same type")
+		interactive   = flag.Bool("interactive", false, "Resolve path and schema conflicts one by one, picking the definition to keep or renaming schemas")
+		resolutions   = flag.String("resolutions", "", "YAML file of saved conflict resolutions, updated with the decisions of --interactive")
+		extDocs       = flag.String("external-docs", "first", "Policy for top-level and tag externalDocs: first, per-tag (move each input's docs to its tags) or drop")
+		extPolicy     = flag.String("extensions", "override", "How x-* extensions of the inputs are merged: override (per key), union (deep merge) or error (on conflict)")
+		renamesFile   = flag.String("renames", "", "YAML file renaming components of inputs before merging (source:OldName: NewName)")
+		renameAlias   = flag.Bool("rename-aliases", false, "Keep the old names of schemas renamed by --renames as deprecated aliases")
+		servers       = flag.String("servers", "", "Comma-separated list of server URLs (format: url:description)")
+		version       = flag.Bool("version", false, "Show version information")
+		versionJSON   = flag.Bool("json", false, "Print --version as JSON")
+		help          = flag.Bool("help", false, "Show help information")
+		verbose       = flag.Bool("verbose", false, "Enable verbose output (alias of --log-level debug)")
+		quiet         = flag.Bool("quiet", false, "Only print errors (alias of --log-level error)")
+		logLevel      = flag.String("log-level", "info", "Minimum level of log messages: debug, info, warn or error")
+		stats         = flag.Bool("stats", false, "Show statistics after merging")
+		profile       = flag.Bool("profile", false, "Show the time spent in each phase and the peak memory with the statistics")
+		ci            = flag.Bool("ci", false, "CI mode: fail on invalid inputs and examples, with distinct exit codes per failure")
+		maxConflicts  = flag.Int("max-conflicts", -1, "Fail when the merge reports more conflicts than this (default: no limit)")
+		logFormat     = flag.String("log-format", "text", "Log format: text, or json for structured events on stderr")
+		outputFormat  = flag.String("output-format", "text", "Diagnostics format: text, or github to also emit GitHub Actions annotations")
+		maxWarnings   = flag.Int("max-warnings", -1, "Fail when linting reports more warnings than this (default: no limit)")
+		progress      = flag.Bool("progress", false, "Show per-file progress while merging")
+		skipInvalid   = flag.Bool("skip-invalid", false, "Skip unreadable or invalid inputs instead of failing")
+		cacheDir      = flag.String("cache-dir", "", "Directory for caching converted inputs between runs")
+		bearerToken   = flag.String("bearer-token", "", "Bearer token for fetching remote inputs (default: $SWAGGER_MERGER_TOKEN)")
+		basicAuth     = flag.String("basic-auth", "", "Basic auth credentials for fetching remote inputs (format: user:password)")
+		retries       = flag.Int("retries", 0, "Number of retries for transient failures when fetching remote inputs")
+		concurrency   = flag.Int("concurrency", merger.DefaultConcurrency, "Number of inputs downloaded and parsed at once (1 = one by one)")
+		rateLimit     = flag.Float64("rate-limit", 0, "Maximum requests per second to each host serving remote inputs (0 = no limit)")
+		rateBurst     = flag.Int("rate-burst", 1, "Requests sent to a host at once before --rate-limit applies")
+		proxy         = flag.String("proxy", "", "Proxy URL for fetching remote inputs (default: $HTTP_PROXY/$HTTPS_PROXY)")
+		caFile        = flag.String("ca-file", "", "PEM bundle of additional CAs trusted for HTTPS inputs")
+		certFile      = flag.String("cert-file", "", "PEM client certificate for HTTPS inputs requiring mutual TLS")
+		keyFile       = flag.String("key-file", "", "PEM client key for --cert-file")
+		insecure      = flag.Bool("insecure-skip-verify", false, "Disable TLS certificate verification for remote inputs (unsafe)")
+		httpTimeout   = flag.Duration("http-timeout", 30*time.Second, "Timeout for each remote input request")
+		fetchTimeout  = flag.Duration("fetch-timeout", 0, "Total time allowed for fetching all remote inputs, including retries (0 = no limit)")
+		mergeTimeout  = flag.Duration("timeout", 0, "Time allowed for the whole run, from discovering and fetching inputs to publishing the merged spec (0 = no limit)")
+		maxDownload   = flag.Int64("max-download-size", 64<<20, "Maximum size of a remote input in bytes, and of the unpacked members of an archive (-1 = no limit)")
+		hubAPIKey     = flag.String("swaggerhub-api-key", "", "SwaggerHub API key for swaggerhub:// inputs (default: $SWAGGERHUB_API_KEY)")
+		hubURL        = flag.String("swaggerhub-url", "", "SwaggerHub registry API URL for on-premise installations")
+		publish       = flag.String("publish", "", "Publish the merged spec after merging (format: swaggerhub://owner/api/version)")
+		cacheControl  = flag.String("output-cache-control", "", "Cache-Control metadata for object storage outputs (s3://, gs://, azblob://)")
+		yamlIndent    = flag.Int("yaml-indent", 4, "Spaces per nesting level of YAML outputs (2 to 9)")
+		yamlWidth     = flag.Int("yaml-line-width", 0, "Wrap long strings of YAML outputs at spaces to fit this width (0: never)")
+		yamlQuote     = flag.String("yaml-quote", "minimal", "Quoting of YAML string values: minimal, single or double")
+		minify        = flag.Bool("minify", false, "Write JSON outputs without whitespace")
+		jsonIndent    = flag.Int("json-indent", 2, "Spaces per nesting level of JSON outputs (1 to 8)")
+		jsonASCII     = flag.Bool("json-ascii", false, "Escape non-ASCII characters of JSON outputs as \\uXXXX")
+		keyOrder      = flag.String("key-order", "sorted", "Order of the keys of outputs: sorted, or source to keep paths, properties and other keys in input order")
+		anchors       = flag.String("yaml-anchors", "expand", "Resolution of YAML aliases of inputs: expand, or components to turn aliased schemas, parameters and responses into $ref components")
+		keepComments  = flag.Bool("preserve-comments", false, "Carry top-of-file and inline comments of YAML inputs over to YAML outputs")
+		backup        = flag.Bool("backup", false, "Keep the previous version of every local output as <output>.bak")
+		compress      = flag.String("compress", "none", "Compress the outputs: none or gzip (appends .gz to output paths)")
+		push          = flag.String("push", "", "Push the merged spec as an OCI artifact (format: oci://registry/repository:tag)")
+		gitRepo       = flag.String("git-repo", "", "Commit and push the merged spec to this git repository")
+		gitBranch     = flag.String("git-branch", "", "Branch for --git-repo (default: repository default branch)")
+		gitPath       = flag.String("git-path", "openapi.yaml", "File path inside --git-repo")
+		gitMessage    = flag.String("git-message", "", "Commit message template for --git-repo ({{.Title}}, {{.Version}}, {{.Date}}, {{.Files}})")
+		discoverK8s   = flag.Bool("discover-kubernetes", false, "Discover specs from annotated Kubernetes services using kubectl")
+		kubeNS        = flag.String("kube-namespace", "", "Namespace for --discover-kubernetes (default: all namespaces)")
+		kubeContext   = flag.String("kube-context", "", "Kubeconfig context for --discover-kubernetes")
+		kubeAnno      = flag.String("kube-annotation", "openapi.path", "Annotation holding the spec path for --discover-kubernetes")
+		kubeIngress   = flag.Bool("kube-ingresses", false, "Also discover annotated ingresses with --discover-kubernetes")
+		consulAddr    = flag.String("consul-addr", "", "Discover specs from services registered in this Consul agent (e.g. http://consul:8500)")
+		consulToken   = flag.String("consul-token", "", "Consul ACL token for --consul-addr (default: $CONSUL_HTTP_TOKEN)")
+		consulDC      = flag.String("consul-datacenter", "", "Consul datacenter for --consul-addr")
+		eurekaURL     = flag.String("eureka-url", "", "Discover specs from applications registered in this Eureka server (e.g. http://eureka:8761/eureka)")
+		outputs       stringList
+		splitByTag    = flag.String("split-by-tag", "", "Also write one spec per tag, plus an index, to this directory")
+		splitByPath   = flag.String("split-by-path-segment", "", "Also write one spec per first path segment, plus an index, to this directory")
+		unbundle      = flag.String("unbundle", "", "Also write the merged spec to this directory as separate path and component files linked by relative $refs")
+		splitFormat   = flag.String("split-format", "yaml", "Format of split and unbundled specs: yaml or json")
+		promote       = flag.Bool("promote-inline-schemas", false, "Move large inline request/response schemas into components.schemas")
+		promoteName   = flag.String("promote-name-template", "{{.OperationID}}{{.Kind}}", "Name template for promoted schemas ({{.OperationID}}, {{.Method}}, {{.Path}}, {{.Status}}, {{.MediaType}}, {{.Kind}})")
+		promoteMin    = flag.Int("promote-min-properties", 3, "Number of properties from which an inline schema is promoted")
+		flatten       = flag.Bool("flatten", false, "Inline every $ref in the merged spec, producing a self-contained document")
+		dedupSchemas  = flag.Bool("dedup-schemas", false, "Collapse structurally identical schemas into one component")
+		genExamples   = flag.Bool("generate-examples", false, "Synthesize examples from schemas for request and response bodies without one")
+		descDir       = flag.String("descriptions", "", "Directory of Markdown descriptions (info.md, tags/<tag>.md, operations/<operationId>.md) injected into the merged spec")
+		errorResps    = flag.Bool("error-responses", false, "Add standard error responses to every operation missing them")
+		errorStatus   = flag.String("error-statuses", "400,401,403,500", "Comma-separated error statuses added by --error-responses")
+		errorSchema   = flag.String("error-schema", "ErrorResponse", "Error body schema referenced by --error-responses, added if missing")
+		mediaTypes    = flag.Bool("normalize-media-types", false, "Normalize body media types: lowercase, drop charset, map aliases and drop duplicate --media-type-drop types")
+		mediaDrop     = flag.String("media-type-drop", "text/plain", "Comma-separated media types --normalize-media-types drops from bodies offering another with the same schema")
+		commonParams  = flag.String("common-params", "", "YAML file listing parameters appended to every operation of the merged spec")
+		addSecurity   = flag.String("add-security", "", "Security scheme name applied as the document-level security requirement (e.g. bearerAuth)")
+		secScheme     = flag.String("security-scheme", "", "YAML file defining the --add-security scheme (default: existing definition or HTTP bearer JWT)")
+		secScopes     = flag.String("security-scopes", "", "Comma-separated scopes required by the --add-security requirement")
+		pruneSec      = flag.Bool("prune-security", false, "Remove security schemes no operation or global requirement uses")
+		tagOrder      = flag.String("tag-order", "as-merged", "Order of the merged tags: as-merged, alpha or file:<order.yaml> (a list of tag names pinned first)")
+		tagGroups     = flag.String("tag-groups", "", "Generate Redoc x-tagGroups: source (one group per input service) or file:<groups.yaml>")
+		apiVersions   = flag.Bool("api-versions", false, "Record the info.version of every input service in the x-api-versions extension")
+		lint          = flag.Bool("lint", false, "Lint the merged spec (operationId, 2xx response, summary, empty schemas, naming conventions)")
+		lintConfig    = flag.String("lint-config", "", "YAML file overriding lint rule severities (rules: {name: error|warn|info|off}) and naming casings (naming: {schemas, properties, paths})")
+		lintRuleset   = flag.String("lint-ruleset", "", "Spectral ruleset (YAML or JSON) whose supported rules are added to --lint")
+		lintFailOn    = flag.String("lint-fail-on", "error", "Exit with an error when lint findings reach this severity: error, warn, info or never")
+		checksum      = flag.Bool("checksum", false, "Write the SHA-256 of every output to <output>.sha256, verifiable with sha256sum -c")
+		embedSum      = flag.Bool("embed-checksum", false, "Embed the checksum of the merged spec in info.x-checksum")
+		semverBump    = flag.String("semver-bump", "", "Compare with the previous --output to suggest a semantic version bump (suggest), or also set it as info.version (apply)")
+		mockAddr      = flag.String("mock-addr", ":4010", "Listen address of the mock command")
+		proxyAddr     = flag.String("proxy-addr", ":8080", "Listen address of the proxy command")
+		validateEx    = flag.Bool("validate-examples", false, "Report body examples of the merged spec that do not match their schemas")
+		envelope      = flag.String("envelope", "", "Check 2xx JSON response bodies against the standard envelope: verify to report deviations, wrap to also wrap them")
+		envSchema     = flag.String("envelope-schema", "Envelope", "Component schema of the envelope, whose properties define it")
+		envProps      = flag.String("envelope-properties", "data,meta,errors", "Comma-separated envelope properties when the merged spec lacks --envelope-schema")
+		envData       = flag.String("envelope-data", "data", "Envelope property holding the original body of wrapped responses")
+		plugins       stringList
+		headers       stringList
+		urlHeaders    stringList
+		webhooks      stringList
+		slackHooks    stringList
+		commonHeaders stringList
+		proxyBackends stringList
+		secAliases    stringList
+		mediaAliases  stringList
+		outputVars    stringList
 	)
+	flag.Var(&outputs, "output", "Output file path or object storage URI (s3://, gs://, azblob://), with {version}, {date} and --output-var variables; .json outputs are written as JSON, can be repeated (default: merged_swagger.yaml)")
+	flag.Var(&plugins, "plugin", "External plugin to run (format: stage:command [args]), can be repeated")
+	flag.Var(&headers, "header", "Header sent when fetching remote inputs (format: 'Name: Value'), can be repeated")
+	flag.Var(&urlHeaders, "url-header", "Header sent to URLs with a prefix (format: 'url-prefix=Name: Value'), can be repeated")
+	flag.Var(&webhooks, "notify-webhook", "Webhook URL receiving a JSON notification after merging, can be repeated")
+	flag.Var(&slackHooks, "notify-slack", "Slack incoming webhook URL notified after merging, can be repeated")
+	flag.Var(&secAliases, "security-alias", "Rename security schemes to a canonical name (format: canonical=alias,alias), can be repeated")
+	flag.Var(&outputVars, "output-var", "Value of a {name} variable of --output paths, such as profile=public (format: name=value), can be repeated")
+	flag.Var(&mediaAliases, "media-type-alias", "Rename media types to a canonical one, implies --normalize-media-types (format: canonical=alias,alias), can be repeated (default: application/json=text/json)")
+	flag.Var(&commonHeaders, "common-header", "Header appended to every operation of the merged spec not declaring it, can be repeated")
+	flag.Var(&proxyBackends, "proxy-backend", "Backend of the proxy command (format: [/path-prefix=]url, the longest prefix wins), can be repeated")
 
-	flag.Parse()
+	// The mock, proxy and preview commands take the same flags, and serve
+	// the merged spec after merging
+	args := os.Args[1:]
+	command := ""
+	if len(args) > 0 && (args[0] == "mock" || args[0] == "proxy" || args[0] == "preview") {
+		command, args = args[0], args[1:]
+	}
+	flag.CommandLine.Parse(args)
+	writeOutputs := !serveCommands[command] || flagGiven("output")
 
 	// Show version
 	if *version {
-		fmt.Println("swagger-merger v1.0.0")
-		fmt.Println("A tool for merging multiple Swagger/OpenAPI files")
+		if err := printVersion(*versionJSON); err != nil {
+			fmt.Fprintf(os.Stderr, "Error printing version: %v\n", err)
+			os.Exit(1)
+		}
 		return
 	}
 
@@ -38,13 +181,64 @@ func main() {
 		return
 	}
 
+	level, err := parseLogLevel(*logLevel, *verbose, *quiet)
+	logger, formatErr := newConsoleLogger(level, *logFormat)
+	if err == nil {
+		err = formatErr
+	}
+	if err != nil {
+		logger.fatal(err.Error())
+	}
+	annotations, err := newAnnotator(*outputFormat)
+	if err != nil {
+		logger.fatal(err.Error())
+	}
+	backends, err := parseBackends(proxyBackends)
+	if err != nil {
+		logger.fatal(err.Error())
+	}
+	if command == "proxy" && len(backends) == 0 {
+		logger.fatal("--proxy-backend is required for the proxy command")
+	}
+
 	// Validate required flags
-	if *inputPaths == "" {
-		log.Fatal("❌ Error: --input flag is required")
+	if *inputPaths == "" && !*discoverK8s && *consulAddr == "" && *eurekaURL == "" {
+		logger.fatal("--input flag is required")
 	}
 
-	if *outputPath == "" {
-		log.Fatal("❌ Error: --output flag is required")
+	var outputPaths []string
+	for _, output := range outputs {
+		for _, path := range strings.Split(output, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				outputPaths = append(outputPaths, path)
+			}
+		}
+	}
+	if len(outputs) == 0 {
+		outputPaths = []string{"merged_swagger.yaml"}
+	}
+	if len(outputPaths) == 0 {
+		logger.fatal("--output flag is required")
+	}
+	switch *compress {
+	case "none":
+	case "gzip":
+		for i, output := range outputPaths {
+			outputPaths[i] = compressedPath(output)
+		}
+	default:
+		logger.fatal(fmt.Sprintf("invalid --compress %q: expected none or gzip", *compress))
+	}
+	var outputVariables map[string]string
+	for _, variable := range outputVars {
+		name, value, found := strings.Cut(variable, "=")
+		if name = strings.TrimSpace(name); !found || name == "" {
+			logger.fatal(fmt.Sprintf("invalid --output-var %q (format: name=value)", variable))
+		}
+		if outputVariables == nil {
+			outputVariables = make(map[string]string)
+		}
+		outputVariables[name] = value
 	}
 
 	// Parse servers
@@ -89,21 +283,305 @@ func main() {
 		serverConfigs = merger.DefaultServers()
 	}
 
+	// Configure remote fetching
+	httpConfig, err := buildHTTPConfig(headers, urlHeaders, *bearerToken, *basicAuth)
+	if err != nil {
+		logger.fatal(err.Error())
+	}
+	httpConfig.Retries = *retries
+	httpConfig.Proxy = *proxy
+	httpConfig.CAFile = *caFile
+	httpConfig.CertFile = *certFile
+	httpConfig.KeyFile = *keyFile
+	httpConfig.InsecureSkipVerify = *insecure
+	httpConfig.Timeout = *httpTimeout
+	httpConfig.TotalTimeout = *fetchTimeout
+	httpConfig.MaxSize = *maxDownload
+	httpConfig.RateLimit = *rateLimit
+	httpConfig.RateBurst = *rateBurst
+
+	// Build transformer pipeline
+	pipeline := merger.DefaultPipeline(serverConfigs)
+	if len(secAliases) > 0 {
+		aliases := make(map[string][]string)
+		for _, rule := range secAliases {
+			canonical, names, found := strings.Cut(rule, "=")
+			canonical = strings.TrimSpace(canonical)
+			if !found || canonical == "" || len(splitList(names)) == 0 {
+				logger.fatal(fmt.Sprintf("invalid --security-alias %q (format: canonical=alias,alias)", rule))
+			}
+			aliases[canonical] = append(aliases[canonical], splitList(names)...)
+		}
+		pipeline.Add(merger.StageInput, merger.NormalizeSecuritySchemes(aliases))
+	}
+	if *renamesFile != "" {
+		data, err := os.ReadFile(*renamesFile)
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Error reading renames: %v", err))
+		}
+		renames, err := merger.ParseRenames(data)
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Invalid renames %s: %v", *renamesFile, err))
+		}
+		pipeline.Add(merger.StageInput, merger.RenameComponents(renames))
+		if *renameAlias {
+			pipeline.Add(merger.StageMerged, merger.RenameAliases(renames))
+		}
+	} else if *renameAlias {
+		logger.fatal("--rename-aliases requires --renames")
+	}
+	var prompt *resolutionPrompt
+	if *interactive || *resolutions != "" {
+		if *interactive && *ci {
+			logger.fatal("--interactive cannot be used with --ci")
+		}
+		var saved []merger.Resolution
+		if *resolutions != "" {
+			saved, err = readResolutions(*resolutions, *interactive)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Error reading resolutions %s: %v", *resolutions, err))
+			}
+		}
+		prompt = newResolutionPrompt(os.Stdin, os.Stderr, saved)
+		if !*interactive {
+			prompt.skipAll = true
+		}
+	}
+	for _, plugin := range plugins {
+		stage, command, found := strings.Cut(plugin, ":")
+		fields := strings.Fields(command)
+		if !found || len(fields) == 0 || (stage != string(merger.StageInput) && stage != string(merger.StageMerged)) {
+			logger.fatal(fmt.Sprintf("invalid --plugin %q (format: input|merged:command [args])", plugin))
+		}
+		pipeline.Add(merger.Stage(stage), merger.ExecPlugin(fields[0], fields[1:]...))
+	}
+	if *mediaTypes || len(mediaAliases) > 0 {
+		opts := merger.MediaTypeOptions{Drop: splitList(*mediaDrop)}
+		if opts.Drop == nil {
+			opts.Drop = []string{}
+		}
+		for _, rule := range mediaAliases {
+			canonical, names, found := strings.Cut(rule, "=")
+			canonical = strings.TrimSpace(canonical)
+			if !found || canonical == "" || len(splitList(names)) == 0 {
+				logger.fatal(fmt.Sprintf("invalid --media-type-alias %q (format: canonical=alias,alias)", rule))
+			}
+			if opts.Aliases == nil {
+				opts.Aliases = make(map[string]string)
+			}
+			for _, name := range splitList(names) {
+				opts.Aliases[name] = canonical
+			}
+		}
+		transformer, err := merger.NormalizeMediaTypes(opts)
+		if err != nil {
+			logger.fatal(err.Error())
+		}
+		pipeline.Add(merger.StageMerged, transformer)
+	}
+	if *promote {
+		transformer, err := merger.PromoteInlineSchemas(merger.PromoteOptions{NameTemplate: *promoteName, MinProperties: *promoteMin})
+		if err != nil {
+			logger.fatal(err.Error())
+		}
+		pipeline.Add(merger.StageMerged, transformer)
+	}
+	if *dedupSchemas {
+		pipeline.Add(merger.StageMerged, merger.DedupSchemas())
+	}
+	if *descDir != "" {
+		transformer, err := merger.InjectDescriptions(*descDir)
+		if err != nil {
+			logger.fatal(err.Error())
+		}
+		pipeline.Add(merger.StageMerged, transformer)
+	}
+	if len(commonHeaders) > 0 || *commonParams != "" {
+		params := merger.HeaderParameters(commonHeaders...)
+		if *commonParams != "" {
+			data, err := os.ReadFile(*commonParams)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Error reading common parameters: %v", err))
+			}
+			fileParams, err := merger.ParseParameters(data)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Invalid common parameters %s: %v", *commonParams, err))
+			}
+			params = append(params, fileParams...)
+		}
+		pipeline.Add(merger.StageMerged, merger.CommonParameters(params))
+	}
+	if *addSecurity != "" {
+		scopes := splitList(*secScopes)
+		transformer := merger.GlobalSecurity(*addSecurity, nil, scopes...)
+		if *secScheme != "" {
+			data, err := os.ReadFile(*secScheme)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Error reading security scheme: %v", err))
+			}
+			scheme, err := merger.ParseSecurityScheme(data)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Invalid security scheme %s: %v", *secScheme, err))
+			}
+			transformer = merger.GlobalSecurity(*addSecurity, scheme, scopes...)
+		}
+		pipeline.Add(merger.StageMerged, transformer)
+	}
+	if *errorResps {
+		transformer, err := merger.StandardErrorResponses(merger.ErrorResponsesOptions{
+			Statuses: splitList(*errorStatus),
+			Schema:   *errorSchema,
+		})
+		if err != nil {
+			logger.fatal(err.Error())
+		}
+		pipeline.Add(merger.StageMerged, transformer)
+	}
+	if *genExamples {
+		pipeline.Add(merger.StageMerged, merger.GenerateExamples())
+	}
+	if *pruneSec {
+		pipeline.Add(merger.StageMerged, merger.PruneSecuritySchemes())
+	}
+	switch {
+	case *tagOrder == "alpha":
+		pipeline.Add(merger.StageMerged, merger.SortTags())
+	case strings.HasPrefix(*tagOrder, "file:"):
+		orderFile := strings.TrimPrefix(*tagOrder, "file:")
+		data, err := os.ReadFile(orderFile)
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Error reading tag order: %v", err))
+		}
+		order, err := merger.ParseTagOrder(data)
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Invalid tag order %s: %v", orderFile, err))
+		}
+		pipeline.Add(merger.StageMerged, merger.OrderTags(order))
+	case *tagOrder != "as-merged":
+		logger.fatal(fmt.Sprintf("invalid --tag-order %q (expected as-merged, alpha or file:<order.yaml>)", *tagOrder))
+	}
+	if *apiVersions {
+		input, merged := merger.APIVersions()
+		pipeline.Add(merger.StageInput, input)
+		pipeline.Add(merger.StageMerged, merged)
+	}
+	switch {
+	case *tagGroups == "source":
+		input, merged := merger.TagGroupsBySource()
+		pipeline.Add(merger.StageInput, input)
+		pipeline.Add(merger.StageMerged, merged)
+	case strings.HasPrefix(*tagGroups, "file:"):
+		groupsFile := strings.TrimPrefix(*tagGroups, "file:")
+		data, err := os.ReadFile(groupsFile)
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Error reading tag groups: %v", err))
+		}
+		groups, err := merger.ParseTagGroups(data)
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Invalid tag groups %s: %v", groupsFile, err))
+		}
+		pipeline.Add(merger.StageMerged, merger.TagGroups(groups))
+	case *tagGroups != "":
+		logger.fatal(fmt.Sprintf("invalid --tag-groups %q (expected source or file:<groups.yaml>)", *tagGroups))
+	}
+	if *flatten {
+		pipeline.Add(merger.StageMerged, merger.Flatten())
+	}
+
+	var linter *merger.Linter
+	if *lint || *lintConfig != "" || *lintRuleset != "" {
+		var config merger.LintConfig
+		if *lintConfig != "" {
+			data, err := os.ReadFile(*lintConfig)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Error reading lint config: %v", err))
+			}
+			if config, err = merger.ParseLintConfig(data); err != nil {
+				logger.fatal(fmt.Sprintf("Invalid lint config %s: %v", *lintConfig, err))
+			}
+		}
+		var err error
+		if linter, err = merger.NewLinter(config); err != nil {
+			logger.fatal(fmt.Sprintf("Invalid lint config: %v", err))
+		}
+		if *lintRuleset != "" {
+			data, err := os.ReadFile(*lintRuleset)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Error reading lint ruleset: %v", err))
+			}
+			skipped, err := linter.AddSpectralRuleset(data)
+			if err != nil {
+				logger.fatal(fmt.Sprintf("Invalid lint ruleset %s: %v", *lintRuleset, err))
+			}
+			if len(skipped) > 0 {
+				logger.Warn(fmt.Sprintf("Skipped unsupported ruleset rules: %s", strings.Join(skipped, ", ")))
+			}
+		}
+	}
+	failOn := merger.Severity(*lintFailOn)
+	switch failOn {
+	case "never":
+		failOn = merger.SeverityOff
+	case merger.SeverityError, merger.SeverityWarn, merger.SeverityInfo:
+	default:
+		logger.fatal(fmt.Sprintf("invalid --lint-fail-on %q (expected error, warn, info or never)", *lintFailOn))
+	}
+
 	// Create merger config
 	config := merger.Config{
-		OutputPath: *outputPath,
-		Servers:    serverConfigs,
+		OutputPath:         outputPaths[0],
+		Outputs:            outputPaths[1:],
+		OutputVariables:    outputVariables,
+		Servers:            serverConfigs,
+		Pipeline:           pipeline,
+		Logger:             logger.library(),
+		SkipInvalid:        *skipInvalid,
+		CacheDir:           *cacheDir,
+		HTTP:               httpConfig,
+		Concurrency:        *concurrency,
+		Profile:            *profile,
+		SwaggerHub:         merger.SwaggerHubConfig{APIKey: *hubAPIKey, URL: *hubURL},
+		OutputCacheControl: *cacheControl,
+		VersionBump:        merger.VersionBumpMode(*semverBump),
+		ChecksumSidecar:    *checksum,
+		EmbedChecksum:      *embedSum,
+		BackupOutputs:      *backup,
+		Envelope: merger.EnvelopeOptions{
+			Mode:       merger.EnvelopeMode(*envelope),
+			Schema:     *envSchema,
+			Properties: splitList(*envProps),
+			Data:       *envData,
+		},
+		KeyOrder: merger.KeyOrder(*keyOrder),
+		JSON: merger.JSONStyle{
+			Indent: *jsonIndent,
+			Minify: *minify,
+			ASCII:  *jsonASCII,
+		},
+		PreserveComments: *keepComments,
+		Anchors:          merger.AnchorPolicy(*anchors),
+		YAML: merger.YAMLStyle{
+			Indent:    *yamlIndent,
+			LineWidth: *yamlWidth,
+			Quote:     merger.QuoteStyle(*yamlQuote),
+		},
+	}
+	if config.SwaggerHub.APIKey == "" {
+		config.SwaggerHub.APIKey = os.Getenv("SWAGGERHUB_API_KEY")
+	}
+	if *progress {
+		config.Progress = printProgress
 	}
 
 	// Create merger instance
 	mergerInstance := merger.New(config)
 
 	// Parse input paths
-	inputPathList := strings.Split(*inputPaths, ",")
+	inputPathList := splitList(*inputPaths)
 	var allInputPaths []string
 
 	// Parse patterns
-	patterns := strings.Split(*pattern, ",")
+	patterns := splitList(*pattern)
 	for i, p := range patterns {
 		patterns[i] = strings.TrimSpace(p)
 	}
@@ -114,100 +592,369 @@ func main() {
 			continue
 		}
 
+		// Remote inputs are fetched by the merger
+		if merger.IsRemoteSource(inputPath) {
+			allInputPaths = append(allInputPaths, inputPath)
+			logger.Debug(fmt.Sprintf("🌐 Remote input: %s", inputPath))
+			continue
+		}
+
+		// Globs are expanded by the merger
+		if merger.IsGlob(inputPath) {
+			allInputPaths = append(allInputPaths, inputPath)
+			logger.Debug(fmt.Sprintf("🔎 Glob input: %s", inputPath))
+			continue
+		}
+
 		// Check if it's a directory
 		info, err := os.Stat(inputPath)
 		if err != nil {
-			log.Printf("⚠️  Warning: Cannot access %s: %v", inputPath, err)
+			logger.Warn(fmt.Sprintf("Cannot access %s: %v", inputPath, err))
 			continue
 		}
 
 		if info.IsDir() {
-			// Scan directory for swagger files
-			if *verbose {
-				fmt.Printf("📁 Scanning directory: %s\n", inputPath)
-			}
-
-			err := filepath.Walk(inputPath, func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-
-				if !info.IsDir() {
-					// Check if file matches any of the patterns
-					matched := false
-					for _, pattern := range patterns {
-						patternMatched, err := filepath.Match(pattern, filepath.Base(path))
-						if err != nil {
-							continue
-						}
-						if patternMatched {
-							matched = true
-							break
-						}
-					}
-
-					if matched {
-						allInputPaths = append(allInputPaths, path)
-						if *verbose {
-							fmt.Printf("  📄 Found: %s\n", path)
-						}
-					}
-				}
-				return nil
-			})
-
-			if err != nil {
-				log.Printf("⚠️  Warning: Error scanning directory %s: %v", inputPath, err)
-			}
+			// Directories are scanned by the merger using the patterns
+			allInputPaths = append(allInputPaths, inputPath)
+			logger.Debug(fmt.Sprintf("📁 Scanning directory: %s", inputPath))
 		} else {
 			// Single file
 			allInputPaths = append(allInputPaths, inputPath)
-			if *verbose {
-				fmt.Printf("📄 Input file: %s\n", inputPath)
-			}
+			logger.Debug(fmt.Sprintf("📄 Input file: %s", inputPath))
+		}
+	}
+
+	// The timeout covers discovery, the merge, notifications and publishing
+	ctx := context.Background()
+	if *mergeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, *mergeTimeout, fmt.Errorf("merge timeout of %v exceeded", *mergeTimeout))
+		defer cancel()
+	}
+
+	// Discover specs served in a Kubernetes cluster
+	if *discoverK8s {
+		urls, err := merger.DiscoverKubernetesContext(ctx, merger.KubernetesDiscovery{
+			Namespace:  *kubeNS,
+			Context:    *kubeContext,
+			Annotation: *kubeAnno,
+			Ingresses:  *kubeIngress,
+			Logger:     logger.library(),
+		})
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Error discovering Kubernetes services: %v", err))
+		}
+		for _, url := range urls {
+			allInputPaths = append(allInputPaths, url)
+			logger.Debug(fmt.Sprintf("☸️  Discovered: %s", url))
+		}
+	}
+
+	// Discover specs from service registries
+	if *consulAddr != "" {
+		token := *consulToken
+		if token == "" {
+			token = os.Getenv("CONSUL_HTTP_TOKEN")
+		}
+		urls, err := mergerInstance.DiscoverConsulContext(ctx, merger.ConsulDiscovery{Address: *consulAddr, Token: token, Datacenter: *consulDC})
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Error discovering Consul services: %v", err))
+		}
+		for _, url := range urls {
+			allInputPaths = append(allInputPaths, url)
+			logger.Debug(fmt.Sprintf("🧭 Discovered: %s", url))
+		}
+		config.HTTP.Anonymous = append(config.HTTP.Anonymous, urls...)
+	}
+	if *eurekaURL != "" {
+		urls, err := mergerInstance.DiscoverEurekaContext(ctx, merger.EurekaDiscovery{URL: *eurekaURL})
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Error discovering Eureka applications: %v", err))
+		}
+		for _, url := range urls {
+			allInputPaths = append(allInputPaths, url)
+			logger.Debug(fmt.Sprintf("🧭 Discovered: %s", url))
 		}
+		config.HTTP.Anonymous = append(config.HTTP.Anonymous, urls...)
 	}
 
 	if len(allInputPaths) == 0 {
-		log.Fatal("❌ Error: No valid input files found")
+		logger.fatal("No valid input files found")
 	}
 
 	// Update config with found files
 	config.InputPaths = allInputPaths
+	config.Scan.Patterns = patterns
+	config.Scan.IgnoreFile = *ignoreFile
+	config.Scan.MaxDepth = *maxDepth
+	if *noRecursive {
+		config.Scan.MaxDepth = 1
+	}
+	config.Scan.Symlinks = merger.SymlinkMode(*symlinks)
+	config.Order = merger.InputOrder(*order)
+	config.SchemaStrategy = merger.SchemaStrategy(*schemaMode)
+	config.IgnoreSchemaDocs = *schemaDocs
+	config.EnumUnion = *enumUnion
+	if prompt != nil {
+		config.Resolver = prompt.resolve
+	}
+	config.ExternalDocs = merger.ExternalDocsPolicy(*extDocs)
+	config.Extensions = merger.ExtensionPolicy(*extPolicy)
 	mergerInstance = merger.New(config)
 
 	// Perform merge
-	if *verbose {
-		fmt.Printf("🔄 Merging %d files...\n", len(allInputPaths))
+	logger.Debug(fmt.Sprintf("🔄 Merging %d inputs...", len(allInputPaths)))
+
+	notifyConfig := merger.NotifyConfig{Webhooks: webhooks, Slack: slackHooks}
+	var mergeStats map[string]int
+	if writeOutputs {
+		mergeStats, err = mergerInstance.MergeWithStatsContext(ctx)
+	} else if _, err = mergerInstance.MergeToDocumentContext(ctx); err == nil {
+		mergeStats = mergerInstance.Stats()
+	}
+	if notifyErr := mergerInstance.NotifyContext(ctx, notifyConfig, err); notifyErr != nil {
+		logger.Warn(fmt.Sprintf("Error sending notifications: %v", notifyErr))
+	}
+	checks := &gate{ci: *ci}
+	if prompt != nil && *interactive && *resolutions != "" {
+		if saved, saveErr := prompt.saveResolutions(*resolutions); saveErr != nil {
+			logger.Warn(fmt.Sprintf("Error saving resolutions: %v", saveErr))
+		} else if saved > 0 {
+			logger.Info(fmt.Sprintf("📝 Saved %d resolutions to %s", saved, *resolutions))
+		}
+	}
+	if err != nil {
+		annotations.inputErrors(err)
+		logger.Error(fmt.Sprintf("Error merging files: %v", err))
+		logger.exit(checks.exitCode(mergeExitCode(err)))
 	}
 
-	if err := mergerInstance.Merge(); err != nil {
-		log.Fatalf("❌ Error merging files: %v", err)
+	skipped := mergerInstance.Skipped()
+	if writeOutputs {
+		logger.Info(fmt.Sprintf("✅ Successfully merged %d files to: %s", mergeStats["total_files"], strings.Join(mergerInstance.OutputPaths(), ", ")))
+	} else {
+		logger.Info(fmt.Sprintf("✅ Successfully merged %d files", mergeStats["total_files"]))
+	}
+	if suggestion := mergerInstance.VersionSuggestion(); suggestion != nil {
+		if suggestion.To != "" {
+			logger.Info(fmt.Sprintf("🔖 Suggested version bump: %s (%s → %s)", suggestion.Bump, suggestion.From, suggestion.To))
+		} else {
+			logger.Info(fmt.Sprintf("🔖 Suggested version bump: %s (%q is not a semantic version)", suggestion.Bump, suggestion.From))
+		}
+		for _, change := range suggestion.Changes {
+			logger.Info(fmt.Sprintf("  - %s", change))
+		}
 	}
 
-	fmt.Printf("✅ Successfully merged %d files to: %s\n", len(allInputPaths), *outputPath)
+	if len(skipped) > 0 {
+		logger.Warn(fmt.Sprintf("Skipped %d invalid inputs:", len(skipped)))
+		for _, err := range skipped {
+			logger.Warn(fmt.Sprintf("  - %v", err))
+		}
+		annotations.inputErrors(skipped)
+		if *ci {
+			checks.fail(exitValidation, fmt.Sprintf("%d inputs are invalid", len(skipped)))
+		}
+	}
+	if renames := mergerInstance.SchemaRenames(); len(renames) > 0 {
+		logger.Info(fmt.Sprintf("🏷️  Renamed %d colliding schemas:", len(renames)))
+		for _, rename := range renames {
+			logger.Info(fmt.Sprintf("  - %s", rename))
+		}
+	}
+	if unions := mergerInstance.EnumUnions(); len(unions) > 0 {
+		logger.Info(fmt.Sprintf("🔀 Unioned the enum values of %d schemas:", len(unions)))
+		for _, union := range unions {
+			logger.Info(fmt.Sprintf("  - %s", union))
+		}
+	}
+	annotations.conflicts(mergerInstance.Conflicts())
+	if conflicts := len(mergerInstance.Conflicts()); *maxConflicts >= 0 && conflicts > *maxConflicts {
+		checks.fail(exitConflicts, fmt.Sprintf("%d conflicts exceed the limit of %d", conflicts, *maxConflicts))
+	}
 
-	// Show statistics if requested
-	if *stats {
-		stats, err := mergerInstance.GetStats()
+	// Check examples against their schemas
+	if *validateEx {
+		issues, err := mergerInstance.ValidateExamples()
 		if err != nil {
-			log.Printf("⚠️  Warning: Could not get statistics: %v", err)
+			logger.fatal(fmt.Sprintf("Error validating examples: %v", err))
+		}
+		if len(issues) > 0 {
+			logger.Warn(fmt.Sprintf("Found %d examples not matching their schemas:", len(issues)))
+			for _, issue := range issues {
+				logger.Warn(fmt.Sprintf("  - %s", issue))
+			}
+			annotations.exampleIssues(issues)
+			if *ci {
+				checks.fail(exitValidation, fmt.Sprintf("%d examples do not match their schemas", len(issues)))
+			}
 		} else {
-			fmt.Println("📊 Statistics:")
-			fmt.Printf("  Total files: %d\n", stats["total_files"])
-			fmt.Printf("  Total paths: %d\n", stats["total_paths"])
-			fmt.Printf("  Total schemas: %d\n", stats["total_schemas"])
-			fmt.Printf("  Total tags: %d\n", stats["total_tags"])
+			logger.Info("🧪 All examples match their schemas")
 		}
 	}
 
-	// Show server information
-	if *verbose {
-		fmt.Println("🌐 Configured servers:")
-		for i, server := range serverConfigs {
-			fmt.Printf("  %d. %s (%s)\n", i+1, server.URL, server.Description)
+	// Report response bodies deviating from the envelope
+	if *envelope != "" {
+		deviations := mergerInstance.EnvelopeDeviations()
+		switch {
+		case len(deviations) == 0:
+			logger.Info("✉️  All 2xx responses use the envelope")
+		case merger.EnvelopeMode(*envelope) == merger.EnvelopeWrap:
+			logger.Info(fmt.Sprintf("✉️  Wrapped %d responses in the envelope:", len(deviations)))
+			for _, deviation := range deviations {
+				logger.Info(fmt.Sprintf("  - %s", deviation))
+			}
+		default:
+			logger.Warn(fmt.Sprintf("Found %d responses not using the envelope:", len(deviations)))
+			for _, deviation := range deviations {
+				logger.Warn(fmt.Sprintf("  - %s", deviation))
+			}
+			annotations.envelopeDeviations(deviations)
+			if *ci {
+				checks.fail(exitValidation, fmt.Sprintf("%d responses do not use the envelope", len(deviations)))
+			}
+		}
+	}
+
+	if linter != nil {
+		findings, err := mergerInstance.Lint(linter)
+		if err != nil {
+			logger.fatal(fmt.Sprintf("Error linting: %v", err))
+		}
+		if len(findings) > 0 {
+			logger.Warn(fmt.Sprintf("Found %d lint findings:", len(findings)))
+			for _, finding := range findings {
+				logger.Warn(fmt.Sprintf("  - %s", finding))
+			}
+			annotations.lintFindings(findings)
+		} else {
+			logger.Info("🧹 No lint findings")
+		}
+		if failing := merger.CountFindings(findings, failOn); failing > 0 {
+			checks.fail(exitLint, fmt.Sprintf("Lint failed: %d findings at %s severity or above", failing, failOn))
+		}
+		warnings := merger.CountFindings(findings, merger.SeverityWarn) - merger.CountFindings(findings, merger.SeverityError)
+		if *maxWarnings >= 0 && warnings > *maxWarnings {
+			checks.fail(exitWarnings, fmt.Sprintf("%d lint warnings exceed the limit of %d", warnings, *maxWarnings))
 		}
 	}
+	checks.check(logger)
+
+	// Split the merged document
+	if *splitByTag != "" {
+		if err := mergerInstance.WriteSplit(*splitByTag, merger.SplitByTag, merger.OutputFormat(*splitFormat)); err != nil {
+			logger.fatal(fmt.Sprintf("Error splitting merged spec: %v", err))
+		}
+		logger.Info(fmt.Sprintf("✂️  Wrote per-tag specs to: %s", *splitByTag))
+	}
+	if *splitByPath != "" {
+		if err := mergerInstance.WriteSplit(*splitByPath, merger.SplitByPathSegment, merger.OutputFormat(*splitFormat)); err != nil {
+			logger.fatal(fmt.Sprintf("Error splitting merged spec: %v", err))
+		}
+		logger.Info(fmt.Sprintf("✂️  Wrote per-path-segment specs to: %s", *splitByPath))
+	}
+
+	// Write the merged document as multiple files
+	if *unbundle != "" {
+		if err := mergerInstance.WriteUnbundled(*unbundle, merger.OutputFormat(*splitFormat)); err != nil {
+			logger.fatal(fmt.Sprintf("Error unbundling merged spec: %v", err))
+		}
+		logger.Info(fmt.Sprintf("🗂️  Wrote unbundled spec to: %s", *unbundle))
+	}
+
+	// Publish the merged document
+	if !writeOutputs && (*publish != "" || *push != "" || *gitRepo != "") {
+		logger.Warn(fmt.Sprintf("Not publishing the merged spec: the %s command only publishes it with --output", command))
+	}
+	if writeOutputs && *publish != "" {
+		if err := mergerInstance.PublishToSwaggerHubContext(ctx, *publish); err != nil {
+			logger.fatal(fmt.Sprintf("Error publishing merged spec: %v", err))
+		}
+		logger.Info(fmt.Sprintf("🚀 Published merged spec to: %s", *publish))
+	}
+
+	// Push the merged document to an OCI registry
+	if writeOutputs && *push != "" {
+		if err := mergerInstance.PushOCIContext(ctx, *push); err != nil {
+			logger.fatal(fmt.Sprintf("Error pushing merged spec: %v", err))
+		}
+		logger.Info(fmt.Sprintf("📦 Pushed merged spec to: %s", *push))
+	}
+
+	// Commit the merged document to a git repository
+	if writeOutputs && *gitRepo != "" {
+		target := merger.GitTarget{Repo: *gitRepo, Branch: *gitBranch, Path: *gitPath, Message: *gitMessage}
+		if err := mergerInstance.CommitToGitContext(ctx, target); err != nil {
+			logger.fatal(fmt.Sprintf("Error committing merged spec: %v", err))
+		}
+		logger.Info(fmt.Sprintf("📝 Committed merged spec to: %s", *gitRepo))
+	}
+
+	// Show statistics if requested
+	if *stats || *profile {
+		fmt.Println("📊 Statistics:")
+		fmt.Printf("  Total files: %d\n", mergeStats["total_files"])
+		fmt.Printf("  Total paths: %d\n", mergeStats["total_paths"])
+		fmt.Printf("  Total schemas: %d\n", mergeStats["total_schemas"])
+		fmt.Printf("  Total tags: %d\n", mergeStats["total_tags"])
+		fmt.Printf("  Total conflicts: %d\n", mergeStats["total_conflicts"])
+		for _, conflict := range mergerInstance.Conflicts() {
+			fmt.Printf("    - %s\n", conflict)
+		}
+		printProfile(mergerInstance.Profile())
+	}
+
+	// Show server information
+	logger.Debug("🌐 Configured servers:")
+	for i, server := range serverConfigs {
+		logger.Debug(fmt.Sprintf("  %d. %s (%s)", i+1, server.URL, server.Description))
+	}
+
+	switch command {
+	case "mock":
+		serveMock(logger, mergerInstance.Document(), *mockAddr)
+	case "proxy":
+		serveProxy(logger, mergerInstance.Document(), *proxyAddr, backends)
+	case "preview":
+		previewDocument(logger, mergerInstance.Document())
+	}
+}
+
+// printProgress prints merge progress events to stderr
+func printProgress(event merger.ProgressEvent) {
+	if event.Source != "" && event.Current > 0 {
+		fmt.Fprintf(os.Stderr, "⏳ [%d/%d] %s %s\n", event.Current, event.Total, event.Stage, event.Source)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⏳ %s\n", event.Stage)
+}
+
+// printProfile prints the phase timings and memory use of a profiled merge
+func printProfile(profile *merger.Profile) {
+	if profile == nil {
+		return
+	}
+	fmt.Println("⏱️  Profile:")
+	for _, phase := range merger.ProfilePhases {
+		fmt.Printf("  %-8s %v\n", phase+":", profile.Phases[phase].Round(100*time.Microsecond))
+	}
+	fmt.Printf("  Total: %v\n", profile.Total.Round(100*time.Microsecond))
+	fmt.Printf("  Peak heap: %s\n", formatBytes(profile.PeakHeap))
+	fmt.Printf("  Allocated: %s\n", formatBytes(profile.Allocated))
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
 }
 
 func showHelp() {
@@ -215,16 +962,137 @@ func showHelp() {
 	fmt.Println("")
 	fmt.Println("Usage:")
 	fmt.Println("  swagger-merger [flags]")
+	fmt.Println("  swagger-merger mock [flags]     Merge, then serve stub responses for the merged spec on --mock-addr (writes and publishes only with --output)")
+	fmt.Println("  swagger-merger proxy [flags]    Merge, then proxy --proxy-addr to --proxy-backend, logging contract violations (writes and publishes only with --output)")
+	fmt.Println("  swagger-merger preview [flags]  Merge, then browse the merged spec in the terminal: paths by tag and expandable schemas (writes and publishes only with --output)")
 	fmt.Println("")
 	fmt.Println("Flags:")
-	fmt.Println("  --input string     Comma-separated list of input swagger files or directories")
-	fmt.Println("  --output string    Output file path (default: merged_swagger.yaml)")
-	fmt.Println("  --pattern string   File pattern for directory scanning (default: *.yaml, supports comma-separated patterns)")
-	fmt.Println("  --servers string   Comma-separated list of server URLs (format: url:description)")
-	fmt.Println("  --version          Show version information")
-	fmt.Println("  --help             Show this help message")
-	fmt.Println("  --verbose          Enable verbose output")
-	fmt.Println("  --stats            Show statistics after merging")
+	fmt.Println("  --input string                  Comma-separated list of input swagger files or directories")
+	fmt.Println("  --output string                 Output file path or object storage URI (s3://, gs://, azblob://); .json outputs are JSON, repeatable (default: merged_swagger.yaml)")
+	fmt.Println("  --output-var string             Value of a {name} variable of --output paths besides {version} and {date} (format: name=value), repeatable")
+	fmt.Println("  --output-cache-control string   Cache-Control metadata for object storage outputs")
+	fmt.Println("  --yaml-indent int               Spaces per nesting level of YAML outputs, 2 to 9 (default: 4)")
+	fmt.Println("  --yaml-line-width int           Wrap long strings of YAML outputs at spaces to fit this width (default: 0, never)")
+	fmt.Println("  --yaml-quote string             Quoting of YAML string values: minimal, single or double (default: minimal)")
+	fmt.Println("  --minify                        Write JSON outputs without whitespace")
+	fmt.Println("  --json-indent int               Spaces per nesting level of JSON outputs, 1 to 8 (default: 2)")
+	fmt.Println("  --json-ascii                    Escape non-ASCII characters of JSON outputs as \\uXXXX")
+	fmt.Println("  --key-order string              Order of the keys of outputs: sorted, or source to keep input order (default: sorted)")
+	fmt.Println("  --yaml-anchors string           Resolution of YAML aliases of inputs: expand, or components to share them as $refs (default: expand)")
+	fmt.Println("  --preserve-comments             Carry top-of-file and inline comments of YAML inputs over to YAML outputs")
+	fmt.Println("  --backup                        Keep the previous version of every local output as <output>.bak")
+	fmt.Println("  --compress string               Compress the outputs: none or gzip, appending .gz to their paths (default: none)")
+	fmt.Println("  --pattern string                File pattern for directory and archive scanning (default: *.yaml, supports **, {a,b} and comma-separated patterns)")
+	fmt.Println("  --ignore-file string            Gitignore-style files excluding paths from directory scanning, - to disable (default: .swaggerignore)")
+	fmt.Println("  --no-recursive                  Only scan the top level of directory inputs")
+	fmt.Println("  --max-depth int                 Maximum directory depth scanned, 1 being the top level (default: 0, no limit)")
+	fmt.Println("  --symlinks string               Symlink handling for directory inputs: files (follow links to files only), follow (with loop detection) or skip (default: files)")
+	fmt.Println("  --order string                  Merge order of inputs; later inputs win conflicts, the first provides info: as-given, alpha or mtime (default: as-given)")
+	fmt.Println("  --schema-strategy string        Combine schemas defined by several inputs: overwrite (last wins), union of properties (failing on type conflicts) strict (failing with a diff unless equal) or rename (differing redefinitions get a content hash suffix) (default: overwrite)")
+	fmt.Println("  --external-docs string          Top-level and tag externalDocs: first, per-tag (each input's docs on its tags) or drop (default: first)")
+	fmt.Println("  --extensions string             Merge x-* extensions: override (later input wins per key), union (deep merge) or error (default: override)")
+	fmt.Println("  --renames string                YAML file of source:OldName: NewName entries renaming input components, with refs rewritten")
+	fmt.Println("  --rename-aliases                Keep the old names of renamed schemas as deprecated aliases (allOf of the new schema)")
+	fmt.Println("  --schema-ignore-docs            Ignore titles, descriptions and examples when comparing schemas in strict mode")
+	fmt.Println("  --enum-union                    Union the values of schemas several inputs define as enums of the same type, reporting added values")
+	fmt.Println("  --interactive                   Resolve path and schema conflicts one by one with a side-by-side diff: keep either definition or rename the schema")
+	fmt.Println("  --resolutions string            Saved conflict resolutions applied without asking, updated with the decisions of --interactive")
+	fmt.Println("  --servers string                Comma-separated list of server URLs (format: url:description)")
+	fmt.Println("  --version                       Show version, commit, build date and kin-openapi version")
+	fmt.Println("  --json                          Print --version as JSON")
+	fmt.Println("  --help                          Show this help message")
+	fmt.Println("  --verbose                       Enable verbose output (alias of --log-level debug)")
+	fmt.Println("  --quiet                         Only print errors (alias of --log-level error)")
+	fmt.Println("  --log-level string              Minimum level of log messages: debug, info, warn or error (default: info)")
+	fmt.Println("  --ci                            Fail on invalid inputs and examples too, exiting with 2 (merge), 3 (validation), 4 (conflicts), 5 (lint), 6 (warnings)")
+	fmt.Println("  --log-format string             Log format: text, or json for structured events (processed input, conflict detected, merge complete) on stderr")
+	fmt.Println("  --output-format string          Diagnostics format: text, or github to also print conflicts, invalid inputs and lint findings as ::error/::warning annotations")
+	fmt.Println("  --max-conflicts int             Fail when the merge reports more conflicts than this, e.g. 0 (default: no limit)")
+	fmt.Println("  --max-warnings int              Fail when linting reports more warnings than this (default: no limit)")
+	fmt.Println("  --stats                         Show statistics after merging")
+	fmt.Println("  --profile                       Show the time spent fetching, parsing, converting, merging, marshaling and writing, and the peak memory, with the statistics")
+	fmt.Println("  --progress                      Show per-file progress while merging")
+	fmt.Println("  --skip-invalid                  Skip unreadable or invalid inputs instead of failing")
+	fmt.Println("  --cache-dir string              Directory for caching converted inputs between runs")
+	fmt.Println("  --header string                 Header sent when fetching remote inputs (format: 'Name: Value', repeatable)")
+	fmt.Println("  --url-header string             Header sent to URLs with a prefix (format: 'url-prefix=Name: Value', repeatable)")
+	fmt.Println("  --bearer-token string           Bearer token for fetching remote inputs (default: $SWAGGER_MERGER_TOKEN)")
+	fmt.Println("  --basic-auth string             Basic auth credentials for fetching remote inputs (format: user:password)")
+	fmt.Println("  --retries int                   Number of retries with exponential backoff for transient fetch failures (default: 0)")
+	fmt.Println("  --concurrency int               Number of inputs downloaded and parsed at once; lower it to spare internal services, 1 loads them one by one (default: 4)")
+	fmt.Println("  --rate-limit float              Maximum requests per second to each host serving remote inputs, retries included (default: no limit)")
+	fmt.Println("  --rate-burst int                Requests sent to a host at once before --rate-limit applies (default: 1)")
+	fmt.Println("  --proxy string                  Proxy URL for fetching remote inputs (default: $HTTP_PROXY/$HTTPS_PROXY, honors $NO_PROXY)")
+	fmt.Println("  --ca-file string                PEM bundle of additional CAs trusted for HTTPS inputs")
+	fmt.Println("  --cert-file string              PEM client certificate for HTTPS inputs requiring mutual TLS")
+	fmt.Println("  --key-file string               PEM client key for --cert-file")
+	fmt.Println("  --insecure-skip-verify          Disable TLS certificate verification for remote inputs (unsafe)")
+	fmt.Println("  --http-timeout duration         Timeout for each remote input request (default: 30s)")
+	fmt.Println("  --fetch-timeout duration        Total time allowed for fetching all remote inputs, including retries (default: no limit)")
+	fmt.Println("  --timeout duration              Time allowed for the whole run, from discovering and fetching inputs to notifying and publishing the merged spec; slow inputs are named in the error (default: no limit)")
+	fmt.Println("  --max-download-size int         Maximum size of a remote input, and of the unpacked members of an archive, in bytes, -1 for no limit (default: 67108864)")
+	fmt.Println("  --swaggerhub-api-key string     SwaggerHub API key for swaggerhub:// inputs (default: $SWAGGERHUB_API_KEY)")
+	fmt.Println("  --swaggerhub-url string         SwaggerHub registry API URL for on-premise installations")
+	fmt.Println("  --promote-inline-schemas        Move large inline request/response schemas into components.schemas")
+	fmt.Println("  --promote-name-template string  Name template for promoted schemas (default: {{.OperationID}}{{.Kind}})")
+	fmt.Println("  --promote-min-properties int    Number of properties from which an inline schema is promoted (default: 3)")
+	fmt.Println("  --flatten                       Inline every $ref in the merged spec; recursive schemas keep their refs")
+	fmt.Println("  --descriptions string           Directory of Markdown files (info.md, tags/<tag>.md, operations/<operationId>.md) replacing descriptions")
+	fmt.Println("  --common-header string          Header appended to every operation not declaring it, e.g. X-Request-ID (repeatable)")
+	fmt.Println("  --common-params string          YAML file listing parameters appended to every operation not declaring them")
+	fmt.Println("  --security-alias string         Rename security schemes to a canonical name, e.g. bearerAuth=BearerAuth,bearer,jwt (repeatable)")
+	fmt.Println("  --add-security string           Register a security scheme and apply it as the document-level requirement, e.g. bearerAuth")
+	fmt.Println("  --security-scheme string        YAML file defining the --add-security scheme (default: existing definition or HTTP bearer JWT)")
+	fmt.Println("  --security-scopes string        Comma-separated scopes of the --add-security requirement")
+	fmt.Println("  --prune-security                Remove security schemes that no operation or global requirement references")
+	fmt.Println("  --tag-order string              Order of the merged tags: as-merged, alpha or file:<order.yaml> pinning listed tags first (default: as-merged)")
+	fmt.Println("  --tag-groups string             Generate Redoc x-tagGroups: source (one group per input service) or file:<groups.yaml>")
+	fmt.Println("  --api-versions                  Record the info.version of every input service, by info.title, in the x-api-versions extension")
+	fmt.Println("  --error-responses               Add shared error responses, referencing the error schema, to operations missing them")
+	fmt.Println("  --error-statuses string         Error statuses added by --error-responses (default: 400,401,403,500)")
+	fmt.Println("  --error-schema string           Error body schema of --error-responses, added when missing (default: ErrorResponse)")
+	fmt.Println("  --normalize-media-types         Normalize body media types: lowercase, without charset, aliases renamed and duplicates of --media-type-drop types removed")
+	fmt.Println("  --media-type-alias string       Rename media types to a canonical one (format: canonical=alias,alias), repeatable (default: application/json=text/json)")
+	fmt.Println("  --media-type-drop string        Media types removed from bodies offering another with the same schema (default: text/plain)")
+	fmt.Println("  --generate-examples             Synthesize examples from schemas (formats, enums, defaults) for bodies without one")
+	fmt.Println("  --lint                          Lint the merged spec: operationId, 2xx response, summary, no empty schemas, naming conventions")
+	fmt.Println("  --lint-config string            YAML file overriding rule severities (rules: {operation-summary: error}) and naming casings (naming: {properties: snake})")
+	fmt.Println("  --lint-ruleset string           Spectral ruleset whose supported rules (JSONPath subset, core functions) are added to --lint")
+	fmt.Println("  --lint-fail-on string           Fail when findings reach this severity: error, warn, info or never (default: error)")
+	fmt.Println("  --checksum                      Write the SHA-256 of every output to <output>.sha256, verifiable with sha256sum -c")
+	fmt.Println("  --embed-checksum                Embed the checksum of the merged spec (sha256 of its JSON without it) in info.x-checksum")
+	fmt.Println("  --semver-bump string            Compare with the previous --output to suggest a version bump (suggest), or apply it to info.version (apply)")
+	fmt.Println("  --mock-addr string              Listen address of the mock command (default: :4010)")
+	fmt.Println("  --proxy-addr string             Listen address of the proxy command (default: :8080)")
+	fmt.Println("  --proxy-backend string          Backend of the proxy command (format: [/path-prefix=]url, longest prefix wins, repeatable)")
+	fmt.Println("  --validate-examples             Report body examples that do not match their schemas, with the operation and input file")
+	fmt.Println("  --envelope string               Check 2xx JSON response bodies against the standard envelope: verify (report deviations with their input) or wrap")
+	fmt.Println("  --envelope-schema string        Component schema of the envelope, whose properties define it (default: Envelope)")
+	fmt.Println("  --envelope-properties string    Envelope properties when the spec lacks --envelope-schema (default: data,meta,errors)")
+	fmt.Println("  --envelope-data string          Envelope property holding the original body of wrapped responses (default: data)")
+	fmt.Println("  --dedup-schemas                 Collapse structurally identical schemas (ignoring descriptions and examples) and rewrite refs")
+	fmt.Println("  --split-by-tag string           Also write one spec per tag, plus an index, to this directory")
+	fmt.Println("  --split-by-path-segment string  Also write one spec per first path segment (/orders, /users, ...), plus an index, to this directory")
+	fmt.Println("  --unbundle string               Also write the merged spec to this directory as path and component files linked by relative $refs")
+	fmt.Println("  --split-format string           Format of split and unbundled specs: yaml or json (default: yaml)")
+	fmt.Println("  --publish string                Publish the merged spec after merging (format: swaggerhub://owner/api/version[?private=false])")
+	fmt.Println("  --push string                   Push the merged spec as an OCI artifact with oras (format: oci://registry/repository:tag)")
+	fmt.Println("  --git-repo string               Commit and push the merged spec to this git repository")
+	fmt.Println("  --git-branch string             Branch for --git-repo (default: repository default branch)")
+	fmt.Println("  --git-path string               File path inside --git-repo (default: openapi.yaml)")
+	fmt.Println("  --git-message string            Commit message template ({{.Title}}, {{.Version}}, {{.Date}}, {{.Files}})")
+	fmt.Println("  --discover-kubernetes           Discover specs from Kubernetes services annotated with the spec path, using kubectl")
+	fmt.Println("  --kube-namespace string         Namespace for --discover-kubernetes (default: all namespaces)")
+	fmt.Println("  --kube-context string           Kubeconfig context for --discover-kubernetes")
+	fmt.Println("  --kube-annotation string        Annotation holding the spec path (default: openapi.path)")
+	fmt.Println("  --kube-ingresses                Also discover annotated ingresses, addressed by their public host")
+	fmt.Println("  --consul-addr string            Discover specs from Consul services whose metadata has openapi_path (e.g. http://consul:8500)")
+	fmt.Println("  --consul-token string           Consul ACL token for --consul-addr (default: $CONSUL_HTTP_TOKEN)")
+	fmt.Println("  --consul-datacenter string      Consul datacenter for --consul-addr")
+	fmt.Println("  --eureka-url string             Discover specs from Eureka instances whose metadata has openapi.path (e.g. http://eureka:8761/eureka)")
+	fmt.Println("  --notify-webhook string         Webhook URL receiving a JSON notification with stats and conflicts after merging (repeatable)")
+	fmt.Println("  --notify-slack string           Slack incoming webhook URL notified after merging (repeatable)")
+	fmt.Println("  --plugin string                 External plugin to run (format: stage:command [args], stage is input or merged, repeatable)")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  # Merge specific files")
@@ -241,4 +1109,13 @@ func showHelp() {
 	fmt.Println("")
 	fmt.Println("  # Verbose output with statistics")
 	fmt.Println("  swagger-merger --input ./docs --output merged.yaml --verbose --stats")
+	fmt.Println("")
+	fmt.Println("  # Serve stub responses for the whole aggregated API")
+	fmt.Println("  swagger-merger mock --input ./docs --output merged.yaml --mock-addr :4010")
+	fmt.Println("")
+	fmt.Println("  # Check live traffic against the merged spec")
+	fmt.Println("  swagger-merger proxy --input ./docs --output merged.yaml --proxy-backend /users=http://users:8080 --proxy-backend http://gateway")
+	fmt.Println("")
+	fmt.Println("  # Post-process the merged document with an external plugin")
+	fmt.Println("  swagger-merger --input ./docs --output merged.yaml --plugin 'merged:./scripts/add-branding.py'")
 }
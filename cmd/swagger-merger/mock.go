@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// serveMock serves stub responses for the operations of the merged spec
+// until the process is stopped
+func serveMock(logger *consoleLogger, doc *openapi3.T, addr string) {
+	mock := merger.MockHandler(doc)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Debug("mock request", "method", r.Method, "path", r.URL.Path)
+		mock.ServeHTTP(w, r)
+	})
+	logger.Info(fmt.Sprintf("🎭 Mock server listening on %s", addr))
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		logger.fatal(fmt.Sprintf("Error serving mock: %v", err))
+	}
+}
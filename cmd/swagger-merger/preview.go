@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// browser browses the tree of a merged document in the terminal,
+// expanding and collapsing entries by number
+type browser struct {
+	root *merger.PreviewNode
+	open map[*merger.PreviewNode]bool
+	in   *bufio.Reader
+	out  io.Writer
+	// clear clears the screen before every render, when out is a
+	// terminal
+	clear bool
+}
+
+// previewRow is a visible entry of the browser
+type previewRow struct {
+	node  *merger.PreviewNode
+	depth int
+}
+
+// previewDocument browses the merged document until the user quits
+func previewDocument(logger *consoleLogger, doc *openapi3.T) {
+	b := &browser{
+		root:  merger.PreviewTree(doc),
+		open:  make(map[*merger.PreviewNode]bool),
+		in:    bufio.NewReader(os.Stdin),
+		out:   os.Stdout,
+		clear: isTerminal(os.Stdout),
+	}
+	if err := b.run(); err != nil {
+		logger.fatal(fmt.Sprintf("Error previewing: %v", err))
+	}
+}
+
+// isTerminal reports whether f is a character device, such as a
+// terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// run renders the tree and applies commands until the user quits or the
+// input ends
+func (b *browser) run() error {
+	message := ""
+	for {
+		rows := b.rows()
+		b.render(rows, message)
+		line, err := b.in.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				fmt.Fprintln(b.out)
+				return nil
+			}
+			return err
+		}
+		var quit bool
+		quit, message = b.command(strings.TrimSpace(line), rows)
+		if quit {
+			return nil
+		}
+	}
+}
+
+// command applies a command to the visible rows, returning whether to
+// quit and a message to show
+func (b *browser) command(command string, rows []previewRow) (bool, string) {
+	switch command {
+	case "q", "quit", "exit":
+		return true, ""
+	case "c":
+		clear(b.open)
+		return false, ""
+	case "", "?", "h", "help":
+		return false, ""
+	}
+
+	// A number toggles an entry; a trailing * expands it three levels deep
+	deep := strings.HasSuffix(command, "*")
+	n, err := strconv.Atoi(strings.TrimSuffix(command, "*"))
+	if err != nil || n < 1 || n > len(rows) {
+		return false, fmt.Sprintf("Unknown command %q", command)
+	}
+	node := rows[n-1].node
+	switch {
+	case !node.Expandable():
+		return false, fmt.Sprintf("%s has no entries", node.Label)
+	case deep:
+		b.expand(node, 3)
+	default:
+		b.open[node] = !b.open[node]
+	}
+	return false, ""
+}
+
+// expand opens node and its entries down to depth levels
+func (b *browser) expand(node *merger.PreviewNode, depth int) {
+	if depth == 0 || !node.Expandable() {
+		return
+	}
+	b.open[node] = true
+	for _, child := range node.Children() {
+		b.expand(child, depth-1)
+	}
+}
+
+// rows returns the visible entries: the children of the root and of
+// every open entry
+func (b *browser) rows() []previewRow {
+	var rows []previewRow
+	var walk func(node *merger.PreviewNode, depth int)
+	walk = func(node *merger.PreviewNode, depth int) {
+		for _, child := range node.Children() {
+			rows = append(rows, previewRow{node: child, depth: depth})
+			if b.open[child] {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(b.root, 0)
+	return rows
+}
+
+// render prints the visible entries, numbered, and the commands
+func (b *browser) render(rows []previewRow, message string) {
+	if b.clear {
+		fmt.Fprint(b.out, "\033[H\033[2J")
+	}
+	fmt.Fprintf(b.out, "🔎 %s\n\n", b.root)
+	width := len(strconv.Itoa(len(rows)))
+	for i, row := range rows {
+		marker := " "
+		if row.node.Expandable() {
+			marker = "▸"
+			if b.open[row.node] {
+				marker = "▾"
+			}
+		}
+		fmt.Fprintf(b.out, "%*d  %s%s %s\n", width, i+1, strings.Repeat("  ", row.depth), marker, row.node)
+	}
+	fmt.Fprintln(b.out)
+	if message != "" {
+		fmt.Fprintf(b.out, "%s\n", message)
+	}
+	fmt.Fprint(b.out, "[n] expand/collapse entry n, [n*] expand it 3 levels, [c] collapse all, [q] quit\n> ")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestBrowserCommand(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(`openapi: "3.0.1"
+info: {title: Users, version: 1.0.0}
+paths:
+  /users:
+    get:
+      tags: [users]
+      responses: {"200": {description: OK}}
+components:
+  schemas:
+    User:
+      type: object
+      properties: {id: {type: string}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &browser{root: merger.PreviewTree(doc), open: make(map[*merger.PreviewNode]bool), out: io.Discard}
+
+	// The steps run in order on the same browser
+	tests := []struct {
+		command string
+		quit    bool
+		message string
+		// rows is the number of visible rows after the command
+		rows int
+	}{
+		{command: "", rows: 2},
+		{command: "help", rows: 2},
+		{command: "1", rows: 3},
+		{command: "1", rows: 2},
+		{command: "2*", rows: 4},
+		{command: "4", message: "id has no entries", rows: 4},
+		{command: "0", message: `Unknown command "0"`, rows: 4},
+		{command: "5", message: `Unknown command "5"`, rows: 4},
+		{command: "open", message: `Unknown command "open"`, rows: 4},
+		{command: "c", rows: 2},
+		{command: "q", quit: true, rows: 2},
+	}
+	for _, tt := range tests {
+		quit, message := b.command(tt.command, b.rows())
+		if quit != tt.quit || message != tt.message {
+			t.Errorf("command(%q) = %v, %q, want %v, %q", tt.command, quit, message, tt.quit, tt.message)
+		}
+		if rows := b.rows(); len(rows) != tt.rows {
+			t.Errorf("Expected %d rows after %q, got %d", tt.rows, tt.command, len(rows))
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/JackBee2912/swagger-merger/pkg/merger"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// serveProxy forwards requests to the backends, logging the requests and
+// responses not conforming to the merged spec, until the process is
+// stopped
+func serveProxy(logger *consoleLogger, doc *openapi3.T, addr string, backends map[string]string) {
+	proxy, err := merger.NewValidatingProxy(doc, merger.ProxyConfig{
+		Backends: backends,
+		OnViolation: func(v merger.ProxyViolation) {
+			args := []any{"method", v.Method, "path", v.Path}
+			if v.Status != 0 {
+				args = append(args, "status", v.Status)
+			}
+			if v.Pointer != "" {
+				args = append(args, "pointer", v.Pointer)
+			}
+			logger.Warn("contract violation", append(args, "message", v.Message)...)
+		},
+	})
+	if err != nil {
+		logger.fatal(fmt.Sprintf("Error creating proxy: %v", err))
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Debug("proxy request", "method", r.Method, "path", r.URL.Path)
+		proxy.ServeHTTP(w, r)
+	})
+	logger.Info(fmt.Sprintf("🛡️  Validating proxy listening on %s", addr))
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		logger.fatal(fmt.Sprintf("Error serving proxy: %v", err))
+	}
+}
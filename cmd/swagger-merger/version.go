@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X main.buildVersion=1.2.0 -X main.buildCommit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unset values fall back to the build info embedded by the Go toolchain.
+var (
+	buildVersion = "1.0.0"
+	buildCommit  string
+	buildDate    string
+)
+
+// kinOpenAPIModule is the module path of the OpenAPI library
+const kinOpenAPIModule = "github.com/getkin/kin-openapi"
+
+// versionInfo describes the build of the CLI
+type versionInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	// Date is the build date, or the commit date when not set at link
+	// time
+	Date       string `json:"date,omitempty"`
+	Go         string `json:"go"`
+	Platform   string `json:"platform"`
+	KinOpenAPI string `json:"kinOpenAPI,omitempty"`
+}
+
+// buildVersionInfo returns the build metadata from the link-time values
+// and the embedded build info
+func buildVersionInfo() versionInfo {
+	info := versionInfo{
+		Version:  buildVersion,
+		Commit:   buildCommit,
+		Date:     buildDate,
+		Go:       runtime.Version(),
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	// go install module@version stamps the module version; builds of a
+	// checkout get (devel) or a pseudo-version
+	if v := build.Main.Version; v != "" && v != "(devel)" && !strings.HasPrefix(v, "v0.0.0-") && !strings.Contains(v, "+") {
+		info.Version = strings.TrimPrefix(v, "v")
+	}
+	var modified bool
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && buildCommit == "" && info.Commit != "" {
+		info.Commit += "-dirty"
+	}
+	for _, dep := range build.Deps {
+		if dep.Path == kinOpenAPIModule {
+			info.KinOpenAPI = dep.Version
+			if dep.Replace != nil {
+				info.KinOpenAPI = dep.Replace.Version
+			}
+		}
+	}
+	return info
+}
+
+// printVersion prints the build metadata, as JSON with asJSON
+func printVersion(asJSON bool) error {
+	info := buildVersionInfo()
+	if asJSON {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("swagger-merger v%s\n", info.Version)
+	fmt.Println("A tool for merging multiple Swagger/OpenAPI files")
+	fmt.Println()
+	if info.Commit != "" {
+		fmt.Printf("  commit:       %s\n", info.Commit)
+	}
+	if info.Date != "" {
+		fmt.Printf("  date:         %s\n", info.Date)
+	}
+	fmt.Printf("  go:           %s %s\n", info.Go, info.Platform)
+	if info.KinOpenAPI != "" {
+		fmt.Printf("  kin-openapi:  %s\n", info.KinOpenAPI)
+	}
+	return nil
+}
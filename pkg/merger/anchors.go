@@ -0,0 +1,436 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnchorPolicy controls how the YAML anchors and aliases of inputs are
+// resolved
+type AnchorPolicy string
+
+const (
+	// AnchorExpand expands every alias into a copy of its anchored node
+	// (default)
+	AnchorExpand AnchorPolicy = "expand"
+	// AnchorComponents turns aliased schemas, parameters and responses into
+	// components referenced with $ref, so shared structures stay shared in
+	// the merged spec. Other aliases, such as merge keys (<<), are expanded.
+	AnchorComponents AnchorPolicy = "components"
+)
+
+// validate checks that the policy is known
+func (p AnchorPolicy) validate() error {
+	switch p {
+	case "", AnchorExpand, AnchorComponents:
+		return nil
+	}
+	return fmt.Errorf("invalid anchor policy %q (expected expand or components)", p)
+}
+
+// unknownAnchorPattern matches the error of an alias without anchor
+var unknownAnchorPattern = regexp.MustCompile(`unknown anchor '([^']*)' referenced`)
+
+// invalidComponentChars matches the characters of anchors not allowed in
+// component names
+var invalidComponentChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// resolveAnchors checks the anchors and aliases of an input and resolves
+// them by the configured policy, returning the data to load. Aliases are
+// local to a YAML document: an alias of an anchor of another input, or of
+// another document of the same file, is reported as such.
+func (m *Merger) resolveAnchors(source string, data []byte) ([]byte, error) {
+	if !bytes.ContainsRune(data, '*') {
+		return data, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if match := unknownAnchorPattern.FindStringSubmatch(err.Error()); match != nil {
+			line := bytes.Count(data[:max(bytes.Index(data, []byte("*"+match[1])), 0)], []byte("\n")) + 1
+			return nil, fmt.Errorf("alias *%s at line %d has no anchor in this document: anchors cannot be shared across inputs or YAML documents, use a $ref instead", match[1], line)
+		}
+		// Not YAML, the loader reports it
+		return data, nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil
+	}
+
+	r := &anchorResolver{
+		swagger2:   rootLocation(doc.Content[0], "definitions") == definitionsLocation,
+		paths:      make(map[*yaml.Node][]string),
+		components: make(map[*yaml.Node]string),
+		uses:       make(map[*yaml.Node][]*yaml.Node),
+	}
+	if err := r.scan(doc.Content[0], nil, nil); err != nil {
+		return nil, err
+	}
+	if err := r.checkCycles(); err != nil {
+		return nil, err
+	}
+	if len(r.aliases) == 0 {
+		return data, nil
+	}
+	if m.config.Anchors != AnchorComponents {
+		m.config.Logger.Debug("expanding YAML aliases", "source", source, "aliases", len(r.aliases))
+		return data, nil
+	}
+
+	r.chooseComponents(doc.Content[0])
+	if len(r.components) == 0 {
+		return data, nil
+	}
+	root := r.resolve(doc.Content[0], nil)
+	r.addComponents(root)
+	for anchor, name := range r.components {
+		m.config.Logger.Debug("turned YAML anchor into component", "source", source, "anchor", anchor.Anchor, "ref", r.ref(r.kind(r.paths[anchor]), name))
+	}
+
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve YAML aliases: %v", err)
+	}
+	return out, nil
+}
+
+// anchorResolver resolves the aliases of a YAML document
+type anchorResolver struct {
+	swagger2 bool
+	// paths are the locations of the nodes of the document, as keys and
+	// sequence indexes
+	paths map[*yaml.Node][]string
+	// aliases of the document, with their locations
+	aliases []anchorAlias
+	// components maps the anchored nodes turned into components to their
+	// name
+	components map[*yaml.Node]string
+	// uses maps anchored nodes to the aliases they contain
+	uses map[*yaml.Node][]*yaml.Node
+}
+
+// anchorAlias is an alias of a document at a location
+type anchorAlias struct {
+	node *yaml.Node
+	path []string
+}
+
+// scan records the locations of the nodes below node and its aliases,
+// rejecting recursive aliases and aliases duplicating operations
+func (r *anchorResolver) scan(node *yaml.Node, path []string, ancestors []*yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		target := node.Alias
+		if slices.Contains(ancestors, target) {
+			return fmt.Errorf("recursive alias *%s at line %d: use a $ref for recursive structures", node.Value, node.Line)
+		}
+		if id := mappingValue(target, "operationId"); id != nil && mappingValue(target, "responses") != nil {
+			return fmt.Errorf("alias *%s at line %d duplicates operationId %q: operations cannot be shared", node.Value, node.Line, id.Value)
+		}
+		r.aliases = append(r.aliases, anchorAlias{node: node, path: path})
+		for _, ancestor := range ancestors {
+			if ancestor.Anchor != "" {
+				r.uses[ancestor] = append(r.uses[ancestor], node)
+			}
+		}
+		return nil
+	}
+	r.paths[node] = path
+	ancestors = append(ancestors, node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := r.scan(node.Content[i+1], appendPath(path, node.Content[i].Value), ancestors); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := r.scan(item, appendPath(path, strconv.Itoa(i)), ancestors); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkCycles rejects aliases expanding, through other aliases, into
+// themselves
+func (r *anchorResolver) checkCycles() error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*yaml.Node]int)
+	var visit func(anchor *yaml.Node) error
+	visit = func(anchor *yaml.Node) error {
+		state[anchor] = visiting
+		for _, alias := range r.uses[anchor] {
+			switch state[alias.Alias] {
+			case visiting:
+				return fmt.Errorf("recursive alias *%s at line %d: use a $ref for recursive structures", alias.Value, alias.Line)
+			case 0:
+				if err := visit(alias.Alias); err != nil {
+					return err
+				}
+			}
+		}
+		state[anchor] = done
+		return nil
+	}
+	for _, alias := range r.aliases {
+		if state[alias.node.Alias] == 0 {
+			if err := visit(alias.node.Alias); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// chooseComponents picks the anchored nodes to turn into components: the
+// schemas, parameters and responses aliased at a location of the same kind
+func (r *anchorResolver) chooseComponents(root *yaml.Node) {
+	used := make(map[string]bool)
+	for _, alias := range r.aliases {
+		target := alias.node.Alias
+		kind := r.kind(r.paths[target])
+		if kind == "" || kind != r.kind(alias.path) {
+			continue
+		}
+		if _, ok := r.components[target]; ok {
+			continue
+		}
+		name, defined := r.definedName(r.paths[target])
+		if !defined {
+			base := invalidComponentChars.ReplaceAllString(target.Anchor, "_")
+			name = base
+			for i := 2; used[kind+"/"+name] || mappingValue(r.container(root, kind), name) != nil; i++ {
+				name = fmt.Sprintf("%s_%d", base, i)
+			}
+		}
+		used[kind+"/"+name] = true
+		r.components[target] = name
+	}
+}
+
+// resolve returns a copy of node without anchors and aliases: aliases of
+// components become $refs, other aliases and merge keys are expanded
+func (r *anchorResolver) resolve(node *yaml.Node, path []string) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		target := node.Alias
+		if name, ok := r.components[target]; ok && r.kind(path) == r.kind(r.paths[target]) {
+			return r.refNode(r.kind(path), name)
+		}
+		// Expand a copy, so a component anchor is not referenced
+		expanded := *target
+		return r.resolve(&expanded, path)
+	}
+	if name, ok := r.components[node]; ok {
+		if _, defined := r.definedName(path); !defined {
+			return r.refNode(r.kind(path), name)
+		}
+	}
+
+	copied := *node
+	copied.Anchor = ""
+	copied.Content = nil
+	switch node.Kind {
+	case yaml.MappingNode:
+		var merged []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" && key.ShortTag() == "!!merge" {
+				merged = append(merged, value)
+				continue
+			}
+			copied.Content = append(copied.Content, key, r.resolve(value, appendPath(path, key.Value)))
+		}
+		// Keys of the mapping override merged ones, earlier merged
+		// mappings override later ones
+		for _, source := range merged {
+			sources := []*yaml.Node{source}
+			if source.Kind == yaml.SequenceNode {
+				sources = source.Content
+			}
+			for _, source := range sources {
+				if source.Kind == yaml.AliasNode {
+					source = source.Alias
+				}
+				// Expand a copy, so component anchors are not referenced
+				expanded := *source
+				source = r.resolve(&expanded, path)
+				for i := 0; i+1 < len(source.Content); i += 2 {
+					if mappingValue(&copied, source.Content[i].Value) == nil {
+						copied.Content = append(copied.Content, source.Content[i], source.Content[i+1])
+					}
+				}
+			}
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for i, item := range node.Content {
+			copied.Content = append(copied.Content, r.resolve(item, appendPath(path, strconv.Itoa(i))))
+		}
+	}
+	return &copied
+}
+
+// addComponents adds the components taken from anchors defined outside
+// the components of the document
+func (r *anchorResolver) addComponents(root *yaml.Node) {
+	for _, target := range sortedAnchors(r.components) {
+		path := r.paths[target]
+		if _, defined := r.definedName(path); defined {
+			continue
+		}
+		kind := r.kind(path)
+		definition := *target
+		definition.Anchor = ""
+		resolved := r.resolve(&definition, r.componentPath(kind, r.components[target]))
+		container := r.container(root, kind)
+		if container == nil {
+			container = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			parent := root
+			containerPath := r.componentPath(kind, "")
+			for _, key := range containerPath[:len(containerPath)-2] {
+				next := mappingValue(parent, key)
+				if next == nil {
+					next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+					parent.Content = append(parent.Content, scalarNode(key), next)
+				}
+				parent = next
+			}
+			parent.Content = append(parent.Content, scalarNode(containerPath[len(containerPath)-2]), container)
+		}
+		container.Content = append(container.Content, scalarNode(r.components[target]), resolved)
+	}
+}
+
+// sortedAnchors returns the anchored nodes of components by anchor name,
+// so generated components are added in a stable order
+func sortedAnchors(components map[*yaml.Node]string) []*yaml.Node {
+	anchors := make([]*yaml.Node, 0, len(components))
+	for node := range components {
+		anchors = append(anchors, node)
+	}
+	slices.SortFunc(anchors, func(a, b *yaml.Node) int {
+		return strings.Compare(a.Anchor, b.Anchor)
+	})
+	return anchors
+}
+
+// kind returns the component kind of a location: schemas, parameters,
+// responses, or "" for other locations
+func (r *anchorResolver) kind(path []string) string {
+	if _, ok := r.definedName(path); ok {
+		if r.swagger2 && path[0] == "definitions" {
+			return "schemas"
+		}
+		return path[len(path)-2]
+	}
+	n := len(path)
+	if n == 0 {
+		return ""
+	}
+	last := path[n-1]
+	parent := ""
+	if n > 1 {
+		parent = path[n-2]
+	}
+	switch {
+	case last == "schema" || last == "items" || last == "additionalProperties" || last == "not":
+		return "schemas"
+	case parent == "properties":
+		return "schemas"
+	case (parent == "allOf" || parent == "anyOf" || parent == "oneOf") && isIndex(last):
+		return "schemas"
+	case parent == "parameters" && isIndex(last):
+		return "parameters"
+	case parent == "responses":
+		return "responses"
+	}
+	return ""
+}
+
+// definedName returns the component name of a location defining a
+// component, such as components/schemas/User
+func (r *anchorResolver) definedName(path []string) (string, bool) {
+	if r.swagger2 {
+		if len(path) == 2 && (path[0] == "definitions" || path[0] == "parameters" || path[0] == "responses") {
+			return path[1], true
+		}
+		return "", false
+	}
+	if len(path) == 3 && path[0] == "components" && (path[1] == "schemas" || path[1] == "parameters" || path[1] == "responses") {
+		return path[2], true
+	}
+	return "", false
+}
+
+// componentPath returns the location of a component
+func (r *anchorResolver) componentPath(kind, name string) []string {
+	if !r.swagger2 {
+		return []string{"components", kind, name}
+	}
+	if kind == "schemas" {
+		return []string{"definitions", name}
+	}
+	return []string{kind, name}
+}
+
+// container returns the mapping holding the components of a kind, or nil
+func (r *anchorResolver) container(root *yaml.Node, kind string) *yaml.Node {
+	path := r.componentPath(kind, "")
+	node := root
+	for _, key := range path[:len(path)-1] {
+		if node = mappingValue(node, key); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// ref returns the $ref of a component
+func (r *anchorResolver) ref(kind, name string) string {
+	return "#/" + strings.Join(r.componentPath(kind, name), "/")
+}
+
+// refNode returns a $ref mapping to a component
+func (r *anchorResolver) refNode(kind, name string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		scalarNode("$ref"), scalarNode(r.ref(kind, name)),
+	}}
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarNode returns a string scalar node
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// appendPath returns path extended by key, without sharing its array
+func appendPath(path []string, key string) []string {
+	return append(path[:len(path):len(path)], key)
+}
+
+// isIndex reports whether a location segment is a sequence index
+func isIndex(segment string) bool {
+	_, err := strconv.Atoi(segment)
+	return err == nil
+}
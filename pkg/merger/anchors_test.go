@@ -0,0 +1,154 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const anchoredSpec = `openapi: "3.0.1"
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: &Page
+                type: object
+                properties:
+                  next: {type: string}
+  /users/{id}:
+    get:
+      parameters:
+        - &idParam
+          name: id
+          in: path
+          required: true
+          schema: {type: string}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /users/{id}/friends:
+    get:
+      parameters:
+        - *idParam
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: *Page
+components:
+  schemas:
+    Base: &base
+      type: object
+      properties:
+        id: {type: string}
+    User:
+      <<: *base
+      description: A user
+      example: *base
+`
+
+func TestResolveAnchors(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.yaml")
+	os.WriteFile(input, []byte(anchoredSpec), 0644)
+
+	m := New(Config{InputPaths: []string{input}, Anchors: AnchorComponents})
+	merged, err := m.loadAndMerge()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	schemas := merged.Components.Schemas
+	if schemas["Page"] == nil || schemas["Page"].Value.Properties["next"] == nil {
+		t.Fatalf("Expected the aliased response schema as a Page component, got %v", sortedKeys(schemas))
+	}
+	for _, path := range []string{"/users", "/users/{id}/friends"} {
+		ref := merged.Paths.Find(path).Get.Responses.Status(200).Value.Content["application/json"].Schema.Ref
+		if ref != "#/components/schemas/Page" {
+			t.Errorf("Expected %s to reference the Page component, got %q", path, ref)
+		}
+	}
+	if param := merged.Components.Parameters["idParam"]; param == nil || param.Value.Name != "id" {
+		t.Errorf("Expected the aliased parameter as an idParam component, got %v", merged.Components.Parameters)
+	}
+	user := schemas["User"].Value
+	if user.Type == nil || !user.Type.Is("object") || user.Properties["id"] == nil || user.Description != "A user" {
+		t.Errorf("Expected the merge key of User to be expanded, got %+v", user)
+	}
+	if example, ok := user.Example.(map[string]any); !ok || example["type"] != "object" {
+		t.Errorf("Expected the example alias to be expanded, got %v", user.Example)
+	}
+
+	m = New(Config{InputPaths: []string{input}})
+	merged, err = m.loadAndMerge()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if merged.Components.Schemas["Page"] != nil {
+		t.Error("Expected aliases to be expanded by default")
+	}
+	if schema := merged.Paths.Find("/users/{id}/friends").Get.Responses.Status(200).Value.Content["application/json"].Schema; schema.Ref != "" || schema.Value.Properties["next"] == nil {
+		t.Errorf("Expected the expanded Page schema, got %+v", schema)
+	}
+}
+
+func TestResolveAnchorsErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{`openapi: "3.0.1"
+info: {title: A, version: "1"}
+paths:
+  /a:
+    get:
+      responses:
+        "200": *shared
+`, "alias *shared at line 7 has no anchor in this document"},
+		{`openapi: "3.0.1"
+info: {title: A, version: "1"}
+paths: {}
+components:
+  schemas:
+    Node: &node
+      type: object
+      properties:
+        child: *node
+`, "recursive alias *node at line 9"},
+		{`openapi: "3.0.1"
+info: {title: A, version: "1"}
+paths:
+  /a:
+    get: &op
+      operationId: getA
+      responses:
+        "200": {description: ok}
+  /b:
+    get: *op
+`, `alias *op at line 10 duplicates operationId "getA"`},
+	}
+	for _, test := range tests {
+		file, err := createTempSwaggerFile(test.spec)
+		if err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		defer os.Remove(file)
+
+		_, err = New(Config{InputPaths: []string{file}}).loadAndMerge()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Expected an error containing %q, got %v", test.want, err)
+		}
+	}
+}
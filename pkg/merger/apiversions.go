@@ -0,0 +1,64 @@
+package merger
+
+import (
+	"path"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// apiVersionsExtension is the extension of the merged document mapping
+// every input service to its info.version
+const apiVersionsExtension = "x-api-versions"
+
+// APIVersions returns the transformers recording the info.version of
+// every input service in the x-api-versions extension of the merged
+// document, so consumers can tell which service versions it aggregates.
+// Services are named after the info.title of the input (or its file
+// name); inputs sharing a title with another version are told apart by
+// their file name. The input transformer records the versions, the merged
+// one writes them; merges reset the recorded versions when they start.
+func APIVersions() (input, merged Transformer) {
+	var (
+		mu       sync.Mutex
+		versions = make(map[string]string)
+	)
+	record := func(doc *openapi3.T, source string) error {
+		if doc.Info == nil || doc.Info.Version == "" {
+			return nil
+		}
+		name := path.Base(source)
+		if doc.Info.Title != "" {
+			name = doc.Info.Title
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if version, ok := versions[name]; ok && version != doc.Info.Version && name != path.Base(source) {
+			name += " (" + path.Base(source) + ")"
+		}
+		versions[name] = doc.Info.Version
+		return nil
+	}
+	input = statefulTransformer{
+		transformerFunc: transformerFunc{name: "api-versions", fn: record},
+		reset: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			versions = make(map[string]string)
+		},
+	}
+	merged = NewTransformer("api-versions", func(doc *openapi3.T, source string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(versions) > 0 {
+			if doc.Extensions == nil {
+				doc.Extensions = make(map[string]any)
+			}
+			doc.Extensions[apiVersionsExtension] = versions
+		}
+		versions = make(map[string]string)
+		return nil
+	})
+	return input, merged
+}
@@ -0,0 +1,97 @@
+package merger
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestAPIVersions(t *testing.T) {
+	load := func(title, version string) *openapi3.T {
+		return loadSpec(t, `
+openapi: 3.0.0
+info: {title: "`+title+`", version: "`+version+`"}
+paths: {}
+`)
+	}
+	docs := []*openapi3.T{load("Users", "1.4.0"), load("", "2.0.1"), load("Users", "1.5.0"), load("Users", "1.4.0")}
+	sources := []string{"specs/users.yaml", "specs/orders.yaml", "legacy/users-v1.yaml", "specs/users.yaml"}
+
+	input, merged := APIVersions()
+	for i, doc := range docs {
+		if err := input.Transform(doc, sources[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	doc, err := MergeDocuments(docs[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := merged.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"Users":                 "1.4.0",
+		"orders.yaml":           "2.0.1",
+		"Users (users-v1.yaml)": "1.5.0",
+	}
+	if got := doc.Extensions["x-api-versions"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// The versions are reset for the next merge
+	next := load("Other", "1.0")
+	if err := merged.Transform(next, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := next.Extensions["x-api-versions"]; ok {
+		t.Error("Expected no versions without inputs")
+	}
+}
+
+func TestAPIVersionsFailedMerge(t *testing.T) {
+	users, err := createTempSwaggerFile("openapi: 3.0.0\ninfo: {title: Users, version: \"1.0\"}\npaths: {}\ntags: [{name: users}]\n")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(users)
+	orders, err := createTempSwaggerFile("openapi: 3.0.0\ninfo: {title: Orders, version: \"2.0\"}\npaths: {}\ntags: [{name: orders}]\n")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(orders)
+
+	// The first merge fails after every input was recorded
+	fail := true
+	pipeline := &Pipeline{Merged: []Transformer{NewTransformer("fail-once", func(*openapi3.T, string) error {
+		if fail {
+			fail = false
+			return errors.New("boom")
+		}
+		return nil
+	})}}
+	versionsInput, versionsMerged := APIVersions()
+	groupsInput, groupsMerged := TagGroupsBySource()
+	pipeline.Add(StageInput, versionsInput)
+	pipeline.Add(StageInput, groupsInput)
+	pipeline.Add(StageMerged, versionsMerged)
+	pipeline.Add(StageMerged, groupsMerged)
+
+	if _, err := New(Config{InputPaths: []string{users}, Pipeline: pipeline}).MergeToDocument(); err == nil {
+		t.Fatal("Expected the first merge to fail")
+	}
+	doc, err := New(Config{InputPaths: []string{orders}, Pipeline: pipeline}).MergeToDocument()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := doc.Extensions["x-api-versions"]; !reflect.DeepEqual(got, map[string]string{"Orders": "2.0"}) {
+		t.Errorf("Expected only the versions of the second merge, got %v", got)
+	}
+	if got := doc.Extensions["x-tagGroups"]; !reflect.DeepEqual(got, []TagGroup{{Name: "Orders", Tags: []string{"orders"}}}) {
+		t.Errorf("Expected only the tag groups of the second merge, got %v", got)
+	}
+}
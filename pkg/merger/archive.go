@@ -0,0 +1,182 @@
+package merger
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// archiveSeparator separates an archive from a member in a source, e.g.
+// specs.zip!/users/openapi.yaml
+const archiveSeparator = "!/"
+
+// isArchive checks if a path names a zip or gzipped tar archive
+func isArchive(path string) bool {
+	if isURL(path) {
+		path = strings.SplitN(path, "?", 2)[0]
+	}
+	path = strings.ToLower(path)
+	return strings.HasSuffix(path, ".zip") || strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// isArchiveMember checks if a source names a file inside an archive
+func isArchiveMember(path string) bool {
+	archive, _, found := strings.Cut(path, archiveSeparator)
+	return found && isArchive(archive)
+}
+
+// isSpecMember checks if an archive member can hold a spec or a document
+// it references; other members are not unpacked
+func isSpecMember(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// archiveReader reads archive members up to a limit on their total
+// unpacked size, so a small archive cannot exhaust memory. A limit of zero
+// disables it.
+type archiveReader struct {
+	name  string
+	limit int64
+	read  int64
+}
+
+// readMember reads a member of the archive
+func (a *archiveReader) readMember(member string, r io.Reader) ([]byte, error) {
+	if a.limit <= 0 {
+		return io.ReadAll(r)
+	}
+	content, err := io.ReadAll(io.LimitReader(r, a.limit-a.read+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s: %v", member, a.name, err)
+	}
+	a.read += int64(len(content))
+	if a.read > a.limit {
+		return nil, fmt.Errorf("archive %s exceeds the %d byte limit once unpacked (at %s)", a.name, a.limit, member)
+	}
+	return content, nil
+}
+
+// archiveFiles returns the spec members of an archive and their content,
+// unpacking at most maxSize bytes (zero meaning no limit)
+func archiveFiles(name string, data []byte, maxSize int64) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	reader := &archiveReader{name: name, limit: maxSize}
+
+	if strings.HasSuffix(strings.ToLower(strings.SplitN(name, "?", 2)[0]), ".zip") {
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid zip archive %s: %v", name, err)
+		}
+		for _, f := range r.File {
+			if f.FileInfo().IsDir() || !isSpecMember(f.Name) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s from %s: %v", f.Name, name, err)
+			}
+			content, err := reader.readMember(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, err
+			}
+			files[f.Name] = content
+		}
+		return files, nil
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tar.gz archive %s: %v", name, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar.gz archive %s: %v", name, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !isSpecMember(hdr.Name) {
+			continue
+		}
+		content, err := reader.readMember(hdr.Name, tr)
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimPrefix(hdr.Name, "./")] = content
+	}
+	return files, nil
+}
+
+// loadArchive reads and unpacks an archive once per merge
+func (m *Merger) loadArchive(archive string) (map[string][]byte, error) {
+	m.archiveMu.Lock()
+	defer m.archiveMu.Unlock()
+	if files, ok := m.archives[archive]; ok {
+		return files, nil
+	}
+
+	data, err := m.readDataFromPath(archive)
+	if err != nil {
+		return nil, err
+	}
+	files, err := archiveFiles(archive, data, m.config.HTTP.maxSize())
+	if err != nil {
+		return nil, err
+	}
+
+	if m.archives == nil {
+		m.archives = make(map[string]map[string][]byte)
+	}
+	m.archives[archive] = files
+	return files, nil
+}
+
+// scanArchive returns the sources of the archive members matching the
+// scan patterns
+func (m *Merger) scanArchive(archive string) ([]string, error) {
+	if err := m.config.Scan.validate(); err != nil {
+		return nil, err
+	}
+
+	files, err := m.loadArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []string
+	for name := range files {
+		if m.config.Scan.match(name) {
+			sources = append(sources, archive+archiveSeparator+name)
+		}
+	}
+	sort.Strings(sources)
+	return sources, nil
+}
+
+// readArchiveMember reads a file inside an archive
+func (m *Merger) readArchiveMember(path string) ([]byte, error) {
+	archive, name, _ := strings.Cut(path, archiveSeparator)
+	files, err := m.loadArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in archive %s", name, archive)
+	}
+	return data, nil
+}
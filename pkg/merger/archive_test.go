@@ -0,0 +1,121 @@
+package merger
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testArchiveFiles are packed into the archives used by the tests
+var testArchiveFiles = map[string]string{
+	"specs/users.yaml":       testSpec,
+	"specs/orders/api.yaml":  testSpec,
+	"specs/README.md":        "not a spec",
+	"specs/fixtures/bad.txt": "ignored",
+}
+
+func testZip(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range testArchiveFiles {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func testTarGz(t *testing.T) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for name, content := range testArchiveFiles {
+		if err := w.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	w.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestMergeArchiveInputs(t *testing.T) {
+	archive := testTarGz(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "specs.zip")
+	if err := os.WriteFile(local, testZip(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(Config{
+		InputPaths: []string{local, server.URL + "/release/specs.tar.gz"},
+		OutputPath: filepath.Join(dir, "merged.yaml"),
+	})
+	stats, err := m.MergeWithStats()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats["total_files"] != 4 {
+		t.Errorf("Expected 4 files, got %d: %v", stats["total_files"], m.inputs)
+	}
+	if m.inputs[0] != local+"!/specs/orders/api.yaml" {
+		t.Errorf("Unexpected archive member source: %s", m.inputs[0])
+	}
+
+	m = New(Config{InputPaths: []string{local + "!/specs/missing.yaml"}, OutputPath: filepath.Join(dir, "merged.yaml")})
+	if err := m.Merge(); err == nil {
+		t.Error("Expected error for missing archive member")
+	}
+}
+
+func TestArchiveFilesLimit(t *testing.T) {
+	for name, data := range map[string][]byte{"specs.zip": testZip(t), "specs.tar.gz": testTarGz(t)} {
+		files, err := archiveFiles(name, data, 0)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(files) != 2 || files["specs/README.md"] != nil {
+			t.Errorf("Expected only the spec members of %s, got %d files", name, len(files))
+		}
+	}
+
+	// A member unpacking past the limit fails without being read whole
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, _ := w.Create("bomb.yaml")
+	f.Write(make([]byte, 4<<20))
+	w.Close()
+	if buf.Len() > 1<<20 {
+		t.Fatalf("Expected a compressed archive, got %d bytes", buf.Len())
+	}
+	if _, err := archiveFiles("bomb.zip", buf.Bytes(), 1<<20); err == nil || !strings.Contains(err.Error(), "exceeds the 1048576 byte limit") {
+		t.Errorf("Expected a size limit error, got %v", err)
+	}
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "bomb.zip")
+	if err := os.WriteFile(local, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := New(Config{InputPaths: []string{local}, OutputPath: filepath.Join(dir, "merged.yaml"), HTTP: HTTPConfig{MaxSize: 1 << 20}})
+	if err := m.Merge(); err == nil || !strings.Contains(err.Error(), "byte limit") {
+		t.Errorf("Expected the merge to fail on the size limit, got %v", err)
+	}
+}
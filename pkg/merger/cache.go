@@ -0,0 +1,125 @@
+package merger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// documentCache keeps converted inputs so repeated operations on the same
+// Merger do not convert unchanged inputs again. Inputs are read on every
+// merge; only their conversion is reused.
+type documentCache struct {
+	// mu guards the maps, inputs being loaded concurrently
+	mu sync.Mutex
+	// docs holds the JSON encoding of converted documents keyed by the
+	// hash of the content they were converted from, which is smaller than
+	// a copy of the document and decoded in a single pass
+	docs map[string][]byte
+	// hashes holds the hash of the content last converted for each source,
+	// so documents of earlier versions of an input are dropped
+	hashes map[string]string
+}
+
+func newDocumentCache() *documentCache {
+	return &documentCache{
+		docs:   make(map[string][]byte),
+		hashes: make(map[string]string),
+	}
+}
+
+// document returns the cached encoding of the document converted from
+// content with the given hash
+func (c *documentCache) document(hash string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.docs[hash]
+	return data, ok
+}
+
+// setDocument caches the encoding of the document converted from the
+// content of source, dropping the one of its previous content unless
+// another source shares it
+func (c *documentCache) setDocument(source, hash string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous, ok := c.hashes[source]
+	c.hashes[source] = hash
+	c.docs[hash] = data
+	if ok && previous != hash && !c.shared(previous) {
+		delete(c.docs, previous)
+	}
+}
+
+// shared reports whether a source still has content with the given hash
+func (c *documentCache) shared(hash string) bool {
+	for _, h := range c.hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// contentHash returns the hex encoded SHA-256 of data
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ClearCache drops all converted inputs, so the next operation converts
+// every input again
+func (m *Merger) ClearCache() {
+	m.cache = newDocumentCache()
+}
+
+// diskCacheVersion is part of every on-disk cache key, so bumping it
+// invalidates entries written by older releases
+const diskCacheVersion = "v2"
+
+// diskCachePath returns the file holding the document converted from
+// content with the given hash
+func diskCachePath(dir, hash string) string {
+	return filepath.Join(dir, "docs", contentHash([]byte(diskCacheVersion+"\x00"+hash))+".json")
+}
+
+// loadFromDisk reads a converted document, and its encoding, from the
+// on-disk cache. A missing or unreadable entry is reported as a cache miss.
+func (m *Merger) loadFromDisk(source, hash string) ([]byte, *openapi3.T, bool) {
+	if m.config.CacheDir == "" {
+		return nil, nil, false
+	}
+
+	data, err := os.ReadFile(diskCachePath(m.config.CacheDir, hash))
+	if err != nil {
+		return nil, nil, false
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		m.config.Logger.Warn("ignoring corrupt cache entry", "source", source, "error", err)
+		return nil, nil, false
+	}
+	return data, doc, true
+}
+
+// storeOnDisk writes the encoding of a converted document to the on-disk
+// cache
+func (m *Merger) storeOnDisk(source, hash string, data []byte) {
+	if m.config.CacheDir == "" {
+		return
+	}
+
+	path := diskCachePath(m.config.CacheDir, hash)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		m.config.Logger.Warn("failed to write cache entry", "source", source, "error", err)
+	}
+}
@@ -0,0 +1,174 @@
+package merger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestDocumentCache(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	var stages []ProgressStage
+	m := New(Config{
+		InputPaths: []string{file},
+		Progress: func(event ProgressEvent) {
+			stages = append(stages, event.Stage)
+		},
+	})
+
+	first, err := m.loadAndMerge()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	first.Info.Title = "Modified"
+
+	stages = nil
+	second, err := m.loadAndMerge()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Inputs are read again, but unchanged ones are not converted again
+	if len(stages) == 0 || stages[0] != ProgressFetching {
+		t.Errorf("Expected input to be read again, got %v", stages)
+	}
+	for _, stage := range stages {
+		if stage == ProgressConverting {
+			t.Errorf("Expected cached document, got stage %s", stage)
+		}
+	}
+
+	if second.Info.Title != "Test API" {
+		t.Errorf("Expected cached document to be unmodified, got title '%s'", second.Info.Title)
+	}
+
+	// An input edited between merges is merged as edited
+	if err := os.WriteFile(file, []byte(`openapi: "3.0.1"
+info:
+  title: Edited API
+  version: 1.0.0
+paths: {}`), 0644); err != nil {
+		t.Fatalf("Failed to update temp file: %v", err)
+	}
+	doc, err := m.MergeToDocument()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if doc.Info.Title != "Edited API" {
+		t.Errorf("Expected the edited input, got title '%s'", doc.Info.Title)
+	}
+	if len(m.cache.docs) != 1 {
+		t.Errorf("Expected the document of the previous content to be dropped, got %d cached", len(m.cache.docs))
+	}
+
+	// Clearing the cache converts the input again
+	m.ClearCache()
+	stages = nil
+	if _, err := m.loadAndMerge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	converted := false
+	for _, stage := range stages {
+		converted = converted || stage == ProgressConverting
+	}
+	if !converted {
+		t.Errorf("Expected input to be converted again, got %v", stages)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	file, err := createTempSwaggerFile(`swagger: "2.0"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	cacheDir := t.TempDir()
+	if _, err := New(Config{InputPaths: []string{file}, CacheDir: cacheDir}).loadAndMerge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A new Merger reuses the converted document from disk
+	var converted bool
+	m := New(Config{
+		InputPaths: []string{file},
+		CacheDir:   cacheDir,
+		Progress: func(event ProgressEvent) {
+			if event.Stage == ProgressConverting {
+				converted = true
+			}
+		},
+	})
+	merged, err := m.loadAndMerge()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if converted {
+		t.Error("Expected unchanged input to be loaded from the cache directory")
+	}
+
+	if merged.Info.Title != "Test API" {
+		t.Errorf("Expected title 'Test API', got '%s'", merged.Info.Title)
+	}
+
+	// Changed inputs are converted again
+	if err := os.WriteFile(file, []byte(`swagger: "2.0"
+info:
+  title: Changed API
+  version: 1.0.0
+paths: {}`), 0644); err != nil {
+		t.Fatalf("Failed to update temp file: %v", err)
+	}
+	merged, err = m.loadAndMerge()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !converted || merged.Info.Title != "Changed API" {
+		t.Errorf("Expected changed input to be converted, got title '%s'", merged.Info.Title)
+	}
+}
+
+func TestHTTPConditionalCache(t *testing.T) {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(testSpec))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		data, err := New(Config{CacheDir: cacheDir}).readDataFromPath(server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if string(data) != testSpec {
+			t.Errorf("Unexpected body on run %d: %s", i+1, data)
+		}
+	}
+
+	if downloads != 1 {
+		t.Errorf("Expected a single full download, got %d", downloads)
+	}
+}
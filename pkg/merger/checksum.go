@@ -0,0 +1,63 @@
+package merger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// checksumExtension is the info extension holding the checksum embedded
+// with Config.EmbedChecksum
+const checksumExtension = "x-checksum"
+
+// DocumentChecksum returns the "sha256:<hex>" checksum of a document: the
+// SHA-256 of its compact JSON encoding without info.x-checksum. It is
+// independent of the output format, so consumers can verify any output.
+func DocumentChecksum(doc *openapi3.T) (string, error) {
+	copied := *doc
+	if doc.Info != nil && doc.Info.Extensions[checksumExtension] != nil {
+		info := *doc.Info
+		info.Extensions = make(map[string]any, len(doc.Info.Extensions))
+		for key, value := range doc.Info.Extensions {
+			if key != checksumExtension {
+				info.Extensions[key] = value
+			}
+		}
+		copied.Info = &info
+	}
+	data, err := json.Marshal(&copied)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling to JSON: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// embedChecksum sets info.x-checksum to the checksum of doc
+func embedChecksum(doc *openapi3.T) error {
+	checksum, err := DocumentChecksum(doc)
+	if err != nil {
+		return err
+	}
+	if doc.Info == nil {
+		doc.Info = &openapi3.Info{}
+	}
+	if doc.Info.Extensions == nil {
+		doc.Info.Extensions = make(map[string]any)
+	}
+	doc.Info.Extensions[checksumExtension] = checksum
+	return nil
+}
+
+// checksumSidecar returns the content of the .sha256 file of an output, in
+// the format of sha256sum so `sha256sum -c` verifies it
+func checksumSidecar(output string, data []byte) []byte {
+	sum := sha256.Sum256(data)
+	name, _, _ := strings.Cut(path.Base(output), "?")
+	return []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name))
+}
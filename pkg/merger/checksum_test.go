@@ -0,0 +1,56 @@
+package merger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChecksumOutputs(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	dir := t.TempDir()
+	yamlOutput, jsonOutput := filepath.Join(dir, "merged.yaml"), filepath.Join(dir, "merged.json")
+	m := New(Config{
+		InputPaths:      []string{file},
+		OutputPath:      yamlOutput,
+		Outputs:         []string{jsonOutput},
+		ChecksumSidecar: true,
+		EmbedChecksum:   true,
+	})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, output := range []string{yamlOutput, jsonOutput} {
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sidecar, err := os.ReadFile(output + ".sha256")
+		if err != nil {
+			t.Fatalf("Expected a checksum sidecar for %s: %v", output, err)
+		}
+		sum := sha256.Sum256(data)
+		if expected := hex.EncodeToString(sum[:]) + "  " + filepath.Base(output) + "\n"; string(sidecar) != expected {
+			t.Errorf("Expected sidecar %q, got %q", expected, sidecar)
+		}
+
+		doc := loadSpec(t, string(data))
+		embedded, _ := doc.Info.Extensions["x-checksum"].(string)
+		if checksum, err := DocumentChecksum(doc); err != nil || !strings.HasPrefix(embedded, "sha256:") || checksum != embedded {
+			t.Errorf("%s: expected the embedded checksum %s to match %s (%v)", output, embedded, checksum, err)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SchemaStrategy controls how schemas defined under the same name by more
+// than one input are combined
+type SchemaStrategy string
+
+const (
+	// SchemaOverwrite keeps the definition of the last input and reports a
+	// conflict when the definitions differ
+	SchemaOverwrite SchemaStrategy = "overwrite"
+	// SchemaUnion merges the definitions into one schema with the
+	// properties of all of them, for names that are intentionally the same
+	// model evolving at different speeds. Properties stay required only if
+	// every definition requires them, and differing types fail the merge.
+	SchemaUnion SchemaStrategy = "union"
+	// SchemaStrict allows a name to be defined by more than one input only
+	// if the definitions are deeply equal, and otherwise fails the merge
+	// with a SchemaMismatchError
+	SchemaStrict SchemaStrategy = "strict"
+	// SchemaRename keeps every definition: schemas an input defines
+	// differently from a previous input are renamed with a short hash of
+	// their content (User_a1b2c3), and the input's references rewritten,
+	// so repeated merges produce the same names without a rename map
+	SchemaRename SchemaStrategy = "rename"
+)
+
+// WithSchemaStrategy sets how schemas with the same name are combined
+// (default: SchemaOverwrite)
+func WithSchemaStrategy(strategy SchemaStrategy) Option {
+	return func(o *mergeOptions) {
+		o.schemaStrategy = strategy
+	}
+}
+
+// WithIgnoreSchemaDocs makes SchemaStrict ignore titles, descriptions,
+// examples and externalDocs when comparing schemas
+func WithIgnoreSchemaDocs() Option {
+	return func(o *mergeOptions) {
+		o.ignoreSchemaDocs = true
+	}
+}
+
+// validate checks that the strategy is known
+func (s SchemaStrategy) validate() error {
+	switch s {
+	case "", SchemaOverwrite, SchemaUnion, SchemaStrict, SchemaRename:
+		return nil
+	}
+	return fmt.Errorf("invalid schema strategy %q (expected overwrite, union, strict or rename)", s)
+}
+
+// mergeSchemas copies the schemas of src into dst according to the schema
+// strategy
+func mergeSchemas(st *mergeState, dst, src openapi3.Schemas, source string) error {
+	src = mergeDiscriminatorMappings(st, dst, src, source)
+	src = mergeEnumUnions(st, dst, src, source)
+	switch st.opts.schemaStrategy {
+	case SchemaUnion:
+	case SchemaStrict:
+		var strip func(any)
+		if st.opts.ignoreSchemaDocs {
+			strip = func(v any) { stripFields(v, documentationFields) }
+		}
+		return mergeStrict(st, "schema", dst, src, source, strip)
+	default:
+		mergeNamed(st, "schema", dst, src, source)
+		return nil
+	}
+
+	for _, name := range sortedKeys(src) {
+		schema := src[name]
+		if existing, ok := dst[name]; ok && !equalJSON(existing, schema) {
+			union, err := unionSchemaRefs(name, existing, schema)
+			if err != nil {
+				return fmt.Errorf("cannot union schema %s from %s with %s: %v", name, source, st.owner("schema", name), err)
+			}
+			schema = union
+		}
+		dst[name] = schema
+		st.own("schema", name, source)
+	}
+	return nil
+}
+
+// mergeExamples copies the examples of src into dst. SchemaStrict applies
+// to examples too, ignoring their summary and description with
+// WithIgnoreSchemaDocs; examples can't be unioned, so SchemaUnion
+// overwrites them like SchemaOverwrite.
+func mergeExamples(st *mergeState, dst, src openapi3.Examples, source string) error {
+	if st.opts.schemaStrategy != SchemaStrict {
+		mergeNamed(st, "example", dst, src, source)
+		return nil
+	}
+	var strip func(any)
+	if st.opts.ignoreSchemaDocs {
+		strip = func(v any) {
+			if example, ok := v.(map[string]any); ok {
+				delete(example, "summary")
+				delete(example, "description")
+			}
+		}
+	}
+	return mergeStrict(st, "example", dst, src, source, strip)
+}
+
+// mergeStrict copies the components of src into dst, failing on the
+// first name whose definitions differ. strip, when set, removes the
+// fields ignored by the comparison from the JSON of a definition.
+func mergeStrict[V any](st *mergeState, kind string, dst, src map[string]V, source string, strip func(any)) error {
+	for _, name := range sortedKeys(src) {
+		v := src[name]
+		if existing, ok := dst[name]; ok {
+			diffs, err := diffValues(existing, v, strip)
+			if err != nil {
+				return fmt.Errorf("failed to compare %s %s: %v", kind, name, err)
+			}
+			if len(diffs) > 0 {
+				return &SchemaMismatchError{
+					Kind:    kind,
+					Name:    name,
+					Sources: []string{st.owner(kind, name), source},
+					Diffs:   diffs,
+				}
+			}
+			continue
+		}
+		dst[name] = v
+		st.own(kind, name, source)
+	}
+	return nil
+}
+
+// SchemaMismatchError reports a schema, or another component merged with
+// the same policy, defined differently by two inputs under SchemaStrict
+type SchemaMismatchError struct {
+	// Kind is the component type: "schema" or "example"
+	Kind string `json:"kind"`
+	// Name is the component name
+	Name string `json:"name"`
+	// Sources lists the input that defined the schema first and the input
+	// that redefined it
+	Sources []string `json:"sources"`
+	// Diffs lists the differences between the two definitions
+	Diffs []SchemaDiff `json:"diffs"`
+}
+
+// SchemaDiff is a difference between two schema definitions. Path is a
+// dotted path into the schema ("" for the schema itself); a side without
+// a value at the path is nil.
+type SchemaDiff struct {
+	Path  string `json:"path"`
+	Left  any    `json:"left"`
+	Right any    `json:"right"`
+}
+
+func (e *SchemaMismatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s is defined differently in %s and %s:", e.Kind, e.Name, e.Sources[0], e.Sources[1])
+	for _, d := range e.Diffs {
+		path := d.Path
+		if path == "" {
+			path = "(root)"
+		}
+		fmt.Fprintf(&b, "\n  %s: %s != %s", path, diffValue(d.Left), diffValue(d.Right))
+	}
+	return b.String()
+}
+
+// diffValue formats one side of a difference
+func diffValue(v any) string {
+	if v == nil {
+		return "(missing)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// diffValues returns the differences between the JSON of two values,
+// after applying strip when set
+func diffValues(a, b any, strip func(any)) ([]SchemaDiff, error) {
+	var values [2]any
+	for i, v := range []any{a, b} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &values[i]); err != nil {
+			return nil, err
+		}
+		if strip != nil {
+			strip(values[i])
+		}
+	}
+	var diffs []SchemaDiff
+	diffJSON("", values[0], values[1], &diffs)
+	return diffs, nil
+}
+
+// diffJSON appends the differences between two generic JSON values to
+// diffs, descending into objects
+func diffJSON(path string, a, b any, diffs *[]SchemaDiff) {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if !aok || !bok {
+		if !equalJSON(a, b) {
+			*diffs = append(*diffs, SchemaDiff{Path: path, Left: a, Right: b})
+		}
+		return
+	}
+
+	keys := make(map[string]bool)
+	for key := range am {
+		keys[key] = true
+	}
+	for key := range bm {
+		keys[key] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		child := key
+		if path != "" {
+			child = path + "." + key
+		}
+		diffJSON(child, am[key], bm[key], diffs)
+	}
+}
+
+// unionSchemaRefs unions two schemas found at path
+func unionSchemaRefs(path string, a, b *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	switch {
+	case a == nil:
+		return b, nil
+	case b == nil:
+		return a, nil
+	case a.Ref != "" || b.Ref != "":
+		if a.Ref != b.Ref {
+			return nil, fmt.Errorf("%s references %q and %q", path, a.Ref, b.Ref)
+		}
+		return a, nil
+	case a.Value == nil:
+		return b, nil
+	case b.Value == nil:
+		return a, nil
+	}
+	value, err := unionSchemas(path, a.Value, b.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &openapi3.SchemaRef{Value: value}, nil
+}
+
+// unionSchemas returns a schema with the properties of a and b. Other
+// fields are taken from a, or from b where a leaves them empty.
+func unionSchemas(path string, a, b *openapi3.Schema) (*openapi3.Schema, error) {
+	if a.Type != nil && b.Type != nil && !equalJSON(a.Type, b.Type) {
+		return nil, fmt.Errorf("%s has type %v and %v", path, a.Type.Slice(), b.Type.Slice())
+	}
+
+	union := *a
+	if union.Type == nil {
+		union.Type = b.Type
+	}
+	if union.Description == "" {
+		union.Description = b.Description
+	}
+	if union.Format == "" {
+		union.Format = b.Format
+	}
+
+	if len(a.Properties) > 0 || len(b.Properties) > 0 {
+		union.Properties = make(openapi3.Schemas, len(a.Properties)+len(b.Properties))
+		for name, prop := range a.Properties {
+			union.Properties[name] = prop
+		}
+		for _, name := range sortedKeys(b.Properties) {
+			prop := b.Properties[name]
+			if existing, ok := union.Properties[name]; ok && !equalJSON(existing, prop) {
+				merged, err := unionSchemaRefs(path+"."+name, existing, prop)
+				if err != nil {
+					return nil, err
+				}
+				prop = merged
+			}
+			union.Properties[name] = prop
+		}
+	}
+	union.Required = commonRequired(a, b)
+
+	if a.Items != nil || b.Items != nil {
+		items, err := unionSchemaRefs(path+"[]", a.Items, b.Items)
+		if err != nil {
+			return nil, err
+		}
+		union.Items = items
+	}
+	return &union, nil
+}
+
+// commonRequired returns the properties required by both a and b
+func commonRequired(a, b *openapi3.Schema) []string {
+	var required []string
+	for _, name := range a.Required {
+		if slices.Contains(b.Required, name) {
+			required = append(required, name)
+		}
+	}
+	return required
+}
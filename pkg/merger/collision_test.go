@@ -0,0 +1,195 @@
+package merger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaDoc returns a document defining a single User schema
+func schemaDoc(t *testing.T, user string) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths: {}
+components:
+  schemas:
+    User:
+`+user)
+}
+
+func TestMergeDocumentsSchemaUnion(t *testing.T) {
+	a := schemaDoc(t, `
+      type: object
+      required: [id, name]
+      properties:
+        id: {type: string}
+        name: {type: string}
+        address:
+          type: object
+          properties:
+            city: {type: string}
+`)
+	b := schemaDoc(t, `
+      type: object
+      required: [id, email]
+      properties:
+        id: {type: string}
+        email: {type: string}
+        address:
+          type: object
+          properties:
+            zip: {type: string}
+`)
+
+	merged, err := MergeDocuments([]*openapi3.T{a, b}, WithSchemaStrategy(SchemaUnion))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	user := merged.Components.Schemas["User"].Value
+	for _, name := range []string{"id", "name", "email", "address"} {
+		if user.Properties[name] == nil {
+			t.Errorf("Expected property %s in the union", name)
+		}
+	}
+	address := user.Properties["address"].Value
+	if address.Properties["city"] == nil || address.Properties["zip"] == nil {
+		t.Errorf("Expected nested properties to be unioned, got %v", address.Properties)
+	}
+	if len(user.Required) != 1 || user.Required[0] != "id" {
+		t.Errorf("Expected only id to stay required, got %v", user.Required)
+	}
+}
+
+func TestMergeDocumentsSchemaUnionTypeConflict(t *testing.T) {
+	a := schemaDoc(t, `
+      type: object
+      properties:
+        id: {type: string}
+`)
+	b := schemaDoc(t, `
+      type: object
+      properties:
+        id: {type: integer}
+`)
+
+	_, err := MergeDocuments([]*openapi3.T{a, b},
+		WithSourceNames("a.yaml", "b.yaml"),
+		WithSchemaStrategy(SchemaUnion))
+	if err == nil {
+		t.Fatal("Expected a type conflict error")
+	}
+	for _, want := range []string{"User.id", "b.yaml", "a.yaml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %s, got %v", want, err)
+		}
+	}
+}
+
+func TestMergeDocumentsInvalidSchemaStrategy(t *testing.T) {
+	_, err := MergeDocuments([]*openapi3.T{schemaDoc(t, "      type: object\n")}, WithSchemaStrategy("merge"))
+	if err == nil {
+		t.Error("Expected an invalid strategy error")
+	}
+}
+
+func TestMergeDocumentsSchemaStrict(t *testing.T) {
+	a := schemaDoc(t, `
+      type: object
+      description: A user
+      properties:
+        id: {type: string}
+        name: {type: string}
+`)
+	b := schemaDoc(t, `
+      type: object
+      description: The user
+      properties:
+        id: {type: integer}
+        email: {type: string}
+`)
+
+	_, err := MergeDocuments([]*openapi3.T{a, b},
+		WithSourceNames("a.yaml", "b.yaml"),
+		WithSchemaStrategy(SchemaStrict),
+		WithIgnoreSchemaDocs())
+	var mismatch *SchemaMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Expected a SchemaMismatchError, got %v", err)
+	}
+	if mismatch.Name != "User" || mismatch.Sources[0] != "a.yaml" || mismatch.Sources[1] != "b.yaml" {
+		t.Errorf("Unexpected mismatch: %+v", mismatch)
+	}
+
+	var paths []string
+	for _, d := range mismatch.Diffs {
+		paths = append(paths, d.Path)
+	}
+	if got := strings.Join(paths, ","); got != "properties.email,properties.id.type,properties.name" {
+		t.Errorf("Unexpected diff paths: %s", got)
+	}
+	if !strings.Contains(err.Error(), `properties.id.type: "string" != "integer"`) {
+		t.Errorf("Expected the diff in the error, got:\n%v", err)
+	}
+}
+
+func TestMergeDocumentsSchemaStrictEqual(t *testing.T) {
+	a := schemaDoc(t, "      type: object\n      description: A user\n")
+	b := schemaDoc(t, "      type: object\n      description: The user\n")
+
+	if _, err := MergeDocuments([]*openapi3.T{a, b}, WithSchemaStrategy(SchemaStrict), WithIgnoreSchemaDocs()); err != nil {
+		t.Errorf("Expected schemas differing in descriptions to be accepted, got %v", err)
+	}
+	a = schemaDoc(t, "      type: object\n      description: A user\n")
+	if _, err := MergeDocuments([]*openapi3.T{a, b}, WithSchemaStrategy(SchemaStrict)); err == nil {
+		t.Error("Expected differing descriptions to fail without WithIgnoreSchemaDocs")
+	}
+}
+
+func TestMergeDocumentsExamples(t *testing.T) {
+	load := func(summary, name string) *openapi3.T {
+		return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths: {}
+components:
+  examples:
+    Ann:
+      summary: `+summary+`
+      value: {name: `+name+`}
+`)
+	}
+
+	var conflicts []Conflict
+	merged, err := MergeDocuments([]*openapi3.T{load("A user", "Ann"), load("A user", "Anna")},
+		WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := merged.Components.Examples["Ann"].Value.Value.(map[string]any)["name"]; got != "Anna" {
+		t.Errorf("Expected the last example to win, got %v", got)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != "example" {
+		t.Errorf("Expected an example conflict, got %v", conflicts)
+	}
+
+	_, err = MergeDocuments([]*openapi3.T{load("A user", "Ann"), load("Another user", "Ann")},
+		WithSchemaStrategy(SchemaStrict), WithIgnoreSchemaDocs())
+	if err != nil {
+		t.Errorf("Expected examples differing in summary to be accepted, got %v", err)
+	}
+
+	_, err = MergeDocuments([]*openapi3.T{load("A user", "Ann"), load("A user", "Anna")},
+		WithSourceNames("a.yaml", "b.yaml"), WithSchemaStrategy(SchemaStrict))
+	var mismatch *SchemaMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Kind != "example" || mismatch.Diffs[0].Path != "value.name" {
+		t.Fatalf("Expected an example mismatch, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "example Ann is defined differently in a.yaml and b.yaml:") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package merger
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// commentIndex holds the comments of the inputs of a merge
+type commentIndex struct {
+	// top are the top-of-file comments of the inputs, in input order
+	top []string
+	// keys are the comments of keys and their values, by location in the
+	// document
+	keys map[string]nodeComments
+}
+
+// comments are the head, line and foot comments of a YAML node
+type comments struct {
+	head, line, foot string
+}
+
+// nodeComments are the comments of a key of an input and its value
+type nodeComments struct {
+	key, value comments
+	// scalar is the value of scalar values, whose comments are only kept
+	// while the merge leaves the value unchanged
+	scalar   string
+	isScalar bool
+}
+
+// commentsOf returns the comments of a node
+func commentsOf(node *yaml.Node) comments {
+	return comments{head: node.HeadComment, line: node.LineComment, foot: node.FootComment}
+}
+
+// empty reports whether there is no comment
+func (c comments) empty() bool {
+	return c == comments{}
+}
+
+// attach sets the comments a node does not have yet
+func (c comments) attach(node *yaml.Node) {
+	if node.HeadComment == "" {
+		node.HeadComment = c.head
+	}
+	if node.LineComment == "" {
+		node.LineComment = c.line
+	}
+	if node.FootComment == "" {
+		node.FootComment = c.foot
+	}
+}
+
+// recordSource records the key order and comments of an input, when the
+// merge keeps them
+func (m *Merger) recordSource(data []byte) {
+	if m.keyOrder == nil && m.comments == nil {
+		return
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return
+	}
+	if m.keyOrder != nil {
+		m.keyOrder.record(&doc)
+	}
+	if m.comments != nil {
+		m.comments.record(&doc)
+	}
+}
+
+// record adds the comments of an input document. Comments of a location
+// already commented by a previous input are ignored. The head comment of
+// the first key is a top-of-file comment.
+func (c *commentIndex) record(doc *yaml.Node) {
+	root := doc.Content[0]
+	top := doc.HeadComment
+	if len(root.Content) > 0 && root.Content[0].HeadComment != "" {
+		top = joinComments(top, root.Content[0].HeadComment)
+		root.Content[0].HeadComment = ""
+	}
+	if top != "" && !slices.Contains(c.top, top) {
+		c.top = append(c.top, top)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		c.walk(rootLocation(root, root.Content[i].Value), root.Content[i], root.Content[i+1])
+	}
+}
+
+// walk records the comments of a key and its value, and of the value's
+// descendants. key is nil for sequence items.
+func (c *commentIndex) walk(location string, key, value *yaml.Node) {
+	if _, ok := c.keys[location]; !ok {
+		var comments nodeComments
+		if key != nil {
+			comments.key = commentsOf(key)
+		}
+		comments.value = commentsOf(value)
+		if !comments.key.empty() || !comments.value.empty() {
+			comments.scalar, comments.isScalar = value.Value, value.Kind == yaml.ScalarNode
+			c.keys[location] = comments
+		}
+	}
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			c.walk(orderLocation(location, value.Content[i].Value), value.Content[i], value.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range value.Content {
+			c.walk(location+"/"+strconv.Itoa(i), nil, item)
+		}
+	}
+}
+
+// apply attaches the recorded comments to the nodes of an output document
+// at the same location
+func (c *commentIndex) apply(node *yaml.Node) {
+	if c == nil {
+		return
+	}
+	root := node
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if len(c.top) > 0 {
+		node.HeadComment = joinComments(strings.Join(c.top, "\n\n"), node.HeadComment)
+	}
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			c.attach(orderLocation("", root.Content[i].Value), root.Content[i], root.Content[i+1])
+		}
+	}
+}
+
+// attach attaches the comments of a location to a key and its value, and
+// those of the value's descendants. key is nil for sequence items.
+func (c *commentIndex) attach(location string, key, value *yaml.Node) {
+	if comments, ok := c.keys[location]; ok {
+		// Block collections are emitted below their key, which carries
+		// their line comment
+		if value.Kind != yaml.ScalarNode {
+			if key != nil && comments.key.line == "" {
+				comments.key.line = comments.value.line
+			}
+			comments.value.line = ""
+		}
+		if key != nil {
+			comments.key.attach(key)
+		}
+		if !comments.isScalar || (value.Kind == yaml.ScalarNode && value.Value == comments.scalar) {
+			comments.value.attach(value)
+		}
+	}
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			c.attach(orderLocation(location, value.Content[i].Value), value.Content[i], value.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range value.Content {
+			c.attach(location+"/"+strconv.Itoa(i), nil, item)
+		}
+	}
+}
+
+// joinComments joins two comments, either of which may be empty
+func joinComments(a, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "\n" + b
+}
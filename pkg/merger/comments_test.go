@@ -0,0 +1,82 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergePreserveComments(t *testing.T) {
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.yaml")
+	os.WriteFile(users, []byte(`# Users service
+# Owner: team-identity
+
+openapi: "3.0.1"
+info:
+  title: Users # service title
+  version: 1.0.0
+paths:
+  # public endpoint
+  /users:
+    get:
+      summary: List users # keep short
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id: {type: string} # uuid
+`), 0644)
+	orders := filepath.Join(dir, "orders.yaml")
+	os.WriteFile(orders, []byte(`# Orders service
+openapi: "3.0.1"
+info:
+  title: Orders # replaced by the first title
+  version: 2.0.0
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok # orders found
+`), 0644)
+
+	output := filepath.Join(dir, "merged.yaml")
+	m := New(Config{InputPaths: []string{users, orders}, OutputPath: output, PreserveComments: true})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := os.ReadFile(output)
+	merged := string(data)
+
+	if !strings.HasPrefix(merged, "# Users service\n# Owner: team-identity\n\n# Orders service\n") {
+		t.Errorf("Expected the top-of-file comments of both inputs first, got:\n%s", merged)
+	}
+	for _, line := range []string{
+		"title: Users # service title",
+		"# public endpoint",
+		"summary: List users # keep short",
+		"id: # uuid",
+		"description: ok # orders found",
+	} {
+		if !strings.Contains(merged, line) {
+			t.Errorf("Expected %q in the output, got:\n%s", line, merged)
+		}
+	}
+	if strings.Contains(merged, "replaced by the first title") {
+		t.Errorf("Expected the comment of a replaced value to be dropped, got:\n%s", merged)
+	}
+
+	m = New(Config{InputPaths: []string{users}, OutputPath: output})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if data, _ := os.ReadFile(output); strings.Contains(string(data), "#") {
+		t.Errorf("Expected no comments by default, got:\n%s", data)
+	}
+}
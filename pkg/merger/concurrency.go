@@ -0,0 +1,69 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// DefaultConcurrency is the number of inputs loaded at once when
+// Config.Concurrency is not set
+const DefaultConcurrency = 4
+
+// loadedInput is the result of loading an input
+type loadedInput struct {
+	doc  *openapi3.T
+	data []byte
+	err  error
+}
+
+// concurrency returns the number of inputs to load at once
+func (m *Merger) concurrency() int {
+	if m.config.Concurrency > 0 {
+		return m.config.Concurrency
+	}
+	return DefaultConcurrency
+}
+
+// loadInputs downloads and parses inputs, up to concurrency at once, and
+// returns the results in input order
+func (m *Merger) loadInputs(inputs []string) []loadedInput {
+	results := make([]loadedInput, len(inputs))
+	workers := min(m.concurrency(), len(inputs))
+	if workers <= 1 {
+		for i, input := range inputs {
+			results[i] = m.loadInput(i, input)
+		}
+		return results
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = m.loadInput(i, inputs[i])
+			}
+		}()
+	}
+	for i := range inputs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// loadInput loads the input at index i, unless the merge was interrupted
+func (m *Merger) loadInput(i int, input string) (result loadedInput) {
+	if ctx := m.context(); ctx.Err() != nil {
+		result.err = fmt.Errorf("not loaded: %v", context.Cause(ctx))
+		return result
+	}
+	result.doc, result.data, result.err = m.loadDocument(i+1, input)
+	return result
+}
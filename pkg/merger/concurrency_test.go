@@ -0,0 +1,66 @@
+package merger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestConcurrency(t *testing.T) {
+	var (
+		mu             sync.Mutex
+		inFlight, peak int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprintf(w, "openapi: 3.0.0\ninfo: {title: %[1]s, version: \"1.0\"}\npaths:\n  %[1]s: {get: {responses: {\"200\": {description: ok}}}}\n", r.URL.Path)
+	}))
+	defer server.Close()
+
+	var inputs []string
+	for i := 0; i < 8; i++ {
+		inputs = append(inputs, fmt.Sprintf("%s/service%d", server.URL, i))
+	}
+	for _, concurrency := range []int{1, 3} {
+		peak = 0
+		var order []string
+		pipeline := &Pipeline{}
+		pipeline.Add(StageInput, NewTransformer("order", func(doc *openapi3.T, source string) error {
+			order = append(order, doc.Info.Title)
+			return nil
+		}))
+		m := New(Config{InputPaths: inputs, Concurrency: concurrency, Pipeline: pipeline})
+		merged, err := m.loadAndMerge()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if merged.Paths.Len() != len(inputs) {
+			t.Errorf("Expected %d paths, got %d", len(inputs), merged.Paths.Len())
+		}
+		if peak > concurrency || (concurrency > 1 && peak < 2) {
+			t.Errorf("Expected up to %d concurrent downloads, got %d", concurrency, peak)
+		}
+		// Inputs are transformed in order whatever the concurrency
+		for i, title := range order {
+			if title != fmt.Sprintf("/service%d", i) {
+				t.Errorf("Expected /service%d to be transformed at %d, got %s", i, i, title)
+			}
+		}
+	}
+
+	if _, err := New(Config{InputPaths: inputs, Concurrency: -1}).loadAndMerge(); err == nil {
+		t.Error("Expected an error for a negative concurrency")
+	}
+}
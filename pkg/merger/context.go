@@ -0,0 +1,34 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+)
+
+// context returns the context of the running merge, or the background
+// context outside MergeContext
+func (m *Merger) context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// withContext makes ctx the context of the merger's remote calls and
+// external tools while fn runs
+func (m *Merger) withContext(ctx context.Context, fn func() error) error {
+	previous := m.ctx
+	m.ctx = ctx
+	defer func() { m.ctx = previous }()
+	return fn()
+}
+
+// interrupted returns an error naming the step the merge was stopped at
+// when its context is done
+func (m *Merger) interrupted(step string) error {
+	ctx := m.context()
+	if ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("merge interrupted while %s: %v", step, context.Cause(ctx))
+}
@@ -0,0 +1,72 @@
+package merger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMergeContext(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		fmt.Fprintf(w, "openapi: 3.0.0\ninfo: {title: Fast, version: \"1.0\"}\npaths: {}\n")
+	}))
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "merged.yaml")
+	m := New(Config{InputPaths: []string{server.URL + "/fast", server.URL + "/slow"}, OutputPath: output, SkipInvalid: true})
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond, errors.New("merge timeout of 200ms exceeded"))
+	defer cancel()
+	start := time.Now()
+	err := m.MergeContext(ctx)
+	if err == nil {
+		t.Fatal("Expected the merge to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the merge to stop at the deadline, took %v", elapsed)
+	}
+	for _, expected := range []string{"merge interrupted while loading inputs", "merge timeout of 200ms exceeded", server.URL + "/slow"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error to mention %q, got %v", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), server.URL+"/fast") {
+		t.Errorf("Expected only the slow input in the error, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("Expected no output after a timeout")
+	}
+
+	// Nothing is fetched once the context is done
+	requests.Store(0)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.MergeWithStatsContext(cancelled); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no requests, got %d", n)
+	}
+	if _, err := m.MergeToDocumentContext(cancelled); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("Expected a cancellation error without writing outputs, got %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no requests, got %d", n)
+	}
+}
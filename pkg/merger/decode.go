@@ -0,0 +1,322 @@
+package merger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// bufferPool holds the buffers inputs are converted to JSON in, reused
+// across inputs and merges
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// maxPooledBuffer is the capacity above which buffers are dropped rather
+// than pooled, so one huge input does not pin its memory
+const maxPooledBuffer = 64 << 20
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns a buffer to the pool
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBuffer {
+		bufferPool.Put(buf)
+	}
+}
+
+// sniffVersion finds the swagger or openapi field of a document without
+// parsing it: in the top-level keys of a JSON object, read as a token
+// stream, or in the unindented lines of a YAML document. It fails on
+// documents it cannot read this way, such as flow-style YAML.
+func sniffVersion(data []byte) (*SwaggerVersion, bool) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return sniffJSONVersion(trimmed)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+			continue
+		}
+		if bytes.Equal(bytes.TrimSpace(line), []byte("---")) {
+			continue
+		}
+		key, value, found := bytes.Cut(line, []byte(":"))
+		if !found {
+			return nil, false
+		}
+		key = bytes.Trim(bytes.TrimSpace(key), `"'`)
+		if string(key) != "swagger" && string(key) != "openapi" {
+			continue
+		}
+		if i := bytes.Index(value, []byte(" #")); i >= 0 {
+			value = value[:i]
+		}
+		version := string(bytes.Trim(bytes.TrimSpace(value), `"'`))
+		if version == "" {
+			return nil, false
+		}
+		return &SwaggerVersion{Version: version, IsYAML: true}, true
+	}
+	return nil, false
+}
+
+// sniffJSONVersion reads the top-level keys of a JSON object up to the
+// swagger or openapi one, skipping the values of the others
+func sniffJSONVersion(data []byte) (*SwaggerVersion, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return nil, false
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, _ := token.(string)
+		if key != "swagger" && key != "openapi" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, false
+			}
+			continue
+		}
+		var version any
+		if err := dec.Decode(&version); err != nil {
+			return nil, false
+		}
+		return &SwaggerVersion{Version: fmt.Sprintf("%v", version), IsYAML: false}, true
+	}
+	return nil, false
+}
+
+// transcodeYAML writes a YAML document to buf as JSON, without decoding it
+// into maps first. Mapping keys become strings, aliases and merge keys
+// are expanded, and timestamps and custom tags are kept as strings.
+func transcodeYAML(data []byte, buf *bytes.Buffer) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return fmt.Errorf("empty document")
+	}
+	return transcodeNode(buf, doc.Content[0], 0)
+}
+
+// maxNodeDepth bounds the nesting of converted YAML, which aliases could
+// otherwise make infinite
+const maxNodeDepth = 1000
+
+// transcodeNode writes a YAML node as JSON
+func transcodeNode(buf *bytes.Buffer, node *yaml.Node, depth int) error {
+	if depth > maxNodeDepth {
+		return fmt.Errorf("line %d: document nested deeper than %d levels", node.Line, maxNodeDepth)
+	}
+	switch node.Kind {
+	case yaml.AliasNode:
+		return transcodeNode(buf, node.Alias, depth+1)
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := transcodeNode(buf, item, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		first := true
+		if err := transcodeMapping(buf, node, &first, depth); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+	case yaml.ScalarNode:
+		return transcodeScalar(buf, node)
+	}
+	return fmt.Errorf("line %d: unsupported YAML node", node.Line)
+}
+
+// transcodeMapping writes the entries of a mapping. Entries of merge keys
+// are written first, so the mapping's own entries override them where
+// JSON decoders keep the last duplicate key.
+func transcodeMapping(buf *bytes.Buffer, node *yaml.Node, first *bool, depth int) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := resolveAlias(node.Content[i]), node.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			continue
+		}
+		merged := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			merged = value.Content
+		}
+		// Earlier mappings of a merge sequence take precedence
+		for j := len(merged) - 1; j >= 0; j-- {
+			m := resolveAlias(merged[j])
+			if m.Kind != yaml.MappingNode {
+				return fmt.Errorf("line %d: merge key value is not a mapping", m.Line)
+			}
+			if err := transcodeMapping(buf, m, first, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := resolveAlias(node.Content[i]), node.Content[i+1]
+		if key.ShortTag() == "!!merge" {
+			continue
+		}
+		if key.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: mapping keys must be scalars", key.Line)
+		}
+		if !*first {
+			buf.WriteByte(',')
+		}
+		*first = false
+		writeJSONString(buf, key.Value)
+		buf.WriteByte(':')
+		if err := transcodeNode(buf, value, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node an alias refers to
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// transcodeScalar writes a scalar with its resolved YAML type
+func transcodeScalar(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.ShortTag() {
+	case "!!null":
+		buf.WriteString("null")
+		return nil
+	case "!!bool", "!!int", "!!float":
+	default:
+		// Strings, timestamps and custom tags
+		writeJSONString(buf, node.Value)
+		return nil
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return fmt.Errorf("line %d: %v", node.Line, err)
+	}
+	switch v := value.(type) {
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		buf.WriteString(strconv.Itoa(v))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(v, 10))
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return fmt.Errorf("line %d: %s cannot be represented in JSON", node.Line, node.Value)
+		}
+		buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		writeJSONString(buf, node.Value)
+	default:
+		writeJSONString(buf, fmt.Sprint(v))
+	}
+	return nil
+}
+
+// writeJSONString writes s as a JSON string, replacing invalid UTF-8
+func writeJSONString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case c == '\n':
+				buf.WriteString(`\n`)
+			case c == '\r':
+				buf.WriteString(`\r`)
+			case c == '\t':
+				buf.WriteString(`\t`)
+			case c < 0x20:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			default:
+				buf.WriteByte(c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(`\ufffd`)
+		} else {
+			buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+}
+
+// loadOpenAPI3 parses an OpenAPI 3.x input. YAML is converted to JSON in
+// a pooled buffer, which the loader decodes directly. Inputs that fail
+// are loaded again through the loader's own YAML support, so errors read
+// the same as before.
+func loadOpenAPI3(data []byte, isYAML bool) (*openapi3.T, error) {
+	if !isYAML {
+		return openapi3.NewLoader().LoadFromData(data)
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := transcodeYAML(data, buf); err == nil {
+		if doc, err := openapi3.NewLoader().LoadFromData(buf.Bytes()); err == nil {
+			return doc, nil
+		}
+	}
+	return openapi3.NewLoader().LoadFromData(data)
+}
+
+// encodeDocument returns the compact JSON encoding of a converted
+// document, which the caches keep instead of a second document tree
+func encodeDocument(doc *openapi3.T) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+	return bytes.Clone(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
@@ -0,0 +1,257 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSniffVersion(t *testing.T) {
+	tests := []struct {
+		data    string
+		version string
+		isYAML  bool
+		ok      bool
+	}{
+		{"# spec\n---\nopenapi: 3.0.3 # comment\ninfo: {}\n", "3.0.3", true, true},
+		{"info:\n  openapi: nested\nswagger: '2.0'\n", "2.0", true, true},
+		{`{"info": {"openapi": "nested"}, "openapi": "3.1.0"}`, "3.1.0", false, true},
+		{"\ufeff  {\"swagger\": \"2.0\"}", "2.0", false, true},
+		{"{openapi: 3.0.0}", "", false, false},
+		{"info:\n  title: x\n", "", false, false},
+	}
+	for _, tt := range tests {
+		version, ok := sniffVersion([]byte(tt.data))
+		if ok != tt.ok {
+			t.Errorf("%q: expected ok %v, got %v", tt.data, tt.ok, ok)
+			continue
+		}
+		if ok && (version.Version != tt.version || version.IsYAML != tt.isYAML) {
+			t.Errorf("%q: expected %s (YAML %v), got %+v", tt.data, tt.version, tt.isYAML, version)
+		}
+	}
+}
+
+func TestTranscodeYAML(t *testing.T) {
+	data := []byte(`
+base: &base {type: string, description: "quoted \"text\"\ttab"}
+merged:
+  <<: *base
+  description: own
+responses:
+  200: {description: ok}
+  default: {description: error}
+values: [1, -2.5, 0x10, true, null, ~, 2024-01-02, "007", 1e3]
+alias: *base
+text: |
+  line 1
+  line 2
+`)
+	var buf bytes.Buffer
+	if err := transcodeYAML(data, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var got, expected any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, buf.Bytes())
+	}
+	if err := json.Unmarshal([]byte(`{
+		"base": {"type": "string", "description": "quoted \"text\"\ttab"},
+		"merged": {"type": "string", "description": "own"},
+		"responses": {"200": {"description": "ok"}, "default": {"description": "error"}},
+		"values": [1, -2.5, 16, true, null, null, "2024-01-02", "007", 1000],
+		"alias": {"type": "string", "description": "quoted \"text\"\ttab"},
+		"text": "line 1\nline 2\n"
+	}`), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if err := transcodeYAML([]byte("value: .inf\n"), &buf); err == nil {
+		t.Error("Expected an error for infinity")
+	}
+}
+
+func TestLoadOpenAPI3(t *testing.T) {
+	data := []byte("openapi: 3.0.0\ninfo: {title: T, version: \"1.0\"}\npaths:\n  /a: {get: {responses: {200: {description: ok}}}}\n")
+	doc, err := loadOpenAPI3(data, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Paths.Value("/a").Get.Responses.Value("200") == nil {
+		t.Error("Expected the 200 response of /a")
+	}
+
+	// Failures report the loader's errors
+	invalid := []byte("openapi: 3.0.0\ninfo: {title: T, version: 1.0}\npaths: {}\n")
+	_, expected := openapi3.NewLoader().LoadFromData(invalid)
+	if _, err := loadOpenAPI3(invalid, true); err == nil || expected == nil || err.Error() != expected.Error() {
+		t.Errorf("Expected %v, got %v", expected, err)
+	}
+}
+
+func TestLoadDocumentCachesEncoding(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(file, largeSpec(3, false), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := New(Config{})
+	first, _, err := m.loadDocument(1, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Paths.Delete("/resources0")
+
+	// The cached document is not affected by changes to loaded ones
+	second, _, err := m.loadDocument(1, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Paths.Value("/resources0") == nil || len(second.Components.Schemas) != 3 {
+		t.Errorf("Expected the pristine document from the cache, got paths %v", sortedKeys(second.Paths.Map()))
+	}
+}
+
+// largeSpec generates a spec with n paths and schemas, as YAML or JSON,
+// of about 30 lines per path in YAML
+func largeSpec(n int, asJSON bool) []byte {
+	var b strings.Builder
+	b.WriteString("openapi: 3.0.3\ninfo: {title: Large, version: \"1.0\"}\npaths:\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `  /resources%[1]d:
+    get:
+      operationId: getResource%[1]d
+      summary: Get resource %[1]d
+      tags: [group%[2]d]
+      parameters:
+        - name: id
+          in: query
+          required: true
+          schema: {type: string, format: uuid}
+      responses:
+        "200":
+          description: The resource
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Resource%[1]d'}
+        "404":
+          description: Not found
+`, i, i%10)
+	}
+	b.WriteString("components:\n  schemas:\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `    Resource%[1]d:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string, format: uuid}
+        name: {type: string, description: The name of resource %[1]d}
+        count: {type: integer, minimum: 0}
+        status: {type: string, enum: [active, archived]}
+        tags:
+          type: array
+          items: {type: string}
+`, i)
+	}
+	if !asJSON {
+		return []byte(b.String())
+	}
+	var v any
+	if err := yaml.Unmarshal([]byte(b.String()), &v); err != nil {
+		panic(err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// legacyLoad loads a document the way inputs were loaded before decoding
+// was streamlined: a full parse to detect the version, the loader's YAML
+// to JSON round trip, and a deep copy for the cache
+func legacyLoad(data []byte) (*openapi3.T, error) {
+	var obj map[string]any
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := openapi3.NewLoader().LoadFromData(encoded); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// BenchmarkLoadDocument loads a generated spec of about 100k lines:
+//
+//	go test ./pkg/merger -run '^$' -bench LoadDocument -benchmem
+//
+// The legacy benchmarks replay the previous pipeline for comparison.
+func BenchmarkLoadDocument(b *testing.B) {
+	const paths = 3500
+	for _, format := range []string{"yaml", "json"} {
+		data := largeSpec(paths, format == "json")
+		file := filepath.Join(b.TempDir(), "spec."+format)
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(format, func(b *testing.B) {
+			m := New(Config{})
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				m.ClearCache()
+				if _, _, err := m.loadDocument(1, file); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(format+"-legacy", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				if _, err := legacyLoad(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLoadDocumentCached loads a spec already converted by a
+// previous merge, as watch mode and repeated library merges do
+func BenchmarkLoadDocumentCached(b *testing.B) {
+	file := filepath.Join(b.TempDir(), "spec.yaml")
+	if err := os.WriteFile(file, largeSpec(3500, false), 0644); err != nil {
+		b.Fatal(err)
+	}
+	m := New(Config{})
+	if _, _, err := m.loadDocument(1, file); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := m.loadDocument(1, file); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
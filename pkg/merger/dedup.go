@@ -0,0 +1,103 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// documentationFields are ignored when comparing schemas structurally
+var documentationFields = []string{"title", "description", "example", "examples", "externalDocs"}
+
+// DedupSchemas returns a transformer that collapses structurally identical
+// schemas, such as the same ErrorResponse contributed by every service
+// under different names, into one component and rewrites all references.
+// Schemas are compared ignoring titles, descriptions and examples; the
+// first name in alphabetical order is kept.
+func DedupSchemas() Transformer {
+	return NewTransformer("dedup-schemas", func(doc *openapi3.T, source string) error {
+		_, err := dedupSchemas(doc)
+		return err
+	})
+}
+
+// dedupSchemas collapses identical schemas until none are left, since
+// collapsing schemas can make the schemas referencing them identical. It
+// returns the names that were replaced and their replacements.
+func dedupSchemas(doc *openapi3.T) (map[string]string, error) {
+	replaced := make(map[string]string)
+	for {
+		if doc.Components == nil {
+			return replaced, nil
+		}
+
+		canonical := make(map[string]string)
+		renames := make(map[string]string)
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			key, err := structuralKey(doc.Components.Schemas[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare schema %s: %v", name, err)
+			}
+			if kept, ok := canonical[key]; ok {
+				renames[name] = kept
+				continue
+			}
+			canonical[key] = name
+		}
+		if len(renames) == 0 {
+			return replaced, nil
+		}
+
+		if err := renameComponents(doc, "schemas", renames); err != nil {
+			return nil, err
+		}
+		for name, kept := range renames {
+			replaced[name] = kept
+		}
+		for name, kept := range replaced {
+			if next, ok := renames[kept]; ok {
+				replaced[name] = next
+			}
+		}
+	}
+}
+
+// structuralKey returns the JSON of a schema without documentation fields
+func structuralKey(schema *openapi3.SchemaRef) (string, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "", err
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", err
+	}
+	stripFields(v, documentationFields)
+	data, err = json.Marshal(v)
+	return string(data), err
+}
+
+// stripFields removes fields from every object of a generic JSON value.
+// The keys of properties maps are property names and are kept.
+func stripFields(v any, fields []string) {
+	switch node := v.(type) {
+	case map[string]any:
+		for _, field := range fields {
+			delete(node, field)
+		}
+		for key, value := range node {
+			if props, ok := value.(map[string]any); ok && key == "properties" {
+				for _, prop := range props {
+					stripFields(prop, fields)
+				}
+				continue
+			}
+			stripFields(value, fields)
+		}
+	case []any:
+		for _, value := range node {
+			stripFields(value, fields)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestDedupSchemas(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    get:
+      responses:
+        "404":
+          description: not found
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/UserError'}
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/UserErrorList'}
+  /orders:
+    get:
+      responses:
+        "404":
+          description: not found
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/ErrorResponse'}
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/OrderErrorList'}
+components:
+  schemas:
+    ErrorResponse:
+      type: object
+      description: An error
+      properties:
+        code: {type: integer}
+        description: {type: string}
+    UserError:
+      type: object
+      description: A user service error
+      properties:
+        code: {type: integer, example: 404}
+        description: {type: string}
+    UserErrorList:
+      type: array
+      items: {$ref: '#/components/schemas/UserError'}
+    OrderErrorList:
+      type: array
+      items: {$ref: '#/components/schemas/ErrorResponse'}
+    Other:
+      type: object
+      properties:
+        code: {type: string}
+        description: {type: string}
+`)
+
+	if err := DedupSchemas().Transform(doc, ""); err != nil {
+		t.Fatalf("DedupSchemas failed: %v", err)
+	}
+
+	if got := schemaNames(doc); len(got) != 3 || got[0] != "ErrorResponse" || got[1] != "OrderErrorList" || got[2] != "Other" {
+		t.Fatalf("Expected ErrorResponse, OrderErrorList and Other, got %v", got)
+	}
+	for path, want := range map[string]string{"/users": "#/components/schemas/ErrorResponse", "/orders": "#/components/schemas/ErrorResponse"} {
+		ref := doc.Paths.Find(path).Get.Responses.Status(404).Value.Content.Get("application/json").Schema.Ref
+		if ref != want {
+			t.Errorf("Expected %s 404 to reference %s, got %s", path, want, ref)
+		}
+	}
+	ref := doc.Paths.Find("/users").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Ref
+	if ref != "#/components/schemas/OrderErrorList" {
+		t.Errorf("Expected the collapsed list to be referenced, got %s", ref)
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Deduplicated document is invalid: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package merger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// descriptionSet holds the Markdown descriptions read from a descriptions
+// directory
+type descriptionSet struct {
+	info       string
+	tags       map[string]string
+	operations map[string]string
+}
+
+// InjectDescriptions returns a transformer setting descriptions of the
+// merged document from Markdown files, so tech writers can enrich the
+// aggregated spec without editing per-service sources. dir may contain:
+//
+//	info.md                  the description of the API
+//	tags/<tag>.md            the description of a tag
+//	operations/<opId>.md     the description of an operation
+//
+// Files replace the descriptions of the specs; files for tags and
+// operations the document doesn't have are ignored.
+func InjectDescriptions(dir string) (Transformer, error) {
+	set, err := readDescriptions(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransformer("descriptions", func(doc *openapi3.T, source string) error {
+		set.apply(doc)
+		return nil
+	}), nil
+}
+
+// readDescriptions reads the Markdown files of a descriptions directory
+func readDescriptions(dir string) (*descriptionSet, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("descriptions directory %s not found", dir)
+	}
+
+	set := &descriptionSet{}
+	data, err := os.ReadFile(filepath.Join(dir, "info.md"))
+	if err == nil {
+		set.info = strings.TrimSpace(string(data))
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if set.tags, err = readMarkdownDir(filepath.Join(dir, "tags")); err != nil {
+		return nil, err
+	}
+	if set.operations, err = readMarkdownDir(filepath.Join(dir, "operations")); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// readMarkdownDir returns the .md files of dir keyed by name without the
+// extension; a missing directory has no files
+func readMarkdownDir(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".md")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[name] = strings.TrimSpace(string(data))
+	}
+	return files, nil
+}
+
+// apply sets the descriptions of doc
+func (set *descriptionSet) apply(doc *openapi3.T) {
+	if set.info != "" && doc.Info != nil {
+		doc.Info.Description = set.info
+	}
+
+	used := make(map[string]bool)
+	if doc.Paths != nil {
+		for _, item := range doc.Paths.Map() {
+			for _, op := range item.Operations() {
+				if description, ok := set.operations[op.OperationID]; ok && op.OperationID != "" {
+					op.Description = description
+				}
+				for _, tag := range op.Tags {
+					used[tag] = true
+				}
+			}
+		}
+	}
+
+	for _, tag := range doc.Tags {
+		if description, ok := set.tags[tag.Name]; ok {
+			tag.Description = description
+		}
+	}
+	// Tags used by operations but not declared are declared with their
+	// description
+	for _, name := range sortedKeys(set.tags) {
+		if used[name] && doc.Tags.Get(name) == nil {
+			doc.Tags = append(doc.Tags, &openapi3.Tag{Name: name, Description: set.tags[name]})
+		}
+	}
+}
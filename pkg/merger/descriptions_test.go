@@ -0,0 +1,72 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInjectDescriptions(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"info.md":                 "# Platform API\n\nAll services.\n",
+		"tags/users.md":           "Manage users.\n",
+		"tags/orders.md":          "Manage orders.\n",
+		"tags/unused.md":          "Not in the spec.\n",
+		"operations/listUsers.md": "Lists **all** users.\n",
+		"operations/notes.txt":    "ignored",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0", description: old}
+tags:
+  - {name: users, description: old}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      tags: [users]
+      description: old
+      responses: {"200": {description: ok}}
+  /orders:
+    get:
+      operationId: listOrders
+      tags: [orders]
+      description: kept
+      responses: {"200": {description: ok}}
+`)
+
+	transformer, err := InjectDescriptions(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := transformer.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := doc.Info.Description; got != "# Platform API\n\nAll services." {
+		t.Errorf("Unexpected info description: %q", got)
+	}
+	if got := doc.Paths.Find("/users").Get.Description; got != "Lists **all** users." {
+		t.Errorf("Unexpected operation description: %q", got)
+	}
+	if got := doc.Paths.Find("/orders").Get.Description; got != "kept" {
+		t.Errorf("Expected operations without a file to keep their description, got %q", got)
+	}
+	if len(doc.Tags) != 2 || doc.Tags.Get("users").Description != "Manage users." || doc.Tags.Get("orders").Description != "Manage orders." {
+		t.Errorf("Unexpected tags: %v", doc.Tags)
+	}
+
+	if _, err := InjectDescriptions(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,173 @@
+package merger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultKubernetesAnnotation marks services and ingresses serving a spec
+	defaultKubernetesAnnotation = "openapi.path"
+	// kubernetesPortAnnotation selects the service port serving the spec
+	kubernetesPortAnnotation = "openapi.port"
+	// kubernetesSchemeAnnotation selects the URL scheme of the spec
+	kubernetesSchemeAnnotation = "openapi.scheme"
+)
+
+// KubernetesDiscovery configures discovery of spec URLs from Kubernetes
+// services and ingresses
+type KubernetesDiscovery struct {
+	// Namespace limits discovery to one namespace; empty means all
+	Namespace string
+	// Context selects the kubeconfig context
+	Context string
+	// Annotation holds the spec path (default: openapi.path)
+	Annotation string
+	// Ingresses also discovers annotated ingresses, addressed by their
+	// public host instead of the in-cluster service name
+	Ingresses bool
+	// ClusterDomain is appended to service hostnames (default: svc)
+	ClusterDomain string
+	// Logger receives a warning for every annotated service skipped
+	// because no port serves its spec. When nil, warnings are discarded.
+	Logger Logger
+}
+
+// kubernetesObject holds the fields of services and ingresses used by
+// discovery
+type kubernetesObject struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Ports []struct {
+			Name string `json:"name"`
+			Port int    `json:"port"`
+		} `json:"ports"`
+		Rules []struct {
+			Host string `json:"host"`
+		} `json:"rules"`
+		TLS []json.RawMessage `json:"tls"`
+	} `json:"spec"`
+}
+
+// DiscoverKubernetes lists services (and optionally ingresses) carrying the
+// spec path annotation, using the kubectl CLI and its credentials, and
+// returns the URLs of their specs. Service URLs use cluster DNS names, so
+// they are reachable when running inside the cluster.
+func DiscoverKubernetes(cfg KubernetesDiscovery) ([]string, error) {
+	return DiscoverKubernetesContext(context.Background(), cfg)
+}
+
+// DiscoverKubernetesContext is DiscoverKubernetes with kubectl killed once
+// ctx is done
+func DiscoverKubernetesContext(ctx context.Context, cfg KubernetesDiscovery) ([]string, error) {
+	if cfg.Annotation == "" {
+		cfg.Annotation = defaultKubernetesAnnotation
+	}
+	if cfg.ClusterDomain == "" {
+		cfg.ClusterDomain = "svc"
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = nopLogger{}
+	}
+
+	services, err := cfg.list(ctx, "services")
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, svc := range services {
+		path, ok := svc.Metadata.Annotations[cfg.Annotation]
+		if !ok {
+			continue
+		}
+		port, err := svc.port()
+		if err != nil {
+			cfg.Logger.Warn("skipping Kubernetes service", "error", err)
+			continue
+		}
+		host := fmt.Sprintf("%s.%s.%s", svc.Metadata.Name, svc.Metadata.Namespace, cfg.ClusterDomain)
+		urls = append(urls, svc.url("http", host+":"+strconv.Itoa(port), path))
+	}
+
+	if cfg.Ingresses {
+		ingresses, err := cfg.list(ctx, "ingresses")
+		if err != nil {
+			return nil, err
+		}
+		for _, ing := range ingresses {
+			path, ok := ing.Metadata.Annotations[cfg.Annotation]
+			if !ok || len(ing.Spec.Rules) == 0 || ing.Spec.Rules[0].Host == "" {
+				continue
+			}
+			scheme := "http"
+			if len(ing.Spec.TLS) > 0 {
+				scheme = "https"
+			}
+			urls = append(urls, ing.url(scheme, ing.Spec.Rules[0].Host, path))
+		}
+	}
+
+	sort.Strings(urls)
+	return urls, nil
+}
+
+// list returns the objects of a resource type
+func (cfg KubernetesDiscovery) list(ctx context.Context, resource string) ([]kubernetesObject, error) {
+	args := []string{"get", resource, "-o", "json"}
+	if cfg.Namespace != "" {
+		args = append(args, "--namespace", cfg.Namespace)
+	} else {
+		args = append(args, "--all-namespaces")
+	}
+	if cfg.Context != "" {
+		args = append(args, "--context", cfg.Context)
+	}
+
+	out, err := runToolContext(ctx, nil, "kubectl", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []kubernetesObject `json:"items"`
+	}
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse kubectl %s output: %v", resource, err)
+	}
+	return list.Items, nil
+}
+
+// port returns the service port serving the spec: the port named or
+// numbered by the port annotation, or the first port
+func (obj kubernetesObject) port() (int, error) {
+	want := obj.Metadata.Annotations[kubernetesPortAnnotation]
+	for _, p := range obj.Spec.Ports {
+		if want == "" || want == p.Name || want == strconv.Itoa(p.Port) {
+			return p.Port, nil
+		}
+	}
+	if port, err := strconv.Atoi(want); err == nil {
+		return port, nil
+	}
+	return 0, fmt.Errorf("service %s/%s has no port serving its spec", obj.Metadata.Namespace, obj.Metadata.Name)
+}
+
+// url builds the spec URL of an object, honoring the scheme annotation
+func (obj kubernetesObject) url(scheme, host, path string) string {
+	if s := obj.Metadata.Annotations[kubernetesSchemeAnnotation]; s != "" {
+		scheme = s
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return scheme + "://" + host + path
+}
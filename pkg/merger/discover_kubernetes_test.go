@@ -0,0 +1,62 @@
+package merger
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverKubernetes(t *testing.T) {
+	fakeToolScript(t, "kubectl", `case "$2" in
+services) cat <<'EOF'
+{"items": [
+  {"metadata": {"name": "users", "namespace": "shop", "annotations": {"openapi.path": "/swagger.json"}},
+   "spec": {"ports": [{"name": "http", "port": 8080}, {"name": "grpc", "port": 9090}]}},
+  {"metadata": {"name": "orders", "namespace": "shop", "annotations": {"openapi.path": "docs/openapi.yaml", "openapi.port": "http"}},
+   "spec": {"ports": [{"name": "grpc", "port": 9090}, {"name": "http", "port": 80}]}},
+  {"metadata": {"name": "redis", "namespace": "shop"}, "spec": {"ports": [{"port": 6379}]}},
+  {"metadata": {"name": "legacy", "namespace": "shop", "annotations": {"openapi.path": "/docs", "openapi.port": "http"}},
+   "spec": {"ports": [{"name": "grpc", "port": 9090}]}}
+]}
+EOF
+;;
+ingresses) cat <<'EOF'
+{"items": [
+  {"metadata": {"name": "billing", "namespace": "shop", "annotations": {"openapi.path": "/openapi.json"}},
+   "spec": {"rules": [{"host": "billing.example.com"}], "tls": [{"hosts": ["billing.example.com"]}]}}
+]}
+EOF
+;;
+esac
+`)
+
+	var log bytes.Buffer
+	logger := NewSlogLogger(slog.NewTextHandler(&log, nil))
+	urls, err := DiscoverKubernetes(KubernetesDiscovery{Ingresses: true, Logger: logger})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"http://orders.shop.svc:80/docs/openapi.yaml",
+		"http://users.shop.svc:8080/swagger.json",
+		"https://billing.example.com/openapi.json",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+	// The service without a usable port is skipped with a warning
+	if got := log.String(); strings.Count(got, "level=WARN") != 1 || !strings.Contains(got, "shop/legacy") {
+		t.Errorf("Expected a warning about shop/legacy, got %s", got)
+	}
+}
+
+func TestDiscoverKubernetesFailure(t *testing.T) {
+	fakeToolScript(t, "kubectl", "echo 'connection refused' >&2\nexit 1\n")
+
+	if _, err := DiscoverKubernetes(KubernetesDiscovery{Namespace: "shop"}); err == nil {
+		t.Error("Expected error when kubectl fails")
+	}
+}
@@ -0,0 +1,253 @@
+package merger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultConsulMetaKey is the Consul service metadata key holding the
+	// spec path (Consul keys cannot contain dots)
+	defaultConsulMetaKey = "openapi_path"
+	// defaultEurekaMetaKey is the Eureka instance metadata key holding the
+	// spec path
+	defaultEurekaMetaKey = "openapi.path"
+)
+
+// ConsulDiscovery configures discovery of spec URLs from a Consul catalog
+type ConsulDiscovery struct {
+	// Address is the Consul HTTP API URL, e.g. http://consul:8500
+	Address string
+	// Token is sent as X-Consul-Token
+	Token string
+	// Datacenter queries a datacenter other than the agent's
+	Datacenter string
+	// MetaKey is the service metadata key holding the spec path
+	// (default: openapi_path)
+	MetaKey string
+}
+
+// consulInstance holds the fields of a Consul health entry used by
+// discovery
+type consulInstance struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		Service string            `json:"Service"`
+		Address string            `json:"Address"`
+		Port    int               `json:"Port"`
+		Meta    map[string]string `json:"Meta"`
+	} `json:"Service"`
+}
+
+// DiscoverConsul returns the spec URLs of the Consul services whose
+// metadata names a spec path, using one healthy instance per service.
+// Add them to HTTPConfig.Anonymous so the credentials sent to the registry
+// are not sent to the instances.
+func (m *Merger) DiscoverConsul(cfg ConsulDiscovery) ([]string, error) {
+	return m.DiscoverConsulContext(context.Background(), cfg)
+}
+
+// DiscoverConsulContext is DiscoverConsul with the requests cancelled once
+// ctx is done
+func (m *Merger) DiscoverConsulContext(ctx context.Context, cfg ConsulDiscovery) (urls []string, err error) {
+	err = m.withContext(ctx, func() error {
+		urls, err = m.discoverConsul(cfg)
+		return err
+	})
+	return urls, err
+}
+
+// discoverConsul queries the Consul catalog and health APIs
+func (m *Merger) discoverConsul(cfg ConsulDiscovery) ([]string, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("Consul address is required")
+	}
+	if cfg.MetaKey == "" {
+		cfg.MetaKey = defaultConsulMetaKey
+	}
+
+	headers := map[string]string{"Accept": "application/json"}
+	if cfg.Token != "" {
+		headers["X-Consul-Token"] = cfg.Token
+	}
+	query := url.Values{}
+	if cfg.Datacenter != "" {
+		query.Set("dc", cfg.Datacenter)
+	}
+	base := strings.TrimSuffix(cfg.Address, "/") + "/v1"
+
+	var services map[string][]string
+	if err := m.getJSON(base+"/catalog/services?"+query.Encode(), headers, &services); err != nil {
+		return nil, fmt.Errorf("failed to list Consul services: %v", err)
+	}
+
+	healthQuery := url.Values{"passing": {"true"}}
+	for k, v := range query {
+		healthQuery[k] = v
+	}
+
+	var urls []string
+	for name := range services {
+		var instances []consulInstance
+		if err := m.getJSON(base+"/health/service/"+url.PathEscape(name)+"?"+healthQuery.Encode(), headers, &instances); err != nil {
+			return nil, fmt.Errorf("failed to list instances of Consul service %s: %v", name, err)
+		}
+		for _, inst := range instances {
+			path, ok := inst.Service.Meta[cfg.MetaKey]
+			if !ok {
+				continue
+			}
+			host := inst.Service.Address
+			if host == "" {
+				host = inst.Node.Address
+			}
+			urls = append(urls, specURL(inst.Service.Meta["openapi_scheme"], host, inst.Service.Port, path))
+			break
+		}
+	}
+
+	sort.Strings(urls)
+	return urls, nil
+}
+
+// EurekaDiscovery configures discovery of spec URLs from a Eureka registry
+type EurekaDiscovery struct {
+	// URL is the Eureka REST base URL, e.g. http://eureka:8761/eureka
+	URL string
+	// MetaKey is the instance metadata key holding the spec path
+	// (default: openapi.path)
+	MetaKey string
+}
+
+// eurekaPort is a Eureka port, whose number is a string or a number
+// depending on the server version
+type eurekaPort struct {
+	Number  json.Number `json:"$"`
+	Enabled string      `json:"@enabled"`
+}
+
+// eurekaInstance holds the fields of a Eureka instance used by discovery
+type eurekaInstance struct {
+	HostName   string            `json:"hostName"`
+	IPAddr     string            `json:"ipAddr"`
+	Status     string            `json:"status"`
+	Port       eurekaPort        `json:"port"`
+	SecurePort eurekaPort        `json:"securePort"`
+	Metadata   map[string]string `json:"metadata"`
+}
+
+// eurekaInstances decodes a Eureka instance list, which older servers
+// encode as a single object when there is only one instance
+type eurekaInstances []eurekaInstance
+
+func (l *eurekaInstances) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		var inst eurekaInstance
+		if err := json.Unmarshal(data, &inst); err != nil {
+			return err
+		}
+		*l = eurekaInstances{inst}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]eurekaInstance)(l))
+}
+
+// DiscoverEureka returns the spec URLs of the Eureka applications whose
+// instance metadata names a spec path, using one UP instance per
+// application. Add them to HTTPConfig.Anonymous so the credentials sent to
+// the registry are not sent to the instances.
+func (m *Merger) DiscoverEureka(cfg EurekaDiscovery) ([]string, error) {
+	return m.DiscoverEurekaContext(context.Background(), cfg)
+}
+
+// DiscoverEurekaContext is DiscoverEureka with the requests cancelled once
+// ctx is done
+func (m *Merger) DiscoverEurekaContext(ctx context.Context, cfg EurekaDiscovery) (urls []string, err error) {
+	err = m.withContext(ctx, func() error {
+		urls, err = m.discoverEureka(cfg)
+		return err
+	})
+	return urls, err
+}
+
+// discoverEureka queries the Eureka applications API
+func (m *Merger) discoverEureka(cfg EurekaDiscovery) ([]string, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("Eureka URL is required")
+	}
+	if cfg.MetaKey == "" {
+		cfg.MetaKey = defaultEurekaMetaKey
+	}
+
+	var apps struct {
+		Applications struct {
+			Application []struct {
+				Name     string          `json:"name"`
+				Instance eurekaInstances `json:"instance"`
+			} `json:"application"`
+		} `json:"applications"`
+	}
+	headers := map[string]string{"Accept": "application/json"}
+	if err := m.getJSON(strings.TrimSuffix(cfg.URL, "/")+"/apps", headers, &apps); err != nil {
+		return nil, fmt.Errorf("failed to list Eureka applications: %v", err)
+	}
+
+	var urls []string
+	for _, app := range apps.Applications.Application {
+		for _, inst := range app.Instance {
+			path, ok := inst.Metadata[cfg.MetaKey]
+			if !ok || inst.Status != "UP" {
+				continue
+			}
+			host := inst.HostName
+			if host == "" {
+				host = inst.IPAddr
+			}
+			scheme, port := "http", inst.Port
+			if inst.SecurePort.Enabled == "true" {
+				scheme, port = "https", inst.SecurePort
+			}
+			number, err := strconv.Atoi(port.Number.String())
+			if err != nil {
+				return nil, fmt.Errorf("invalid port for Eureka application %s: %v", app.Name, err)
+			}
+			urls = append(urls, specURL(scheme, host, number, path))
+			break
+		}
+	}
+
+	sort.Strings(urls)
+	return urls, nil
+}
+
+// getJSON fetches url with the configured HTTP settings and decodes its
+// JSON body into v
+func (m *Merger) getJSON(url string, headers map[string]string, v any) error {
+	data, err := m.fetchURLWithHeaders(url, headers)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid JSON from %s: %v", url, err)
+	}
+	return nil
+}
+
+// specURL builds the URL of a spec served by a registered instance
+func specURL(scheme, host string, port int, path string) string {
+	if scheme == "" {
+		scheme = "http"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port)) + path
+}
@@ -0,0 +1,132 @@
+package merger
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDiscoverConsul(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "secret" || r.URL.Query().Get("dc") != "eu" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/catalog/services":
+			w.Write([]byte(`{"users": [], "orders": ["v2"], "payments": [], "consul": []}`))
+		case "/v1/health/service/users":
+			w.Write([]byte(`[{"Node": {"Address": "10.0.0.1"}, "Service": {"Port": 8080, "Meta": {"openapi_path": "/swagger.json"}}},
+				{"Node": {"Address": "10.0.0.2"}, "Service": {"Port": 8080, "Meta": {"openapi_path": "/swagger.json"}}}]`))
+		case "/v1/health/service/orders":
+			w.Write([]byte(`[{"Node": {"Address": "10.0.0.3"}, "Service": {"Address": "orders.internal", "Port": 443, "Meta": {"openapi_path": "v2/openapi.yaml", "openapi_scheme": "https"}}}]`))
+		case "/v1/health/service/payments":
+			w.Write([]byte(`[{"Node": {"Address": "10.0.0.4"}, "Service": {"Address": "fe80::1", "Port": 8080, "Meta": {"openapi_path": "/openapi.json"}}}]`))
+		case "/v1/health/service/consul":
+			w.Write([]byte(`[{"Node": {"Address": "10.0.0.9"}, "Service": {"Port": 8300}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m := New(Config{})
+	urls, err := m.DiscoverConsul(ConsulDiscovery{Address: server.URL, Token: "secret", Datacenter: "eu"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"http://10.0.0.1:8080/swagger.json", "http://[fe80::1]:8080/openapi.json", "https://orders.internal:443/v2/openapi.yaml"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+}
+
+func TestDiscoverEureka(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/eureka/apps" || r.Header.Get("Accept") != "application/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"applications": {"application": [
+			{"name": "USERS", "instance": [
+				{"hostName": "users-1", "status": "DOWN", "port": {"$": 8080, "@enabled": "true"}, "metadata": {"openapi.path": "/v3/api-docs"}},
+				{"hostName": "users-2", "status": "UP", "port": {"$": 8080, "@enabled": "true"}, "metadata": {"openapi.path": "/v3/api-docs"}}
+			]},
+			{"name": "ORDERS", "instance":
+				{"hostName": "orders", "status": "UP", "port": {"$": "80", "@enabled": "false"}, "securePort": {"$": "8443", "@enabled": "true"}, "metadata": {"openapi.path": "openapi.json"}}
+			},
+			{"name": "GATEWAY", "instance": [{"hostName": "gateway", "status": "UP", "port": {"$": 80, "@enabled": "true"}, "metadata": {}}]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	m := New(Config{})
+	urls, err := m.DiscoverEureka(EurekaDiscovery{URL: server.URL + "/eureka/"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"http://users-2:8080/v3/api-docs", "https://orders:8443/openapi.json"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+}
+
+func TestDiscoveredInstancesAnonymous(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		leaked  []string
+		fetched int
+	)
+	instance := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetched++
+		for _, name := range []string{"Authorization", "X-Registry-Key"} {
+			if r.Header.Get(name) != "" {
+				leaked = append(leaked, name)
+			}
+		}
+		w.Write([]byte(testSpec))
+	}))
+	defer instance.Close()
+	port := instance.Listener.Addr().(*net.TCPAddr).Port
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Registry-Key") != "registry" || r.Header.Get("Authorization") != "Bearer registry" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"applications": {"application": [{"name": "USERS", "instance": [
+			{"hostName": "127.0.0.1", "status": "UP", "port": {"$": %d, "@enabled": "true"}, "metadata": {"openapi.path": "/openapi.yaml"}}
+		]}]}}`, port)
+	}))
+	defer registry.Close()
+
+	cfg := HTTPConfig{BearerToken: "registry", Headers: map[string]string{"X-Registry-Key": "registry"}}
+	urls, err := New(Config{HTTP: cfg}).DiscoverEureka(EurekaDiscovery{URL: registry.URL})
+	if err != nil || len(urls) != 1 {
+		t.Fatalf("Expected one instance, got %v (%v)", urls, err)
+	}
+	if u, _ := url.Parse(urls[0]); u.Host != instance.Listener.Addr().String() {
+		t.Fatalf("Expected the instance URL, got %s", urls[0])
+	}
+
+	cfg.Anonymous = urls
+	m := New(Config{InputPaths: urls, HTTP: cfg})
+	if _, err := m.MergeToDocument(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fetched != 1 || len(leaked) > 0 {
+		t.Errorf("Expected one anonymous request to the instance, got %d leaking %v", fetched, leaked)
+	}
+}
@@ -0,0 +1,110 @@
+package merger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// mergeDiscriminatorMappings unions the discriminator mappings of the
+// schemas src redefines in dst, so services that each contribute their
+// subtypes of a shared polymorphic base keep every mapping entry. Both
+// definitions get the merged mapping, so a base that only differs by its
+// mapping is not reported as a conflict. A key mapped to different schemas
+// is reported as a "discriminator" conflict, the mapping of src winning.
+// The strict strategy is left alone: its definitions must match exactly.
+func mergeDiscriminatorMappings(st *mergeState, dst, src openapi3.Schemas, source string) openapi3.Schemas {
+	if st.opts.schemaStrategy == SchemaStrict {
+		return src
+	}
+
+	merged := make(openapi3.Schemas, len(src))
+	for name, schema := range src {
+		merged[name] = schema
+		existing, ok := dst[name]
+		if !ok || !hasDiscriminator(existing) || !hasDiscriminator(schema) {
+			continue
+		}
+		left, right := existing.Value.Discriminator, schema.Value.Discriminator
+		if left.PropertyName != right.PropertyName {
+			continue
+		}
+
+		mapping := make(openapi3.StringMap, len(left.Mapping)+len(right.Mapping))
+		for key, target := range left.Mapping {
+			mapping[key] = target
+		}
+		for _, key := range sortedKeys(right.Mapping) {
+			if target, ok := mapping[key]; ok && target != right.Mapping[key] {
+				st.conflict("discriminator", name+"."+key, source)
+			}
+			mapping[key] = right.Mapping[key]
+		}
+
+		dst[name] = withMapping(existing, mapping)
+		merged[name] = withMapping(schema, mapping)
+	}
+	return merged
+}
+
+// hasDiscriminator reports whether a schema is an inline schema with a
+// discriminator
+func hasDiscriminator(ref *openapi3.SchemaRef) bool {
+	return ref != nil && ref.Ref == "" && ref.Value != nil && ref.Value.Discriminator != nil
+}
+
+// withMapping returns a copy of a schema with its discriminator mapping
+// replaced, leaving the input documents untouched
+func withMapping(ref *openapi3.SchemaRef, mapping openapi3.StringMap) *openapi3.SchemaRef {
+	schema := *ref.Value
+	discriminator := *schema.Discriminator
+	discriminator.Mapping = make(openapi3.StringMap, len(mapping))
+	for key, target := range mapping {
+		discriminator.Mapping[key] = target
+	}
+	schema.Discriminator = &discriminator
+	return openapi3.NewSchemaRef("", &schema)
+}
+
+// validateDiscriminators checks that every schema a discriminator mapping
+// of the merged components references exists. Mapping values are schema
+// names or references; references to other documents are not checked.
+func validateDiscriminators(components *openapi3.Components) error {
+	if components == nil {
+		return nil
+	}
+	var missing []string
+	for _, name := range sortedKeys(components.Schemas) {
+		if !hasDiscriminator(components.Schemas[name]) {
+			continue
+		}
+		mapping := components.Schemas[name].Value.Discriminator.Mapping
+		for _, key := range sortedKeys(mapping) {
+			target, ok := mappedSchema(mapping[key])
+			if !ok {
+				continue
+			}
+			if _, exists := components.Schemas[target]; !exists {
+				missing = append(missing, fmt.Sprintf("%s.%s -> %s", name, key, mapping[key]))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("discriminator mapping references missing schemas: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// mappedSchema returns the component name a discriminator mapping value
+// refers to, and false for references to other documents
+func mappedSchema(target string) (string, bool) {
+	const prefix = "#/components/schemas/"
+	if name, ok := strings.CutPrefix(target, prefix); ok {
+		return unescapeRef(name), true
+	}
+	if strings.ContainsAny(target, "#/") {
+		return "", false
+	}
+	return target, true
+}
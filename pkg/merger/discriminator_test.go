@@ -0,0 +1,104 @@
+package merger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// petDoc returns a document with a Pet base mapping one subtype
+func petDoc(t *testing.T, kind, subtype string) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [petType]
+      properties:
+        petType: {type: string}
+      discriminator:
+        propertyName: petType
+        mapping:
+          `+kind+`: '#/components/schemas/`+subtype+`'
+    `+subtype+`:
+      allOf:
+        - $ref: '#/components/schemas/Pet'
+`)
+}
+
+func TestMergeDocumentsDiscriminatorMapping(t *testing.T) {
+	for _, strategy := range []SchemaStrategy{SchemaOverwrite, SchemaUnion} {
+		t.Run(string(strategy), func(t *testing.T) {
+			var conflicts []Conflict
+			merged, err := MergeDocuments(
+				[]*openapi3.T{petDoc(t, "dog", "Dog"), petDoc(t, "cat", "Cat")},
+				WithSchemaStrategy(strategy),
+				WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }),
+			)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			mapping := merged.Components.Schemas["Pet"].Value.Discriminator.Mapping
+			if mapping["dog"] != "#/components/schemas/Dog" || mapping["cat"] != "#/components/schemas/Cat" {
+				t.Errorf("Expected the mappings to be merged, got %v", mapping)
+			}
+			if len(conflicts) != 0 {
+				t.Errorf("Expected no conflicts, got %v", conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeDocumentsDiscriminatorMappingConflict(t *testing.T) {
+	a := petDoc(t, "dog", "Dog")
+	b := petDoc(t, "dog", "Hound")
+
+	var conflicts []Conflict
+	merged, err := MergeDocuments([]*openapi3.T{a, b},
+		WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := merged.Components.Schemas["Pet"].Value.Discriminator.Mapping["dog"]; got != "#/components/schemas/Hound" {
+		t.Errorf("Expected the last mapping to win, got %s", got)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != "discriminator" || conflicts[0].Name != "Pet.dog" {
+		t.Errorf("Expected a discriminator conflict for Pet.dog, got %v", conflicts)
+	}
+}
+
+func TestMergeDocumentsDiscriminatorMissingSchema(t *testing.T) {
+	a := petDoc(t, "dog", "Dog")
+	b := petDoc(t, "cat", "Cat")
+	delete(b.Components.Schemas, "Cat")
+
+	_, err := MergeDocuments([]*openapi3.T{a, b})
+	if err == nil || !strings.Contains(err.Error(), "Pet.cat -> #/components/schemas/Cat") {
+		t.Errorf("Expected a missing schema error for Pet.cat, got %v", err)
+	}
+}
+
+func TestMappedSchema(t *testing.T) {
+	tests := []struct {
+		target string
+		name   string
+		ok     bool
+	}{
+		{"#/components/schemas/Dog", "Dog", true},
+		{"#/components/schemas/a~1b", "a/b", true},
+		{"Dog", "Dog", true},
+		{"pets.yaml#/Dog", "", false},
+	}
+	for _, tt := range tests {
+		name, ok := mappedSchema(tt.target)
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("mappedSchema(%q) = %q, %v, expected %q, %v", tt.target, name, ok, tt.name, tt.ok)
+		}
+	}
+}
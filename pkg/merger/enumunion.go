@@ -0,0 +1,95 @@
+package merger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// EnumUnion reports a schema defined as an enum by several inputs whose
+// values were unioned
+type EnumUnion struct {
+	// Name is the schema name
+	Name string `json:"name"`
+	// Sources lists the input that defined the schema first and the input
+	// that redefined it
+	Sources []string `json:"sources"`
+	// Added lists the values of the redefinition missing from the first
+	// definition
+	Added []any `json:"added"`
+}
+
+func (u EnumUnion) String() string {
+	values := make([]string, len(u.Added))
+	for i, v := range u.Added {
+		values[i] = diffValue(v)
+	}
+	return fmt.Sprintf("schema %s enum unioned across %v, adding %s", u.Name, u.Sources, strings.Join(values, ", "))
+}
+
+// WithEnumUnion unions the values of schemas that several inputs define
+// as enums of the same type, such as shared status or country code enums,
+// instead of keeping one definition. fn, when set, is called for every
+// union adding values.
+func WithEnumUnion(fn func(EnumUnion)) Option {
+	return func(o *mergeOptions) {
+		o.enumUnion = true
+		if fn != nil {
+			o.enumUnionHandlers = append(o.enumUnionHandlers, fn)
+		}
+	}
+}
+
+// mergeEnumUnions unions the enum values of the schemas src redefines in
+// dst with WithEnumUnion. Both definitions get the unioned values, so
+// enums that only differ by their values are not reported as conflicts,
+// and SchemaStrict accepts them.
+func mergeEnumUnions(st *mergeState, dst, src openapi3.Schemas, source string) openapi3.Schemas {
+	if !st.opts.enumUnion {
+		return src
+	}
+
+	merged := make(openapi3.Schemas, len(src))
+	for _, name := range sortedKeys(src) {
+		schema := src[name]
+		merged[name] = schema
+		existing, ok := dst[name]
+		if !ok || !isEnum(existing) || !isEnum(schema) || !equalJSON(existing.Value.Type, schema.Value.Type) {
+			continue
+		}
+
+		values := slices.Clone(existing.Value.Enum)
+		var added []any
+		for _, v := range schema.Value.Enum {
+			if !slices.ContainsFunc(values, func(w any) bool { return equalJSON(v, w) }) {
+				values = append(values, v)
+				added = append(added, v)
+			}
+		}
+		if len(added) > 0 {
+			u := EnumUnion{Name: name, Sources: []string{st.owner("schema", name), source}, Added: added}
+			for _, fn := range st.opts.enumUnionHandlers {
+				fn(u)
+			}
+		}
+
+		dst[name] = withEnum(existing, values)
+		merged[name] = withEnum(schema, values)
+	}
+	return merged
+}
+
+// isEnum reports whether a schema is an inline schema with enum values
+func isEnum(ref *openapi3.SchemaRef) bool {
+	return ref != nil && ref.Ref == "" && ref.Value != nil && len(ref.Value.Enum) > 0
+}
+
+// withEnum returns a copy of a schema with its enum values replaced,
+// leaving the input documents untouched
+func withEnum(ref *openapi3.SchemaRef, values []any) *openapi3.SchemaRef {
+	schema := *ref.Value
+	schema.Enum = values
+	return openapi3.NewSchemaRef("", &schema)
+}
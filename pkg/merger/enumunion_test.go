@@ -0,0 +1,70 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// statusDoc returns a document with a Status enum schema of a type
+func statusDoc(t *testing.T, typ, values string) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths: {}
+components:
+  schemas:
+    Status:
+      type: `+typ+`
+      enum: `+values+`
+`)
+}
+
+func TestMergeDocumentsEnumUnion(t *testing.T) {
+	for _, strategy := range []SchemaStrategy{SchemaOverwrite, SchemaUnion, SchemaStrict} {
+		t.Run(string(strategy), func(t *testing.T) {
+			var conflicts []Conflict
+			var unions []EnumUnion
+			first := statusDoc(t, "string", "[active, pending]")
+			merged, err := MergeDocuments(
+				[]*openapi3.T{first, statusDoc(t, "string", "[pending, archived]")},
+				WithSourceNames("a.yaml", "b.yaml"),
+				WithSchemaStrategy(strategy),
+				WithEnumUnion(func(u EnumUnion) { unions = append(unions, u) }),
+				WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }),
+			)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if got := merged.Components.Schemas["Status"].Value.Enum; !equalJSON(got, []any{"active", "pending", "archived"}) {
+				t.Errorf("Expected the enum values to be unioned, got %v", got)
+			}
+			if len(conflicts) != 0 {
+				t.Errorf("Expected no conflicts, got %v", conflicts)
+			}
+			if len(unions) != 1 || unions[0].String() != `schema Status enum unioned across [a.yaml b.yaml], adding "archived"` {
+				t.Errorf("Expected the union to be reported, got %v", unions)
+			}
+		})
+	}
+}
+
+func TestMergeDocumentsEnumUnionTypeMismatch(t *testing.T) {
+	var conflicts []Conflict
+	merged, err := MergeDocuments(
+		[]*openapi3.T{statusDoc(t, "string", "[active]"), statusDoc(t, "integer", "[1, 2]")},
+		WithEnumUnion(nil),
+		WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := merged.Components.Schemas["Status"].Value.Enum; !equalJSON(got, []any{1, 2}) {
+		t.Errorf("Expected the last definition to win, got %v", got)
+	}
+	if len(conflicts) != 1 {
+		t.Errorf("Expected 1 conflict, got %v", conflicts)
+	}
+}
@@ -0,0 +1,266 @@
+package merger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// EnvelopeMode controls whether merges check or enforce the response
+// envelope
+type EnvelopeMode string
+
+const (
+	// EnvelopeVerify reports the 2xx response bodies not wrapped in the
+	// envelope, see Merger.EnvelopeDeviations
+	EnvelopeVerify EnvelopeMode = "verify"
+	// EnvelopeWrap also wraps them in the envelope
+	EnvelopeWrap EnvelopeMode = "wrap"
+)
+
+// validate checks that the mode is known
+func (m EnvelopeMode) validate() error {
+	switch m {
+	case "", EnvelopeVerify, EnvelopeWrap:
+		return nil
+	}
+	return fmt.Errorf("invalid envelope mode %q (expected verify or wrap)", m)
+}
+
+// EnvelopeOptions configure the standard envelope of 2xx JSON response
+// bodies, such as {data, meta, errors}
+type EnvelopeOptions struct {
+	// Mode enables the envelope policy of a merge
+	Mode EnvelopeMode
+	// Schema names the envelope schema in components.schemas (default:
+	// Envelope). Its properties are the envelope properties.
+	Schema string
+	// Properties are the envelope properties when the merged document
+	// doesn't define Schema, which wrapping then adds (default: data, meta
+	// and errors)
+	Properties []string
+	// Data is the envelope property holding the payload of wrapped bodies
+	// (default: data)
+	Data string
+}
+
+// defaultEnvelopeProperties are the envelope properties by default
+var defaultEnvelopeProperties = []string{"data", "meta", "errors"}
+
+// withDefaults returns the options with defaults for unset fields
+func (o EnvelopeOptions) withDefaults() EnvelopeOptions {
+	if o.Schema == "" {
+		o.Schema = "Envelope"
+	}
+	if len(o.Properties) == 0 {
+		o.Properties = defaultEnvelopeProperties
+	}
+	if o.Data == "" {
+		o.Data = "data"
+	}
+	return o
+}
+
+// EnvelopeDeviation is a 2xx response body not wrapped in the envelope
+type EnvelopeDeviation struct {
+	// Source is the input that contributed the operation, when known
+	Source    string `json:"source,omitempty"`
+	Path      string `json:"path"`
+	Method    string `json:"method"`
+	Status    string `json:"status"`
+	MediaType string `json:"mediaType"`
+	// Missing lists the envelope properties the body lacks
+	Missing []string `json:"missing"`
+	// Wrapped is set when the body was wrapped in the envelope
+	Wrapped bool `json:"wrapped,omitempty"`
+}
+
+func (d EnvelopeDeviation) String() string {
+	where := d.Method + " " + d.Path + " response " + d.Status + " " + d.MediaType
+	if d.Source != "" {
+		where += " (" + d.Source + ")"
+	}
+	message := "missing envelope properties " + strings.Join(d.Missing, ", ")
+	if d.Wrapped {
+		message += ", wrapped"
+	}
+	return where + ": " + message
+}
+
+// CheckEnvelope returns the 2xx JSON response bodies of doc that neither
+// reference the envelope schema nor have every envelope property
+func CheckEnvelope(doc *openapi3.T, opts EnvelopeOptions) []EnvelopeDeviation {
+	deviations, _ := envelopeDeviations(doc, opts.withDefaults())
+	return deviations
+}
+
+// WrapEnvelope returns a transformer wrapping the 2xx JSON response bodies
+// of the merged document not conforming to the envelope: the body becomes
+// the envelope schema with the original body as its Data property
+func WrapEnvelope(opts EnvelopeOptions) Transformer {
+	opts = opts.withDefaults()
+	return NewTransformer("envelope", func(doc *openapi3.T, source string) error {
+		_, media := envelopeDeviations(doc, opts)
+		wrapEnvelope(doc, opts, media)
+		return nil
+	})
+}
+
+// envelopeDeviations returns the deviations of doc with their media type
+// objects, in path and method order
+func envelopeDeviations(doc *openapi3.T, opts EnvelopeOptions) ([]EnvelopeDeviation, []*openapi3.MediaType) {
+	if doc.Paths == nil {
+		return nil, nil
+	}
+	properties := opts.Properties
+	var envelope *openapi3.SchemaRef
+	if doc.Components != nil {
+		envelope = doc.Components.Schemas[opts.Schema]
+	}
+	if envelope != nil && envelope.Value != nil && len(envelope.Value.Properties) > 0 {
+		properties = sortedKeys(envelope.Value.Properties)
+	}
+	ref := "#/components/schemas/" + opts.Schema
+
+	var deviations []EnvelopeDeviation
+	var media []*openapi3.MediaType
+	paths := doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		operations := paths[path].Operations()
+		for _, method := range sortedKeys(operations) {
+			op := operations[method]
+			if op.Responses == nil {
+				continue
+			}
+			responses := op.Responses.Map()
+			for _, status := range sortedKeys(responses) {
+				resp := responses[status]
+				if !strings.HasPrefix(status, "2") || resp == nil || resp.Value == nil {
+					continue
+				}
+				for _, mediaType := range sortedKeys(resp.Value.Content) {
+					content := resp.Value.Content[mediaType]
+					if !isJSONMediaType(mediaType) || content == nil || content.Schema == nil {
+						continue
+					}
+					missing := missingEnvelopeProperties(content.Schema, ref, properties)
+					if len(missing) == 0 {
+						continue
+					}
+					deviations = append(deviations, EnvelopeDeviation{
+						Path:      path,
+						Method:    method,
+						Status:    status,
+						MediaType: mediaType,
+						Missing:   missing,
+					})
+					media = append(media, content)
+				}
+			}
+		}
+	}
+	return deviations, media
+}
+
+// missingEnvelopeProperties returns the envelope properties a body schema
+// lacks, or nil when it references the envelope
+func missingEnvelopeProperties(schema *openapi3.SchemaRef, ref string, properties []string) []string {
+	present := make(map[string]bool)
+	var visit func(schema *openapi3.SchemaRef) bool
+	visit = func(schema *openapi3.SchemaRef) bool {
+		if schema == nil {
+			return false
+		}
+		if schema.Ref == ref {
+			return true
+		}
+		if schema.Value == nil {
+			return false
+		}
+		for name := range schema.Value.Properties {
+			present[name] = true
+		}
+		for _, part := range schema.Value.AllOf {
+			if visit(part) {
+				return true
+			}
+		}
+		return false
+	}
+	if visit(schema) {
+		return nil
+	}
+	var missing []string
+	for _, name := range properties {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// wrapEnvelope wraps the schemas of media in the envelope, adding the
+// envelope schema when doc doesn't define it. Media shared through
+// response components are wrapped once.
+func wrapEnvelope(doc *openapi3.T, opts EnvelopeOptions, media []*openapi3.MediaType) {
+	if len(media) == 0 {
+		return
+	}
+	if doc.Components == nil {
+		doc.Components = &openapi3.Components{}
+	}
+	if doc.Components.Schemas == nil {
+		doc.Components.Schemas = openapi3.Schemas{}
+	}
+	envelope, ok := doc.Components.Schemas[opts.Schema]
+	if !ok {
+		schema := openapi3.NewObjectSchema()
+		for _, name := range opts.Properties {
+			schema.WithProperty(name, &openapi3.Schema{})
+		}
+		envelope = openapi3.NewSchemaRef("", schema)
+		doc.Components.Schemas[opts.Schema] = envelope
+	}
+
+	var wrapped []*openapi3.MediaType
+	for _, content := range media {
+		if slices.Contains(wrapped, content) {
+			continue
+		}
+		wrapped = append(wrapped, content)
+		payload := openapi3.NewObjectSchema().WithPropertyRef(opts.Data, content.Schema)
+		content.Schema = openapi3.NewSchemaRef("", &openapi3.Schema{AllOf: openapi3.SchemaRefs{
+			{Ref: "#/components/schemas/" + opts.Schema, Value: envelope.Value},
+			openapi3.NewSchemaRef("", payload),
+		}})
+	}
+}
+
+// applyEnvelope checks, or wraps, the response bodies of the merged
+// document by the configured envelope policy, recording the deviations
+// with the input that contributed them
+func (m *Merger) applyEnvelope(merged *openapi3.T) {
+	m.envelopeDeviations = nil
+	opts := m.config.Envelope.withDefaults()
+	if opts.Mode == "" {
+		return
+	}
+	deviations, media := envelopeDeviations(merged, opts)
+	if opts.Mode == EnvelopeWrap {
+		wrapEnvelope(merged, opts, media)
+	}
+	for i := range deviations {
+		deviations[i].Source = m.pathSources[deviations[i].Path]
+		deviations[i].Wrapped = opts.Mode == EnvelopeWrap
+	}
+	m.envelopeDeviations = deviations
+}
+
+// EnvelopeDeviations returns the 2xx response bodies of the last merge not
+// wrapped in the envelope, which were wrapped in EnvelopeWrap mode, or nil
+// without envelope policy
+func (m *Merger) EnvelopeDeviations() []EnvelopeDeviation {
+	return m.envelopeDeviations
+}
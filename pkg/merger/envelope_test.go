@@ -0,0 +1,139 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const envelopeSpec = `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Order'}
+            text/plain:
+              schema: {type: string}
+        "204": {description: none}
+        "404":
+          description: missing
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Order'}
+  /users:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                allOf:
+                  - $ref: '#/components/schemas/Envelope'
+                  - properties: {data: {type: string}}
+    post:
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                type: object
+                properties: {data: {type: string}, meta: {type: object}}
+components:
+  schemas:
+    Envelope:
+      type: object
+      properties: {data: {}, meta: {type: object}}
+    Order:
+      type: object
+      properties: {total: {type: number}}
+`
+
+func TestCheckEnvelope(t *testing.T) {
+	doc := loadSpec(t, envelopeSpec)
+
+	deviations := CheckEnvelope(doc, EnvelopeOptions{})
+	if len(deviations) != 1 {
+		t.Fatalf("Expected 1 deviation, got %v", deviations)
+	}
+	if got := deviations[0].String(); got != "GET /orders response 200 application/json: missing envelope properties data, meta" {
+		t.Errorf("Unexpected deviation %q", got)
+	}
+
+	// Without the envelope schema, the default properties define it
+	delete(doc.Components.Schemas, "Envelope")
+	deviations = CheckEnvelope(doc, EnvelopeOptions{})
+	if len(deviations) != 2 || strings.Join(deviations[1].Missing, ",") != "errors" {
+		t.Errorf("Expected 2 deviations, got %v", deviations)
+	}
+}
+
+func TestWrapEnvelope(t *testing.T) {
+	doc := loadSpec(t, envelopeSpec)
+	delete(doc.Components.Schemas, "Envelope")
+
+	opts := EnvelopeOptions{Schema: "Response", Properties: []string{"result", "meta"}, Data: "result"}
+	if err := WrapEnvelope(opts).Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	if deviations := CheckEnvelope(doc, opts); len(deviations) != 0 {
+		t.Errorf("Expected no deviation after wrapping, got %v", deviations)
+	}
+	envelope := doc.Components.Schemas["Response"]
+	if envelope == nil || envelope.Value.Properties["result"] == nil {
+		t.Fatal("Expected the Response schema to be defined")
+	}
+	schema := doc.Paths.Find("/orders").Get.Responses.Status(200).Value.Content["application/json"].Schema.Value
+	if len(schema.AllOf) != 2 || schema.AllOf[0].Ref != "#/components/schemas/Response" ||
+		schema.AllOf[1].Value.Properties["result"].Ref != "#/components/schemas/Order" {
+		t.Errorf("Expected the body to be wrapped, got %+v", schema)
+	}
+	if doc.Paths.Find("/orders").Get.Responses.Status(404).Value.Content["application/json"].Schema.Ref == "" {
+		t.Error("Expected error responses to be left alone")
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+}
+
+func TestMergeEnvelope(t *testing.T) {
+	dir := t.TempDir()
+	orders := filepath.Join(dir, "orders.yaml")
+	os.WriteFile(orders, []byte(envelopeSpec), 0644)
+	output := filepath.Join(dir, "merged.yaml")
+
+	m := New(Config{InputPaths: []string{orders}, OutputPath: output, Envelope: EnvelopeOptions{Mode: EnvelopeVerify}})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	deviations := m.EnvelopeDeviations()
+	if len(deviations) != 1 || deviations[0].Source != orders || deviations[0].Wrapped {
+		t.Errorf("Expected an unwrapped deviation from %s, got %v", orders, deviations)
+	}
+
+	m = New(Config{InputPaths: []string{orders}, OutputPath: output, Envelope: EnvelopeOptions{Mode: EnvelopeWrap}})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deviations := m.EnvelopeDeviations(); len(deviations) != 1 || !deviations[0].Wrapped {
+		t.Errorf("Expected a wrapped deviation, got %v", deviations)
+	}
+	data, _ := os.ReadFile(output)
+	if !strings.Contains(string(data), "$ref: '#/components/schemas/Envelope'") {
+		t.Errorf("Expected wrapped bodies in the output, got:\n%s", data)
+	}
+
+	if err := New(Config{InputPaths: []string{orders}, OutputPath: output, Envelope: EnvelopeOptions{Mode: "enforce"}}).Merge(); err == nil {
+		t.Error("Expected an invalid envelope mode to fail")
+	}
+}
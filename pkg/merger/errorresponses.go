@@ -0,0 +1,99 @@
+package merger
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ErrorResponsesOptions configure StandardErrorResponses
+type ErrorResponsesOptions struct {
+	// Statuses lists the error statuses every operation should document
+	// (default: 400, 401, 403 and 500)
+	Statuses []string
+	// Schema names the error body schema in components.schemas (default:
+	// ErrorResponse). A schema with code and message properties is added
+	// when the merged document doesn't define it.
+	Schema string
+}
+
+// defaultErrorStatuses are the statuses documented by default
+var defaultErrorStatuses = []string{"400", "401", "403", "500"}
+
+// StandardErrorResponses returns a transformer giving every operation of
+// the merged document a consistent error contract: each configured status
+// an operation doesn't document is added as a reference to a shared
+// response component, named after the status (BadRequest, Unauthorized,
+// ...), whose body is the error schema. Existing responses and components
+// are left untouched.
+func StandardErrorResponses(opts ErrorResponsesOptions) (Transformer, error) {
+	if len(opts.Statuses) == 0 {
+		opts.Statuses = defaultErrorStatuses
+	}
+	if opts.Schema == "" {
+		opts.Schema = "ErrorResponse"
+	}
+	texts := make(map[string]string, len(opts.Statuses))
+	for _, status := range opts.Statuses {
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 400 || http.StatusText(code) == "" {
+			return nil, fmt.Errorf("invalid error status %q", status)
+		}
+		texts[status] = http.StatusText(code)
+	}
+
+	return NewTransformer("error-responses", func(doc *openapi3.T, source string) error {
+		if doc.Components == nil {
+			doc.Components = &openapi3.Components{}
+		}
+		c := doc.Components
+		if c.Schemas == nil {
+			c.Schemas = openapi3.Schemas{}
+		}
+		if c.Responses == nil {
+			c.Responses = openapi3.ResponseBodies{}
+		}
+
+		schema, ok := c.Schemas[opts.Schema]
+		if !ok {
+			schema = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+				WithProperty("code", openapi3.NewIntegerSchema()).
+				WithProperty("message", openapi3.NewStringSchema()).
+				WithRequired([]string{"code", "message"}))
+			c.Schemas[opts.Schema] = schema
+		}
+
+		refs := make(map[string]*openapi3.ResponseRef, len(opts.Statuses))
+		for _, status := range opts.Statuses {
+			name := pascalCase(texts[status])
+			resp, ok := c.Responses[name]
+			if !ok {
+				schemaRef := &openapi3.SchemaRef{Ref: "#/components/schemas/" + opts.Schema, Value: schema.Value}
+				resp = &openapi3.ResponseRef{Value: openapi3.NewResponse().
+					WithDescription(texts[status]).
+					WithContent(openapi3.NewContentWithJSONSchemaRef(schemaRef))}
+				c.Responses[name] = resp
+			}
+			refs[status] = &openapi3.ResponseRef{Ref: "#/components/responses/" + name, Value: resp.Value}
+		}
+
+		if doc.Paths == nil {
+			return nil
+		}
+		for _, item := range doc.Paths.Map() {
+			for _, op := range item.Operations() {
+				if op.Responses == nil {
+					op.Responses = openapi3.NewResponsesWithCapacity(len(opts.Statuses))
+				}
+				for _, status := range opts.Statuses {
+					if op.Responses.Value(status) == nil {
+						op.Responses.Set(status, refs[status])
+					}
+				}
+			}
+		}
+		return nil
+	}), nil
+}
@@ -0,0 +1,72 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestStandardErrorResponses(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    get:
+      responses:
+        "200": {description: ok}
+        "401": {description: custom}
+`)
+
+	transformer, err := StandardErrorResponses(ErrorResponsesOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := transformer.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := doc.Paths.Find("/users").Get.Responses
+	if got := responses.Status(401).Value.Description; got == nil || *got != "custom" {
+		t.Error("Expected the existing 401 response to be kept")
+	}
+	for status, ref := range map[int]string{
+		400: "#/components/responses/BadRequest",
+		403: "#/components/responses/Forbidden",
+		500: "#/components/responses/InternalServerError",
+	} {
+		if got := responses.Status(status); got == nil || got.Ref != ref {
+			t.Errorf("Expected %d to reference %s, got %+v", status, ref, got)
+		}
+	}
+	if _, ok := doc.Components.Responses["Unauthorized"]; !ok {
+		t.Error("Expected the Unauthorized component to be defined")
+	}
+	if schema := doc.Components.Schemas["ErrorResponse"]; schema == nil || schema.Value.Properties["message"] == nil {
+		t.Error("Expected a default ErrorResponse schema")
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+
+	// Status texts with punctuation still give valid component names
+	transformer, err = StandardErrorResponses(ErrorResponsesOptions{Statuses: []string{"418", "505"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := transformer.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"IMATeapot", "HTTPVersionNotSupported"} {
+		if _, ok := doc.Components.Responses[name]; !ok {
+			t.Errorf("Expected the %s component, got %v", name, sortedKeys(doc.Components.Responses))
+		}
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+
+	if _, err := StandardErrorResponses(ErrorResponsesOptions{Statuses: []string{"200"}}); err == nil {
+		t.Error("Expected an error for a non-error status")
+	}
+}
@@ -0,0 +1,45 @@
+package merger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InputError records why a single input could not be processed
+type InputError struct {
+	Source string
+	Err    error
+}
+
+func (e *InputError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// InputErrors lists every input that failed, so all problems can be fixed
+// in one pass
+type InputErrors []*InputError
+
+func (e InputErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d inputs failed:", len(e))
+	for _, err := range e {
+		fmt.Fprintf(&b, "\n  - %v", err)
+	}
+	return b.String()
+}
+
+func (e InputErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
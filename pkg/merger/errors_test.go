@@ -0,0 +1,69 @@
+package merger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeAggregatesInputErrors(t *testing.T) {
+	m := New(Config{
+		InputPaths: []string{"missing1.yaml", "missing2.yaml"},
+		OutputPath: "output.yaml",
+	})
+
+	err := m.Merge()
+	if err == nil {
+		t.Fatal("Expected error for missing inputs")
+	}
+
+	var inputErrs InputErrors
+	if !errors.As(err, &inputErrs) {
+		t.Fatalf("Expected InputErrors, got %T", err)
+	}
+
+	if len(inputErrs) != 2 {
+		t.Errorf("Expected 2 input errors, got %d", len(inputErrs))
+	}
+
+	for _, source := range []string{"missing1.yaml", "missing2.yaml"} {
+		if !strings.Contains(err.Error(), source) {
+			t.Errorf("Expected error to mention %s, got %v", source, err)
+		}
+	}
+}
+
+func TestMergeSkipInvalid(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	m := New(Config{
+		InputPaths:  []string{file, "missing.yaml"},
+		OutputPath:  filepath.Join(t.TempDir(), "merged.yaml"),
+		SkipInvalid: true,
+	})
+
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	skipped := m.Skipped()
+	if len(skipped) != 1 || skipped[0].Source != "missing.yaml" {
+		t.Errorf("Expected missing.yaml to be skipped, got %v", skipped)
+	}
+
+	// Fails when nothing valid is left
+	m = New(Config{InputPaths: []string{"missing.yaml"}, OutputPath: "output.yaml", SkipInvalid: true})
+	if err := m.Merge(); err == nil {
+		t.Error("Expected error when all inputs are invalid")
+	}
+}
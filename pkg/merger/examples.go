@@ -0,0 +1,204 @@
+package merger
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// formatExamples are the example values of string formats
+var formatExamples = map[string]string{
+	"date":      "2024-01-01",
+	"date-time": "2024-01-01T00:00:00Z",
+	"time":      "12:00:00",
+	"email":     "user@example.com",
+	"uuid":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"byte":      "ZXhhbXBsZQ==",
+	"binary":    "example",
+	"password":  "********",
+}
+
+// GenerateExamples returns a transformer that adds an example, synthesized
+// from the schema, to every request and response body without one. Schema
+// examples, defaults and the first enum value are used where present, and
+// string formats such as date-time, email and uuid get matching values.
+func GenerateExamples() Transformer {
+	return NewTransformer("generate-examples", func(doc *openapi3.T, source string) error {
+		forEachBody(doc, func(body bodyContent) {
+			if body.media.Example != nil || len(body.media.Examples) > 0 || body.media.Schema == nil {
+				return
+			}
+			g := &exampler{request: body.status == "", visiting: make(map[*openapi3.Schema]bool)}
+			if example := g.example(body.media.Schema); example != nil {
+				body.media.Example = example
+			}
+		})
+		return nil
+	})
+}
+
+// bodyContent is a media type of a request or response body of an
+// operation
+type bodyContent struct {
+	path, method string
+	// status is the response status, or "" for the request body
+	status    string
+	mediaType string
+	media     *openapi3.MediaType
+}
+
+// forEachBody calls fn for every media type of the request and response
+// bodies of every operation, in order. Bodies referenced from components
+// are visited through the operations using them.
+func forEachBody(doc *openapi3.T, fn func(bodyContent)) {
+	if doc.Paths == nil {
+		return
+	}
+	visit := func(body bodyContent, content openapi3.Content) {
+		for _, mediaType := range sortedKeys(content) {
+			if media := content[mediaType]; media != nil {
+				body.mediaType, body.media = mediaType, media
+				fn(body)
+			}
+		}
+	}
+
+	paths := doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		ops := paths[path].Operations()
+		for _, method := range sortedKeys(ops) {
+			op := ops[method]
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				visit(bodyContent{path: path, method: method}, op.RequestBody.Value.Content)
+			}
+			if op.Responses == nil {
+				continue
+			}
+			responses := op.Responses.Map()
+			for _, status := range sortedKeys(responses) {
+				if resp := responses[status]; resp != nil && resp.Value != nil {
+					visit(bodyContent{path: path, method: method, status: status}, resp.Value.Content)
+				}
+			}
+		}
+	}
+}
+
+// exampler synthesizes examples for a request or response body
+type exampler struct {
+	// request leaves out readOnly properties, responses leave out
+	// writeOnly ones
+	request bool
+	// visiting holds the schemas being expanded so recursive schemas
+	// terminate
+	visiting map[*openapi3.Schema]bool
+}
+
+// example synthesizes an example value for a schema
+func (g *exampler) example(ref *openapi3.SchemaRef) any {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	s := ref.Value
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case g.visiting[s]:
+		return nil
+	}
+	g.visiting[s] = true
+	defer delete(g.visiting, s)
+
+	if len(s.AllOf) > 0 {
+		return g.allOf(s)
+	}
+	if len(s.OneOf) > 0 {
+		return g.example(s.OneOf[0])
+	}
+	if len(s.AnyOf) > 0 {
+		return g.example(s.AnyOf[0])
+	}
+
+	switch {
+	case s.Type.Is(openapi3.TypeString):
+		if example, ok := formatExamples[s.Format]; ok {
+			return example
+		}
+		return "string"
+	case s.Type.Is(openapi3.TypeInteger):
+		if s.Min != nil {
+			return int64(*s.Min)
+		}
+		return 0
+	case s.Type.Is(openapi3.TypeNumber):
+		if s.Min != nil {
+			return *s.Min
+		}
+		return 0.0
+	case s.Type.Is(openapi3.TypeBoolean):
+		return true
+	case s.Type.Is(openapi3.TypeArray):
+		if item := g.example(s.Items); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case s.Type.Is(openapi3.TypeObject) || len(s.Properties) > 0:
+		return g.object(s)
+	}
+	return nil
+}
+
+// object synthesizes an example object with every property
+func (g *exampler) object(s *openapi3.Schema) map[string]any {
+	object := make(map[string]any, len(s.Properties))
+	for _, name := range sortedKeys(s.Properties) {
+		prop := s.Properties[name]
+		if prop != nil && prop.Value != nil && (g.request && prop.Value.ReadOnly || !g.request && prop.Value.WriteOnly) {
+			continue
+		}
+		if value := g.example(prop); value != nil {
+			object[name] = value
+		}
+	}
+	if additional := s.AdditionalProperties.Schema; additional != nil && len(object) == 0 {
+		if value := g.example(additional); value != nil {
+			object["key"] = value
+		}
+	}
+	return object
+}
+
+// allOf combines the examples of the allOf subschemas of s
+func (g *exampler) allOf(s *openapi3.Schema) any {
+	var combined map[string]any
+	if len(s.Properties) > 0 {
+		combined = g.object(s)
+	}
+	for _, sub := range s.AllOf {
+		value := g.example(sub)
+		object, ok := value.(map[string]any)
+		if !ok {
+			if combined == nil && value != nil {
+				return value
+			}
+			continue
+		}
+		if combined == nil {
+			combined = make(map[string]any)
+		}
+		for name, v := range object {
+			combined[name] = v
+		}
+	}
+	if combined == nil {
+		return nil
+	}
+	return combined
+}
@@ -0,0 +1,72 @@
+package merger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestGenerateExamples(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/User'}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+        "400":
+          description: bad request
+          content:
+            application/json:
+              schema: {type: object}
+              example: {message: kept}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id: {type: string, format: uuid, readOnly: true}
+        email: {type: string, format: email}
+        password: {type: string, writeOnly: true}
+        role: {type: string, enum: [admin, user]}
+        age: {type: integer, minimum: 18}
+        active: {type: boolean, default: false}
+        tags:
+          type: array
+          items: {type: string}
+        manager: {$ref: '#/components/schemas/User'}
+`)
+
+	if err := GenerateExamples().Transform(doc, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	op := doc.Paths.Find("/users").Post
+	example := func(media *openapi3.MediaType) string {
+		data, err := json.Marshal(media.Example)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if got, want := example(op.RequestBody.Value.Content.Get("application/json")), `{"active":false,"age":18,"email":"user@example.com","password":"string","role":"admin","tags":["string"]}`; got != want {
+		t.Errorf("Unexpected request example:\n got %s\nwant %s", got, want)
+	}
+	if got, want := example(op.Responses.Status(200).Value.Content.Get("application/json")), `{"active":false,"age":18,"email":"user@example.com","id":"3fa85f64-5717-4562-b3fc-2c963f66afa6","role":"admin","tags":["string"]}`; got != want {
+		t.Errorf("Unexpected response example:\n got %s\nwant %s", got, want)
+	}
+	if got := example(op.Responses.Status(400).Value.Content.Get("application/json")); got != `{"message":"kept"}` {
+		t.Errorf("Expected the existing example to be kept, got %s", got)
+	}
+}
@@ -0,0 +1,154 @@
+package merger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExtensionPolicy controls how the x-* specification extensions of the
+// documents and of their components sections are merged
+type ExtensionPolicy string
+
+const (
+	// ExtensionsOverride merges extensions key by key: a later input
+	// defining an extension differently replaces it, and the redefinition
+	// is reported as a conflict
+	ExtensionsOverride ExtensionPolicy = "override"
+	// ExtensionsUnion deep-merges extensions: objects are merged
+	// recursively, arrays get the items they don't have yet, and the first
+	// input wins on differing values
+	ExtensionsUnion ExtensionPolicy = "union"
+	// ExtensionsError deep-merges extensions like ExtensionsUnion but
+	// fails the merge on differing values
+	ExtensionsError ExtensionPolicy = "error"
+)
+
+// WithExtensionPolicy sets how extensions are merged (default:
+// ExtensionsOverride)
+func WithExtensionPolicy(policy ExtensionPolicy) Option {
+	return func(o *mergeOptions) {
+		o.extensions = policy
+	}
+}
+
+// validate checks that the policy is known
+func (p ExtensionPolicy) validate() error {
+	switch p {
+	case "", ExtensionsOverride, ExtensionsUnion, ExtensionsError:
+		return nil
+	}
+	return fmt.Errorf("invalid extension policy %q (expected override, union or error)", p)
+}
+
+// mergeExtensions merges the x-* extensions of src into dst, returning
+// the merged map. where prefixes the names of conflicting extensions,
+// e.g. "components.".
+func mergeExtensions(st *mergeState, where string, dst, src map[string]any, source string) (map[string]any, error) {
+	for _, key := range sortedKeys(src) {
+		if !strings.HasPrefix(key, "x-") || slices.Contains(webhookKeys, key) {
+			continue
+		}
+		if dst == nil {
+			dst = make(map[string]any)
+		}
+		name := where + key
+		value := src[key]
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			st.own("extension", name, source)
+			continue
+		}
+
+		switch st.opts.extensions {
+		case ExtensionsUnion, ExtensionsError:
+			merged, err := deepMerge(name, existing, value, st.opts.extensions == ExtensionsError)
+			if err != nil {
+				return nil, fmt.Errorf("cannot merge extension from %s with %s: %v", source, st.owner("extension", name), err)
+			}
+			dst[key] = merged
+		default:
+			if !equalJSON(existing, value) {
+				st.conflict("extension", name, source)
+			}
+			dst[key] = value
+			st.own("extension", name, source)
+		}
+	}
+	return dst, nil
+}
+
+// deepMerge merges two generic JSON values found at path. Differing
+// values keep a, or fail when strict is set.
+func deepMerge(path string, a, b any, strict bool) (any, error) {
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			merged := make(map[string]any, len(av)+len(bv))
+			for key, value := range av {
+				merged[key] = value
+			}
+			for _, key := range sortedKeys(bv) {
+				existing, ok := merged[key]
+				if !ok {
+					merged[key] = bv[key]
+					continue
+				}
+				value, err := deepMerge(path+"."+key, existing, bv[key], strict)
+				if err != nil {
+					return nil, err
+				}
+				merged[key] = value
+			}
+			return merged, nil
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			if strict && !equalJSON(av, bv) {
+				return nil, fmt.Errorf("%s has differing values", path)
+			}
+			merged := slices.Clone(av)
+			for _, item := range bv {
+				if !slices.ContainsFunc(merged, func(v any) bool { return equalJSON(v, item) }) {
+					merged = append(merged, item)
+				}
+			}
+			return merged, nil
+		}
+	}
+	if strict && !equalJSON(a, b) {
+		return nil, fmt.Errorf("%s has differing values", path)
+	}
+	return a, nil
+}
+
+// ownExtensions records source as the definer of the extensions in ext
+func ownExtensions(st *mergeState, where string, ext map[string]any, source string) {
+	for key := range ext {
+		if strings.HasPrefix(key, "x-") {
+			st.own("extension", where+key, source)
+		}
+	}
+}
+
+// mergeDocumentExtensions merges the top-level and components extensions
+// of doc into merged
+func mergeDocumentExtensions(st *mergeState, merged, doc *openapi3.T, source string) error {
+	ext, err := mergeExtensions(st, "", merged.Extensions, doc.Extensions, source)
+	if err != nil {
+		return err
+	}
+	merged.Extensions = ext
+	if doc.Components == nil {
+		return nil
+	}
+	ext, err = mergeExtensions(st, "components.", merged.Components.Extensions, doc.Components.Extensions, source)
+	if err != nil {
+		return err
+	}
+	merged.Components.Extensions = ext
+	return nil
+}
@@ -0,0 +1,73 @@
+package merger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestMergeDocumentsExtensions(t *testing.T) {
+	docs := func() []*openapi3.T {
+		var docs []*openapi3.T
+		for _, spec := range []string{`
+openapi: 3.0.0
+info: {title: Users, version: "1.0"}
+paths: {}
+x-gateway: {timeout: 30, plugins: [cors]}
+x-owner: users-team
+components:
+  x-internal: true
+`, `
+openapi: 3.0.0
+info: {title: Orders, version: "1.0"}
+paths: {}
+x-gateway: {timeout: 60, plugins: [cors, rate-limit], retries: 2}
+x-audience: public
+components:
+  x-generated: true
+`} {
+			docs = append(docs, loadSpec(t, spec))
+		}
+		return docs
+	}
+	gateway := func(doc *openapi3.T) string {
+		data, err := json.Marshal(doc.Extensions["x-gateway"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	var conflicts []Conflict
+	merged, err := MergeDocuments(docs(), WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gateway(merged); got != `{"plugins":["cors","rate-limit"],"retries":2,"timeout":60}` {
+		t.Errorf("Expected the later x-gateway to override, got %s", got)
+	}
+	if merged.Extensions["x-owner"] != "users-team" || merged.Extensions["x-audience"] != "public" {
+		t.Errorf("Expected extensions of both inputs, got %v", merged.Extensions)
+	}
+	if merged.Components.Extensions["x-internal"] != true || merged.Components.Extensions["x-generated"] != true {
+		t.Errorf("Expected components extensions of both inputs, got %v", merged.Components.Extensions)
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != "extension" || conflicts[0].Name != "x-gateway" {
+		t.Errorf("Expected an x-gateway conflict, got %v", conflicts)
+	}
+
+	merged, err = MergeDocuments(docs(), WithExtensionPolicy(ExtensionsUnion))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gateway(merged); got != `{"plugins":["cors","rate-limit"],"retries":2,"timeout":30}` {
+		t.Errorf("Expected x-gateway to be deep-merged, got %s", got)
+	}
+
+	_, err = MergeDocuments(docs(), WithSourceNames("users.yaml", "orders.yaml"), WithExtensionPolicy(ExtensionsError))
+	if err == nil || !strings.Contains(err.Error(), "x-gateway.") {
+		t.Errorf("Expected a conflicting x-gateway error, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package merger
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExternalDocsPolicy controls what happens to the top-level and tag-level
+// externalDocs of the inputs when merging
+type ExternalDocsPolicy string
+
+const (
+	// ExternalDocsFirst keeps the top-level externalDocs of the first input
+	// defining one, and the externalDocs of every tag
+	ExternalDocsFirst ExternalDocsPolicy = "first"
+	// ExternalDocsPerTag moves the top-level externalDocs of each input to
+	// the tags of that input that have none, declaring the tags its
+	// operations use, so every service keeps a link to its documentation.
+	// The merged document has no top-level externalDocs.
+	ExternalDocsPerTag ExternalDocsPolicy = "per-tag"
+	// ExternalDocsDrop removes top-level and tag-level externalDocs
+	ExternalDocsDrop ExternalDocsPolicy = "drop"
+)
+
+// WithExternalDocs sets the externalDocs policy (default:
+// ExternalDocsFirst)
+func WithExternalDocs(policy ExternalDocsPolicy) Option {
+	return func(o *mergeOptions) {
+		o.externalDocs = policy
+	}
+}
+
+// validate checks that the policy is known
+func (p ExternalDocsPolicy) validate() error {
+	switch p {
+	case "", ExternalDocsFirst, ExternalDocsPerTag, ExternalDocsDrop:
+		return nil
+	}
+	return fmt.Errorf("invalid externalDocs policy %q (expected first, per-tag or drop)", p)
+}
+
+// prepareExternalDocs applies the per-tag policy to an input before it is
+// merged
+func prepareExternalDocs(policy ExternalDocsPolicy, doc *openapi3.T) {
+	if policy != ExternalDocsPerTag || doc.ExternalDocs == nil {
+		return
+	}
+	if doc.Paths != nil {
+		for _, item := range doc.Paths.Map() {
+			for _, op := range item.Operations() {
+				for _, name := range op.Tags {
+					if doc.Tags.Get(name) == nil {
+						doc.Tags = append(doc.Tags, &openapi3.Tag{Name: name})
+					}
+				}
+			}
+		}
+	}
+	for _, tag := range doc.Tags {
+		if tag.ExternalDocs == nil {
+			tag.ExternalDocs = doc.ExternalDocs
+		}
+	}
+}
+
+// mergeExternalDocs sets the top-level externalDocs of the merged document
+// from the inputs and applies the policy to its tags
+func mergeExternalDocs(policy ExternalDocsPolicy, merged *openapi3.T, docs []*openapi3.T) {
+	switch policy {
+	case ExternalDocsPerTag:
+		merged.ExternalDocs = nil
+	case ExternalDocsDrop:
+		merged.ExternalDocs = nil
+		for _, tag := range merged.Tags {
+			tag.ExternalDocs = nil
+		}
+	default:
+		for _, doc := range docs {
+			if merged.ExternalDocs != nil {
+				break
+			}
+			merged.ExternalDocs = doc.ExternalDocs
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestMergeDocumentsExternalDocs(t *testing.T) {
+	docs := func() []*openapi3.T {
+		var docs []*openapi3.T
+		for _, spec := range []string{`
+openapi: 3.0.0
+info: {title: Users, version: "1.0"}
+paths:
+  /users:
+    get:
+      tags: [users]
+      responses: {"200": {description: ok}}
+`, `
+openapi: 3.0.0
+info: {title: Orders, version: "1.0"}
+externalDocs: {url: https://docs.example.com/orders}
+tags:
+  - name: orders
+  - name: refunds
+    externalDocs: {url: https://docs.example.com/refunds}
+paths:
+  /orders:
+    get:
+      tags: [orders, shipping]
+      responses: {"200": {description: ok}}
+`} {
+			docs = append(docs, loadSpec(t, spec))
+		}
+		return docs
+	}
+	tagDocs := func(doc *openapi3.T, name string) string {
+		if tag := doc.Tags.Get(name); tag != nil && tag.ExternalDocs != nil {
+			return tag.ExternalDocs.URL
+		}
+		return ""
+	}
+
+	merged, err := MergeDocuments(docs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ExternalDocs == nil || merged.ExternalDocs.URL != "https://docs.example.com/orders" {
+		t.Errorf("Expected the first externalDocs to be kept, got %v", merged.ExternalDocs)
+	}
+
+	merged, err = MergeDocuments(docs(), WithExternalDocs(ExternalDocsPerTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ExternalDocs != nil {
+		t.Errorf("Expected no top-level externalDocs, got %v", merged.ExternalDocs)
+	}
+	for tag, want := range map[string]string{
+		"orders":   "https://docs.example.com/orders",
+		"shipping": "https://docs.example.com/orders",
+		"refunds":  "https://docs.example.com/refunds",
+		"users":    "",
+	} {
+		if got := tagDocs(merged, tag); got != want {
+			t.Errorf("Expected tag %s to link %q, got %q", tag, want, got)
+		}
+	}
+
+	merged, err = MergeDocuments(docs(), WithExternalDocs(ExternalDocsDrop))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ExternalDocs != nil || tagDocs(merged, "refunds") != "" {
+		t.Error("Expected every externalDocs to be dropped")
+	}
+
+	if _, err := MergeDocuments(docs(), WithExternalDocs("last")); err == nil {
+		t.Error("Expected an invalid policy error")
+	}
+}
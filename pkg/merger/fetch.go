@@ -0,0 +1,422 @@
+package merger
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HTTPConfig configures how remote inputs are fetched
+type HTTPConfig struct {
+	// Headers are sent with every request
+	Headers map[string]string
+	// BearerToken, when set, is sent as "Authorization: Bearer <token>"
+	BearerToken string
+	// Username and Password, when set, are sent as basic auth
+	Username string
+	Password string
+	// URLHeaders adds headers to requests whose URL starts with the map key.
+	// They take precedence over the global settings above.
+	URLHeaders map[string]map[string]string
+	// Anonymous lists URLs whose origin (scheme, host and port) receives
+	// none of Headers, BearerToken and basic auth, such as the instances
+	// found by registry discovery. URLHeaders still apply.
+	Anonymous []string
+	// Retries is the number of times a transient failure (network error,
+	// timeout, 5xx or 429 status) is retried
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubled for every
+	// further attempt up to 30s and randomized with jitter. Defaults to
+	// 500ms.
+	RetryBackoff time.Duration
+	// Proxy is the URL of the proxy used for all requests. When empty,
+	// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+	Proxy string
+	// CAFile is a PEM bundle of additional certificate authorities trusted
+	// for HTTPS inputs, for internal services with a private PKI
+	CAFile string
+	// CertFile and KeyFile hold a PEM client certificate and key for
+	// servers that require mutual TLS
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify disables server certificate verification. Only
+	// meant for testing; never enable it for production merges.
+	InsecureSkipVerify bool
+	// Timeout limits each HTTP request. Defaults to 30 seconds.
+	Timeout time.Duration
+	// TotalTimeout limits the time spent fetching all remote inputs of a
+	// merge, including retries. Zero means no limit.
+	TotalTimeout time.Duration
+	// MaxSize limits the size of a downloaded input in bytes, and the
+	// unpacked size of archive inputs. Defaults to 64 MiB; a negative value
+	// disables the limit.
+	MaxSize int64
+	// AllowedContentTypes lists the media types accepted for remote inputs.
+	// Defaults to JSON, YAML and plain text; responses without a
+	// Content-Type header are always accepted.
+	AllowedContentTypes []string
+	// RateLimit is the maximum number of requests per second sent to each
+	// host, retries included, so merging many services behind one gateway
+	// stays under its rate limiter. Zero means no limit.
+	RateLimit float64
+	// RateBurst is the number of requests sent to a host at once before
+	// RateLimit applies. Defaults to 1.
+	RateBurst int
+}
+
+// defaultMaxSize is the download limit when none is configured
+const defaultMaxSize = 64 << 20
+
+// defaultContentTypes are the media types accepted for remote inputs
+var defaultContentTypes = []string{
+	"application/json",
+	"application/yaml",
+	"application/x-yaml",
+	"application/vnd.oai.openapi",
+	"application/vnd.oai.openapi+json",
+	"application/octet-stream",
+	"text/yaml",
+	"text/x-yaml",
+	"text/plain",
+}
+
+// defaultHTTPTimeout is the per-request timeout when none is configured
+const defaultHTTPTimeout = 30 * time.Second
+
+// defaultRetryBackoff is the initial retry delay when none is configured
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// maxRetryDelay caps the delay between two attempts
+const maxRetryDelay = 30 * time.Second
+
+// isURL reports whether path is an http(s) URL
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// IsRemoteSource reports whether path is fetched remotely (http(s) URL, git
+// repository, object storage or SwaggerHub) or is an index of specs, rather
+// than read from the local filesystem
+func IsRemoteSource(path string) bool {
+	return isURL(path) || isGitSource(path) || isObjectStorageSource(path) || isSwaggerHubSource(path) || isIndexSource(path)
+}
+
+// readDataFromPath reads data from a local file, URL, git repository or
+// object storage
+func (m *Merger) readDataFromPath(path string) ([]byte, error) {
+	if isArchiveMember(path) {
+		return m.readArchiveMember(path)
+	}
+
+	// Check if it's a URL
+	if isURL(path) {
+		return m.fetchURL(path)
+	}
+
+	if isGitSource(path) {
+		return readGitSource(m.context(), path)
+	}
+
+	if isObjectStorageSource(path) {
+		return readObjectStorageSource(m.context(), path)
+	}
+
+	if isSwaggerHubSource(path) {
+		return m.readSwaggerHubSource(path)
+	}
+
+	// Read local file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", path, err)
+	}
+
+	return data, nil
+}
+
+// fetchURL downloads a remote input, retrying transient failures
+func (m *Merger) fetchURL(url string) ([]byte, error) {
+	return m.fetchURLWithHeaders(url, nil)
+}
+
+// fetchURLWithHeaders downloads a remote input, sending headers in
+// addition to the configured ones
+func (m *Merger) fetchURLWithHeaders(url string, headers map[string]string) ([]byte, error) {
+	client, err := m.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := m.config.HTTP.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	ctx := m.context()
+	if !m.fetchDeadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadlineCause(ctx, m.fetchDeadline, fmt.Errorf("total fetch timeout of %v exceeded", m.config.HTTP.TotalTimeout))
+		defer cancel()
+	}
+
+	for attempt := 0; ; attempt++ {
+		data, retryable, err := m.fetchOnce(ctx, client, url, headers)
+		if err == nil || !retryable || attempt >= m.config.HTTP.Retries {
+			return data, err
+		}
+
+		delay := retryDelay(backoff, attempt)
+		m.config.Logger.Warn("retrying remote input", "source", url, "attempt", attempt+1, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%v while fetching %s", context.Cause(ctx), url)
+		}
+	}
+}
+
+// httpClient returns the client used for remote inputs, creating it from
+// the HTTP configuration on first use
+func (m *Merger) httpClient() (*http.Client, error) {
+	m.clientMu.Lock()
+	defer m.clientMu.Unlock()
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if m.config.HTTP.Proxy != "" {
+		proxyURL, err := url.Parse(m.config.HTTP.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %s: %v", m.config.HTTP.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := m.config.HTTP.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if m.config.HTTP.InsecureSkipVerify {
+		m.config.Logger.Warn("TLS certificate verification is disabled for remote inputs")
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	// Create HTTP client with timeout
+	timeout := m.config.HTTP.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	m.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+	return m.client, nil
+}
+
+// tlsConfig builds the TLS settings for remote inputs
+func (c HTTPConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle %s: %v", c.CAFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// fetchOnce performs a single download attempt. retryable reports whether
+// the failure is transient (network error, timeout or 5xx/429 status).
+func (m *Merger) fetchOnce(ctx context.Context, client *http.Client, url string, headers map[string]string) (data []byte, retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid URL %s: %v", url, err)
+	}
+	if err := m.throttle(ctx, req.URL.Host); err != nil {
+		return nil, false, fmt.Errorf("%v while waiting to fetch %s", err, url)
+	}
+	m.config.HTTP.authorize(req)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	cached, cachedBody, hasCache := m.loadHTTPCache(url)
+	if hasCache {
+		cached.setConditionalHeaders(req)
+	}
+
+	// Make HTTP request
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false, fmt.Errorf("%v while fetching %s", context.Cause(ctx), url)
+		}
+		return nil, true, fmt.Errorf("failed to fetch URL %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	// Unchanged since the last run
+	if resp.StatusCode == http.StatusNotModified && hasCache {
+		m.config.Logger.Debug("remote input not modified", "source", url)
+		return cachedBody, false, nil
+	}
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		retryable = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retryable, fmt.Errorf("HTTP request failed with status %d for URL %s", resp.StatusCode, url)
+	}
+
+	if err := m.config.HTTP.checkContentType(resp.Header.Get("Content-Type")); err != nil {
+		return nil, false, fmt.Errorf("unexpected response from %s: %v", url, err)
+	}
+
+	// Read response body, one byte past the limit to detect oversized inputs
+	maxSize := m.config.HTTP.maxSize()
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return nil, false, fmt.Errorf("response from %s is %d bytes, exceeding the %d byte limit", url, resp.ContentLength, maxSize)
+	}
+	body := io.Reader(resp.Body)
+	if maxSize > 0 {
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	data, err = io.ReadAll(body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false, fmt.Errorf("%v while reading %s", context.Cause(ctx), url)
+		}
+		return nil, true, fmt.Errorf("failed to read response body from %s: %v", url, err)
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, false, fmt.Errorf("response from %s exceeds the %d byte limit", url, maxSize)
+	}
+	m.storeHTTPCache(url, resp.Header, data)
+
+	return data, false, nil
+}
+
+// maxSize returns the effective download limit, zero meaning unlimited
+func (c HTTPConfig) maxSize() int64 {
+	switch {
+	case c.MaxSize < 0:
+		return 0
+	case c.MaxSize == 0:
+		return defaultMaxSize
+	}
+	return c.MaxSize
+}
+
+// checkContentType rejects responses that cannot be an API document, such
+// as HTML error pages
+func (c HTTPConfig) checkContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q", contentType)
+	}
+
+	allowed := c.AllowedContentTypes
+	if len(allowed) == 0 {
+		allowed = defaultContentTypes
+	}
+	for _, t := range allowed {
+		if strings.EqualFold(mediaType, t) {
+			return nil
+		}
+	}
+	return fmt.Errorf("content type %s is not allowed (expected one of %s)", mediaType, strings.Join(allowed, ", "))
+}
+
+// retryDelay returns the delay before retrying after the given attempt:
+// backoff doubled for every earlier retry, with jitter, up to
+// maxRetryDelay
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	delay := backoff
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(jitter(min(delay, maxRetryDelay)), maxRetryDelay)
+}
+
+// anonymous reports whether u has the origin of one of the Anonymous URLs
+func (c HTTPConfig) anonymous(u *url.URL) bool {
+	for _, raw := range c.Anonymous {
+		a, err := url.Parse(raw)
+		if err == nil && strings.EqualFold(a.Scheme, u.Scheme) && strings.EqualFold(a.Host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// jitter spreads d randomly over [d/2, 3d/2) so concurrent retries do not
+// hit a recovering service at the same instant
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d)
+}
+
+// authorize adds the configured credentials and headers to req
+func (c HTTPConfig) authorize(req *http.Request) {
+	if !c.anonymous(req.URL) {
+		if c.Username != "" || c.Password != "" {
+			req.SetBasicAuth(c.Username, c.Password)
+		}
+		if c.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+		}
+		for name, value := range c.Headers {
+			req.Header.Set(name, value)
+		}
+	}
+
+	// Apply per-URL headers, longest prefix last so it wins
+	url := req.URL.String()
+	var prefixes []string
+	for prefix := range c.URLHeaders {
+		if strings.HasPrefix(url, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) < len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		for name, value := range c.URLHeaders[prefix] {
+			req.Header.Set(name, value)
+		}
+	}
+}
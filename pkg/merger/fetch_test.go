@@ -0,0 +1,208 @@
+package merger
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSpec = `openapi: "3.0.1"
+info:
+  title: Remote API
+  version: 1.0.0
+paths: {}`
+
+func TestFetchURLAuthentication(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Api-Key") != "private" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(testSpec))
+	}))
+	defer server.Close()
+
+	m := New(Config{})
+	if _, err := m.readDataFromPath(server.URL + "/private/openapi.yaml"); err == nil {
+		t.Error("Expected error for anonymous request")
+	}
+
+	m = New(Config{HTTP: HTTPConfig{
+		BearerToken: "secret",
+		Headers:     map[string]string{"X-Api-Key": "public"},
+		URLHeaders: map[string]map[string]string{
+			server.URL + "/private/": {"X-Api-Key": "private"},
+		},
+	}})
+	data, err := m.readDataFromPath(server.URL + "/private/openapi.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if string(data) != testSpec {
+		t.Errorf("Unexpected response body: %s", data)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	// Shifting the backoff by 100 attempts would overflow
+	for attempt := 0; attempt <= 100; attempt++ {
+		delay := retryDelay(500*time.Millisecond, attempt)
+		if delay <= 0 || delay > maxRetryDelay {
+			t.Fatalf("Attempt %d: expected a delay within (0, %v], got %v", attempt, maxRetryDelay, delay)
+		}
+	}
+	if delay := retryDelay(time.Second, 0); delay < 500*time.Millisecond || delay >= 1500*time.Millisecond {
+		t.Errorf("Expected the first delay around the backoff, got %v", delay)
+	}
+	if delay := retryDelay(time.Hour, 0); delay < maxRetryDelay/2 || delay > maxRetryDelay {
+		t.Errorf("Expected a large backoff to be capped, got %v", delay)
+	}
+	for _, d := range []time.Duration{0, -time.Second} {
+		if got := jitter(d); got != 0 {
+			t.Errorf("Expected no delay for %v, got %v", d, got)
+		}
+	}
+}
+
+func TestFetchURLRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testSpec))
+	}))
+	defer server.Close()
+
+	m := New(Config{HTTP: HTTPConfig{Retries: 2, RetryBackoff: time.Millisecond}})
+	if _, err := m.readDataFromPath(server.URL); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+
+	// Client errors are not retried
+	requests = 0
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer notFound.Close()
+	if _, err := m.readDataFromPath(notFound.URL); err == nil {
+		t.Error("Expected error for missing document")
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request for a client error, got %d", requests)
+	}
+}
+
+func TestFetchURLProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(testSpec))
+	}))
+	defer proxy.Close()
+
+	m := New(Config{HTTP: HTTPConfig{Proxy: proxy.URL}})
+	if _, err := m.readDataFromPath("http://specs.internal/openapi.yaml"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if proxied != "http://specs.internal/openapi.yaml" {
+		t.Errorf("Expected request to go through the proxy, got %q", proxied)
+	}
+}
+
+func TestFetchURLTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testSpec))
+	}))
+	defer server.Close()
+
+	// Untrusted certificates are rejected
+	if _, err := New(Config{}).readDataFromPath(server.URL); err == nil {
+		t.Error("Expected error for untrusted certificate")
+	}
+
+	// Trusted through a custom CA bundle
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0644); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+	if _, err := New(Config{HTTP: HTTPConfig{CAFile: caFile}}).readDataFromPath(server.URL); err != nil {
+		t.Errorf("Expected no error with CA bundle, got %v", err)
+	}
+
+	// Explicitly insecure
+	if _, err := New(Config{HTTP: HTTPConfig{InsecureSkipVerify: true}}).readDataFromPath(server.URL); err != nil {
+		t.Errorf("Expected no error with verification disabled, got %v", err)
+	}
+}
+
+func TestFetchURLTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte(testSpec))
+	}))
+	defer server.Close()
+
+	m := New(Config{HTTP: HTTPConfig{Timeout: 20 * time.Millisecond}})
+	if _, err := m.readDataFromPath(server.URL); err == nil {
+		t.Error("Expected per-request timeout")
+	}
+
+	// The total timeout also bounds retries
+	m = New(Config{
+		InputPaths: []string{server.URL},
+		HTTP:       HTTPConfig{Timeout: 20 * time.Millisecond, Retries: 100, RetryBackoff: time.Millisecond, TotalTimeout: 100 * time.Millisecond},
+	})
+	start := time.Now()
+	if _, err := m.loadAndMerge(); err == nil {
+		t.Error("Expected total timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected merge to stop at the total timeout, took %v", elapsed)
+	}
+}
+
+func TestFetchURLLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/error":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>Login required</html>"))
+		default:
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write([]byte(testSpec))
+		}
+	}))
+	defer server.Close()
+
+	m := New(Config{})
+	if _, err := m.readDataFromPath(server.URL + "/error"); err == nil {
+		t.Error("Expected HTML response to be rejected")
+	}
+
+	if _, err := m.readDataFromPath(server.URL + "/openapi.yaml"); err != nil {
+		t.Errorf("Expected YAML response to be accepted, got %v", err)
+	}
+
+	m = New(Config{HTTP: HTTPConfig{MaxSize: 10}})
+	if _, err := m.readDataFromPath(server.URL + "/openapi.yaml"); err == nil {
+		t.Error("Expected oversized response to be rejected")
+	}
+}
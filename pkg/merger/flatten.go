@@ -0,0 +1,161 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Flatten returns a transformer that inlines every local $ref, see
+// FlattenDocument
+func Flatten() Transformer {
+	return NewTransformer("flatten", func(doc *openapi3.T, source string) error {
+		flat, err := FlattenDocument(doc)
+		if err != nil {
+			return err
+		}
+		*doc = *flat
+		return nil
+	})
+}
+
+// FlattenDocument returns a copy of doc with every local $ref replaced by
+// the value it references. References that would recurse forever, such as
+// a tree schema referencing itself, are kept along with their components.
+// Security schemes are kept since security requirements reference them by
+// name; other components are dropped.
+func FlattenDocument(doc *openapi3.T) (*openapi3.T, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	f := &flattener{root: root, stack: make(map[string]bool), keep: make(map[string]bool)}
+	flat := make(map[string]any, len(root))
+	for key, value := range root {
+		if key != "components" {
+			flat[key] = f.inline(copyValue(value))
+		}
+	}
+
+	// Components referenced recursively stay, with their own references
+	// inlined up to the recursion
+	components := make(map[string]any)
+	if all, ok := root["components"].(map[string]any); ok {
+		if schemes, ok := all["securitySchemes"]; ok {
+			components["securitySchemes"] = schemes
+		}
+		done := make(map[string]bool)
+		for len(done) < len(f.keep) {
+			for ref := range f.keep {
+				if done[ref] {
+					continue
+				}
+				done[ref] = true
+				parts := strings.SplitN(strings.TrimPrefix(ref, "#/components/"), "/", 2)
+				if len(parts) != 2 || strings.Contains(parts[1], "/") {
+					continue
+				}
+				f.stack[ref] = true
+				value := f.inline(copyValue(resolvePointer(root, ref)))
+				delete(f.stack, ref)
+
+				named, ok := components[parts[0]].(map[string]any)
+				if !ok {
+					named = make(map[string]any)
+					components[parts[0]] = named
+				}
+				named[unescapeRef(parts[1])] = value
+			}
+		}
+	}
+	if len(components) > 0 {
+		flat["components"] = components
+	}
+
+	data, err = json.Marshal(flat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal flattened document: %v", err)
+	}
+	result, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load flattened document: %v", err)
+	}
+	return result, nil
+}
+
+// flattener inlines references of a generic JSON document
+type flattener struct {
+	root map[string]any
+	// stack holds the references being inlined, to detect recursion
+	stack map[string]bool
+	// keep holds the recursive references left in place
+	keep map[string]bool
+}
+
+// inline replaces the local references in v, modifying it in place
+func (f *flattener) inline(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		if ref, ok := node["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+			if f.stack[ref] {
+				f.keep[ref] = true
+				return node
+			}
+			target := resolvePointer(f.root, ref)
+			if target == nil {
+				return node
+			}
+			f.stack[ref] = true
+			value := f.inline(copyValue(target))
+			delete(f.stack, ref)
+			return value
+		}
+		for key, value := range node {
+			node[key] = f.inline(value)
+		}
+	case []any:
+		for i, value := range node {
+			node[i] = f.inline(value)
+		}
+	}
+	return v
+}
+
+// resolvePointer returns the value a local JSON pointer references, or nil
+func resolvePointer(root map[string]any, ref string) any {
+	var current any = root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		node, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = node[unescapeRef(token)]
+	}
+	return current
+}
+
+// copyValue deep-copies a generic JSON value
+func copyValue(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(node))
+		for key, value := range node {
+			c[key] = copyValue(value)
+		}
+		return c
+	case []any:
+		c := make([]any, len(node))
+		for i, value := range node {
+			c[i] = copyValue(value)
+		}
+		return c
+	}
+	return v
+}
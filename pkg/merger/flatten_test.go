@@ -0,0 +1,45 @@
+package merger
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestFlattenDocument(t *testing.T) {
+	doc := loadSplitSpec(t)
+	doc.Components.Schemas["Node"] = openapi3.NewSchemaRef("", &openapi3.Schema{
+		Type:       &openapi3.Types{"object"},
+		Properties: openapi3.Schemas{"children": openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"array"}, Items: openapi3.NewSchemaRef("#/components/schemas/Node", nil)})},
+	})
+	doc.Paths.Value("/health").Get.Responses.Status(200).Value.Content = openapi3.NewContentWithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/Node", nil))
+
+	flat, err := FlattenDocument(doc)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := flat.Validate(context.Background()); err != nil {
+		t.Errorf("Flattened document is invalid: %v", err)
+	}
+
+	data, _ := json.Marshal(flat.Paths)
+	if strings.Contains(string(data), "#/components/schemas/User") || strings.Contains(string(data), "#/components/schemas/Address") {
+		t.Errorf("Expected non-recursive refs to be inlined, got %s", data)
+	}
+	if !strings.Contains(string(data), `"address":{"type":"object"}`) {
+		t.Errorf("Expected nested Address schema to be inlined, got %s", data)
+	}
+
+	// Only the recursive schema is kept
+	if len(flat.Components.Schemas) != 1 || flat.Components.Schemas["Node"] == nil {
+		t.Errorf("Expected only the recursive Node schema to be kept, got %v", schemaNames(flat))
+	}
+
+	// The original document is unchanged
+	if doc.Paths.Value("/users").Get.Responses.Status(200).Value.Content["application/json"].Schema.Ref != "#/components/schemas/User" {
+		t.Error("Expected the original document to keep its refs")
+	}
+}
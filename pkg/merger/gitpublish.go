@@ -0,0 +1,147 @@
+package merger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultCommitMessage is used when GitTarget.Message is empty
+const defaultCommitMessage = "Update merged API spec {{.Version}}"
+
+// GitTarget describes where the merged document is committed
+type GitTarget struct {
+	// Repo is the repository URL, anything git clone accepts
+	Repo string
+	// Branch to commit to. Defaults to the repository's default branch.
+	Branch string
+	// Path of the file inside the repository
+	Path string
+	// Message is a text/template for the commit message with the fields
+	// .Title, .Version, .Date and .Files (number of merged inputs)
+	Message string
+}
+
+// commitMessageData holds the fields available to commit message templates
+type commitMessageData struct {
+	Title   string
+	Version string
+	Date    string
+	Files   int
+}
+
+// CommitToGit commits the document produced by the last merge to a git
+// repository and pushes it. Nothing is committed when the file is
+// unchanged. Credentials come from the usual git configuration.
+func (m *Merger) CommitToGit(target GitTarget) error {
+	return m.CommitToGitContext(context.Background(), target)
+}
+
+// CommitToGitContext is CommitToGit with git killed once ctx is done
+func (m *Merger) CommitToGitContext(ctx context.Context, target GitTarget) error {
+	return m.withContext(ctx, func() error { return m.commitToGit(target) })
+}
+
+// commitToGit clones the target repository, commits and pushes
+func (m *Merger) commitToGit(target GitTarget) error {
+	if m.merged == nil {
+		return fmt.Errorf("nothing to commit, merge first")
+	}
+	if target.Repo == "" || target.Path == "" {
+		return fmt.Errorf("git target needs a repository and a path")
+	}
+	if strings.HasPrefix(target.Repo, "-") {
+		return fmt.Errorf("invalid git repository %s: must not start with '-'", target.Repo)
+	}
+	if target.Branch != "" {
+		if err := checkRefName(target.Branch); err != nil {
+			return fmt.Errorf("invalid git branch: %v", err)
+		}
+	}
+
+	message, err := m.commitMessage(target.Message)
+	if err != nil {
+		return err
+	}
+
+	data, err := m.encode(m.merged, FormatYAML)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "swagger-merger-commit-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := m.context()
+	cloneArgs := []string{"clone", "--quiet", "--depth", "1"}
+	if target.Branch != "" {
+		cloneArgs = append(cloneArgs, "--branch", target.Branch)
+	}
+	if _, err := runToolContext(ctx, nil, "git", append(cloneArgs, "--", target.Repo, dir)...); err != nil {
+		return fmt.Errorf("failed to clone %s: %v", target.Repo, err)
+	}
+
+	file := filepath.Join(dir, filepath.FromSlash(target.Path))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	if _, err := runGit(ctx, dir, "add", "--", target.Path); err != nil {
+		return err
+	}
+	status, err := runGit(ctx, dir, "status", "--porcelain", "--", target.Path)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(status)) == 0 {
+		m.config.Logger.Debug("merged spec unchanged, nothing to commit", "repo", target.Repo)
+		return nil
+	}
+
+	if _, err := runGit(ctx, dir, "commit", "--quiet", "-m", message); err != nil {
+		return fmt.Errorf("failed to commit: %v", err)
+	}
+	if _, err := runGit(ctx, dir, "push", "--quiet", "--", "origin", "HEAD"); err != nil {
+		return fmt.Errorf("failed to push to %s: %v", target.Repo, err)
+	}
+
+	m.config.Logger.Debug("committed merged spec", "repo", target.Repo, "path", target.Path)
+	return nil
+}
+
+// commitMessage renders the commit message template
+func (m *Merger) commitMessage(text string) (string, error) {
+	if text == "" {
+		text = defaultCommitMessage
+	}
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit message template: %v", err)
+	}
+
+	data := commitMessageData{
+		Date:  time.Now().UTC().Format("2006-01-02"),
+		Files: len(m.inputs) - len(m.skipped),
+	}
+	if m.merged.Info != nil {
+		data.Title = m.merged.Info.Title
+		data.Version = m.merged.Info.Version
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid commit message template: %v", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
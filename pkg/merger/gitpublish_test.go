@@ -0,0 +1,79 @@
+package merger
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestCommitToGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	// Remote with an initial commit on main
+	remote := t.TempDir()
+	work := t.TempDir()
+	for _, step := range []struct {
+		dir  string
+		args []string
+	}{
+		{remote, []string{"init", "--quiet", "--bare", "--initial-branch", "main"}},
+		{work, []string{"init", "--quiet", "--initial-branch", "main"}},
+		{work, []string{"commit", "--quiet", "--allow-empty", "-m", "init"}},
+		{work, []string{"push", "--quiet", remote, "main"}},
+	} {
+		if _, err := runGit(context.Background(), step.dir, step.args...); err != nil {
+			t.Fatalf("Failed to set up repository: %v", err)
+		}
+	}
+
+	m := New(Config{InputPaths: []string{"a.yaml"}})
+	m.merged = &openapi3.T{OpenAPI: "3.0.1", Info: &openapi3.Info{Title: "Platform API", Version: "2.1.0"}}
+
+	target := GitTarget{Repo: remote, Branch: "main", Path: "docs/openapi.yaml", Message: "docs: {{.Title}} {{.Version}} from {{.Files}} files"}
+	if err := m.CommitToGit(target); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	log, err := runGit(context.Background(), remote, "log", "--format=%s", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(log), "docs: Platform API 2.1.0 from 1 files\n") {
+		t.Errorf("Unexpected commit log: %s", log)
+	}
+
+	// Unchanged output does not create a new commit
+	if err := m.CommitToGit(target); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	count, _ := runGit(context.Background(), remote, "rev-list", "--count", "main")
+	if strings.TrimSpace(string(count)) != "2" {
+		t.Errorf("Expected 2 commits, got %s", count)
+	}
+
+	// Option-shaped repositories and branches never reach git
+	for _, bad := range []GitTarget{
+		{Repo: "--upload-pack=touch pwned", Path: "openapi.yaml"},
+		{Repo: remote, Branch: "--upload-pack=touch pwned", Path: "openapi.yaml"},
+	} {
+		if err := m.CommitToGit(bad); err == nil {
+			t.Errorf("Expected an error for %+v", bad)
+		}
+	}
+
+	// git runs under the given context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.CommitToGitContext(ctx, target); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package merger
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsGlob reports whether a local input is a glob pattern (*, ?, [...],
+// {a,b} or **) rather than a file or directory name
+func IsGlob(input string) bool {
+	return !IsRemoteSource(input) && strings.ContainsAny(input, "*?[{")
+}
+
+// expandBraces expands {a,b} alternatives into separate patterns
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+
+	// Find the matching closing brace and the top-level commas
+	depth, commas, end := 0, []int{}, -1
+	for i := open; i < len(pattern) && end < 0; i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		}
+	}
+	if end < 0 {
+		return []string{pattern}
+	}
+
+	var patterns []string
+	start := open + 1
+	for _, i := range append(commas, end) {
+		alt := pattern[:open] + pattern[start:i] + pattern[end+1:]
+		patterns = append(patterns, expandBraces(alt)...)
+		start = i + 1
+	}
+	return patterns
+}
+
+// matchGlob reports whether a slash-separated path matches a pattern
+// where ** matches any number of directories and {a,b} alternatives
+func matchGlob(pattern, name string) bool {
+	for _, p := range expandBraces(pattern) {
+		if matchSegments(strings.Split(p, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// validGlob checks that every alternative of a pattern is well-formed
+func validGlob(pattern string) error {
+	for _, p := range expandBraces(pattern) {
+		for _, segment := range strings.Split(p, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// globRoot splits a glob into the directory without glob characters and
+// the pattern relative to it
+func globRoot(glob string) (string, string) {
+	segments := strings.Split(filepath.ToSlash(glob), "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[{") {
+			root := strings.Join(segments[:i], "/")
+			if root == "" && i > 0 {
+				root = "/"
+			} else if root == "" {
+				root = "."
+			}
+			return filepath.FromSlash(root), strings.Join(segments[i:], "/")
+		}
+	}
+	return filepath.Dir(glob), filepath.Base(glob)
+}
+
+// expandGlob returns the files matching a glob input
+func expandGlob(glob string) ([]string, error) {
+	root, pattern := globRoot(glob)
+	if err := validGlob(pattern); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if matchGlob(pattern, filepath.ToSlash(rel)) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,70 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.yaml", "users.yaml", true},
+		{"*.{yaml,yml,json}", "users.json", true},
+		{"*.{yaml,yml}", "users.json", false},
+		{"services/**/openapi.yaml", "services/openapi.yaml", true},
+		{"services/**/openapi.yaml", "services/users/v1/openapi.yaml", true},
+		{"services/**/openapi.yaml", "legacy/users/openapi.yaml", false},
+		{"**/{api,openapi}.{yaml,json}", "a/b/api.json", true},
+		{"services/*/openapi.yaml", "services/users/v1/openapi.yaml", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExpandGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"services/users/openapi.yaml",
+		"services/orders/v2/openapi.json",
+		"services/orders/v2/schemas.yaml",
+		"tools/openapi.yaml",
+	} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(testSpec), 0644)
+	}
+
+	files, err := expandGlob(filepath.Join(dir, "services/**/openapi.{yaml,yml,json}"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{
+		filepath.Join(dir, "services/orders/v2/openapi.json"),
+		filepath.Join(dir, "services/users/openapi.yaml"),
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	// Scan patterns with a slash match paths relative to the directory
+	files, err = scanDirectory(dir, ScanOptions{Patterns: []string{"services/**/*.json", "tools/*.yaml"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected = []string{
+		filepath.Join(dir, "services/orders/v2/openapi.json"),
+		filepath.Join(dir, "tools/openapi.yaml"),
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
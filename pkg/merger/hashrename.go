@@ -0,0 +1,86 @@
+package merger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaHashLength is the number of hex digits of content hash suffixes
+const schemaHashLength = 6
+
+// WithRenameHandler registers a function called for every schema renamed
+// by SchemaRename
+func WithRenameHandler(fn func(Rename)) Option {
+	return func(o *mergeOptions) {
+		o.renameHandlers = append(o.renameHandlers, fn)
+	}
+}
+
+// schemaHashName returns the name of a schema suffixed with a short hash
+// of its content
+func schemaHashName(name string, schema *openapi3.SchemaRef) (string, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash schema %s: %v", name, err)
+	}
+	sum := sha256.Sum256(data)
+	return name + "_" + hex.EncodeToString(sum[:])[:schemaHashLength], nil
+}
+
+// freeSchemaName returns hashed, or hashed with a numeric suffix when doc
+// already defines hashed differently from schema. An equal definition
+// keeps the name, the renamed schema merging into it.
+func freeSchemaName(doc *openapi3.T, hashed string, schema *openapi3.SchemaRef) string {
+	name := hashed
+	for i := 2; ; i++ {
+		taken, ok := doc.Components.Schemas[name]
+		if !ok || equalJSON(taken, schema) {
+			return name
+		}
+		name = fmt.Sprintf("%s_%d", hashed, i)
+	}
+}
+
+// renameCollidingSchemas renames the schemas of doc that merged already
+// defines differently to their content hash name, rewriting the references
+// of doc, before doc is merged with SchemaRename. Renaming a schema changes
+// the schemas referencing it, which are checked again until none collides.
+func renameCollidingSchemas(st *mergeState, merged, doc *openapi3.T, source string) error {
+	if doc.Components == nil || merged.Components == nil {
+		return nil
+	}
+	// Each round renames colliding schemas to new names; bound the rounds
+	// in case a hash name collides as well
+	for round := 0; round <= len(doc.Components.Schemas); round++ {
+		renames := make(map[string]string)
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			schema := doc.Components.Schemas[name]
+			existing, ok := merged.Components.Schemas[name]
+			if !ok || equalJSON(existing, schema) {
+				continue
+			}
+			hashed, err := schemaHashName(name, schema)
+			if err != nil {
+				return err
+			}
+			renames[name] = freeSchemaName(doc, hashed, schema)
+		}
+		if len(renames) == 0 {
+			return nil
+		}
+		if err := renameComponents(doc, "schemas", renames); err != nil {
+			return fmt.Errorf("failed to rename colliding schemas of %s: %v", source, err)
+		}
+		for _, name := range sortedKeys(renames) {
+			r := Rename{Source: source, Kind: "schemas", From: name, To: renames[name]}
+			for _, fn := range st.opts.renameHandlers {
+				fn(r)
+			}
+		}
+	}
+	return nil
+}
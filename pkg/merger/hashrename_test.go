@@ -0,0 +1,113 @@
+package merger
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// userDoc returns a document with a User schema with an id of a type,
+// referenced by an Order schema and a path
+func userDoc(t *testing.T, path, idType string) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  `+path+`:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id: {type: `+idType+`}
+    Order:
+      type: object
+      properties:
+        buyer: {$ref: '#/components/schemas/User'}
+`)
+}
+
+func TestMergeDocumentsSchemaRename(t *testing.T) {
+	merge := func() (*openapi3.T, []Rename, []Conflict) {
+		var renames []Rename
+		var conflicts []Conflict
+		merged, err := MergeDocuments(
+			[]*openapi3.T{userDoc(t, "/a", "string"), userDoc(t, "/b", "integer"), userDoc(t, "/c", "integer")},
+			WithSourceNames("a.yaml", "b.yaml", "c.yaml"),
+			WithSchemaStrategy(SchemaRename),
+			WithRenameHandler(func(r Rename) { renames = append(renames, r) }),
+			WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }),
+		)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return merged, renames, conflicts
+	}
+
+	merged, renames, conflicts := merge()
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+	// User is renamed, then Order, which references the renamed User
+	if len(renames) != 4 || renames[0].Source != "b.yaml" || renames[0].From != "User" || renames[1].From != "Order" {
+		t.Fatalf("Expected User and Order of b.yaml and c.yaml to be renamed, got %v", renames)
+	}
+	user := renames[0].To
+	if !regexp.MustCompile(`^User_[0-9a-f]{6}$`).MatchString(user) || renames[2].To != user {
+		t.Errorf("Expected equal definitions to get the same hash name, got %v", renames)
+	}
+
+	schemas := merged.Components.Schemas
+	if len(schemas) != 4 || !schemas["User"].Value.Properties["id"].Value.Type.Is("string") ||
+		!schemas[user].Value.Properties["id"].Value.Type.Is("integer") {
+		t.Errorf("Expected both User definitions, got %v", sortedKeys(schemas))
+	}
+	if ref := merged.Paths.Find("/b").Get.Responses.Status(200).Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/"+user {
+		t.Errorf("Expected the reference of b.yaml to be rewritten, got %s", ref)
+	}
+	if ref := schemas[renames[1].To].Value.Properties["buyer"].Ref; ref != "#/components/schemas/"+user {
+		t.Errorf("Expected the renamed Order to reference %s, got %s", user, ref)
+	}
+
+	if _, again, _ := merge(); !equalJSON(again, renames) {
+		t.Errorf("Expected stable names, got %v and %v", renames, again)
+	}
+}
+
+func TestMergeDocumentsSchemaRenameTakenName(t *testing.T) {
+	other := userDoc(t, "/b", "integer")
+	hashed, err := schemaHashName("User", other.Components.Schemas["User"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// other already defines the hash name of its User, differently
+	other.Components.Schemas[hashed] = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+
+	var renames []Rename
+	merged, err := MergeDocuments([]*openapi3.T{userDoc(t, "/a", "string"), other},
+		WithSchemaStrategy(SchemaRename),
+		WithRenameHandler(func(r Rename) { renames = append(renames, r) }),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(renames) == 0 || renames[0].From != "User" || renames[0].To != hashed+"_2" {
+		t.Fatalf("Expected User to be renamed to %s_2, got %v", hashed, renames)
+	}
+	schemas := merged.Components.Schemas
+	if !schemas[hashed].Value.Type.Is("string") || !schemas[hashed+"_2"].Value.Properties["id"].Value.Type.Is("integer") {
+		t.Errorf("Expected both the existing %s and the renamed User, got %v", hashed, sortedKeys(schemas))
+	}
+	if ref := merged.Paths.Find("/b").Get.Responses.Status(200).Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/"+hashed+"_2" {
+		t.Errorf("Expected the reference to follow the renamed User, got %s", ref)
+	}
+}
@@ -0,0 +1,65 @@
+package merger
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Health is a Telemetry tracking merge results for liveness and readiness
+// probes. Mount Liveness and Readiness as the /healthz and /readyz
+// handlers of a service running the merger; combine it with other
+// telemetry using MultiTelemetry.
+type Health struct {
+	// ready is set by the first successful merge
+	ready atomic.Bool
+}
+
+// NewHealth creates a Health, not ready until a merge succeeds
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// Ready reports whether a merge has succeeded
+func (h *Health) Ready() bool {
+	return h.ready.Load()
+}
+
+// Liveness returns a handler always answering 200 OK
+func (h *Health) Liveness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// Readiness returns a handler answering 200 OK once a merge succeeded, and
+// 503 Service Unavailable before. A later failing merge keeps the service
+// ready, as it still serves the last merged spec.
+func (h *Health) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.Ready() {
+			http.Error(w, "initial merge not completed", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// healthSpan marks its Health ready when a merge ends successfully
+type healthSpan struct {
+	health *Health
+	merge  bool
+}
+
+func (h *Health) StartSpan(parent Span, name string, attrs ...Attribute) Span {
+	return healthSpan{health: h, merge: name == "swagger_merger.merge"}
+}
+
+func (s healthSpan) End(err error) {
+	if s.merge && err == nil {
+		s.health.ready.Store(true)
+	}
+}
+
+func (h *Health) RecordDuration(string, time.Duration, ...Attribute) {}
+func (h *Health) AddCount(string, int64, ...Attribute)               {}
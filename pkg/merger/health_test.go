@@ -0,0 +1,57 @@
+package merger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	health := NewHealth()
+	metrics := NewMetrics()
+	telemetry := MultiTelemetry(metrics, health)
+	status := func(handler http.Handler) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+		return recorder.Code
+	}
+
+	if code := status(health.Liveness()); code != http.StatusOK {
+		t.Errorf("Expected liveness 200, got %d", code)
+	}
+	if code := status(health.Readiness()); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected readiness 503 before merging, got %d", code)
+	}
+
+	if _, err := New(Config{InputPaths: []string{file + ".missing"}, Telemetry: telemetry}).loadAndMerge(); err == nil {
+		t.Fatal("Expected an error for a missing input")
+	}
+	if code := status(health.Readiness()); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected readiness 503 after a failed merge, got %d", code)
+	}
+
+	if _, err := New(Config{InputPaths: []string{file}, Telemetry: telemetry}).loadAndMerge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if code := status(health.Readiness()); code != http.StatusOK {
+		t.Errorf("Expected readiness 200 after a successful merge, got %d", code)
+	}
+
+	var body strings.Builder
+	metrics.WriteTo(&body)
+	if !strings.Contains(body.String(), `swagger_merger_merges_total{result="success"} 1`) {
+		t.Errorf("Expected MultiTelemetry to also feed the metrics, got:\n%s", body.String())
+	}
+}
@@ -0,0 +1,47 @@
+package merger
+
+import "github.com/getkin/kin-openapi/openapi3"
+
+// Hooks lets consumers inject custom transformations into the merge.
+// Any hook may be nil. Returning an error aborts the merge.
+type Hooks struct {
+	// BeforeDocumentMerge is called for every input document, in order,
+	// before it is merged. source is the file path or URL it came from.
+	BeforeDocumentMerge func(doc *openapi3.T, source string) error
+
+	// AfterMerge is called once with the merged document before it is returned.
+	AfterMerge func(merged *openapi3.T) error
+}
+
+// WithHooks registers hooks to run while merging
+func WithHooks(hooks Hooks) Option {
+	return func(o *mergeOptions) {
+		o.hooks = append(o.hooks, hooks)
+	}
+}
+
+// beforeDocumentMerge runs every BeforeDocumentMerge hook for doc
+func (o *mergeOptions) beforeDocumentMerge(doc *openapi3.T, source string) error {
+	for _, h := range o.hooks {
+		if h.BeforeDocumentMerge == nil {
+			continue
+		}
+		if err := h.BeforeDocumentMerge(doc, source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterMerge runs every AfterMerge hook for merged
+func (o *mergeOptions) afterMerge(merged *openapi3.T) error {
+	for _, h := range o.hooks {
+		if h.AfterMerge == nil {
+			continue
+		}
+		if err := h.AfterMerge(merged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package merger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestMergeDocumentsHooks(t *testing.T) {
+	doc1 := &openapi3.T{OpenAPI: "3.0.1", Info: &openapi3.Info{Title: "API 1"}}
+	doc2 := &openapi3.T{OpenAPI: "3.0.1", Info: &openapi3.Info{Title: "API 2"}}
+
+	var sources []string
+	hooks := Hooks{
+		BeforeDocumentMerge: func(doc *openapi3.T, source string) error {
+			sources = append(sources, source)
+			doc.Tags = append(doc.Tags, &openapi3.Tag{Name: source})
+			return nil
+		},
+		AfterMerge: func(merged *openapi3.T) error {
+			merged.Info.Title = "Merged"
+			return nil
+		},
+	}
+
+	merged, err := MergeDocuments([]*openapi3.T{doc1, doc2}, WithSourceNames("a.yaml", "b.yaml"), WithHooks(hooks))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(sources) != 2 || sources[0] != "a.yaml" || sources[1] != "b.yaml" {
+		t.Errorf("Expected hooks for a.yaml and b.yaml, got %v", sources)
+	}
+
+	if len(merged.Tags) != 2 {
+		t.Errorf("Expected 2 tags added by hooks, got %d", len(merged.Tags))
+	}
+
+	if merged.Info.Title != "Merged" {
+		t.Errorf("Expected title 'Merged', got '%s'", merged.Info.Title)
+	}
+
+	// Hook errors abort the merge
+	failing := Hooks{AfterMerge: func(*openapi3.T) error { return fmt.Errorf("boom") }}
+	if _, err := MergeDocuments([]*openapi3.T{doc1}, WithHooks(failing)); err == nil {
+		t.Error("Expected error from failing hook")
+	}
+}
@@ -0,0 +1,85 @@
+package merger
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// httpCacheEntry holds the validators of a cached remote response
+type httpCacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// httpCachePaths returns the metadata and body files of a cached URL
+func httpCachePaths(dir, url string) (string, string) {
+	base := filepath.Join(dir, "http", contentHash([]byte(url)))
+	return base + ".json", base + ".body"
+}
+
+// loadHTTPCache returns the cached validators and body of url, if any
+func (m *Merger) loadHTTPCache(url string) (*httpCacheEntry, []byte, bool) {
+	if m.config.CacheDir == "" {
+		return nil, nil, false
+	}
+
+	metaPath, bodyPath := httpCachePaths(m.config.CacheDir, url)
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	var entry httpCacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URL != url {
+		return nil, nil, false
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	return &entry, body, true
+}
+
+// storeHTTPCache saves a response body with its validators. Responses
+// without an ETag or Last-Modified header are not cached.
+func (m *Merger) storeHTTPCache(url string, header http.Header, body []byte) {
+	if m.config.CacheDir == "" {
+		return
+	}
+
+	entry := httpCacheEntry{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return
+	}
+
+	metaPath, bodyPath := httpCachePaths(m.config.CacheDir, url)
+	meta, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(metaPath), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(bodyPath, body, 0644)
+	}
+	if err == nil {
+		err = os.WriteFile(metaPath, meta, 0644)
+	}
+	if err != nil {
+		m.config.Logger.Warn("failed to cache remote input", "source", url, "error", err)
+	}
+}
+
+// setConditionalHeaders asks the server to skip the body if it is unchanged
+func (e *httpCacheEntry) setConditionalHeaders(req *http.Request) {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
@@ -0,0 +1,117 @@
+package merger
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultIgnoreFile is the name of the files listing paths excluded from
+// directory scanning, in gitignore syntax
+const defaultIgnoreFile = ".swaggerignore"
+
+// ignoreRule is a single pattern of an ignore file
+type ignoreRule struct {
+	// base is the slash-separated directory of the ignore file, relative
+	// to the scanned directory
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// parseIgnore parses an ignore file located in base
+func parseIgnore(data []byte, base string) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// match reports whether the rule matches a slash-separated path relative
+// to the scanned directory
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, r.base+"/")
+	}
+	if r.anchored {
+		return matchGlob(r.pattern, rel)
+	}
+	return matchGlob(r.pattern, path.Base(rel))
+}
+
+// ignorer holds the rules of the ignore files found while scanning
+type ignorer struct {
+	name  string
+	rules []ignoreRule
+}
+
+// load adds the rules of the ignore file in dir, if any
+func (ig *ignorer) load(root, dir string) error {
+	if ig.name == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ig.name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	base, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	if base == "." {
+		base = ""
+	}
+	ig.rules = append(ig.rules, parseIgnore(data, filepath.ToSlash(base))...)
+	return nil
+}
+
+// ignored reports whether a path is excluded; the last matching rule wins
+func (ig *ignorer) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range ig.rules {
+		if rule.match(rel, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
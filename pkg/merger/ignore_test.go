@@ -0,0 +1,49 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanDirectoryIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"users.yaml":                   testSpec,
+		"users.generated.yaml":         testSpec,
+		"vendor/stripe.yaml":           testSpec,
+		"orders/openapi.yaml":          testSpec,
+		"orders/test/snippet.yaml":     testSpec,
+		"orders/keep.generated.yaml":   testSpec,
+		"orders/fixtures/fixture.yaml": testSpec,
+		".swaggerignore":               "# generated specs\n*.generated.yaml\n/vendor/\n**/fixtures\n",
+		"orders/.swaggerignore":        "test/\n!keep.generated.yaml\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	found, err := scanDirectory(dir, ScanOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{
+		filepath.Join(dir, "orders/keep.generated.yaml"),
+		filepath.Join(dir, "orders/openapi.yaml"),
+		filepath.Join(dir, "users.yaml"),
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+
+	found, err = scanDirectory(dir, ScanOptions{IgnoreFile: "-"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(found) != 7 {
+		t.Errorf("Expected all 7 specs without ignore files, got %v", found)
+	}
+}
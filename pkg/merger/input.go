@@ -0,0 +1,238 @@
+package merger
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// indexPrefix marks an input listing the specs to merge, e.g.
+// index+https://registry.example.com/specs.json
+const indexPrefix = "index+"
+
+// isIndexSource checks if a path is an index of specs
+func isIndexSource(path string) bool {
+	return strings.HasPrefix(path, indexPrefix)
+}
+
+// InputOrder controls the order in which inputs are merged. Later inputs
+// win conflicts, and the first input provides the info of the result.
+type InputOrder string
+
+const (
+	// OrderAsGiven merges inputs in the order given, with directory files
+	// in lexical order and index entries in their explicit order
+	OrderAsGiven InputOrder = "as-given"
+	// OrderAlpha merges all inputs sorted by name
+	OrderAlpha InputOrder = "alpha"
+	// OrderMtime merges local files from oldest to newest modification
+	// time, followed by remote inputs in the order given
+	OrderMtime InputOrder = "mtime"
+)
+
+// indexEntry is a spec listed in an index, either a plain string or an
+// object with a url field and an optional order
+type indexEntry struct {
+	URL   string `yaml:"url"`
+	Order *int   `yaml:"order"`
+}
+
+func (e *indexEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&e.URL)
+	}
+	type plain indexEntry
+	return node.Decode((*plain)(e))
+}
+
+// parseIndex parses a JSON or YAML index: a list of entries, or an object
+// listing them under specs
+func parseIndex(data []byte) ([]string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	var entries []indexEntry
+	if len(node.Content) > 0 && node.Content[0].Kind == yaml.MappingNode {
+		var index struct {
+			Specs []indexEntry `yaml:"specs"`
+		}
+		if err := node.Decode(&index); err != nil {
+			return nil, err
+		}
+		entries = index.Specs
+	} else if err := node.Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	// Entries with an explicit order come first, the others keep their
+	// position in the index
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Order, entries[j].Order
+		return a != nil && (b == nil || *a < *b)
+	})
+
+	var sources []string
+	for _, e := range entries {
+		if e.URL == "" {
+			return nil, fmt.Errorf("index entry without url")
+		}
+		sources = append(sources, e.URL)
+	}
+	return sources, nil
+}
+
+// resolveIndexEntry resolves an entry relative to the index listing it.
+// Remote indexes are not trusted like local ones: their entries must be
+// http(s) URLs, so whoever serves the index cannot make the merge run git
+// or read object storage with the local credentials.
+func resolveIndexEntry(index, entry string) (string, error) {
+	if IsRemoteSource(index) {
+		if !isURL(index) {
+			return "", fmt.Errorf("entry %s: only http(s) indexes can be listed remotely", entry)
+		}
+		base, err := url.Parse(index)
+		if err != nil {
+			return "", fmt.Errorf("invalid index URL %s: %v", index, err)
+		}
+		ref, err := url.Parse(entry)
+		if err != nil {
+			return "", fmt.Errorf("invalid entry %s: %v", entry, err)
+		}
+		resolved := base.ResolveReference(ref).String()
+		if !isURL(resolved) {
+			return "", fmt.Errorf("entry %s: remote indexes may only list http(s) URLs", entry)
+		}
+		return resolved, nil
+	}
+	if IsRemoteSource(entry) {
+		return entry, nil
+	}
+	if filepath.IsAbs(entry) {
+		return entry, nil
+	}
+	return filepath.Join(filepath.Dir(index), entry), nil
+}
+
+// expandInputs replaces index inputs with the specs they list, and
+// directories and archives with the files matching the scan patterns
+func (m *Merger) expandInputs(inputs []string) ([]string, error) {
+	m.archives = nil
+	expanded, err := m.expand(inputs, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return orderInputs(expanded, m.config.Order)
+}
+
+// orderInputs sorts expanded inputs according to order
+func orderInputs(inputs []string, order InputOrder) ([]string, error) {
+	switch order {
+	case "", OrderAsGiven:
+	case OrderAlpha:
+		sort.Strings(inputs)
+	case OrderMtime:
+		mtimes := make(map[string]time.Time)
+		for _, input := range inputs {
+			if IsRemoteSource(input) || isArchiveMember(input) {
+				continue
+			}
+			info, err := os.Stat(input)
+			if err != nil {
+				continue
+			}
+			mtimes[input] = info.ModTime()
+		}
+		sort.SliceStable(inputs, func(i, j int) bool {
+			a, aok := mtimes[inputs[i]]
+			b, bok := mtimes[inputs[j]]
+			return aok && (!bok || a.Before(b))
+		})
+	default:
+		return nil, fmt.Errorf("invalid input order %q (expected as-given, alpha or mtime)", order)
+	}
+	return inputs, nil
+}
+
+// expand expands inputs, recursing into nested indexes; seen guards
+// against index cycles
+func (m *Merger) expand(inputs []string, seen map[string]bool) ([]string, error) {
+	var expanded []string
+	for _, input := range inputs {
+		var files []string
+		var err error
+		switch {
+		case isIndexSource(input):
+			files, err = m.expandIndex(input, seen)
+		case IsGlob(input):
+			files, err = expandGlob(input)
+			if err != nil {
+				err = fmt.Errorf("error expanding %s: %v", input, err)
+			}
+		case isArchive(input):
+			files, err = m.scanArchive(input)
+		case isLocalDirectory(input):
+			files, err = scanDirectory(input, m.config.Scan)
+			if err != nil {
+				err = fmt.Errorf("error scanning directory %s: %v", input, err)
+			}
+		default:
+			expanded = append(expanded, input)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 && !isIndexSource(input) {
+			m.config.Logger.Warn("no matching files found", "source", input, "patterns", m.config.Scan.patterns())
+		}
+		for _, file := range files {
+			m.config.Logger.Debug("found input", "source", file)
+		}
+		expanded = append(expanded, files...)
+	}
+	return expanded, nil
+}
+
+// expandIndex returns the specs listed by an index input
+func (m *Merger) expandIndex(input string, seen map[string]bool) ([]string, error) {
+	index := strings.TrimPrefix(input, indexPrefix)
+	if seen[index] {
+		return nil, fmt.Errorf("index %s includes itself", index)
+	}
+	seen[index] = true
+
+	data, err := m.readDataFromPath(index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %v", index, err)
+	}
+	entries, err := parseIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index %s: %v", index, err)
+	}
+	for i, entry := range entries {
+		resolved, err := resolveIndexEntry(index, strings.TrimPrefix(entry, indexPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %s: %v", index, err)
+		}
+		if isIndexSource(entry) {
+			resolved = indexPrefix + resolved
+		}
+		entries[i] = resolved
+	}
+	m.config.Logger.Debug("expanded index", "source", index, "count", len(entries))
+
+	sources, err := m.expand(entries, seen)
+	if err != nil {
+		return nil, err
+	}
+	delete(seen, index)
+	return sources, nil
+}
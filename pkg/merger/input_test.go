@@ -0,0 +1,158 @@
+package merger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseIndex(t *testing.T) {
+	for _, data := range []string{
+		`["users.yaml", "https://example.com/orders.json"]`,
+		"specs:\n  - users.yaml\n  - url: https://example.com/orders.json\n",
+	} {
+		sources, err := parseIndex([]byte(data))
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got %v", data, err)
+		}
+		if !reflect.DeepEqual(sources, []string{"users.yaml", "https://example.com/orders.json"}) {
+			t.Errorf("Unexpected sources for %q: %v", data, sources)
+		}
+	}
+
+	if _, err := parseIndex([]byte(`specs: [{name: users}]`)); err == nil {
+		t.Error("Expected error for entry without url")
+	}
+}
+
+func TestMergeIndexInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/registry/index.json":
+			w.Write([]byte(`{"specs": ["specs/users.yaml", {"url": "/registry/specs/orders.yaml"}]}`))
+		case "/registry/specs/users.yaml", "/registry/specs/orders.yaml":
+			w.Write([]byte(testSpec))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "local.yaml")
+	os.WriteFile(local, []byte(testSpec), 0644)
+	index := filepath.Join(dir, "index.yaml")
+	os.WriteFile(index, []byte("- local.yaml\n- index+"+server.URL+"/registry/index.json\n"), 0644)
+
+	m := New(Config{InputPaths: []string{"index+" + index}, OutputPath: filepath.Join(dir, "merged.yaml")})
+	stats, err := m.MergeWithStats()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats["total_files"] != 3 {
+		t.Errorf("Expected 3 files, got %d", stats["total_files"])
+	}
+
+	expected := []string{local, server.URL + "/registry/specs/users.yaml", server.URL + "/registry/specs/orders.yaml"}
+	if !reflect.DeepEqual(m.inputs, expected) {
+		t.Errorf("Expected inputs %v, got %v", expected, m.inputs)
+	}
+
+	os.WriteFile(index, []byte("- index+index.yaml\n"), 0644)
+	if err := m.Merge(); err == nil {
+		t.Error("Expected error for index including itself")
+	}
+}
+
+func TestRemoteIndexEntries(t *testing.T) {
+	base := "https://registry.example.com/specs/index.json"
+	for entry, expected := range map[string]string{
+		"users.yaml":                         "https://registry.example.com/specs/users.yaml",
+		"/other/orders.yaml":                 "https://registry.example.com/other/orders.yaml",
+		"http://mirror.example.com/a.yaml":   "http://mirror.example.com/a.yaml",
+		"https://mirror.example.com/b.json":  "https://mirror.example.com/b.json",
+		"../shared/index.json?version=2#top": "https://registry.example.com/shared/index.json?version=2#top",
+	} {
+		resolved, err := resolveIndexEntry(base, entry)
+		if err != nil || resolved != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", entry, expected, resolved, err)
+		}
+	}
+
+	// Other schemes would run git or use the local cloud credentials
+	for _, entry := range []string{
+		"git+https://host/repo.git?ref=main&path=a.yaml",
+		"git+file:///tmp/repo?path=a.yaml",
+		"s3://bucket/secret.yaml",
+		"gs://bucket/secret.yaml",
+		"azblob://account/container/secret.yaml",
+		"swaggerhub://org/api",
+		"file:///etc/passwd",
+	} {
+		if _, err := resolveIndexEntry(base, entry); err == nil {
+			t.Errorf("Expected %s to be rejected in a remote index", entry)
+		}
+	}
+	if _, err := resolveIndexEntry("s3://bucket/index.yaml", "users.yaml"); err == nil {
+		t.Error("Expected entries of a non-http remote index to be rejected")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("- git+https://host/repo.git?ref=--upload-pack=touch%20pwned&path=a.yaml\n"))
+	}))
+	defer server.Close()
+	m := New(Config{InputPaths: []string{"index+" + server.URL + "/index.yaml"}, OutputPath: filepath.Join(t.TempDir(), "merged.yaml")})
+	if _, err := m.MergeToDocument(); err == nil {
+		t.Error("Expected a remote index listing a git input to be rejected")
+	}
+}
+
+func TestOrderInputs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	var files []string
+	for i, name := range []string{"b.yaml", "c.yaml", "a.yaml"} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(testSpec), 0644)
+		// c.yaml is the oldest, a.yaml the newest
+		mtime := now.Add(time.Duration([]int{-1, -2, 0}[i]) * time.Hour)
+		os.Chtimes(path, mtime, mtime)
+		files = append(files, path)
+	}
+	remote := "https://example.com/openapi.yaml"
+
+	tests := []struct {
+		order InputOrder
+		want  []string
+	}{
+		{OrderAsGiven, []string{remote, files[0], files[1], files[2]}},
+		{OrderAlpha, []string{files[2], files[0], files[1], remote}},
+		{OrderMtime, []string{files[1], files[0], files[2], remote}},
+	}
+	for _, tt := range tests {
+		got, err := orderInputs(append([]string{remote}, files...), tt.order)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.order, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.order, tt.want, got)
+		}
+	}
+
+	if _, err := orderInputs(files, "random"); err == nil {
+		t.Error("Expected error for invalid order")
+	}
+
+	sources, err := parseIndex([]byte("specs:\n  - late.yaml\n  - {url: second.yaml, order: 2}\n  - {url: first.yaml, order: 1}\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(sources, []string{"first.yaml", "second.yaml", "late.yaml"}) {
+		t.Errorf("Unexpected index order: %v", sources)
+	}
+}
@@ -0,0 +1,18 @@
+package merger
+
+import "github.com/getkin/kin-openapi/openapi3"
+
+// Interface is the part of Merger applications usually depend on. Code
+// taking an Interface instead of a *Merger can be tested with a fake and
+// given another implementation, such as one calling a remote merge
+// service.
+type Interface interface {
+	// Merge merges the inputs and writes the outputs
+	Merge() error
+	// MergeToDocument merges the inputs without writing any output
+	MergeToDocument() (*openapi3.T, error)
+	// Stats returns statistics about the last merge, or nil
+	Stats() map[string]int
+}
+
+var _ Interface = (*Merger)(nil)
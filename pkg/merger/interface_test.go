@@ -0,0 +1,87 @@
+package merger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// fakeMerger is an Interface implementation as applications would write
+// to test code depending on a merger
+type fakeMerger struct {
+	doc    *openapi3.T
+	merged bool
+}
+
+func (f *fakeMerger) Merge() error {
+	_, err := f.MergeToDocument()
+	return err
+}
+
+func (f *fakeMerger) MergeToDocument() (*openapi3.T, error) {
+	if f.doc == nil {
+		return nil, fmt.Errorf("no document")
+	}
+	f.merged = true
+	return f.doc, nil
+}
+
+func (f *fakeMerger) Stats() map[string]int {
+	if !f.merged {
+		return nil
+	}
+	return map[string]int{"total_paths": f.doc.Paths.Len()}
+}
+
+// countPaths is application code depending on Interface
+func countPaths(m Interface) (int, error) {
+	if _, err := m.MergeToDocument(); err != nil {
+		return 0, err
+	}
+	return m.Stats()["total_paths"], nil
+}
+
+func TestInterface(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        "200": {description: ok}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	output := filepath.Join(t.TempDir(), "merged.yaml")
+	m := New(Config{InputPaths: []string{file}, OutputPath: output})
+	if m.Stats() != nil {
+		t.Errorf("Expected no stats before merging, got %v", m.Stats())
+	}
+	if n, err := countPaths(m); err != nil || n != 1 {
+		t.Errorf("Expected 1 path, got %d (%v)", n, err)
+	}
+	if m.Document() == nil {
+		t.Error("Expected MergeToDocument to keep the merged document")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("Expected MergeToDocument to write no output")
+	}
+
+	fake := &fakeMerger{doc: &openapi3.T{Paths: openapi3.NewPaths(
+		openapi3.WithPath("/a", &openapi3.PathItem{}),
+		openapi3.WithPath("/b", &openapi3.PathItem{}),
+	)}}
+	if n, err := countPaths(fake); err != nil || n != 2 {
+		t.Errorf("Expected 2 paths from the fake, got %d (%v)", n, err)
+	}
+	if _, err := countPaths(&fakeMerger{}); err == nil {
+		t.Error("Expected the fake's error")
+	}
+}
@@ -0,0 +1,80 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// defaultJSONIndent is the indentation of JSON outputs when not configured
+const defaultJSONIndent = 2
+
+// JSONStyle controls how JSON outputs are emitted
+type JSONStyle struct {
+	// Indent is the number of spaces per nesting level, from 1 to 8
+	// (default 2)
+	Indent int
+	// Minify writes JSON without whitespace
+	Minify bool
+	// ASCII escapes every non-ASCII character as \uXXXX, for tools that
+	// only accept ASCII
+	ASCII bool
+}
+
+// validate checks that the style is supported
+func (s JSONStyle) validate() error {
+	if s.Indent < 0 || s.Indent > 8 {
+		return fmt.Errorf("invalid JSON indent %d (expected 1 to 8)", s.Indent)
+	}
+	return nil
+}
+
+// formatJSON reformats JSON encoded with the default indentation in a
+// style
+func formatJSON(data []byte, style JSONStyle) ([]byte, error) {
+	if err := style.validate(); err != nil {
+		return nil, err
+	}
+	if style.Minify || (style.Indent != 0 && style.Indent != defaultJSONIndent) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, data); err != nil {
+			return nil, fmt.Errorf("error formatting JSON: %v", err)
+		}
+		if !style.Minify {
+			compact := buf.Bytes()
+			buf = bytes.Buffer{}
+			if err := json.Indent(&buf, compact, "", strings.Repeat(" ", style.Indent)); err != nil {
+				return nil, fmt.Errorf("error formatting JSON: %v", err)
+			}
+		}
+		buf.WriteByte('\n')
+		data = buf.Bytes()
+	}
+	if style.ASCII {
+		data = escapeNonASCII(data)
+	}
+	return data, nil
+}
+
+// escapeNonASCII escapes the non-ASCII characters of JSON, which can only
+// appear in strings, as \uXXXX, with surrogate pairs beyond the BMP
+func escapeNonASCII(data []byte) []byte {
+	var buf bytes.Buffer
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		switch {
+		case r < utf8.RuneSelf:
+			buf.WriteByte(data[0])
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&buf, `\u%04x\u%04x`, r1, r2)
+		default:
+			fmt.Fprintf(&buf, `\u%04x`, r)
+		}
+		data = data[size:]
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,39 @@
+package merger
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFormatJSON(t *testing.T) {
+	value := map[string]any{"title": "Café 🚀", "tags": []any{"a<b"}}
+	data, _ := json.MarshalIndent(value, "", "  ")
+	data = append(data, '\n')
+
+	tests := []struct {
+		style JSONStyle
+		want  string
+	}{
+		{JSONStyle{}, "{\n  \"tags\": [\n    \"a\\u003cb\"\n  ],\n  \"title\": \"Café 🚀\"\n}\n"},
+		{JSONStyle{Indent: 4}, "{\n    \"tags\": [\n        \"a\\u003cb\"\n    ],\n    \"title\": \"Café 🚀\"\n}\n"},
+		{JSONStyle{Minify: true}, "{\"tags\":[\"a\\u003cb\"],\"title\":\"Café 🚀\"}\n"},
+		{JSONStyle{Minify: true, ASCII: true}, "{\"tags\":[\"a\\u003cb\"],\"title\":\"Caf\\u00e9 \\ud83d\\ude80\"}\n"},
+	}
+	for _, test := range tests {
+		got, err := formatJSON(data, test.style)
+		if err != nil {
+			t.Fatalf("%+v: expected no error, got %v", test.style, err)
+		}
+		if string(got) != test.want {
+			t.Errorf("%+v: expected:\n%s\ngot:\n%s", test.style, test.want, got)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(got, &decoded); err != nil || decoded["title"] != value["title"] {
+			t.Errorf("%+v: expected the same value back, got %v (%v)", test.style, decoded, err)
+		}
+	}
+
+	if _, err := formatJSON(data, JSONStyle{Indent: 9}); err == nil {
+		t.Error("Expected an invalid indent to fail")
+	}
+}
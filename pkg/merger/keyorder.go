@@ -0,0 +1,143 @@
+package merger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyOrder controls the order of the keys of outputs
+type KeyOrder string
+
+const (
+	// KeyOrderSorted sorts the keys of every object alphabetically
+	// (default)
+	KeyOrderSorted KeyOrder = "sorted"
+	// KeyOrderSource keeps keys, such as paths and schema properties, in
+	// the order they first appear in the inputs. Keys no input has at the
+	// same location, like renamed schemas, follow in sorted order.
+	KeyOrderSource KeyOrder = "source"
+)
+
+// validate checks that the order is known
+func (o KeyOrder) validate() error {
+	switch o {
+	case "", KeyOrderSorted, KeyOrderSource:
+		return nil
+	}
+	return fmt.Errorf("invalid key order %q (expected sorted or source)", o)
+}
+
+// keyOrder is an ordered index of the keys of the objects of the inputs, by
+// location in the document
+type keyOrder map[string][]string
+
+// record adds the keys of an input document that were not seen before, in
+// document order
+func (o keyOrder) record(doc *yaml.Node) {
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i].Value, root.Content[i+1]
+		location := rootLocation(root, key)
+		if location == definitionsLocation {
+			o.add("", "components")
+			o.add(orderLocation("", "components"), "schemas")
+		} else {
+			o.add("", key)
+		}
+		o.walk(location, value)
+	}
+}
+
+// definitionsLocation is the location of component schemas, where the
+// definitions of Swagger 2.0 inputs end up
+var definitionsLocation = orderLocation(orderLocation("", "components"), "schemas")
+
+// rootLocation returns the location in the merged document of a top-level
+// key of an input
+func rootLocation(root *yaml.Node, key string) string {
+	if key == "definitions" {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "swagger" {
+				return definitionsLocation
+			}
+		}
+	}
+	return orderLocation("", key)
+}
+
+// walk records the keys of a node and its descendants
+func (o keyOrder) walk(location string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			o.add(location, key)
+			o.walk(orderLocation(location, key), node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			o.walk(location+"/[]", item)
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			o.walk(location, node.Alias)
+		}
+	}
+}
+
+// add appends a key to the keys of a location, unless already there
+func (o keyOrder) add(location, key string) {
+	for _, known := range o[location] {
+		if known == key {
+			return
+		}
+	}
+	o[location] = append(o[location], key)
+}
+
+// apply reorders the mappings below a node, at location, by the recorded
+// order. Keys not recorded keep their relative order after the others.
+func (o keyOrder) apply(location string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			o.apply(location, child)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			o.apply(location+"/[]", item)
+		}
+	case yaml.MappingNode:
+		rank := make(map[string]int)
+		for i, key := range o[location] {
+			rank[key] = i
+		}
+		type pair struct{ key, value *yaml.Node }
+		pairs := make([]pair, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			ri, iok := rank[pairs[i].key.Value]
+			rj, jok := rank[pairs[j].key.Value]
+			if iok && jok {
+				return ri < rj
+			}
+			return iok && !jok
+		})
+		node.Content = node.Content[:0]
+		for _, p := range pairs {
+			node.Content = append(node.Content, p.key, p.value)
+			o.apply(orderLocation(location, p.key.Value), p.value)
+		}
+	}
+}
+
+// orderLocation returns the location of a key of the object at location,
+// escaping slashes like JSON pointers
+func orderLocation(location, key string) string {
+	return location + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,105 @@
+package merger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeKeyOrderSource(t *testing.T) {
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.yaml")
+	os.WriteFile(users, []byte(`openapi: "3.0.1"
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        fullName: {type: string}
+        userId: {type: integer}
+        emailAddress: {type: string}
+`), 0644)
+	orders := filepath.Join(dir, "orders.json")
+	os.WriteFile(orders, []byte(`{"swagger": "2.0", "info": {"title": "Orders", "version": "1.0.0"},
+"paths": {"/orders": {"get": {"responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Order"}}}}}},
+"definitions": {"Order": {"type": "object", "properties": {"total": {"type": "number"}, "created": {"type": "string"}}}}}`), 0644)
+
+	yamlOut := filepath.Join(dir, "merged.yaml")
+	jsonOut := filepath.Join(dir, "merged.json")
+	m := New(Config{InputPaths: []string{users, orders}, OutputPath: yamlOut, Outputs: []string{jsonOut}, KeyOrder: KeyOrderSource})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, output := range []string{yamlOut, jsonOut} {
+		data, _ := os.ReadFile(output)
+		assertOrder(t, output, string(data), "/users", "/users/{id}", "/orders")
+		assertOrder(t, output, string(data), "fullName", "userId", "emailAddress")
+		assertOrder(t, output, string(data), "User", "Order")
+		assertOrder(t, output, string(data), "total", "created")
+		assertOrder(t, output, string(data), "openapi", "info", "paths", "components")
+	}
+
+	data, _ := os.ReadFile(jsonOut)
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, data)
+	}
+
+	if err := New(Config{InputPaths: []string{users}, OutputPath: yamlOut, KeyOrder: "random"}).Merge(); err == nil {
+		t.Error("Expected an invalid key order to fail")
+	}
+}
+
+// assertOrder checks that keys first appear in data in the given order
+func assertOrder(t *testing.T, name, data string, keys ...string) {
+	t.Helper()
+	last := -1
+	for _, key := range keys {
+		i := strings.Index(data, key)
+		if i < last {
+			t.Errorf("%s: expected %v in this order, got:\n%s", name, keys, data)
+			return
+		}
+		last = i
+	}
+}
+
+func TestEncodeJSONNodeMatchesMarshalIndent(t *testing.T) {
+	value := map[string]any{
+		"b": []any{1.5, "x<y", true, nil, map[string]any{}},
+		"a": map[string]any{"z": "é", "y": []any{}},
+	}
+	want, _ := json.MarshalIndent(value, "", "  ")
+	node, err := documentNode(value, FormatJSON)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	got, err := encodeJSONNode(node)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(got) != string(want)+"\n" {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,366 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is the level of a lint rule and its findings
+type Severity string
+
+const (
+	// SeverityError findings fail the lint by default
+	SeverityError Severity = "error"
+	// SeverityWarn findings are reported
+	SeverityWarn Severity = "warn"
+	// SeverityInfo findings are informational
+	SeverityInfo Severity = "info"
+	// SeverityOff disables a rule
+	SeverityOff Severity = "off"
+)
+
+// rank orders severities, from off (0) to error (3)
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarn:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as severe as threshold or more. Nothing is
+// at least SeverityOff.
+func (s Severity) AtLeast(threshold Severity) bool {
+	return threshold.rank() > 0 && s.rank() >= threshold.rank()
+}
+
+// validate checks that the severity is known
+func (s Severity) validate() error {
+	switch s {
+	case SeverityError, SeverityWarn, SeverityInfo, SeverityOff:
+		return nil
+	}
+	return fmt.Errorf("invalid severity %q (expected error, warn, info or off)", s)
+}
+
+// LintFinding is a violation of a lint rule
+type LintFinding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Location is the dotted path of the offending node, e.g.
+	// paths./users.get.responses
+	Location string `json:"location"`
+	// Path is the API path the finding belongs to, if any
+	Path string `json:"path,omitempty"`
+	// Schema is the component schema the finding belongs to, if any
+	Schema string `json:"schema,omitempty"`
+	// Source is the input that contributed the path or schema, when known
+	Source  string `json:"source,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f LintFinding) String() string {
+	s := fmt.Sprintf("%s %s %s: %s", f.Severity, f.Rule, f.Location, f.Message)
+	if f.Source != "" {
+		s += " (" + f.Source + ")"
+	}
+	return s
+}
+
+// LintRule checks a document. Check returns findings with their Location,
+// Path and Message set; the linter fills in the rule name and severity.
+type LintRule struct {
+	Name        string
+	Description string
+	// Severity is the default severity of the rule's findings
+	Severity Severity
+	Check    func(doc *openapi3.T) []LintFinding
+}
+
+// LintConfig overrides the severities of lint rules by name, turning them
+// off with SeverityOff, and sets the casings of the naming rules
+type LintConfig struct {
+	Rules  map[string]Severity `json:"rules" yaml:"rules"`
+	Naming NamingConfig        `json:"naming" yaml:"naming"`
+}
+
+// ParseLintConfig parses a YAML or JSON lint configuration:
+//
+//	rules:
+//	  operation-summary: error
+//	  no-empty-schema: off
+//	naming:
+//	  properties: snake
+func ParseLintConfig(data []byte) (LintConfig, error) {
+	var config LintConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("failed to parse lint config: %v", err)
+	}
+	for name, severity := range config.Rules {
+		if err := severity.validate(); err != nil {
+			return config, fmt.Errorf("rule %s: %v", name, err)
+		}
+	}
+	return config, nil
+}
+
+// Linter runs lint rules against a document
+type Linter struct {
+	rules []LintRule
+}
+
+// NewLinter creates a linter with the built-in and naming rules, their
+// severities overridden by config
+func NewLinter(config LintConfig) (*Linter, error) {
+	l := &Linter{}
+	for _, rule := range DefaultLintRules() {
+		l.Add(rule)
+	}
+	naming, err := NamingLintRules(config.Naming)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range naming {
+		l.Add(rule)
+	}
+	if err := l.Configure(config); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Add registers a rule, replacing any rule with the same name
+func (l *Linter) Add(rule LintRule) {
+	for i := range l.rules {
+		if l.rules[i].Name == rule.Name {
+			l.rules[i] = rule
+			return
+		}
+	}
+	l.rules = append(l.rules, rule)
+}
+
+// Configure applies the severities of config to the registered rules
+func (l *Linter) Configure(config LintConfig) error {
+	for _, name := range sortedKeys(config.Rules) {
+		severity := config.Rules[name]
+		if err := severity.validate(); err != nil {
+			return fmt.Errorf("rule %s: %v", name, err)
+		}
+		rule := l.rule(name)
+		if rule == nil {
+			return fmt.Errorf("unknown lint rule %q", name)
+		}
+		rule.Severity = severity
+	}
+	return nil
+}
+
+// Rules returns the registered rules
+func (l *Linter) Rules() []LintRule {
+	return l.rules
+}
+
+// Lint runs the enabled rules against doc, returning the findings sorted
+// by location
+func (l *Linter) Lint(doc *openapi3.T) []LintFinding {
+	var findings []LintFinding
+	for _, rule := range l.rules {
+		if rule.Severity == SeverityOff || rule.Severity == "" {
+			continue
+		}
+		for _, finding := range rule.Check(doc) {
+			finding.Rule, finding.Severity = rule.Name, rule.Severity
+			findings = append(findings, finding)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Location != findings[j].Location {
+			return findings[i].Location < findings[j].Location
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+	return findings
+}
+
+// Lint runs a linter against the last merged spec, reporting findings with
+// the input that contributed their path or schema
+func (m *Merger) Lint(l *Linter) ([]LintFinding, error) {
+	if m.merged == nil {
+		return nil, fmt.Errorf("nothing to lint, merge first")
+	}
+	findings := l.Lint(m.merged)
+	for i := range findings {
+		switch {
+		case findings[i].Path != "":
+			findings[i].Source = m.pathSources[findings[i].Path]
+		case findings[i].Schema != "":
+			findings[i].Source = m.schemaSources[findings[i].Schema]
+		}
+	}
+	return findings, nil
+}
+
+// CountFindings returns the number of findings at least as severe as
+// threshold
+func CountFindings(findings []LintFinding, threshold Severity) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Severity.AtLeast(threshold) {
+			count++
+		}
+	}
+	return count
+}
+
+// DefaultLintRules returns the built-in rules:
+//
+//   - operation-operationId (error): operations have an operationId
+//   - operation-success-response (error): operations have a 2xx response
+//   - operation-summary (warn): operations have a summary
+//   - no-empty-schema (warn): component and body schemas constrain
+//     something beyond their title, description and examples
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{
+			Name:        "operation-operationId",
+			Description: "Operations must have an operationId",
+			Severity:    SeverityError,
+			Check: operationRule(func(op *openapi3.Operation) string {
+				if op.OperationID == "" {
+					return "operation has no operationId"
+				}
+				return ""
+			}),
+		},
+		{
+			Name:        "operation-success-response",
+			Description: "Operations must have a 2xx response",
+			Severity:    SeverityError,
+			Check: operationRule(func(op *openapi3.Operation) string {
+				if op.Responses != nil {
+					for status := range op.Responses.Map() {
+						if strings.HasPrefix(status, "2") {
+							return ""
+						}
+					}
+				}
+				return "operation has no 2xx response"
+			}),
+		},
+		{
+			Name:        "operation-summary",
+			Description: "Operations must have a summary",
+			Severity:    SeverityWarn,
+			Check: operationRule(func(op *openapi3.Operation) string {
+				if strings.TrimSpace(op.Summary) == "" {
+					return "operation has no summary"
+				}
+				return ""
+			}),
+		},
+		{
+			Name:        "no-empty-schema",
+			Description: "Schemas must not be empty",
+			Severity:    SeverityWarn,
+			Check:       checkEmptySchemas,
+		},
+	}
+}
+
+// operationRule returns a check calling fn for every operation; a
+// non-empty result is reported as a finding on the operation
+func operationRule(fn func(op *openapi3.Operation) string) func(doc *openapi3.T) []LintFinding {
+	return func(doc *openapi3.T) []LintFinding {
+		var findings []LintFinding
+		forEachOperation(doc, func(path, method string, op *openapi3.Operation) {
+			if message := fn(op); message != "" {
+				findings = append(findings, LintFinding{
+					Location: "paths." + path + "." + strings.ToLower(method),
+					Path:     path,
+					Message:  message,
+				})
+			}
+		})
+		return findings
+	}
+}
+
+// forEachOperation calls fn for every operation of doc, in order
+func forEachOperation(doc *openapi3.T, fn func(path, method string, op *openapi3.Operation)) {
+	if doc.Paths == nil {
+		return
+	}
+	paths := doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		ops := paths[path].Operations()
+		for _, method := range sortedKeys(ops) {
+			fn(path, method, ops[method])
+		}
+	}
+}
+
+// checkEmptySchemas reports the component schemas and inline body schemas
+// that are empty
+func checkEmptySchemas(doc *openapi3.T) []LintFinding {
+	var findings []LintFinding
+	if doc.Components != nil {
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			if isEmptySchema(doc.Components.Schemas[name]) {
+				findings = append(findings, LintFinding{
+					Location: "components.schemas." + name,
+					Schema:   name,
+					Message:  "schema is empty",
+				})
+			}
+		}
+	}
+	forEachBody(doc, func(body bodyContent) {
+		if !isEmptySchema(body.media.Schema) {
+			return
+		}
+		findings = append(findings, LintFinding{
+			Location: body.schemaLocation(),
+			Path:     body.path,
+			Message:  "schema is empty",
+		})
+	})
+	return findings
+}
+
+// schemaLocation returns the lint location of the schema of a body, e.g.
+// paths./users.post.requestBody.content.application/json.schema
+func (b bodyContent) schemaLocation() string {
+	location := "paths." + b.path + "." + strings.ToLower(b.method) + ".requestBody"
+	if b.status != "" {
+		location = "paths." + b.path + "." + strings.ToLower(b.method) + ".responses." + b.status
+	}
+	return location + ".content." + b.mediaType + ".schema"
+}
+
+// isEmptySchema reports whether an inline schema has no keyword other than
+// documentation
+func isEmptySchema(ref *openapi3.SchemaRef) bool {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return false
+	}
+	data, err := json.Marshal(ref.Value)
+	if err != nil {
+		return false
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return false
+	}
+	stripFields(schema, documentationFields)
+	return len(schema) == 0
+}
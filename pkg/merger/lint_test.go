@@ -0,0 +1,122 @@
+package merger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// lintDoc returns a document breaking every built-in rule once
+func lintDoc(t *testing.T) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      summary: List users
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+    post:
+      summary: Create a user
+      requestBody:
+        content:
+          application/json:
+            schema: {description: anything}
+      responses: {"201": {description: created}}
+  /health:
+    get:
+      operationId: health
+      responses: {default: {description: status}}
+components:
+  schemas:
+    User:
+      type: object
+    Anything:
+      title: Anything
+`)
+}
+
+func TestLinter(t *testing.T) {
+	linter, err := NewLinter(LintConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, finding := range linter.Lint(lintDoc(t)) {
+		got = append(got, finding.Rule+" "+finding.Location)
+	}
+	expected := []string{
+		"no-empty-schema components.schemas.Anything",
+		"operation-success-response paths./health.get",
+		"operation-summary paths./health.get",
+		"operation-operationId paths./users.post",
+		"no-empty-schema paths./users.post.requestBody.content.application/json.schema",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLinterConfig(t *testing.T) {
+	config, err := ParseLintConfig([]byte(`
+rules:
+  operation-summary: error
+  no-empty-schema: off
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	linter, err := NewLinter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := linter.Lint(lintDoc(t))
+	if len(findings) != 3 {
+		t.Fatalf("Expected 3 findings, got %v", findings)
+	}
+	for _, finding := range findings {
+		if finding.Severity != SeverityError {
+			t.Errorf("Expected error severity, got %s", finding)
+		}
+	}
+	if CountFindings(findings, SeverityWarn) != 3 || CountFindings(findings, SeverityOff) != 0 {
+		t.Errorf("Unexpected finding counts for %v", findings)
+	}
+
+	if _, err := NewLinter(LintConfig{Rules: map[string]Severity{"missing": SeverityWarn}}); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+	if _, err := ParseLintConfig([]byte("rules: {operation-summary: fatal}")); err == nil {
+		t.Error("Expected an error for an invalid severity")
+	}
+}
+
+func TestMergerLint(t *testing.T) {
+	m := New(Config{})
+	linter, _ := NewLinter(LintConfig{})
+	if _, err := m.Lint(linter); err == nil {
+		t.Error("Expected an error before merging")
+	}
+
+	m.merged = lintDoc(t)
+	m.pathSources = map[string]string{"/users": "users.yaml"}
+	findings, err := m.Lint(linter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, finding := range findings {
+		if finding.Path == "/users" && finding.Source != "users.yaml" {
+			t.Errorf("Expected users.yaml as source of %s", finding)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package merger
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// componentSections maps conflict kinds to their components section
+var componentSections = map[string]string{
+	"schema":         "schemas",
+	"response":       "responses",
+	"parameter":      "parameters",
+	"requestBody":    "requestBodies",
+	"header":         "headers",
+	"example":        "examples",
+	"link":           "links",
+	"callback":       "callbacks",
+	"securityScheme": "securitySchemes",
+}
+
+// swagger2Sections maps OpenAPI 3 components sections to where Swagger 2.0
+// inputs define them
+var swagger2Sections = map[string]string{
+	"schemas":         "definitions",
+	"parameters":      "parameters",
+	"responses":       "responses",
+	"securitySchemes": "securityDefinitions",
+}
+
+// Keys returns the keys locating the conflicting definition in an input,
+// e.g. [components schemas User], for use with LineOf
+func (c Conflict) Keys() []string {
+	switch c.Kind {
+	case "path":
+		return []string{"paths", c.Name}
+	case "webhook":
+		return []string{"webhooks", c.Name}
+	case "extension":
+		if name, ok := strings.CutPrefix(c.Name, "components."); ok {
+			return []string{"components", name}
+		}
+		return []string{c.Name}
+	case "discriminator":
+		schema, _, _ := strings.Cut(c.Name, ".")
+		return []string{"components", "schemas", schema}
+	}
+	// Callbacks and links carried over from a replaced operation are
+	// named "METHOD /path ..."
+	if _, rest, ok := strings.Cut(c.Name, " /"); ok {
+		path, _, _ := strings.Cut(rest, " ")
+		return []string{"paths", "/" + path}
+	}
+	if section, ok := componentSections[c.Kind]; ok {
+		return []string{"components", section, c.Name}
+	}
+	return nil
+}
+
+// LineOf returns the line at which a YAML or JSON document defines the
+// value at keys, or of its deepest ancestor found, or 0. Component keys
+// are also looked up where Swagger 2.0 documents define them.
+func LineOf(data []byte, keys ...string) int {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return 0
+	}
+	line := lineOf(root.Content[0], keys)
+	if len(keys) > 1 && keys[0] == "components" {
+		if section, ok := swagger2Sections[keys[1]]; ok {
+			if swagger2 := lineOf(root.Content[0], append([]string{section}, keys[2:]...)); swagger2 > line {
+				line = swagger2
+			}
+		}
+	}
+	return line
+}
+
+// lineOf walks a mapping node down keys, returning the line of the last
+// key found
+func lineOf(node *yaml.Node, keys []string) int {
+	line := 0
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return line
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line, node, found = node.Content[i].Line, node.Content[i+1], true
+				break
+			}
+		}
+		if !found {
+			return line
+		}
+	}
+	return line
+}
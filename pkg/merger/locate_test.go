@@ -0,0 +1,63 @@
+package merger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLineOf(t *testing.T) {
+	spec := []byte(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    get:
+      responses: {"200": {description: ok}}
+components:
+  schemas:
+    User:
+      type: object
+`)
+	tests := []struct {
+		keys []string
+		line int
+	}{
+		{[]string{"paths", "/users"}, 4},
+		{[]string{"components", "schemas", "User"}, 9},
+		{[]string{"components", "schemas", "Order"}, 8},
+		{[]string{"webhooks", "ping"}, 0},
+	}
+	for _, tt := range tests {
+		if line := LineOf(spec, tt.keys...); line != tt.line {
+			t.Errorf("LineOf(%v) = %d, expected %d", tt.keys, line, tt.line)
+		}
+	}
+
+	swagger := []byte(`{
+  "swagger": "2.0",
+  "definitions": {
+    "User": {"type": "object"}
+  }
+}`)
+	if line := LineOf(swagger, "components", "schemas", "User"); line != 4 {
+		t.Errorf("Expected the Swagger 2.0 definition at line 4, got %d", line)
+	}
+}
+
+func TestConflictKeys(t *testing.T) {
+	tests := []struct {
+		conflict Conflict
+		keys     []string
+	}{
+		{Conflict{Kind: "path", Name: "/users"}, []string{"paths", "/users"}},
+		{Conflict{Kind: "schema", Name: "User"}, []string{"components", "schemas", "User"}},
+		{Conflict{Kind: "securityScheme", Name: "oauth"}, []string{"components", "securitySchemes", "oauth"}},
+		{Conflict{Kind: "discriminator", Name: "Pet.dog"}, []string{"components", "schemas", "Pet"}},
+		{Conflict{Kind: "extension", Name: "components.x-owner"}, []string{"components", "x-owner"}},
+		{Conflict{Kind: "link", Name: "get /users 200 next"}, []string{"paths", "/users"}},
+	}
+	for _, tt := range tests {
+		if keys := tt.conflict.Keys(); !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("%v: expected keys %v, got %v", tt.conflict, tt.keys, keys)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package merger
+
+import "log/slog"
+
+// Logger receives the merger's diagnostic output. Messages carry
+// structured key/value pairs in the style of log/slog, so a *slog.Logger
+// satisfies the interface directly.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NewSlogLogger returns a Logger that writes to the given slog handler
+func NewSlogLogger(handler slog.Handler) Logger {
+	return slog.New(handler)
+}
+
+// nopLogger discards all messages
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
@@ -0,0 +1,88 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	m := New(Config{InputPaths: []string{file}, Logger: logger})
+	if _, err := m.loadAndMerge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), file) {
+		t.Errorf("Expected log output to mention %s, got:\n%s", file, buf.String())
+	}
+}
+
+func TestStructuredEvents(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses: {"200": {description: ok}}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.NewJSONHandler(&buf, nil))
+	output := filepath.Join(t.TempDir(), "merged.yaml")
+
+	events := func() map[string]map[string]any {
+		t.Helper()
+		events := make(map[string]map[string]any)
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var event map[string]any
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				t.Fatalf("Expected JSON log lines, got %q", line)
+			}
+			events[event["msg"].(string)] = event
+		}
+		buf.Reset()
+		return events
+	}
+
+	m := New(Config{InputPaths: []string{file}, OutputPath: output, Logger: logger})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	merged := events()
+	if processed := merged["processed input"]; processed == nil || processed["source"] != file || processed["paths"] != float64(1) {
+		t.Errorf("Expected a processed input event for %s, got %v", file, processed)
+	}
+	if complete := merged["merge complete"]; complete == nil || complete["files"] != float64(1) || complete["conflicts"] != float64(0) {
+		t.Errorf("Expected a merge complete event with stats, got %v", complete)
+	}
+
+	// Merging to a document reports completion the same way
+	if _, err := m.MergeToDocument(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if complete := events()["merge complete"]; complete == nil || complete["paths"] != float64(1) || complete["duration_ms"] == nil {
+		t.Errorf("Expected a merge complete event from MergeToDocument, got %v", complete)
+	}
+}
@@ -0,0 +1,152 @@
+package merger
+
+import (
+	"fmt"
+	"mime"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// MediaTypeOptions configure NormalizeMediaTypes
+type MediaTypeOptions struct {
+	// Aliases map media types to their canonical name (default: text/json
+	// to application/json)
+	Aliases map[string]string
+	// Parameters lists the media type parameters removed (default:
+	// charset)
+	Parameters []string
+	// Drop lists the media types removed from bodies offering another
+	// media type with the same schema (default: text/plain)
+	Drop []string
+}
+
+// NormalizeMediaTypes returns a transformer normalizing the media types of
+// the request and response bodies of the merged document, which different
+// generators spell differently: names are lowercased, aliased and stripped
+// of the configured parameters, so that application/json; charset=utf-8
+// becomes application/json. When several media types of a body normalize
+// to the same one, the one already spelled canonically, or else the first
+// alphabetically, is kept. Media types to drop are removed when they
+// duplicate another media type of the body.
+func NormalizeMediaTypes(opts MediaTypeOptions) (Transformer, error) {
+	if opts.Aliases == nil {
+		opts.Aliases = map[string]string{"text/json": "application/json"}
+	}
+	if opts.Parameters == nil {
+		opts.Parameters = []string{"charset"}
+	}
+	if opts.Drop == nil {
+		opts.Drop = []string{"text/plain"}
+	}
+	n := mediaTypeNormalizer{aliases: make(map[string]string, len(opts.Aliases)), parameters: opts.Parameters}
+	for from, to := range opts.Aliases {
+		for _, mediaType := range []string{from, to} {
+			if _, ok := n.normalize(mediaType); !ok {
+				return nil, fmt.Errorf("invalid media type %q", mediaType)
+			}
+		}
+		from, _ = n.normalize(from)
+		n.aliases[from], _ = n.normalize(to)
+	}
+	for _, mediaType := range opts.Drop {
+		canonical, ok := n.normalize(mediaType)
+		if !ok {
+			return nil, fmt.Errorf("invalid media type %q", mediaType)
+		}
+		n.drop = append(n.drop, canonical)
+	}
+
+	return NewTransformer("media-types", func(doc *openapi3.T, source string) error {
+		if doc.Paths != nil {
+			for _, item := range doc.Paths.Map() {
+				for _, op := range item.Operations() {
+					if op.RequestBody != nil && op.RequestBody.Value != nil {
+						op.RequestBody.Value.Content = n.apply(op.RequestBody.Value.Content)
+					}
+					if op.Responses != nil {
+						for _, resp := range op.Responses.Map() {
+							if resp.Value != nil {
+								resp.Value.Content = n.apply(resp.Value.Content)
+							}
+						}
+					}
+				}
+			}
+		}
+		if doc.Components != nil {
+			for _, body := range doc.Components.RequestBodies {
+				if body.Value != nil {
+					body.Value.Content = n.apply(body.Value.Content)
+				}
+			}
+			for _, resp := range doc.Components.Responses {
+				if resp.Value != nil {
+					resp.Value.Content = n.apply(resp.Value.Content)
+				}
+			}
+		}
+		return nil
+	}), nil
+}
+
+// mediaTypeNormalizer normalizes the media types of bodies
+type mediaTypeNormalizer struct {
+	aliases    map[string]string
+	parameters []string
+	drop       []string
+}
+
+// normalize returns the canonical name of a media type, or the media type
+// and false when it doesn't parse
+func (n mediaTypeNormalizer) normalize(mediaType string) (string, bool) {
+	name, params, err := mime.ParseMediaType(mediaType)
+	if err != nil || !strings.Contains(name, "/") {
+		return mediaType, false
+	}
+	if alias, ok := n.aliases[name]; ok {
+		name = alias
+	}
+	for _, param := range n.parameters {
+		delete(params, param)
+	}
+	return mime.FormatMediaType(name, params), true
+}
+
+// apply returns the content with normalized media types, without the
+// duplicates to drop
+func (n mediaTypeNormalizer) apply(content openapi3.Content) openapi3.Content {
+	if len(content) == 0 {
+		return content
+	}
+	normalized := make(openapi3.Content, len(content))
+	var renamed []string
+	for _, mediaType := range sortedKeys(content) {
+		if canonical, _ := n.normalize(mediaType); canonical == mediaType {
+			normalized[mediaType] = content[mediaType]
+		} else {
+			renamed = append(renamed, mediaType)
+		}
+	}
+	for _, mediaType := range renamed {
+		canonical, _ := n.normalize(mediaType)
+		if _, ok := normalized[canonical]; !ok {
+			normalized[canonical] = content[mediaType]
+		}
+	}
+
+	for _, mediaType := range n.drop {
+		media, ok := normalized[mediaType]
+		if !ok {
+			continue
+		}
+		duplicate := slices.ContainsFunc(sortedKeys(normalized), func(other string) bool {
+			return other != mediaType && equalJSON(normalized[other].Schema, media.Schema)
+		})
+		if duplicate {
+			delete(normalized, mediaType)
+		}
+	}
+	return normalized
+}
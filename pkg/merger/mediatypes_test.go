@@ -0,0 +1,84 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestNormalizeMediaTypes(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /orders:
+    post:
+      requestBody:
+        content:
+          Application/JSON; charset=UTF-8:
+            schema: {$ref: '#/components/schemas/Order'}
+          text/json:
+            schema: {$ref: '#/components/schemas/Order'}
+          text/plain:
+            schema: {$ref: '#/components/schemas/Order'}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: object}
+            application/json; charset=utf-8:
+              schema: {type: string}
+            application/vnd.orders+json; version=2:
+              schema: {type: object}
+            text/plain:
+              schema: {type: object}
+components:
+  responses:
+    Error:
+      description: error
+      content:
+        text/plain; charset=utf-8:
+          schema: {type: string}
+  schemas:
+    Order: {type: object}
+`)
+
+	transformer, err := NormalizeMediaTypes(MediaTypeOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := transformer.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	op := doc.Paths.Find("/orders").Post
+	assertMediaTypes(t, "request", op.RequestBody.Value.Content, "application/json")
+	response := op.Responses.Status(200).Value.Content
+	assertMediaTypes(t, "response", response, "application/json", "application/vnd.orders+json; version=2")
+	if response["application/json"].Schema.Value.Type.Is("string") {
+		t.Error("Expected the media type already spelled canonically to be kept")
+	}
+	// A sole text/plain body is not a duplicate
+	assertMediaTypes(t, "component", doc.Components.Responses["Error"].Value.Content, "text/plain")
+
+	if _, err := NormalizeMediaTypes(MediaTypeOptions{Aliases: map[string]string{"json": "application/json"}}); err == nil {
+		t.Error("Expected an error for an invalid media type")
+	}
+}
+
+// assertMediaTypes checks the media types of a content
+func assertMediaTypes(t *testing.T, name string, content openapi3.Content, want ...string) {
+	t.Helper()
+	got := sortedKeys(content)
+	if len(got) != len(want) {
+		t.Errorf("%s: expected media types %v, got %v", name, want, got)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: expected media types %v, got %v", name, want, got)
+			return
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Conflict describes a path or component defined by more than one input
+// with different content. The definition of the last source wins.
+type Conflict struct {
+	// Kind is "path" or the component type ("schema", "response", ...)
+	Kind string `json:"kind"`
+	// Name is the path or component name
+	Name string `json:"name"`
+	// Sources lists the input that defined the name first and the input
+	// that redefined it
+	Sources []string `json:"sources"`
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s %s defined differently in %v", c.Kind, c.Name, c.Sources)
+}
+
+// WithConflictHandler registers a function called for every conflict
+// found while merging
+func WithConflictHandler(fn func(Conflict)) Option {
+	return func(o *mergeOptions) {
+		o.conflictHandlers = append(o.conflictHandlers, fn)
+	}
+}
+
+// mergeState tracks which input contributed each path and component
+type mergeState struct {
+	opts   *mergeOptions
+	owners map[string]string
+}
+
+// own records source as the definer of a name
+func (st *mergeState) own(kind, name, source string) {
+	st.owners[kind+"\x00"+name] = source
+}
+
+// owner returns the input that defined a name
+func (st *mergeState) owner(kind, name string) string {
+	return st.owners[kind+"\x00"+name]
+}
+
+// conflict reports that source redefined a name with different content
+func (st *mergeState) conflict(kind, name, source string) {
+	c := Conflict{Kind: kind, Name: name, Sources: []string{st.owner(kind, name), source}}
+	for _, fn := range st.opts.conflictHandlers {
+		fn(c)
+	}
+}
+
+// equalJSON reports whether two values serialize to the same JSON
+func equalJSON(a, b any) bool {
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aj, bj)
+}
+
+// mergeNamed copies src into dst, reporting names that src redefines with
+// different content
+func mergeNamed[V any](st *mergeState, kind string, dst, src map[string]V, source string) {
+	for name, v := range src {
+		if existing, ok := dst[name]; ok && !equalJSON(existing, v) {
+			st.conflict(kind, name, source)
+		}
+		dst[name] = v
+		st.own(kind, name, source)
+	}
+}
+
+// ownNamed records source as the definer of every name in m
+func ownNamed[V any](st *mergeState, kind string, m map[string]V, source string) {
+	for name := range m {
+		st.own(kind, name, source)
+	}
+}
+
+// MergeDocuments merges already parsed OpenAPI 3.0 documents into one.
+// The first document is used as the base and is modified in place; paths,
+// components and tags from later documents are added to it, with later
+// documents winning on name collisions unless another schema strategy is
+// set with WithSchemaStrategy.
+func MergeDocuments(docs []*openapi3.T, opts ...Option) (*openapi3.T, error) {
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("no documents to merge")
+	}
+
+	o := newOptions(opts)
+	if err := o.schemaStrategy.validate(); err != nil {
+		return nil, err
+	}
+	if err := o.externalDocs.validate(); err != nil {
+		return nil, err
+	}
+	if err := o.extensions.validate(); err != nil {
+		return nil, err
+	}
+
+	for i, doc := range docs {
+		if doc == nil {
+			return nil, fmt.Errorf("%s is nil", o.sourceName(i))
+		}
+		if err := o.beforeDocumentMerge(doc, o.sourceName(i)); err != nil {
+			return nil, fmt.Errorf("before merge hook failed for %s: %v", o.sourceName(i), err)
+		}
+		prepareExternalDocs(o.externalDocs, doc)
+	}
+
+	merged := docs[0]
+	if merged.Components == nil {
+		merged.Components = &openapi3.Components{}
+	}
+
+	st := &mergeState{opts: o, owners: make(map[string]string)}
+	base := o.sourceName(0)
+	for path := range merged.Paths.Map() {
+		st.own("path", path, base)
+	}
+	for _, key := range webhookKeys {
+		if webhooks, ok := merged.Extensions[key].(map[string]any); ok {
+			ownNamed(st, "webhook", webhooks, base)
+		}
+	}
+	ownComponents(st, merged.Components, base)
+	ownExtensions(st, "", merged.Extensions, base)
+	ownExtensions(st, "components.", merged.Components.Extensions, base)
+
+	for i := 1; i < len(docs); i++ {
+		doc := docs[i]
+		source := o.sourceName(i)
+		if err := resolveCollisions(st, merged, doc, source); err != nil {
+			return nil, err
+		}
+		if o.schemaStrategy == SchemaRename {
+			if err := renameCollidingSchemas(st, merged, doc, source); err != nil {
+				return nil, err
+			}
+		}
+
+		// Merge paths
+		if doc.Paths != nil {
+			if merged.Paths == nil {
+				merged.Paths = &openapi3.Paths{}
+			}
+			for path, item := range doc.Paths.Map() {
+				if existing := merged.Paths.Value(path); existing != nil && !equalJSON(existing, item) {
+					st.conflict("path", path, source)
+					mergeReplacedOperations(st, path, existing, item, source)
+				}
+				merged.Paths.Set(path, item)
+				st.own("path", path, source)
+			}
+		}
+
+		// Initialize components if nil
+		if merged.Components.Schemas == nil {
+			merged.Components.Schemas = openapi3.Schemas{}
+		}
+		if merged.Components.Responses == nil {
+			merged.Components.Responses = openapi3.ResponseBodies{}
+		}
+		if merged.Components.Parameters == nil {
+			merged.Components.Parameters = openapi3.ParametersMap{}
+		}
+		if merged.Components.RequestBodies == nil {
+			merged.Components.RequestBodies = openapi3.RequestBodies{}
+		}
+		if merged.Components.Headers == nil {
+			merged.Components.Headers = openapi3.Headers{}
+		}
+		if merged.Components.Examples == nil {
+			merged.Components.Examples = openapi3.Examples{}
+		}
+		if merged.Components.Links == nil {
+			merged.Components.Links = openapi3.Links{}
+		}
+		if merged.Components.Callbacks == nil {
+			merged.Components.Callbacks = openapi3.Callbacks{}
+		}
+		if merged.Components.SecuritySchemes == nil {
+			merged.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+		}
+
+		// Merge components
+		if doc.Components != nil {
+			if err := mergeComponents(st, merged.Components, doc.Components, source); err != nil {
+				return nil, err
+			}
+		}
+
+		mergeWebhooks(st, merged, doc, source)
+		if err := mergeDocumentExtensions(st, merged, doc, source); err != nil {
+			return nil, err
+		}
+
+		// Merge tags
+		if doc.Tags != nil {
+			merged.Tags = append(merged.Tags, doc.Tags...)
+		}
+	}
+	mergeExternalDocs(o.externalDocs, merged, docs)
+	if err := validateDiscriminators(merged.Components); err != nil {
+		return nil, err
+	}
+
+	if err := o.afterMerge(merged); err != nil {
+		return nil, fmt.Errorf("after merge hook failed: %v", err)
+	}
+
+	return merged, nil
+}
+
+// mergeReplacedOperations keeps the callbacks and response links of the
+// operations of a path item that item, from source, replaces: those the
+// new operations don't define are carried over, and those they redefine
+// differently are reported as conflicts named "METHOD path name" (and
+// "METHOD path status name" for links)
+func mergeReplacedOperations(st *mergeState, path string, existing, item *openapi3.PathItem, source string) {
+	owner := st.owner("path", path)
+	for method, op := range item.Operations() {
+		old := existing.GetOperation(method)
+		if old == nil {
+			continue
+		}
+
+		if len(old.Callbacks) > 0 && op.Callbacks == nil {
+			op.Callbacks = openapi3.Callbacks{}
+		}
+		carryOver(st, "callback", method+" "+path+" ", op.Callbacks, old.Callbacks, owner, source)
+
+		if old.Responses == nil || op.Responses == nil {
+			continue
+		}
+		for status, resp := range op.Responses.Map() {
+			oldResp := old.Responses.Value(status)
+			if resp.Ref != "" || resp.Value == nil || oldResp == nil || oldResp.Value == nil {
+				continue
+			}
+			if len(oldResp.Value.Links) > 0 && resp.Value.Links == nil {
+				resp.Value.Links = openapi3.Links{}
+			}
+			carryOver(st, "link", method+" "+path+" "+status+" ", resp.Value.Links, oldResp.Value.Links, owner, source)
+		}
+	}
+}
+
+// carryOver copies the entries of old, defined by owner, missing from dst
+// and reports those dst, from source, defines differently
+func carryOver[V any](st *mergeState, kind, prefix string, dst, old map[string]V, owner, source string) {
+	for name, v := range old {
+		current, ok := dst[name]
+		if !ok {
+			dst[name] = v
+			continue
+		}
+		if !equalJSON(current, v) {
+			st.own(kind, prefix+name, owner)
+			st.conflict(kind, prefix+name, source)
+		}
+	}
+}
+
+// webhookKeys are the document fields holding webhooks: webhooks in
+// OpenAPI 3.1 and the x-webhooks extension used with 3.0. Both are kept
+// in the document extensions by the loader.
+var webhookKeys = []string{"webhooks", "x-webhooks"}
+
+// mergeWebhooks copies the webhooks of doc into merged, reporting webhooks
+// that doc redefines with different content like paths
+func mergeWebhooks(st *mergeState, merged, doc *openapi3.T, source string) {
+	for _, key := range webhookKeys {
+		webhooks, ok := doc.Extensions[key].(map[string]any)
+		if !ok {
+			continue
+		}
+		if merged.Extensions == nil {
+			merged.Extensions = make(map[string]any)
+		}
+		dst, ok := merged.Extensions[key].(map[string]any)
+		if !ok {
+			dst = make(map[string]any, len(webhooks))
+			merged.Extensions[key] = dst
+		}
+		mergeNamed(st, "webhook", dst, webhooks, source)
+	}
+}
+
+// mergeComponents copies the components of src into dst
+func mergeComponents(st *mergeState, dst, src *openapi3.Components, source string) error {
+	if err := mergeSchemas(st, dst.Schemas, src.Schemas, source); err != nil {
+		return err
+	}
+	mergeNamed(st, "response", dst.Responses, src.Responses, source)
+	mergeNamed(st, "parameter", dst.Parameters, src.Parameters, source)
+	mergeNamed(st, "requestBody", dst.RequestBodies, src.RequestBodies, source)
+	mergeNamed(st, "header", dst.Headers, src.Headers, source)
+	mergeNamed(st, "callback", dst.Callbacks, src.Callbacks, source)
+	mergeNamed(st, "link", dst.Links, src.Links, source)
+	if err := mergeExamples(st, dst.Examples, src.Examples, source); err != nil {
+		return err
+	}
+	return mergeSecuritySchemes(st, dst.SecuritySchemes, src.SecuritySchemes, source)
+}
+
+// ownComponents records source as the definer of the components in c
+func ownComponents(st *mergeState, c *openapi3.Components, source string) {
+	ownNamed(st, "schema", c.Schemas, source)
+	ownNamed(st, "response", c.Responses, source)
+	ownNamed(st, "parameter", c.Parameters, source)
+	ownNamed(st, "requestBody", c.RequestBodies, source)
+	ownNamed(st, "header", c.Headers, source)
+	ownNamed(st, "callback", c.Callbacks, source)
+	ownNamed(st, "link", c.Links, source)
+	ownNamed(st, "example", c.Examples, source)
+	ownNamed(st, "securityScheme", c.SecuritySchemes, source)
+}
@@ -1,14 +1,12 @@
-
 package merger
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/getkin/kin-openapi/openapi2"
@@ -27,7 +25,104 @@ type SwaggerVersion struct {
 type Config struct {
 	InputPaths []string
 	OutputPath string
-	Servers    []Server
+	// Outputs lists additional outputs written from the same merge. Every
+	// output, including OutputPath, is written as JSON when its extension
+	// is .json and as YAML otherwise, gzip compressed when followed by .gz
+	// (merged.yaml.gz). Paths may use the {version}, {date} and
+	// OutputVariables variables (dist/api-{version}.yaml).
+	Outputs []string
+	Servers []Server
+	Hooks   Hooks
+	// Pipeline holds the transformers applied to the documents. When nil,
+	// DefaultPipeline(Servers) is used.
+	Pipeline *Pipeline
+	// Logger receives diagnostic output. When nil, output is discarded.
+	Logger Logger
+	// Telemetry receives traces and metrics of merges. When nil, they are
+	// discarded.
+	Telemetry Telemetry
+	// Progress, when set, is called as each file moves through the merge
+	Progress ProgressFunc
+	// Concurrency is the number of inputs downloaded and parsed at once
+	// (default: DefaultConcurrency); 1 loads them one by one. Inputs are
+	// still transformed and merged in order, so the result does not
+	// depend on it, but Logger and Telemetry must be safe for concurrent
+	// use.
+	Concurrency int
+	// Profile records the time spent in each phase of Merge and its peak
+	// memory, returned by Merger.Profile
+	Profile bool
+	// Order controls the order in which inputs are merged (default:
+	// OrderAsGiven)
+	Order InputOrder
+	// Scan controls which files of directory and archive inputs are merged
+	Scan ScanOptions
+	// SchemaStrategy controls how schemas defined by more than one input
+	// are combined (default: SchemaOverwrite)
+	SchemaStrategy SchemaStrategy
+	// IgnoreSchemaDocs makes SchemaStrict ignore titles, descriptions and
+	// examples when comparing schemas
+	IgnoreSchemaDocs bool
+	// EnumUnion unions the values of schemas several inputs define as
+	// enums of the same type, see Merger.EnumUnions
+	EnumUnion bool
+	// Resolver decides path and schema collisions before they are merged,
+	// see WithConflictResolver
+	Resolver ConflictResolver
+	// ExternalDocs controls the top-level and tag-level externalDocs of the
+	// merged spec (default: ExternalDocsFirst)
+	ExternalDocs ExternalDocsPolicy
+	// Extensions controls how x-* extensions of the documents and their
+	// components are merged (default: ExtensionsOverride)
+	Extensions ExtensionPolicy
+	// SkipInvalid skips inputs that cannot be read or parsed instead of
+	// failing the merge; see Merger.Skipped
+	SkipInvalid bool
+	// CacheDir, when set, persists converted documents keyed by their
+	// content hash so later runs only reprocess inputs that changed, and
+	// remote responses so they are revalidated with ETag/Last-Modified
+	// instead of downloaded again
+	CacheDir string
+	// HTTP configures how remote inputs are fetched
+	HTTP HTTPConfig
+	// SwaggerHub configures swaggerhub://owner/api/version inputs
+	SwaggerHub SwaggerHubConfig
+	// OutputCacheControl is the Cache-Control metadata set when OutputPath
+	// is an object storage URI (s3://, gs:// or azblob://)
+	OutputCacheControl string
+	// VersionBump, when set, compares the merged spec with the previous
+	// content of OutputPath to suggest, or apply to info.version, a
+	// semantic version bump; see Merger.VersionSuggestion
+	VersionBump VersionBumpMode
+	// ChecksumSidecar writes the SHA-256 of every output to <output>.sha256,
+	// in the format of sha256sum
+	ChecksumSidecar bool
+	// EmbedChecksum sets info.x-checksum of the merged spec to its
+	// DocumentChecksum
+	EmbedChecksum bool
+	// YAML is the style of YAML outputs
+	YAML YAMLStyle
+	// JSON is the style of JSON outputs
+	JSON JSONStyle
+	// KeyOrder is the order of the keys of outputs, sorted by default
+	KeyOrder KeyOrder
+	// Anchors controls how the YAML anchors and aliases of inputs are
+	// resolved, expanded by default
+	Anchors AnchorPolicy
+	// PreserveComments carries the comments of YAML inputs over to YAML
+	// outputs: top-of-file comments and the comments of keys and values
+	// the merge left in place
+	PreserveComments bool
+	// BackupOutputs keeps the previous version of every local output as
+	// <output>.bak before replacing it
+	BackupOutputs bool
+	// OutputVariables are the values of custom {name} variables of output
+	// paths, such as {profile}, besides {version} (the merged info.version)
+	// and {date} (YYYY-MM-DD, UTC)
+	OutputVariables map[string]string
+	// Envelope checks, or wraps, the 2xx JSON response bodies of the
+	// merged spec against the standard response envelope
+	Envelope EnvelopeOptions
 }
 
 // Server represents an API server configuration
@@ -48,7 +143,57 @@ func DefaultServers() []Server {
 
 // Merger handles swagger file merging operations
 type Merger struct {
-	config Config
+	config  Config
+	skipped InputErrors
+	merged  *openapi3.T
+	// inputs of the last merge, with indexes, directories and archives
+	// expanded
+	inputs []string
+	// archives caches the files of archive inputs during a merge
+	archives map[string]map[string][]byte
+	// conflicts found by the last merge
+	conflicts []Conflict
+	// enumUnions of the last merge, when EnumUnion is set
+	enumUnions []EnumUnion
+	// schemaRenames of the last merge, when SchemaStrategy is SchemaRename
+	schemaRenames []Rename
+	// outputPaths of the last merge, with their variables expanded
+	outputPaths []string
+	// pathSources maps the paths of the last merge to the input that
+	// contributed them
+	pathSources map[string]string
+	// schemaSources maps the component schemas of the last merge to the
+	// input that defined them last
+	schemaSources map[string]string
+	cache         *documentCache
+	client        *http.Client
+	// clientMu guards the creation of client
+	clientMu sync.Mutex
+	// archiveMu guards archives
+	archiveMu sync.Mutex
+	// progressMu serializes the Progress callback
+	progressMu sync.Mutex
+	// limiter spaces out requests per host when HTTP.RateLimit is set
+	limiter *hostLimiter
+	// fetchDeadline bounds remote fetching when HTTP.TotalTimeout is set
+	fetchDeadline time.Time
+	// ctx is the context of the running MergeContext
+	ctx context.Context
+	// profiler times the running merge when Profile is set
+	profiler *profiler
+	// profile of the last merge, when Profile is set
+	profile *Profile
+	// span is the telemetry span of the running merge
+	span Span
+	// versionSuggestion of the last merge, when VersionBump is set
+	versionSuggestion *VersionSuggestion
+	// keyOrder of the inputs of the last merge, when KeyOrder is source
+	keyOrder keyOrder
+	// comments of the inputs of the last merge, when PreserveComments is
+	// set
+	comments *commentIndex
+	// envelopeDeviations of the last merge, when Envelope.Mode is set
+	envelopeDeviations []EnvelopeDeviation
 }
 
 // New creates a new Merger instance
@@ -56,11 +201,29 @@ func New(config Config) *Merger {
 	if config.Servers == nil {
 		config.Servers = DefaultServers()
 	}
-	return &Merger{config: config}
+	if config.Pipeline == nil {
+		config.Pipeline = DefaultPipeline(config.Servers)
+	}
+	if config.Logger == nil {
+		config.Logger = nopLogger{}
+	}
+	if config.Telemetry == nil {
+		config.Telemetry = nopTelemetry{}
+	}
+	return &Merger{
+		config:  config,
+		inputs:  config.InputPaths,
+		cache:   newDocumentCache(),
+		limiter: newHostLimiter(config.HTTP.RateLimit, config.HTTP.RateBurst),
+	}
 }
 
 // detectSwaggerVersion detects if a file is Swagger 2.0 or OpenAPI 3.0
 func (m *Merger) detectSwaggerVersion(data []byte) (*SwaggerVersion, error) {
+	if version, ok := sniffVersion(data); ok {
+		return version, nil
+	}
+
 	var obj map[string]interface{}
 
 	// Try YAML first
@@ -88,34 +251,34 @@ func (m *Merger) detectSwaggerVersion(data []byte) (*SwaggerVersion, error) {
 
 // convertToOpenAPI3 converts a swagger file to OpenAPI 3.0
 func (m *Merger) convertToOpenAPI3(data []byte, version *SwaggerVersion) (*openapi3.T, error) {
+	done := m.phase(PhaseParse)
 	if strings.HasPrefix(version.Version, "3.") {
 		// Already OpenAPI 3.0, just parse it
-		loader := openapi3.NewLoader()
-		return loader.LoadFromData(data)
+		defer done()
+		return loadOpenAPI3(data, version.IsYAML)
 	}
 
 	// Convert from Swagger 2.0 to OpenAPI 3.0
-	var jsonObj map[string]interface{}
-
 	if version.IsYAML {
 		// Convert YAML -> JSON
-		if err := yaml.Unmarshal(data, &jsonObj); err != nil {
-			return nil, fmt.Errorf("failed to parse YAML to map: %v", err)
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if err := transcodeYAML(data, buf); err != nil {
+			return nil, fmt.Errorf("failed to convert YAML to JSON: %v", err)
 		}
-		jsonBytes, err := json.Marshal(jsonObj)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal YAML to JSON: %v", err)
-		}
-		data = jsonBytes
+		data = buf.Bytes()
 	}
 
 	// Parse swagger2 (JSON)
 	var swagger2Doc openapi2.T
-	if err := swagger2Doc.UnmarshalJSON(data); err != nil {
+	err := swagger2Doc.UnmarshalJSON(data)
+	done()
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse Swagger2 JSON: %v", err)
 	}
 
 	// Convert to OpenAPI 3.0
+	defer m.phase(PhaseConvert)()
 	openapi3Doc, err := openapi2conv.ToV3(&swagger2Doc)
 	if err != nil {
 		return nil, fmt.Errorf("convert to openapi 3.0 failed: %v", err)
@@ -125,156 +288,332 @@ func (m *Merger) convertToOpenAPI3(data []byte, version *SwaggerVersion) (*opena
 }
 
 // mergeOpenAPI3 merges multiple OpenAPI 3.0 documents
-func (m *Merger) mergeOpenAPI3(docs []*openapi3.T) (*openapi3.T, error) {
-	if len(docs) == 0 {
-		return nil, fmt.Errorf("no documents to merge")
-	}
-
-	merged := docs[0]
-
-	for i := 1; i < len(docs); i++ {
-		doc := docs[i]
-
-		// Merge paths
-		if doc.Paths != nil {
-			if merged.Paths == nil {
-				merged.Paths = &openapi3.Paths{}
-			}
-			for path, item := range doc.Paths.Map() {
-				merged.Paths.Set(path, item)
-			}
-		}
-
-		// Initialize components if nil
-		if merged.Components.Schemas == nil {
-			merged.Components.Schemas = openapi3.Schemas{}
-		}
-		if merged.Components.Responses == nil {
-			merged.Components.Responses = openapi3.ResponseBodies{}
+func (m *Merger) mergeOpenAPI3(docs []*openapi3.T, sources []string) (*openapi3.T, error) {
+	m.conflicts = nil
+	m.enumUnions = nil
+	m.schemaRenames = nil
+	m.pathSources = make(map[string]string)
+	m.schemaSources = make(map[string]string)
+	for i, doc := range docs {
+		if doc == nil || i >= len(sources) {
+			continue
 		}
-		if merged.Components.Parameters == nil {
-			merged.Components.Parameters = openapi3.ParametersMap{}
-		}
-		if merged.Components.RequestBodies == nil {
-			merged.Components.RequestBodies = openapi3.RequestBodies{}
-		}
-		if merged.Components.Headers == nil {
-			merged.Components.Headers = openapi3.Headers{}
-		}
-
-		// Merge components
-		if doc.Components.Schemas != nil {
-			for k, v := range doc.Components.Schemas {
-				merged.Components.Schemas[k] = v
-			}
-		}
-		if doc.Components.Responses != nil {
-			for k, v := range doc.Components.Responses {
-				merged.Components.Responses[k] = v
-			}
-		}
-		if doc.Components.Parameters != nil {
-			for k, v := range doc.Components.Parameters {
-				merged.Components.Parameters[k] = v
-			}
-		}
-		if doc.Components.RequestBodies != nil {
-			for k, v := range doc.Components.RequestBodies {
-				merged.Components.RequestBodies[k] = v
+		if doc.Paths != nil {
+			for path := range doc.Paths.Map() {
+				m.pathSources[path] = sources[i]
 			}
 		}
-		if doc.Components.Headers != nil {
-			for k, v := range doc.Components.Headers {
-				merged.Components.Headers[k] = v
+		if doc.Components != nil {
+			for name := range doc.Components.Schemas {
+				// Renamed redefinitions leave the name to its first input
+				if _, ok := m.schemaSources[name]; !ok || m.config.SchemaStrategy != SchemaRename {
+					m.schemaSources[name] = sources[i]
+				}
 			}
 		}
-
-		// Merge tags
-		if doc.Tags != nil {
-			merged.Tags = append(merged.Tags, doc.Tags...)
-		}
 	}
+	return MergeDocuments(docs,
+		WithSourceNames(sources...),
+		WithHooks(m.config.Hooks),
+		WithSchemaStrategy(m.config.SchemaStrategy),
+		m.schemaDocsOption(),
+		m.enumUnionOption(),
+		m.resolverOption(),
+		WithRenameHandler(func(r Rename) {
+			m.schemaRenames = append(m.schemaRenames, r)
+			m.schemaSources[r.To] = r.Source
+			m.config.Logger.Info("schema renamed", "source", r.Source, "from", r.From, "to", r.To)
+		}),
+		WithExternalDocs(m.config.ExternalDocs),
+		WithExtensionPolicy(m.config.Extensions),
+		WithConflictHandler(func(c Conflict) {
+			m.conflicts = append(m.conflicts, c)
+			m.config.Logger.Warn("conflict detected", "kind", c.Kind, "name", c.Name, "sources", c.Sources)
+			m.config.Telemetry.AddCount("swagger_merger.conflicts", 1, Attribute{Key: "kind", Value: c.Kind})
+		}),
+	)
+}
 
-	return merged, nil
+// schemaDocsOption returns WithIgnoreSchemaDocs if configured
+func (m *Merger) schemaDocsOption() Option {
+	if m.config.IgnoreSchemaDocs {
+		return WithIgnoreSchemaDocs()
+	}
+	return nil
 }
 
-// readDataFromPath reads data from either a local file or URL
-func (m *Merger) readDataFromPath(path string) ([]byte, error) {
-	// Check if it's a URL
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		// Create HTTP client with timeout
-		client := &http.Client{
-			Timeout: 30 * time.Second,
-		}
+// enumUnionOption returns WithEnumUnion, recording the unions, if
+// configured
+func (m *Merger) enumUnionOption() Option {
+	if !m.config.EnumUnion {
+		return nil
+	}
+	return WithEnumUnion(func(u EnumUnion) {
+		m.enumUnions = append(m.enumUnions, u)
+		m.config.Logger.Info("enum values unioned", "name", u.Name, "sources", u.Sources, "added", u.Added)
+	})
+}
 
-		// Make HTTP request
-		resp, err := client.Get(path)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch URL %s: %v", path, err)
+// resolverOption returns WithConflictResolver, keeping the sources of
+// the resolved paths and schemas up to date, if configured
+func (m *Merger) resolverOption() Option {
+	if m.config.Resolver == nil {
+		return nil
+	}
+	return WithConflictResolver(func(c Collision) (Resolution, error) {
+		r, err := m.config.Resolver(c)
+		if err != nil || !r.decided() {
+			return r, err
 		}
-		defer resp.Body.Close()
-
-		// Check status code
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("HTTP request failed with status %d for URL %s", resp.StatusCode, path)
+		sources := m.schemaSources
+		if c.Kind == "path" {
+			sources = m.pathSources
 		}
-
-		// Read response body
-		data, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body from %s: %v", path, err)
+		switch {
+		case r.Rename != "":
+			sources[r.Rename] = c.Sources[1]
+			sources[c.Name] = c.Sources[0]
+		case r.keeps(c.Sources[0]):
+			sources[c.Name] = c.Sources[0]
 		}
+		m.config.Logger.Info("collision resolved", "kind", c.Kind, "name", c.Name, "keep", r.Keep, "rename", r.Rename)
+		return r, nil
+	})
+}
 
-		return data, nil
+// processSwaggerFile processes a single loaded swagger file, in input
+// order; data is its content
+func (m *Merger) processSwaggerFile(filePath string, doc *openapi3.T, data []byte) error {
+	done := m.phase(PhaseParse)
+	m.recordSource(data)
+	done()
+
+	// Apply input transformers (version stamping, server override, ...)
+	done = m.phase(PhaseConvert)
+	err := m.config.Pipeline.RunContext(m.context(), StageInput, doc, filePath)
+	done()
+	if err != nil {
+		return fmt.Errorf("failed to transform: %v", err)
 	}
 
-	// Read local file
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %v", path, err)
+	schemas := 0
+	if doc.Components != nil {
+		schemas = len(doc.Components.Schemas)
 	}
+	m.config.Logger.Info("processed input", "source", filePath, "paths", doc.Paths.Len(), "schemas", schemas)
 
-	return data, nil
+	return nil
 }
 
-// processSwaggerFile processes a single swagger file
-func (m *Merger) processSwaggerFile(filePath string) (*openapi3.T, error) {
-	// Read data from file or URL
+// loadDocument reads and converts a single input, reusing the converted
+// document when the input's content was converted before. It returns the
+// document and the content it was converted from, and is safe to call
+// for several inputs at once.
+func (m *Merger) loadDocument(index int, filePath string) (*openapi3.T, []byte, error) {
+	// Read data from file or URL, every time so changed inputs are seen
+	m.config.Logger.Debug("reading input", "source", filePath)
+	m.progress(ProgressFetching, filePath, index)
+	_, end := m.trace("fetch", Attribute{Key: "source", Value: filePath})
+	done := m.phase(PhaseFetch)
 	data, err := m.readDataFromPath(filePath)
+	done()
+	end(err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read: %v", err)
+	}
+	data, err = m.resolveAnchors(filePath, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash := contentHash(data)
+	if cached, ok := m.cache.document(hash); ok {
+		m.config.Logger.Debug("using cached document", "source", filePath)
+		m.cache.setDocument(filePath, hash, cached)
+		defer m.phase(PhaseParse)()
+		doc, err := openapi3.NewLoader().LoadFromData(cached)
+		return doc, data, err
+	}
+	if cached, doc, ok := m.loadFromDisk(filePath, hash); ok {
+		m.config.Logger.Debug("using document from cache directory", "source", filePath)
+		m.cache.setDocument(filePath, hash, cached)
+		return doc, data, nil
+	}
+
+	doc, err := m.convertDocument(index, filePath, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read %s: %v", filePath, err)
+		return nil, nil, err
 	}
 
+	// Keep a pristine encoding, transformers modify the returned document
+	defer m.phase(PhaseConvert)()
+	cached, err := encodeDocument(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	m.cache.setDocument(filePath, hash, cached)
+	m.storeOnDisk(filePath, hash, cached)
+
+	return doc, data, nil
+}
+
+// convertDocument parses an input and converts it to OpenAPI 3.0
+func (m *Merger) convertDocument(index int, filePath string, data []byte) (doc *openapi3.T, err error) {
+	_, end := m.trace("convert", Attribute{Key: "source", Value: filePath})
+	defer func() { end(err) }()
+
 	// Detect version
+	m.progress(ProgressParsing, filePath, index)
+	done := m.phase(PhaseParse)
 	version, err := m.detectSwaggerVersion(data)
+	done()
 	if err != nil {
-		return nil, fmt.Errorf("failed to detect version for %s: %v", filePath, err)
+		return nil, fmt.Errorf("failed to detect version: %v", err)
 	}
+	m.config.Logger.Debug("detected version", "source", filePath, "version", version.Version)
 
 	// Convert to OpenAPI 3.0
-	doc, err := m.convertToOpenAPI3(data, version)
+	m.progress(ProgressConverting, filePath, index)
+	doc, err = m.convertToOpenAPI3(data, version)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert %s: %v", filePath, err)
+		return nil, fmt.Errorf("failed to convert: %v", err)
+	}
+	return doc, nil
+}
+
+// loadAndMerge processes every input and returns the merged document
+func (m *Merger) loadAndMerge() (merged *openapi3.T, err error) {
+	span, end := m.trace("merge")
+	m.span = span
+	defer func() {
+		m.span = nil
+		end(err)
+	}()
+
+	if err := m.config.KeyOrder.validate(); err != nil {
+		return nil, err
+	}
+	if err := m.config.Anchors.validate(); err != nil {
+		return nil, err
+	}
+	if err := m.config.Envelope.Mode.validate(); err != nil {
+		return nil, err
+	}
+	if m.config.Concurrency < 0 {
+		return nil, fmt.Errorf("invalid concurrency %d: expected a positive number of inputs", m.config.Concurrency)
+	}
+	if m.config.HTTP.RateLimit < 0 || m.config.HTTP.RateBurst < 0 {
+		return nil, fmt.Errorf("invalid rate limit %v with burst %d: expected positive values", m.config.HTTP.RateLimit, m.config.HTTP.RateBurst)
+	}
+	m.config.Pipeline.Reset()
+	m.keyOrder = nil
+	if m.config.KeyOrder == KeyOrderSource {
+		m.keyOrder = make(keyOrder)
+	}
+	m.comments = nil
+	if m.config.PreserveComments {
+		m.comments = &commentIndex{keys: make(map[string]nodeComments)}
 	}
 
-	// Set common properties
-	doc.OpenAPI = "3.0.1"
+	m.fetchDeadline = time.Time{}
+	if m.config.HTTP.TotalTimeout > 0 {
+		m.fetchDeadline = time.Now().Add(m.config.HTTP.TotalTimeout)
+	}
 
-	// Always override servers with configured servers
-	servers := make(openapi3.Servers, len(m.config.Servers))
-	for i, server := range m.config.Servers {
-		servers[i] = &openapi3.Server{
-			URL:         server.URL,
-			Description: server.Description,
+	// Process each file, collecting the errors of all failing inputs
+	var docs []*openapi3.T
+	var sources []string
+	var errs InputErrors
+	m.inputs = m.config.InputPaths
+	inputs, err := m.expandInputs(m.config.InputPaths)
+	if err != nil {
+		return nil, err
+	}
+	m.inputs = inputs
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no input files found")
+	}
+
+	// Load inputs in parallel, then process them in order so transformers
+	// and recorded comments see the same order whatever the concurrency
+	loaded := m.loadInputs(m.inputs)
+	if err := m.interrupted("loading inputs"); err != nil {
+		// Name the inputs still loading rather than skipping them
+		for i, input := range loaded {
+			if input.err != nil {
+				errs = append(errs, &InputError{Source: m.inputs[i], Err: input.err})
+			}
+		}
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("%v: %v", err, errs)
+		}
+		return nil, err
+	}
+	for i, input := range loaded {
+		filePath := m.inputs[i]
+		err := input.err
+		if err == nil {
+			err = m.processSwaggerFile(filePath, input.doc, input.data)
+		}
+		if err != nil {
+			errs = append(errs, &InputError{Source: filePath, Err: err})
+			continue
+		}
+		docs = append(docs, input.doc)
+		sources = append(sources, filePath)
+	}
+	m.skipped = nil
+	if len(errs) > 0 {
+		if !m.config.SkipInvalid {
+			return nil, errs
+		}
+		for _, err := range errs {
+			m.config.Logger.Warn("skipping invalid input", "source", err.Source, "error", err.Err)
+		}
+		m.skipped = errs
+		if len(docs) == 0 {
+			return nil, fmt.Errorf("all inputs are invalid: %v", errs)
 		}
 	}
-	doc.Servers = servers
 
-	return doc, nil
+	// Merge all documents
+	if err := m.interrupted("parsing inputs"); err != nil {
+		return nil, err
+	}
+	m.config.Logger.Debug("merging documents", "count", len(docs))
+	m.progress(ProgressMerging, "", 0)
+	defer m.phase(PhaseMerge)()
+	merged, err = m.mergeOpenAPI3(docs, sources)
+	if err != nil {
+		return nil, fmt.Errorf("error merging documents: %v", err)
+	}
+
+	// Apply transformers to the merged result
+	if err := m.config.Pipeline.RunContext(m.context(), StageMerged, merged, ""); err != nil {
+		return nil, fmt.Errorf("error transforming merged document: %v", err)
+	}
+	m.applyEnvelope(merged)
+
+	return merged, nil
 }
 
 // Merge merges all swagger files and writes the result to output file
 func (m *Merger) Merge() error {
+	return m.MergeContext(context.Background())
+}
+
+// MergeContext merges like Merge, stopping with an error once ctx is done.
+// Remote fetches and external tools are cancelled with ctx, so a deadline
+// set with context.WithTimeoutCause bounds the whole merge and its cause
+// is reported in the error.
+func (m *Merger) MergeContext(ctx context.Context) error {
+	return m.withContext(ctx, m.merge)
+}
+
+// merge runs a merge under the context set by MergeContext
+func (m *Merger) merge() error {
+	defer m.startProfile()()
+
 	if len(m.config.InputPaths) == 0 {
 		return fmt.Errorf("no input paths provided")
 	}
@@ -283,56 +622,65 @@ func (m *Merger) Merge() error {
 		return fmt.Errorf("output path is required")
 	}
 
-	// Process each file
-	var docs []*openapi3.T
-	for _, filePath := range m.config.InputPaths {
-		doc, err := m.processSwaggerFile(filePath)
-		if err != nil {
-			return fmt.Errorf("error processing %s: %v", filePath, err)
-		}
-		docs = append(docs, doc)
+	if _, err := m.expandOutputs(nil); err != nil {
+		return err
 	}
 
-	// Merge all documents
-	merged, err := m.mergeOpenAPI3(docs)
+	start := time.Now()
+	m.merged = nil
+	m.outputPaths = nil
+	merged, err := m.loadAndMerge()
 	if err != nil {
-		return fmt.Errorf("error merging documents: %v", err)
+		return err
 	}
-
-	// Write output
-	out, err := yaml.Marshal(merged)
+	if err := m.suggestVersion(merged); err != nil {
+		return err
+	}
+	outputs, err := m.expandOutputs(merged)
 	if err != nil {
-		return fmt.Errorf("error marshaling to YAML: %v", err)
+		return err
 	}
 
-	if err := os.WriteFile(m.config.OutputPath, out, 0644); err != nil {
-		return fmt.Errorf("error writing file: %v", err)
+	// Write outputs
+	if err := m.writeOutputs(merged, outputs); err != nil {
+		return err
 	}
 
+	m.merged = merged
+	m.outputPaths = outputs
+	m.logComplete(merged, start)
+
 	return nil
 }
 
-// MergeFromDirectory merges all swagger files found in a directory
-func (m *Merger) MergeFromDirectory(inputDir, pattern string) error {
-	var swaggerFiles []string
+// logComplete emits the merge complete event for a merge started at start
+func (m *Merger) logComplete(merged *openapi3.T, start time.Time) {
+	stats := m.stats(merged)
+	m.config.Logger.Info("merge complete",
+		"files", stats["total_files"],
+		"paths", stats["total_paths"],
+		"schemas", stats["total_schemas"],
+		"tags", stats["total_tags"],
+		"conflicts", stats["total_conflicts"],
+		"skipped", len(m.skipped),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+}
 
-	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// OutputPaths returns the outputs written by the last merge, with the
+// variables of their paths expanded
+func (m *Merger) OutputPaths() []string {
+	return m.outputPaths
+}
 
-		if !info.IsDir() {
-			matched, err := filepath.Match(pattern, filepath.Base(path))
-			if err != nil {
-				return err
-			}
-			if matched {
-				swaggerFiles = append(swaggerFiles, path)
-			}
-		}
-		return nil
-	})
+// Skipped returns the inputs skipped by the last merge when SkipInvalid is set
+func (m *Merger) Skipped() InputErrors {
+	return m.skipped
+}
 
+// MergeFromDirectory merges all swagger files found in a directory
+func (m *Merger) MergeFromDirectory(inputDir, pattern string) error {
+	swaggerFiles, err := scanDirectory(inputDir, ScanOptions{Patterns: []string{pattern}})
 	if err != nil {
 		return fmt.Errorf("error finding files: %v", err)
 	}
@@ -347,34 +695,113 @@ func (m *Merger) MergeFromDirectory(inputDir, pattern string) error {
 	return m.Merge()
 }
 
-// GetStats returns statistics about the merged document
+// GetStats returns statistics about the merged document. It reuses the
+// document produced by a previous Merge and only merges the inputs itself
+// when nothing has been merged yet.
 func (m *Merger) GetStats() (map[string]int, error) {
 	if len(m.config.InputPaths) == 0 {
 		return nil, fmt.Errorf("no input paths provided")
 	}
 
-	// Process each file
-	var docs []*openapi3.T
-	for _, filePath := range m.config.InputPaths {
-		doc, err := m.processSwaggerFile(filePath)
+	if m.merged == nil {
+		merged, err := m.loadAndMerge()
 		if err != nil {
-			return nil, fmt.Errorf("error processing %s: %v", filePath, err)
+			return nil, err
 		}
-		docs = append(docs, doc)
+		m.merged = merged
 	}
 
-	// Merge all documents
-	merged, err := m.mergeOpenAPI3(docs)
+	return m.stats(m.merged), nil
+}
+
+// MergeWithStats merges all swagger files, writes the output and returns
+// statistics about the merged document in a single pass
+func (m *Merger) MergeWithStats() (map[string]int, error) {
+	return m.MergeWithStatsContext(context.Background())
+}
+
+// MergeWithStatsContext is MergeWithStats bounded by ctx, like MergeContext
+func (m *Merger) MergeWithStatsContext(ctx context.Context) (map[string]int, error) {
+	if err := m.MergeContext(ctx); err != nil {
+		return nil, err
+	}
+	return m.stats(m.merged), nil
+}
+
+// MergeToDocument merges all swagger files and returns the merged document
+// without writing any output. Document and Stats report on it afterwards.
+func (m *Merger) MergeToDocument() (*openapi3.T, error) {
+	return m.MergeToDocumentContext(context.Background())
+}
+
+// MergeToDocumentContext is MergeToDocument bounded by ctx, like
+// MergeContext
+func (m *Merger) MergeToDocumentContext(ctx context.Context) (*openapi3.T, error) {
+	if len(m.config.InputPaths) == 0 {
+		return nil, fmt.Errorf("no input paths provided")
+	}
+
+	var merged *openapi3.T
+	err := m.withContext(ctx, func() error {
+		defer m.startProfile()()
+		start := time.Now()
+		m.merged = nil
+		m.outputPaths = nil
+		var err error
+		merged, err = m.loadAndMerge()
+		if err != nil {
+			return err
+		}
+		m.logComplete(merged, start)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error merging documents: %v", err)
+		return nil, err
+	}
+	m.merged = merged
+	return merged, nil
+}
+
+// Document returns the document produced by the last successful merge, or
+// nil if nothing has been merged yet
+func (m *Merger) Document() *openapi3.T {
+	return m.merged
+}
+
+// Stats returns statistics about the document of the last successful
+// merge, or nil if nothing has been merged yet. Unlike GetStats, it never
+// merges the inputs itself.
+func (m *Merger) Stats() map[string]int {
+	if m.merged == nil {
+		return nil
 	}
+	return m.stats(m.merged)
+}
 
-	stats := map[string]int{
-		"total_files":   len(m.config.InputPaths),
-		"total_paths":   len(merged.Paths.Map()),
-		"total_schemas": len(merged.Components.Schemas),
-		"total_tags":    len(merged.Tags),
+// stats computes statistics about a merged document
+func (m *Merger) stats(merged *openapi3.T) map[string]int {
+	return map[string]int{
+		"total_files":     len(m.inputs) - len(m.skipped),
+		"total_paths":     merged.Paths.Len(),
+		"total_schemas":   len(merged.Components.Schemas),
+		"total_tags":      len(merged.Tags),
+		"total_conflicts": len(m.conflicts),
 	}
+}
+
+// Conflicts returns the conflicts found by the last merge
+func (m *Merger) Conflicts() []Conflict {
+	return m.conflicts
+}
+
+// SchemaRenames returns the schemas the last merge renamed with
+// SchemaRename
+func (m *Merger) SchemaRenames() []Rename {
+	return m.schemaRenames
+}
 
-	return stats, nil
+// EnumUnions returns the enum schemas whose values the last merge unioned
+// with EnumUnion
+func (m *Merger) EnumUnions() []EnumUnion {
+	return m.enumUnions
 }
@@ -2,6 +2,8 @@ package merger
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -167,11 +169,21 @@ func createTempSwaggerFile(content string) (string, error) {
 	return tmpfile.Name(), nil
 }
 
+// loadSpec parses an OpenAPI document, failing the test when it cannot
+func loadSpec(t *testing.T, spec string) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
 func TestMergeOpenAPI3(t *testing.T) {
 	merger := &Merger{}
 
 	// Test empty docs
-	_, err := merger.mergeOpenAPI3([]*openapi3.T{})
+	_, err := merger.mergeOpenAPI3([]*openapi3.T{}, nil)
 	if err == nil {
 		t.Error("Expected error for empty documents")
 	}
@@ -185,7 +197,7 @@ func TestMergeOpenAPI3(t *testing.T) {
 		},
 	}
 
-	merged, err := merger.mergeOpenAPI3([]*openapi3.T{doc1})
+	merged, err := merger.mergeOpenAPI3([]*openapi3.T{doc1}, nil)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -194,3 +206,265 @@ func TestMergeOpenAPI3(t *testing.T) {
 		t.Errorf("Expected title 'API 1', got '%s'", merged.Info.Title)
 	}
 }
+
+func TestMergeDocuments(t *testing.T) {
+	doc1 := &openapi3.T{
+		OpenAPI: "3.0.1",
+		Info:    &openapi3.Info{Title: "API 1", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(openapi3.WithPath("/users", &openapi3.PathItem{})),
+	}
+	doc2 := &openapi3.T{
+		OpenAPI: "3.0.1",
+		Info:    &openapi3.Info{Title: "API 2", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(openapi3.WithPath("/orders", &openapi3.PathItem{})),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{"Order": openapi3.NewSchemaRef("", openapi3.NewObjectSchema())},
+		},
+	}
+
+	merged, err := MergeDocuments([]*openapi3.T{doc1, doc2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if merged.Paths.Len() != 2 {
+		t.Errorf("Expected 2 paths, got %d", merged.Paths.Len())
+	}
+
+	if _, ok := merged.Components.Schemas["Order"]; !ok {
+		t.Error("Expected schema 'Order' to be merged")
+	}
+
+	// Nil documents are reported by source name
+	_, err = MergeDocuments([]*openapi3.T{doc1, nil}, WithSourceNames("a.yaml", "b.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "b.yaml") {
+		t.Errorf("Expected error mentioning b.yaml, got %v", err)
+	}
+}
+
+func TestMergeWithStats(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+tags:
+  - name: users
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    User:
+      type: object`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	fetches := 0
+	m := New(Config{
+		InputPaths: []string{file},
+		OutputPath: filepath.Join(t.TempDir(), "merged.yaml"),
+		Progress: func(event ProgressEvent) {
+			if event.Stage == ProgressFetching {
+				fetches++
+			}
+		},
+	})
+
+	stats, err := m.MergeWithStats()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if stats["total_files"] != 1 || stats["total_paths"] != 1 || stats["total_schemas"] != 1 || stats["total_tags"] != 1 {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+
+	// GetStats reuses the merged document
+	if _, err := m.GetStats(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if fetches != 1 {
+		t.Errorf("Expected inputs to be fetched once, got %d", fetches)
+	}
+
+	if m.Document() == nil {
+		t.Error("Expected merged document to be retained")
+	}
+}
+
+func TestMergeDocumentsConflicts(t *testing.T) {
+	doc := func(userType string) *openapi3.T {
+		return &openapi3.T{
+			OpenAPI: "3.0.1",
+			Info:    &openapi3.Info{Title: "API", Version: "1.0.0"},
+			Paths:   openapi3.NewPaths(),
+			Components: &openapi3.Components{Schemas: openapi3.Schemas{
+				"User":  openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{userType}}),
+				"Error": openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+			}},
+		}
+	}
+
+	var conflicts []Conflict
+	_, err := MergeDocuments([]*openapi3.T{doc("object"), doc("object"), doc("string")},
+		WithSourceNames("a.yaml", "b.yaml", "c.yaml"),
+		WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %v", conflicts)
+	}
+	if c := conflicts[0]; c.Kind != "schema" || c.Name != "User" || c.Sources[0] != "b.yaml" || c.Sources[1] != "c.yaml" {
+		t.Errorf("Unexpected conflict: %v", c)
+	}
+}
+
+func TestMergeDocumentsWebhooks(t *testing.T) {
+	users := loadSpec(t, `
+openapi: 3.1.0
+info: {title: Users, version: "1.0"}
+paths: {}
+webhooks:
+  userCreated:
+    post:
+      responses: {"200": {description: ok}}
+`)
+	orders := loadSpec(t, `
+openapi: 3.1.0
+info: {title: Orders, version: "1.0"}
+paths: {}
+webhooks:
+  orderPlaced:
+    post:
+      responses: {"200": {description: ok}}
+  userCreated:
+    post:
+      responses: {"204": {description: done}}
+x-webhooks:
+  orderShipped:
+    post:
+      responses: {"200": {description: ok}}
+`)
+
+	var conflicts []Conflict
+	merged, err := MergeDocuments([]*openapi3.T{users, orders},
+		WithSourceNames("users.yaml", "orders.yaml"),
+		WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	webhooks, ok := merged.Extensions["webhooks"].(map[string]any)
+	if !ok || len(webhooks) != 2 || webhooks["orderPlaced"] == nil {
+		t.Errorf("Expected both webhooks, got %v", merged.Extensions["webhooks"])
+	}
+	if legacy, ok := merged.Extensions["x-webhooks"].(map[string]any); !ok || legacy["orderShipped"] == nil {
+		t.Errorf("Expected x-webhooks to be merged, got %v", merged.Extensions["x-webhooks"])
+	}
+	if len(conflicts) != 1 || conflicts[0].Kind != "webhook" || conflicts[0].Name != "userCreated" || conflicts[0].Sources[0] != "users.yaml" {
+		t.Errorf("Expected a userCreated webhook conflict, got %v", conflicts)
+	}
+}
+
+func TestMergeDocumentsCallbacks(t *testing.T) {
+	users := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Users, version: "1.0"}
+paths:
+  /subscriptions:
+    post:
+      callbacks:
+        onUserEvent:
+          '{$request.body#/url}':
+            post:
+              responses: {"200": {description: ok}}
+      responses: {"201": {description: created}}
+`)
+	orders := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Orders, version: "1.0"}
+paths:
+  /subscriptions:
+    post:
+      callbacks:
+        onOrderEvent:
+          $ref: '#/components/callbacks/OrderEvent'
+      responses: {"201": {description: created}}
+components:
+  callbacks:
+    OrderEvent:
+      '{$request.body#/url}':
+        post:
+          responses: {"200": {description: ok}}
+`)
+
+	merged, err := MergeDocuments([]*openapi3.T{users, orders})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if merged.Components.Callbacks["OrderEvent"] == nil {
+		t.Error("Expected components.callbacks to be merged")
+	}
+	callbacks := merged.Paths.Find("/subscriptions").Post.Callbacks
+	if callbacks["onUserEvent"] == nil || callbacks["onOrderEvent"] == nil {
+		t.Errorf("Expected the callbacks of both operations, got %v", callbacks)
+	}
+	if err := merged.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+}
+
+func TestMergeDocumentsLinks(t *testing.T) {
+	users := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Users, version: "1.0"}
+paths:
+  /users:
+    post:
+      responses:
+        "201":
+          description: created
+          links:
+            GetUser:
+              operationId: getUser
+              parameters: {id: '$response.body#/id'}
+`)
+	orders := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Orders, version: "1.0"}
+paths:
+  /users:
+    post:
+      responses:
+        "201":
+          description: created
+          links:
+            GetOrders:
+              $ref: '#/components/links/GetOrders'
+components:
+  links:
+    GetOrders:
+      operationId: listOrders
+      parameters: {userId: '$response.body#/id'}
+`)
+
+	merged, err := MergeDocuments([]*openapi3.T{users, orders})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if merged.Components.Links["GetOrders"] == nil {
+		t.Error("Expected components.links to be merged")
+	}
+	links := merged.Paths.Find("/users").Post.Responses.Status(201).Value.Links
+	if links["GetUser"] == nil || links["GetOrders"] == nil {
+		t.Errorf("Expected the links of both responses, got %v", links)
+	}
+}
@@ -0,0 +1,192 @@
+package merger
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the duration
+// histograms exported by Metrics
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics is a Telemetry exporting merges in the Prometheus text format.
+// Mount it as the /metrics handler of a service running the merger:
+//   - swagger_merger_merges_total{result="success|failure"}
+//   - swagger_merger_last_success_timestamp_seconds
+//   - swagger_merger_fetch_errors_total{source}
+//   - swagger_merger_conflicts_total{kind}
+//   - swagger_merger_{merge,fetch,convert}_duration_seconds histograms
+type Metrics struct {
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+// metricFamily is a metric with its series, by formatted labels
+type metricFamily struct {
+	kind   string
+	series map[string]*metricSeries
+}
+
+// metricSeries holds a counter or gauge value, or a histogram
+type metricSeries struct {
+	value   float64
+	buckets []uint64
+	count   uint64
+}
+
+// NewMetrics creates an empty Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{families: make(map[string]*metricFamily)}
+}
+
+// metricsSpan updates the merge and fetch error metrics when it ends
+type metricsSpan struct {
+	metrics *Metrics
+	name    string
+	attrs   []Attribute
+}
+
+func (m *Metrics) StartSpan(parent Span, name string, attrs ...Attribute) Span {
+	return &metricsSpan{metrics: m, name: name, attrs: attrs}
+}
+
+func (s *metricsSpan) End(err error) {
+	switch s.name {
+	case "swagger_merger.merge":
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		s.metrics.add("swagger_merger_merges_total", 1, Attribute{Key: "result", Value: result})
+		if err == nil {
+			s.metrics.set("swagger_merger_last_success_timestamp_seconds", float64(time.Now().UnixMilli())/1000)
+		}
+	case "swagger_merger.fetch":
+		if err != nil {
+			s.metrics.add("swagger_merger_fetch_errors_total", 1, s.attrs...)
+		}
+	}
+}
+
+func (m *Metrics) RecordDuration(name string, d time.Duration, attrs ...Attribute) {
+	name = metricName(strings.TrimSuffix(name, ".duration")) + "_duration_seconds"
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	series := m.series(name, "histogram", attrs)
+	if series.buckets == nil {
+		series.buckets = make([]uint64, len(durationBuckets))
+	}
+	for i, bound := range durationBuckets {
+		if d.Seconds() <= bound {
+			series.buckets[i]++
+		}
+	}
+	series.value += d.Seconds()
+	series.count++
+}
+
+func (m *Metrics) AddCount(name string, n int64, attrs ...Attribute) {
+	m.add(metricName(name)+"_total", float64(n), attrs...)
+}
+
+// add adds n to a counter
+func (m *Metrics) add(name string, n float64, attrs ...Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series(name, "counter", attrs).value += n
+}
+
+// set sets a gauge
+func (m *Metrics) set(name string, value float64, attrs ...Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series(name, "gauge", attrs).value = value
+}
+
+// series returns the series of a metric for attrs, creating it. The
+// caller holds mu.
+func (m *Metrics) series(name, kind string, attrs []Attribute) *metricSeries {
+	family, ok := m.families[name]
+	if !ok {
+		family = &metricFamily{kind: kind, series: make(map[string]*metricSeries)}
+		m.families[name] = family
+	}
+	labels := formatLabels(attrs)
+	series, ok := family.series[labels]
+	if !ok {
+		series = &metricSeries{}
+		family.series[labels] = series
+	}
+	return series
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+	for _, name := range sortedKeys(m.families) {
+		family := m.families[name]
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, family.kind)
+		for _, labels := range sortedKeys(family.series) {
+			series := family.series[labels]
+			if family.kind != "histogram" {
+				fmt.Fprintf(&b, "%s%s %v\n", name, wrapLabels(labels), series.value)
+				continue
+			}
+			for i, bound := range durationBuckets {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, wrapLabels(joinLabels(labels, fmt.Sprintf("le=%q", fmt.Sprint(bound)))), series.buckets[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, wrapLabels(joinLabels(labels, `le="+Inf"`)), series.count)
+			fmt.Fprintf(&b, "%s_sum%s %v\n", name, wrapLabels(labels), series.value)
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, wrapLabels(labels), series.count)
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// metricName turns a telemetry name such as swagger_merger.fetch into a
+// Prometheus metric name
+func metricName(name string) string {
+	return strings.ReplaceAll(name, ".", "_")
+}
+
+// formatLabels formats attributes as sorted Prometheus labels, without
+// braces
+func formatLabels(attrs []Attribute) string {
+	labels := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(fmt.Sprint(attr.Value))
+		labels = append(labels, fmt.Sprintf(`%s="%s"`, attr.Key, value))
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ",")
+}
+
+// joinLabels appends a label to formatted labels
+func joinLabels(labels, label string) string {
+	if labels == "" {
+		return label
+	}
+	return labels + "," + label
+}
+
+// wrapLabels wraps formatted labels in braces, if any
+func wrapLabels(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
@@ -0,0 +1,48 @@
+package merger
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	metrics := NewMetrics()
+	if _, err := New(Config{InputPaths: []string{file}, Telemetry: metrics}).loadAndMerge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	missing := file + ".missing"
+	if _, err := New(Config{InputPaths: []string{missing}, Telemetry: metrics}).loadAndMerge(); err == nil {
+		t.Fatal("Expected an error for a missing input")
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		"# TYPE swagger_merger_merges_total counter\n",
+		`swagger_merger_merges_total{result="success"} 1` + "\n",
+		`swagger_merger_merges_total{result="failure"} 1` + "\n",
+		"# TYPE swagger_merger_last_success_timestamp_seconds gauge\n",
+		`swagger_merger_fetch_errors_total{source="` + missing + `"} 1` + "\n",
+		"# TYPE swagger_merger_merge_duration_seconds histogram\n",
+		`swagger_merger_merge_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"swagger_merger_merge_duration_seconds_count 2\n",
+		`swagger_merger_fetch_duration_seconds_count{source="` + file + `"} 1` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
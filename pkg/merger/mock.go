@@ -0,0 +1,206 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// MockHandler returns a handler serving stub responses for the operations
+// of doc, so clients can be developed before the backends exist. Requests
+// are routed by path template and method to the operation's first 2xx
+// response, or the one whose status is asked for with a "Prefer: code=404"
+// header. The body is the media type's example, its first named example,
+// or one synthesized from the schema, preferring JSON media types.
+func MockHandler(doc *openapi3.T) http.Handler {
+	var routes []mockRoute
+	if doc.Paths != nil {
+		paths := doc.Paths.Map()
+		for _, path := range sortedKeys(paths) {
+			routes = append(routes, mockRoute{segments: strings.Split(strings.Trim(path, "/"), "/"), item: paths[path]})
+		}
+	}
+	// Prefer literal segments over parameters, e.g. /users/me over
+	// /users/{id}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].params() < routes[j].params()
+	})
+	return &mockHandler{routes: routes}
+}
+
+// mockHandler serves the stub responses of MockHandler
+type mockHandler struct {
+	routes []mockRoute
+}
+
+// mockRoute is a path template split into segments
+type mockRoute struct {
+	segments []string
+	item     *openapi3.PathItem
+}
+
+// params returns the number of parameter segments of the route
+func (r mockRoute) params() int {
+	n := 0
+	for _, segment := range r.segments {
+		if strings.Contains(segment, "{") {
+			n++
+		}
+	}
+	return n
+}
+
+// matches reports whether the route matches a request path
+func (r mockRoute) matches(segments []string) bool {
+	if len(segments) != len(r.segments) {
+		return false
+	}
+	for i, segment := range r.segments {
+		if !strings.Contains(segment, "{") && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *mockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var allowed []string
+	for _, route := range h.routes {
+		if !route.matches(segments) {
+			continue
+		}
+		if op := route.item.GetOperation(r.Method); op != nil {
+			serveMockResponse(w, op, r.Header.Get("Prefer"))
+			return
+		}
+		for method := range route.item.Operations() {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(slices.Compact(allowed), ", "))
+		writeMockError(w, http.StatusMethodNotAllowed, fmt.Sprintf("no %s operation for %s", r.Method, r.URL.Path))
+		return
+	}
+	writeMockError(w, http.StatusNotFound, fmt.Sprintf("no path of the spec matches %s", r.URL.Path))
+}
+
+// serveMockResponse writes the stub response of an operation
+func serveMockResponse(w http.ResponseWriter, op *openapi3.Operation, prefer string) {
+	status, resp := mockResponse(op, preferredCode(prefer))
+	if resp == nil {
+		writeMockError(w, http.StatusNotImplemented, "the operation has no response to mock")
+		return
+	}
+	code := 200
+	if n, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(status), "XX", "00")); err == nil {
+		code = n
+	}
+
+	mediaType, media := mockMedia(resp.Content)
+	if media == nil {
+		w.WriteHeader(code)
+		return
+	}
+	body := mockBody(media)
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(code)
+	if text, ok := body.(string); ok && !isJSONMediaType(mediaType) {
+		w.Write([]byte(text))
+		return
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// preferredCode returns the status asked for by a Prefer header, or ""
+func preferredCode(prefer string) string {
+	for _, pref := range strings.Split(prefer, ",") {
+		if code, ok := strings.CutPrefix(strings.TrimSpace(pref), "code="); ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// mockResponse returns the response of op with status code, or the first
+// 2xx one, falling back to the default and then the first response
+func mockResponse(op *openapi3.Operation, code string) (string, *openapi3.Response) {
+	if op.Responses == nil {
+		return "", nil
+	}
+	responses := op.Responses.Map()
+	statuses := sortedKeys(responses)
+	pick := func(match func(status string) bool) (string, *openapi3.Response) {
+		for _, status := range statuses {
+			if ref := responses[status]; match(status) && ref != nil && ref.Value != nil {
+				return status, ref.Value
+			}
+		}
+		return "", nil
+	}
+	if code != "" {
+		if status, resp := pick(func(status string) bool { return status == code }); resp != nil {
+			return status, resp
+		}
+	}
+	if status, resp := pick(func(status string) bool { return strings.HasPrefix(status, "2") }); resp != nil {
+		return status, resp
+	}
+	if status, resp := pick(func(status string) bool { return status == "default" }); resp != nil {
+		return status, resp
+	}
+	return pick(func(string) bool { return true })
+}
+
+// mockMedia returns the media type of a response to mock, preferring JSON
+func mockMedia(content openapi3.Content) (string, *openapi3.MediaType) {
+	mediaTypes := sortedKeys(content)
+	for _, mediaType := range mediaTypes {
+		if isJSONMediaType(mediaType) && content[mediaType] != nil {
+			return mediaType, content[mediaType]
+		}
+	}
+	for _, mediaType := range mediaTypes {
+		if content[mediaType] != nil {
+			return mediaType, content[mediaType]
+		}
+	}
+	return "", nil
+}
+
+// mockBody returns the example of a media type, or one synthesized from
+// its schema
+func mockBody(media *openapi3.MediaType) any {
+	if media.Example != nil {
+		return media.Example
+	}
+	for _, name := range sortedKeys(media.Examples) {
+		if example := media.Examples[name]; example != nil && example.Value != nil && example.Value.Value != nil {
+			return example.Value.Value
+		}
+	}
+	g := &exampler{visiting: make(map[*openapi3.Schema]bool)}
+	return g.example(media.Schema)
+}
+
+// isJSONMediaType reports whether a media type is JSON, such as
+// application/json or application/problem+json
+func isJSONMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// writeMockError writes a JSON error of the mock server
+func writeMockError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,89 @@
+package merger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMockHandler(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: string, format: uuid}
+                  password: {type: string, writeOnly: true}
+        "404":
+          description: not found
+          content:
+            application/json:
+              example: {error: not found}
+  /users/me:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              examples:
+                me: {value: {id: me}}
+    delete:
+      responses:
+        "204": {description: deleted}
+  /health:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            text/plain:
+              example: ok
+`)
+	handler := MockHandler(doc)
+
+	tests := []struct {
+		method, path, prefer string
+		code                 int
+		body                 string
+	}{
+		{"GET", "/users/42", "", 200, `{"id":"3fa85f64-5717-4562-b3fc-2c963f66afa6"}`},
+		{"GET", "/users/42", "code=404", 404, `{"error":"not found"}`},
+		{"GET", "/users/me", "", 200, `{"id":"me"}`},
+		{"DELETE", "/users/me", "", 204, ""},
+		{"GET", "/health", "", 200, "ok"},
+		{"POST", "/users/42", "", 405, `{"error":"no POST operation for /users/42"}`},
+		{"GET", "/orders", "", 404, `{"error":"no path of the spec matches /orders"}`},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.prefer != "" {
+			req.Header.Set("Prefer", tt.prefer)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		if recorder.Code != tt.code || strings.TrimSpace(recorder.Body.String()) != tt.body {
+			t.Errorf("%s %s: expected %d %s, got %d %s", tt.method, tt.path, tt.code, tt.body, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest("PUT", "/users/me", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	if allow := recorder.Header().Get("Allow"); allow != "DELETE, GET" {
+		t.Errorf("Expected Allow: DELETE, GET, got %q", allow)
+	}
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,169 @@
+package merger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// NamingConfig sets the casing enforced by the naming lint rules: flat,
+// camel, pascal, kebab, cobol, snake or macro. Empty fields use the
+// defaults: PascalCase schemas, camelCase properties and kebab-case path
+// segments.
+type NamingConfig struct {
+	Schemas    string `json:"schemas,omitempty" yaml:"schemas"`
+	Properties string `json:"properties,omitempty" yaml:"properties"`
+	Paths      string `json:"paths,omitempty" yaml:"paths"`
+}
+
+// withDefaults returns the config with the default casings filled in
+func (c NamingConfig) withDefaults() NamingConfig {
+	if c.Schemas == "" {
+		c.Schemas = "pascal"
+	}
+	if c.Properties == "" {
+		c.Properties = "camel"
+	}
+	if c.Paths == "" {
+		c.Paths = "kebab"
+	}
+	return c
+}
+
+// NamingLintRules returns the naming convention rules, all warnings:
+//
+//   - naming-schema: component schema names follow the schema casing
+//   - naming-property: property names of component and body schemas follow
+//     the property casing
+//   - naming-path: path segments, except {parameters}, follow the path
+//     casing
+//   - path-keys-no-trailing-slash: paths do not end with a slash
+func NamingLintRules(config NamingConfig) ([]LintRule, error) {
+	config = config.withDefaults()
+	for _, casing := range [][2]string{{"schemas", config.Schemas}, {"properties", config.Properties}, {"paths", config.Paths}} {
+		if _, ok := casingPatterns[casing[1]]; !ok {
+			return nil, fmt.Errorf("invalid %s casing %q (expected flat, camel, pascal, kebab, cobol, snake or macro)", casing[0], casing[1])
+		}
+	}
+	schemaCase, propertyCase, pathCase := casingPatterns[config.Schemas], casingPatterns[config.Properties], casingPatterns[config.Paths]
+
+	return []LintRule{
+		{
+			Name:        "naming-schema",
+			Description: "Schema names must be " + config.Schemas + " case",
+			Severity:    SeverityWarn,
+			Check: func(doc *openapi3.T) []LintFinding {
+				var findings []LintFinding
+				if doc.Components == nil {
+					return nil
+				}
+				for _, name := range sortedKeys(doc.Components.Schemas) {
+					if !schemaCase.MatchString(name) {
+						findings = append(findings, LintFinding{
+							Location: "components.schemas." + name,
+							Schema:   name,
+							Message:  fmt.Sprintf("schema name %q is not %s case", name, config.Schemas),
+						})
+					}
+				}
+				return findings
+			},
+		},
+		{
+			Name:        "naming-property",
+			Description: "Property names must be " + config.Properties + " case",
+			Severity:    SeverityWarn,
+			Check: func(doc *openapi3.T) []LintFinding {
+				var findings []LintFinding
+				check := func(finding LintFinding, ref *openapi3.SchemaRef) {
+					forEachProperty(ref, finding.Location, make(map[*openapi3.Schema]bool), func(location, name string) {
+						if !propertyCase.MatchString(name) {
+							finding.Location = location
+							finding.Message = fmt.Sprintf("property name %q is not %s case", name, config.Properties)
+							findings = append(findings, finding)
+						}
+					})
+				}
+				if doc.Components != nil {
+					for _, name := range sortedKeys(doc.Components.Schemas) {
+						check(LintFinding{Location: "components.schemas." + name, Schema: name}, doc.Components.Schemas[name])
+					}
+				}
+				forEachBody(doc, func(body bodyContent) {
+					check(LintFinding{Location: body.schemaLocation(), Path: body.path}, body.media.Schema)
+				})
+				return findings
+			},
+		},
+		{
+			Name:        "naming-path",
+			Description: "Path segments must be " + config.Paths + " case",
+			Severity:    SeverityWarn,
+			Check: func(doc *openapi3.T) []LintFinding {
+				var findings []LintFinding
+				if doc.Paths == nil {
+					return nil
+				}
+				for _, path := range sortedKeys(doc.Paths.Map()) {
+					for _, segment := range strings.Split(path, "/") {
+						if segment == "" || strings.HasPrefix(segment, "{") || pathCase.MatchString(segment) {
+							continue
+						}
+						findings = append(findings, LintFinding{
+							Location: "paths." + path,
+							Path:     path,
+							Message:  fmt.Sprintf("path segment %q is not %s case", segment, config.Paths),
+						})
+					}
+				}
+				return findings
+			},
+		},
+		{
+			Name:        "path-keys-no-trailing-slash",
+			Description: "Paths must not end with a slash",
+			Severity:    SeverityWarn,
+			Check: func(doc *openapi3.T) []LintFinding {
+				var findings []LintFinding
+				if doc.Paths == nil {
+					return nil
+				}
+				for _, path := range sortedKeys(doc.Paths.Map()) {
+					if len(path) > 1 && strings.HasSuffix(path, "/") {
+						findings = append(findings, LintFinding{
+							Location: "paths." + path,
+							Path:     path,
+							Message:  "path ends with a slash",
+						})
+					}
+				}
+				return findings
+			},
+		},
+	}, nil
+}
+
+// forEachProperty calls fn with the location and name of every property of
+// an inline schema and of its inline subschemas. Referenced schemas are
+// checked where they are defined.
+func forEachProperty(ref *openapi3.SchemaRef, location string, visiting map[*openapi3.Schema]bool, fn func(location, name string)) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil || visiting[ref.Value] {
+		return
+	}
+	schema := ref.Value
+	visiting[schema] = true
+	defer delete(visiting, schema)
+
+	for _, name := range sortedKeys(schema.Properties) {
+		fn(location+".properties."+name, name)
+		forEachProperty(schema.Properties[name], location+".properties."+name, visiting, fn)
+	}
+	forEachProperty(schema.Items, location+".items", visiting, fn)
+	forEachProperty(schema.AdditionalProperties.Schema, location+".additionalProperties", visiting, fn)
+	for kind, refs := range map[string]openapi3.SchemaRefs{"allOf": schema.AllOf, "anyOf": schema.AnyOf, "oneOf": schema.OneOf} {
+		for i, ref := range refs {
+			forEachProperty(ref, fmt.Sprintf("%s.%s.%d", location, kind, i), visiting, fn)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package merger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// namingDoc returns a document breaking the default naming conventions
+func namingDoc(t *testing.T) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /userOrders/{order_id}/:
+    get:
+      operationId: getOrder
+      summary: Get an order
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  order_id: {type: string}
+  /users/{id}:
+    get:
+      operationId: getUser
+      summary: Get a user
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/user_profile'}
+components:
+  schemas:
+    user_profile:
+      type: object
+      properties:
+        displayName: {type: string}
+        Address:
+          type: object
+          properties:
+            zip_code: {type: string}
+        tags:
+          type: array
+          items:
+            type: object
+            properties:
+              tagName: {type: string}
+`)
+}
+
+// describeFindings formats findings as "rule location" lines
+func describeFindings(findings []LintFinding) string {
+	var lines []string
+	for _, finding := range findings {
+		lines = append(lines, finding.Rule+" "+finding.Location)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestNamingLintRules(t *testing.T) {
+	linter, err := NewLinter(LintConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := describeFindings(linter.Lint(namingDoc(t)))
+	expected := strings.Join([]string{
+		"naming-schema components.schemas.user_profile",
+		"naming-property components.schemas.user_profile.properties.Address",
+		"naming-property components.schemas.user_profile.properties.Address.properties.zip_code",
+		"naming-path paths./userOrders/{order_id}/",
+		"path-keys-no-trailing-slash paths./userOrders/{order_id}/",
+		"naming-property paths./userOrders/{order_id}/.get.responses.200.content.application/json.schema.properties.order_id",
+	}, "\n")
+	if got != expected {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestNamingConfig(t *testing.T) {
+	config, err := ParseLintConfig([]byte(`
+rules:
+  path-keys-no-trailing-slash: off
+naming:
+  schemas: snake
+  properties: snake
+  paths: flat
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter, err := NewLinter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := describeFindings(linter.Lint(namingDoc(t)))
+	expected := strings.Join([]string{
+		"naming-property components.schemas.user_profile.properties.Address",
+		"naming-property components.schemas.user_profile.properties.displayName",
+		"naming-property components.schemas.user_profile.properties.tags.items.properties.tagName",
+		"naming-path paths./userOrders/{order_id}/",
+	}, "\n")
+	if got != expected {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", expected, got)
+	}
+
+	if _, err := NewLinter(LintConfig{Naming: NamingConfig{Paths: "title"}}); err == nil {
+		t.Error("Expected an error for an unknown casing")
+	}
+}
+
+func TestMergerLintSchemaSource(t *testing.T) {
+	linter, err := NewLinter(LintConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(Config{})
+	m.merged = namingDoc(t)
+	m.schemaSources = map[string]string{"user_profile": "users.yaml"}
+
+	findings, err := m.Lint(linter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, finding := range findings {
+		if finding.Schema == "user_profile" && finding.Source != "users.yaml" {
+			t.Errorf("Expected users.yaml as source of %s", finding)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package merger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotifyConfig lists the endpoints notified after a merge
+type NotifyConfig struct {
+	// Webhooks receive the Notification as JSON
+	Webhooks []string
+	// Slack incoming webhook URLs receive a short text summary
+	Slack []string
+}
+
+// Notification describes the outcome of a merge
+type Notification struct {
+	Status    string         `json:"status"`
+	Output    string         `json:"output"`
+	Inputs    []string       `json:"inputs"`
+	Stats     map[string]int `json:"stats,omitempty"`
+	Conflicts []Conflict     `json:"conflicts,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Time      time.Time      `json:"time"`
+}
+
+// Notify posts the outcome of the last merge to the configured webhooks
+// and Slack channels. mergeErr is the error returned by the merge, if any.
+func (m *Merger) Notify(cfg NotifyConfig, mergeErr error) error {
+	return m.NotifyContext(context.Background(), cfg, mergeErr)
+}
+
+// NotifyContext is Notify, abandoning pending posts once ctx is done
+func (m *Merger) NotifyContext(ctx context.Context, cfg NotifyConfig, mergeErr error) error {
+	return m.withContext(ctx, func() error { return m.notify(cfg, mergeErr) })
+}
+
+// notify posts the outcome of the last merge under the merge context
+func (m *Merger) notify(cfg NotifyConfig, mergeErr error) error {
+	if len(cfg.Webhooks) == 0 && len(cfg.Slack) == 0 {
+		return nil
+	}
+
+	n := m.notification(mergeErr)
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("error marshaling notification: %v", err)
+	}
+	slack, err := json.Marshal(map[string]string{"text": slackText(n)})
+	if err != nil {
+		return fmt.Errorf("error marshaling notification: %v", err)
+	}
+
+	var errs []error
+	for _, url := range cfg.Webhooks {
+		if err := m.postJSON(url, payload); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, url := range cfg.Slack {
+		if err := m.postJSON(url, slack); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notification builds the Notification for the last merge
+func (m *Merger) notification(mergeErr error) Notification {
+	n := Notification{
+		Status: "success",
+		Output: m.config.OutputPath,
+		Inputs: m.inputs,
+		Time:   time.Now().UTC(),
+	}
+	if mergeErr != nil {
+		n.Status = "failure"
+		n.Error = mergeErr.Error()
+		return n
+	}
+	if m.merged != nil {
+		n.Stats = m.stats(m.merged)
+		n.Conflicts = m.conflicts
+	}
+	if len(m.outputPaths) > 0 {
+		n.Output = m.outputPaths[0]
+	}
+	return n
+}
+
+// slackText summarizes a notification for a chat message
+func slackText(n Notification) string {
+	if n.Status != "success" {
+		return fmt.Sprintf("❌ Merging %d inputs into %s failed: %s", len(n.Inputs), n.Output, n.Error)
+	}
+	return fmt.Sprintf("✅ Merged %d files into %s: %d paths, %d schemas, %d tags, %d conflicts",
+		n.Stats["total_files"], n.Output, n.Stats["total_paths"], n.Stats["total_schemas"],
+		n.Stats["total_tags"], n.Stats["total_conflicts"])
+}
+
+// postJSON posts a JSON payload to url
+func (m *Merger) postJSON(url string, payload []byte) error {
+	client, err := m.httpClient()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(m.context(), http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to notify %s: %v", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to notify %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("notification to %s failed with status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package merger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNotify(t *testing.T) {
+	var webhook Notification
+	var slack map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/webhook":
+			json.NewDecoder(r.Body).Decode(&webhook)
+		case "/slack":
+			json.NewDecoder(r.Body).Decode(&slack)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.yaml")
+	orders := filepath.Join(dir, "orders.yaml")
+	spec := `openapi: "3.0.1"
+info:
+  title: API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      summary: %s
+      responses:
+        "200":
+          description: OK`
+	os.WriteFile(users, []byte(fmt.Sprintf(spec, "Get users")), 0644)
+	os.WriteFile(orders, []byte(fmt.Sprintf(spec, "List users")), 0644)
+
+	m := New(Config{InputPaths: []string{users, orders}, OutputPath: filepath.Join(dir, "merged.yaml")})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cfg := NotifyConfig{Webhooks: []string{server.URL + "/webhook"}, Slack: []string{server.URL + "/slack"}}
+	if err := m.Notify(cfg, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if webhook.Status != "success" || webhook.Stats["total_files"] != 2 {
+		t.Errorf("Unexpected webhook payload: %+v", webhook)
+	}
+	if len(webhook.Conflicts) != 1 || webhook.Conflicts[0].Kind != "path" || webhook.Conflicts[0].Sources[1] != orders {
+		t.Errorf("Expected one path conflict from %s, got %+v", orders, webhook.Conflicts)
+	}
+	if !strings.Contains(slack["text"], "1 conflicts") {
+		t.Errorf("Unexpected Slack message: %q", slack["text"])
+	}
+
+	if err := m.Notify(cfg, errors.New("boom")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if webhook.Status != "failure" || webhook.Error != "boom" {
+		t.Errorf("Unexpected failure payload: %+v", webhook)
+	}
+
+	if err := m.Notify(NotifyConfig{Webhooks: []string{server.URL + "/missing"}}, nil); err == nil {
+		t.Error("Expected error for failing webhook")
+	}
+
+	// Nothing is posted once the context is done
+	webhook = Notification{}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.NotifyContext(cancelled, cfg, nil); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+	if webhook.Status != "" {
+		t.Errorf("Expected no notification, got %+v", webhook)
+	}
+}
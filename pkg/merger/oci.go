@@ -0,0 +1,77 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ociArtifactType identifies merged specs pushed as OCI artifacts
+	ociArtifactType = "application/vnd.oai.openapi"
+	// ociLayerMediaType is the media type of the spec layer
+	ociLayerMediaType = "application/vnd.oai.openapi+yaml"
+)
+
+// PushOCI pushes the document produced by the last merge to an OCI
+// registry as an artifact, using the oras CLI and its registry
+// credentials. target has the form oci://registry/repository:tag.
+func (m *Merger) PushOCI(target string) error {
+	return m.PushOCIContext(context.Background(), target)
+}
+
+// PushOCIContext is PushOCI with oras killed once ctx is done
+func (m *Merger) PushOCIContext(ctx context.Context, target string) error {
+	return m.withContext(ctx, func() error { return m.pushOCI(target) })
+}
+
+// pushOCI writes the merged document to a temporary file and pushes it
+func (m *Merger) pushOCI(target string) error {
+	if m.merged == nil {
+		return fmt.Errorf("nothing to push, merge first")
+	}
+
+	ref := strings.TrimPrefix(target, "oci://")
+	if ref == target || ref == "" || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid OCI target %s (format: oci://registry/repository:tag)", target)
+	}
+
+	data, err := m.encode(m.merged, FormatYAML)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "swagger-merger-oci-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "openapi.yaml")
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	args := []string{"push",
+		"--artifact-type", ociArtifactType,
+		"--disable-path-validation",
+	}
+	if info := m.merged.Info; info != nil {
+		if info.Title != "" {
+			args = append(args, "--annotation", "org.opencontainers.image.title="+info.Title)
+		}
+		if info.Version != "" {
+			args = append(args, "--annotation", "org.opencontainers.image.version="+info.Version)
+		}
+	}
+	args = append(args, "--", ref, file+":"+ociLayerMediaType)
+
+	if _, err := runToolContext(m.context(), nil, "oras", args...); err != nil {
+		return fmt.Errorf("failed to push %s: %v", ref, err)
+	}
+
+	m.config.Logger.Debug("pushed OCI artifact", "reference", ref)
+	return nil
+}
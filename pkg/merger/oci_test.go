@@ -0,0 +1,74 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestPushOCI(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	fakeToolScript(t, "oras", "for arg in \"$@\"; do echo \"$arg\"; done > "+argsFile+"\n")
+
+	m := New(Config{})
+	if err := m.PushOCI("oci://registry.example.com/api/spec:1.0.0"); err == nil {
+		t.Error("Expected error before merging")
+	}
+
+	m.merged = &openapi3.T{OpenAPI: "3.0.1", Info: &openapi3.Info{Title: "Platform API", Version: "1.0.0"}}
+	if err := m.PushOCI("registry.example.com/api/spec:1.0.0"); err == nil {
+		t.Error("Expected error for target without oci:// scheme")
+	}
+
+	if err := m.PushOCI("oci://registry.example.com/api/spec:1.0.0"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Split(strings.TrimSpace(string(data)), "\n")
+	expected := []string{"push",
+		"--artifact-type", ociArtifactType,
+		"--disable-path-validation",
+		"--annotation", "org.opencontainers.image.title=Platform API",
+		"--annotation", "org.opencontainers.image.version=1.0.0",
+		"--", "registry.example.com/api/spec:1.0.0",
+	}
+	if len(args) != len(expected)+1 {
+		t.Fatalf("Expected %d arguments, got %q", len(expected)+1, args)
+	}
+	for i, arg := range expected {
+		if args[i] != arg {
+			t.Errorf("Argument %d: expected %q, got %q", i, arg, args[i])
+		}
+	}
+	if layer := args[len(args)-1]; !strings.HasSuffix(layer, "/openapi.yaml:"+ociLayerMediaType) {
+		t.Errorf("Expected the spec layer last, got %q", layer)
+	}
+
+	// Option-shaped references never reach oras
+	os.Remove(argsFile)
+	for _, target := range []string{"oci://--config=/x", "oci://-v"} {
+		if err := m.PushOCI(target); err == nil {
+			t.Errorf("Expected an error for %s", target)
+		}
+	}
+	if _, err := os.Stat(argsFile); !os.IsNotExist(err) {
+		t.Error("Expected oras not to run for option-shaped references")
+	}
+}
+
+func TestPushOCIFailure(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	m := New(Config{})
+	m.merged = &openapi3.T{OpenAPI: "3.0.1", Info: &openapi3.Info{Title: "Platform API", Version: "1.0.0"}}
+	if err := m.PushOCI("oci://registry.example.com/api/spec:1.0.0"); err == nil {
+		t.Error("Expected error when oras is not installed")
+	}
+}
@@ -0,0 +1,52 @@
+package merger
+
+import "fmt"
+
+// Option configures how MergeDocuments combines documents
+type Option func(*mergeOptions)
+
+// mergeOptions holds the settings collected from Option values
+type mergeOptions struct {
+	sourceNames []string
+	hooks       []Hooks
+
+	conflictHandlers []func(Conflict)
+	schemaStrategy   SchemaStrategy
+	ignoreSchemaDocs bool
+	externalDocs     ExternalDocsPolicy
+	extensions       ExtensionPolicy
+
+	enumUnion         bool
+	enumUnionHandlers []func(EnumUnion)
+	renameHandlers    []func(Rename)
+
+	resolver ConflictResolver
+}
+
+// newOptions applies opts on top of the default merge options
+func newOptions(opts []Option) *mergeOptions {
+	o := &mergeOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// WithSourceNames names the documents passed to MergeDocuments, in order.
+// The names are used in error messages and reported to hooks; documents
+// without a name are referred to by their index.
+func WithSourceNames(names ...string) Option {
+	return func(o *mergeOptions) {
+		o.sourceNames = names
+	}
+}
+
+// sourceName returns the name of the i-th document
+func (o *mergeOptions) sourceName(i int) string {
+	if i < len(o.sourceNames) && o.sourceNames[i] != "" {
+		return o.sourceNames[i]
+	}
+	return fmt.Sprintf("document %d", i)
+}
@@ -0,0 +1,393 @@
+package merger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat is the serialization of an output document
+type OutputFormat string
+
+const (
+	// FormatYAML writes YAML
+	FormatYAML OutputFormat = "yaml"
+	// FormatJSON writes indented JSON
+	FormatJSON OutputFormat = "json"
+)
+
+// outputFormat returns the format of an output, chosen by its extension
+// before any .gz; outputs without a .json extension are written as YAML
+func outputFormat(output string) OutputFormat {
+	if isURL(output) || isObjectStorageSource(output) {
+		output = strings.SplitN(output, "?", 2)[0]
+	}
+	if isCompressedOutput(output) {
+		output = output[:len(output)-len(".gz")]
+	}
+	if strings.EqualFold(path.Ext(output), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// isCompressedOutput reports whether an output is written gzip compressed,
+// which outputs with a .gz extension are
+func isCompressedOutput(output string) bool {
+	if isURL(output) || isObjectStorageSource(output) {
+		output = strings.SplitN(output, "?", 2)[0]
+	}
+	return strings.EqualFold(path.Ext(output), ".gz")
+}
+
+// gzipData compresses data with gzip. The header has no timestamp, so
+// the output is reproducible.
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("error compressing output: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("error compressing output: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzipData decompresses gzip data
+func gunzipData(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing: %v", err)
+	}
+	defer zr.Close()
+	data, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing: %v", err)
+	}
+	return data, nil
+}
+
+// sidecarPath returns the path of a file accompanying an output, adding
+// suffix before the query string of object storage URIs
+func sidecarPath(output, suffix string) string {
+	if isObjectStorageSource(output) {
+		if base, query, ok := strings.Cut(output, "?"); ok {
+			return base + suffix + "?" + query
+		}
+	}
+	return output + suffix
+}
+
+// contentType returns the media type of a format
+func (f OutputFormat) contentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "application/yaml"
+}
+
+// encode serializes a document or other value in a format, in the
+// configured style, with keys in the configured order and the comments of
+// the inputs when kept
+func (m *Merger) encode(v any, format OutputFormat) ([]byte, error) {
+	if m.keyOrder != nil || (m.comments != nil && format == FormatYAML) {
+		node, err := documentNode(v, format)
+		if err != nil {
+			return nil, err
+		}
+		if m.keyOrder != nil {
+			m.keyOrder.apply("", node)
+		}
+		if format == FormatJSON {
+			data, err := encodeJSONNode(node)
+			if err != nil {
+				return nil, err
+			}
+			return formatJSON(data, m.config.JSON)
+		}
+		m.comments.apply(node)
+		return encodeYAMLNode(node, m.config.YAML)
+	}
+	if format == FormatJSON {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling to JSON: %v", err)
+		}
+		return formatJSON(append(data, '\n'), m.config.JSON)
+	}
+	return encodeYAML(v, m.config.YAML)
+}
+
+// documentNode encodes a value as a YAML node. JSON is decoded from the
+// JSON encoding, a subset of YAML, to keep its numbers and strings as they
+// are.
+func documentNode(v any, format OutputFormat) (*yaml.Node, error) {
+	var node yaml.Node
+	if format != FormatJSON {
+		if err := node.Encode(v); err != nil {
+			return nil, fmt.Errorf("error marshaling to YAML: %v", err)
+		}
+		return &node, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling to JSON: %v", err)
+	}
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("error marshaling to JSON: %v", err)
+	}
+	return &node, nil
+}
+
+// encodeJSONNode serializes a node decoded from JSON as indented JSON
+func encodeJSONNode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeJSONNode(&buf, node, ""); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeJSONNode writes a node decoded from JSON back as indented JSON
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSONNode(buf, node.Content[0], indent)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + "  ")
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.Write(key)
+			buf.WriteString(": ")
+			if err := writeJSONNode(buf, node.Content[i+1], indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[")
+		for i, item := range node.Content {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + indent + "  ")
+			if err := writeJSONNode(buf, item, indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" {
+			value, _ := json.Marshal(node.Value)
+			buf.Write(value)
+			return nil
+		}
+		buf.WriteString(node.Value)
+	default:
+		return fmt.Errorf("error marshaling to JSON: unexpected YAML node kind %v", node.Kind)
+	}
+	return nil
+}
+
+// writeOutputs serializes the merged document once per format and writes
+// it to every output
+func (m *Merger) writeOutputs(merged *openapi3.T, outputs []string) error {
+	done := m.phase(PhaseMarshal)
+	if m.config.EmbedChecksum {
+		if err := embedChecksum(merged); err != nil {
+			return err
+		}
+	}
+
+	// Encode every output before writing any, so a failure never leaves
+	// outputs of different merges behind
+	type pendingOutput struct {
+		path string
+		data []byte
+		meta ObjectMetadata
+	}
+	var pending []pendingOutput
+	encoded := make(map[OutputFormat][]byte)
+	for _, output := range outputs {
+		format := outputFormat(output)
+		data, ok := encoded[format]
+		if !ok {
+			var err error
+			data, err = m.encode(merged, format)
+			if err != nil {
+				return err
+			}
+			encoded[format] = data
+		}
+
+		meta := ObjectMetadata{ContentType: format.contentType()}
+		if isCompressedOutput(output) {
+			var err error
+			if data, err = gzipData(data); err != nil {
+				return err
+			}
+			meta.ContentEncoding = "gzip"
+		}
+		pending = append(pending, pendingOutput{path: output, data: data, meta: meta})
+	}
+	done()
+	if err := m.interrupted("encoding outputs"); err != nil {
+		return err
+	}
+
+	defer m.phase(PhaseWrite)()
+
+	for _, output := range pending {
+		m.progress(ProgressWriting, output.path, 0)
+		if m.config.BackupOutputs && !isObjectStorageSource(output.path) {
+			if err := backupOutput(output.path); err != nil {
+				return err
+			}
+		}
+		if err := m.writeOutput(output.path, output.data, output.meta); err != nil {
+			return err
+		}
+		m.config.Logger.Debug("wrote output", "path", output.path, "format", outputFormat(output.path), "bytes", len(output.data))
+
+		if m.config.ChecksumSidecar {
+			sidecar := sidecarPath(output.path, ".sha256")
+			if err := m.writeOutput(sidecar, checksumSidecar(output.path, output.data), ObjectMetadata{ContentType: "text/plain"}); err != nil {
+				return err
+			}
+			m.config.Logger.Debug("wrote checksum", "path", sidecar)
+		}
+	}
+	return nil
+}
+
+// writeOutput writes an output file to a local file or, for s3://, gs://
+// and azblob:// targets, to object storage
+func (m *Merger) writeOutput(path string, data []byte, meta ObjectMetadata) error {
+	if isObjectStorageSource(path) {
+		meta.CacheControl = m.config.OutputCacheControl
+		if err := writeObjectStorage(m.context(), path, data, meta); err != nil {
+			return fmt.Errorf("error uploading %s: %v", path, err)
+		}
+		return nil
+	}
+
+	// Templated paths often name directories that don't exist yet
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating output directory: %v", err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %v", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes a file through a temporary file in the same
+// directory renamed over it, so that readers and crashes never see a
+// partially written file. An existing file keeps its mode; a new one gets
+// perm less the umask, as with os.WriteFile.
+func writeFileAtomic(name string, data []byte, perm fs.FileMode) error {
+	info, statErr := os.Stat(name)
+	if statErr == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := createTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-", perm)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// The umask applied at creation may have dropped bits of an existing
+	// file's mode
+	if statErr == nil {
+		if err := os.Chmod(tmp.Name(), perm); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(name))
+	return nil
+}
+
+// createTemp creates a new file named prefix plus a random suffix in dir,
+// with perm less the umask, unlike os.CreateTemp which always uses 0600
+func createTemp(dir, prefix string, perm fs.FileMode) (*os.File, error) {
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 36))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, fs.ErrExist) && i < 10000 {
+			continue
+		}
+		return f, err
+	}
+}
+
+// syncDir flushes a directory so a rename into it survives a crash. Not
+// all platforms can sync directories, so failures are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// backupOutput copies the current content of a local output, if any, to
+// <output>.bak
+func backupOutput(output string) error {
+	data, err := os.ReadFile(output)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err == nil {
+		err = writeFileAtomic(output+".bak", data, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("error backing up %s: %v", output, err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package merger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeMultipleOutputs(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.yaml")
+	os.WriteFile(input, []byte(testSpec), 0644)
+
+	yamlOut := filepath.Join(dir, "merged.yaml")
+	jsonOut := filepath.Join(dir, "merged.json")
+	upperOut := filepath.Join(dir, "MERGED.JSON")
+	m := New(Config{InputPaths: []string{input}, OutputPath: yamlOut, Outputs: []string{jsonOut, upperOut}})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var fromYAML, fromJSON map[string]any
+	data, _ := os.ReadFile(yamlOut)
+	if err := yaml.Unmarshal(data, &fromYAML); err != nil || strings.HasPrefix(string(data), "{") {
+		t.Fatalf("Expected YAML output, got %s", data)
+	}
+	data, _ = os.ReadFile(jsonOut)
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatalf("Expected JSON output, got %v: %s", err, data)
+	}
+	if fromYAML["openapi"] != fromJSON["openapi"] || fromJSON["info"].(map[string]any)["title"] != "Remote API" {
+		t.Errorf("Outputs diverge: %v vs %v", fromYAML, fromJSON)
+	}
+
+	upper, _ := os.ReadFile(upperOut)
+	if string(upper) != string(data) {
+		t.Error("Expected .JSON output to be written as JSON")
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	for output, want := range map[string]OutputFormat{
+		"merged.yaml":                           FormatYAML,
+		"merged.yml":                            FormatYAML,
+		"merged":                                FormatYAML,
+		"merged.json":                           FormatJSON,
+		"s3://bucket/api/openapi.json":          FormatJSON,
+		"gs://bucket/openapi.json?generation=1": FormatJSON,
+		"merged.yaml.gz":                        FormatYAML,
+		"merged.JSON.GZ":                        FormatJSON,
+		"s3://bucket/openapi.json.gz":           FormatJSON,
+	} {
+		if got := outputFormat(output); got != want {
+			t.Errorf("outputFormat(%q) = %s, want %s", output, got, want)
+		}
+	}
+}
+
+func TestMergeCompressedOutput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.yaml")
+	os.WriteFile(input, []byte(testSpec), 0644)
+
+	output := filepath.Join(dir, "merged.yaml.gz")
+	m := New(Config{InputPaths: []string{input}, OutputPath: output, ChecksumSidecar: true})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	compressed, _ := os.ReadFile(output)
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Expected gzip output, got %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress output: %v", err)
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc["openapi"] == nil {
+		t.Fatalf("Expected compressed YAML spec, got %v: %s", err, data)
+	}
+
+	sidecar, _ := os.ReadFile(output + ".sha256")
+	if want := string(checksumSidecar(output, compressed)); string(sidecar) != want {
+		t.Errorf("Expected sidecar to cover the compressed bytes, got %q want %q", sidecar, want)
+	}
+
+	// Merging again must yield identical bytes
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if again, _ := os.ReadFile(output); !bytes.Equal(again, compressed) {
+		t.Error("Expected reproducible compressed output")
+	}
+}
+
+func TestWriteFileAtomicMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes not supported")
+	}
+	dir := t.TempDir()
+
+	// New files get the mode os.WriteFile gives them under the umask
+	probe := filepath.Join(dir, "probe.yaml")
+	if err := os.WriteFile(probe, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := os.Stat(probe)
+	output := filepath.Join(dir, "merged.yaml")
+	if err := writeFileAtomic(output, []byte("a"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if info, _ := os.Stat(output); info.Mode().Perm() != expected.Mode().Perm() {
+		t.Errorf("Expected mode %v, got %v", expected.Mode().Perm(), info.Mode().Perm())
+	}
+
+	// Existing files keep their mode
+	if err := os.Chmod(output, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(output, []byte("b"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	info, _ := os.Stat(output)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the private output to stay 0600, got %v", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(output); string(data) != "b" {
+		t.Errorf("Expected the new content, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected no temporary files left, got %d entries", len(entries))
+	}
+}
+
+func TestMergeBackupOutputs(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.yaml")
+	os.WriteFile(input, []byte(testSpec), 0644)
+	output := filepath.Join(dir, "merged.yaml")
+	os.WriteFile(output, []byte("previous"), 0644)
+
+	m := New(Config{InputPaths: []string{input}, OutputPath: output, BackupOutputs: true})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if backup, _ := os.ReadFile(output + ".bak"); string(backup) != "previous" {
+		t.Errorf("Expected the previous output in the backup, got %q", backup)
+	}
+	if data, _ := os.ReadFile(output); !strings.Contains(string(data), "Remote API") {
+		t.Errorf("Expected the merged spec in the output, got %s", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("Expected no temporary file left, found %s", entry.Name())
+		}
+	}
+}
+
+func TestMergeOutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.yaml")
+	os.WriteFile(input, []byte(testSpec), 0644)
+
+	m := New(Config{
+		InputPaths:      []string{input},
+		OutputPath:      filepath.Join(dir, "api-{version}-{profile}.yaml"),
+		Outputs:         []string{filepath.Join(dir, "{date}", "api.json")},
+		OutputVariables: map[string]string{"profile": "public"},
+	})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "api-1.0.0-public.yaml"),
+		filepath.Join(dir, time.Now().UTC().Format("2006-01-02"), "api.json"),
+	}
+	if got := m.OutputPaths(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected outputs %v, got %v", expected, got)
+	}
+	for _, output := range expected {
+		if _, err := os.Stat(output); err != nil {
+			t.Errorf("Expected %s to be written, got %v", output, err)
+		}
+	}
+
+	err := New(Config{InputPaths: []string{input}, OutputPath: filepath.Join(dir, "api-{branch}.yaml")}).Merge()
+	if err == nil || !strings.Contains(err.Error(), "{branch}") {
+		t.Errorf("Expected an unknown variable error, got %v", err)
+	}
+}
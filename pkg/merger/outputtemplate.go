@@ -0,0 +1,72 @@
+package merger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// outputVariable matches the {name} variables of output paths
+var outputVariable = regexp.MustCompile(`\{([A-Za-z][A-Za-z0-9_-]*)\}`)
+
+// pathUnsafe replaces the characters of variable values that would add
+// path segments
+var pathUnsafe = strings.NewReplacer("/", "-", `\`, "-")
+
+// expandOutputPath replaces the {name} variables of an output path with
+// their values, failing on variables without a value
+func expandOutputPath(output string, vars map[string]string) (string, error) {
+	var missing []string
+	expanded := outputVariable.ReplaceAllStringFunc(output, func(match string) string {
+		name := match[1 : len(match)-1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, match)
+			return match
+		}
+		return pathUnsafe.Replace(value)
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unknown variables %s in output path %s (expected {version}, {date} or an output variable)", strings.Join(missing, ", "), output)
+	}
+	return expanded, nil
+}
+
+// outputVariables returns the values of the variables of output paths for
+// a merged document: {version}, its info.version, {date}, the UTC date of
+// the merge, and OutputVariables, which take precedence
+func (m *Merger) outputVariables(merged *openapi3.T) map[string]string {
+	vars := map[string]string{"date": time.Now().UTC().Format("2006-01-02")}
+	if merged != nil && merged.Info != nil && merged.Info.Version != "" {
+		vars["version"] = merged.Info.Version
+	}
+	for name, value := range m.config.OutputVariables {
+		vars[name] = value
+	}
+	return vars
+}
+
+// expandOutputs returns every output of a merge with the variables of
+// their paths expanded for the merged document. Without a document, only
+// checks that every variable is known.
+func (m *Merger) expandOutputs(merged *openapi3.T) ([]string, error) {
+	vars := m.outputVariables(merged)
+	if merged == nil {
+		vars["version"] = ""
+	}
+	outputs := append([]string{m.config.OutputPath}, m.config.Outputs...)
+	for i, output := range outputs {
+		expanded, err := expandOutputPath(output, vars)
+		if err != nil {
+			if _, ok := vars["version"]; !ok && strings.Contains(output, "{version}") {
+				return nil, fmt.Errorf("output path %s uses {version} but the merged spec has no info.version", output)
+			}
+			return nil, err
+		}
+		outputs[i] = expanded
+	}
+	return outputs, nil
+}
@@ -0,0 +1,119 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseParameters parses a YAML or JSON list of OpenAPI parameter objects,
+// e.g. the shared headers passed to CommonParameters
+func ParseParameters(data []byte) ([]*openapi3.Parameter, error) {
+	jsonBytes, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var params []*openapi3.Parameter
+	if err := json.Unmarshal(jsonBytes, &params); err != nil {
+		return nil, fmt.Errorf("expected a list of parameters: %v", err)
+	}
+	for i, param := range params {
+		if param == nil || param.Name == "" || param.In == "" {
+			return nil, fmt.Errorf("parameter %d needs a name and in", i+1)
+		}
+	}
+	return params, nil
+}
+
+// yamlToJSON converts a YAML (or JSON) value to JSON
+func yamlToJSON(data []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %v", err)
+	}
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML to JSON: %v", err)
+	}
+	return jsonBytes, nil
+}
+
+// HeaderParameters returns optional string header parameters
+func HeaderParameters(names ...string) []*openapi3.Parameter {
+	params := make([]*openapi3.Parameter, len(names))
+	for i, name := range names {
+		params[i] = openapi3.NewHeaderParameter(name).WithSchema(openapi3.NewStringSchema())
+	}
+	return params
+}
+
+// CommonParameters returns a transformer appending shared parameters, such
+// as X-Request-ID or X-Tenant-ID headers, to every operation of the merged
+// document. The parameters are added to components.parameters and
+// referenced from the operations; operations that already declare a
+// parameter with the same name and location, themselves or on their path,
+// keep their own.
+func CommonParameters(params []*openapi3.Parameter) Transformer {
+	return NewTransformer("common-parameters", func(doc *openapi3.T, source string) error {
+		if len(params) == 0 || doc.Paths == nil {
+			return nil
+		}
+		if doc.Components == nil {
+			doc.Components = &openapi3.Components{}
+		}
+		if doc.Components.Parameters == nil {
+			doc.Components.Parameters = openapi3.ParametersMap{}
+		}
+
+		refs := make([]*openapi3.ParameterRef, len(params))
+		for i, param := range params {
+			name := parameterComponentName(doc.Components.Parameters, param)
+			doc.Components.Parameters[name] = &openapi3.ParameterRef{Value: param}
+			refs[i] = &openapi3.ParameterRef{Ref: "#/components/parameters/" + name, Value: param}
+		}
+
+		for _, item := range doc.Paths.Map() {
+			for _, op := range item.Operations() {
+				for _, ref := range refs {
+					if !declaresParameter(op.Parameters, ref.Value) && !declaresParameter(item.Parameters, ref.Value) {
+						op.Parameters = append(op.Parameters, ref)
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// parameterComponentName returns the component name of a shared
+// parameter: its name in PascalCase, reusing an identical existing
+// component and numbered when a different one has the name
+func parameterComponentName(existing openapi3.ParametersMap, param *openapi3.Parameter) string {
+	base := pascalCase(param.Name)
+	name := base
+	for i := 2; ; i++ {
+		current, ok := existing[name]
+		if !ok || current.Ref == "" && equalJSON(current.Value, param) {
+			return name
+		}
+		name = base + strconv.Itoa(i)
+	}
+}
+
+// declaresParameter reports whether params has a parameter with the name
+// and location of param; header names are case-insensitive
+func declaresParameter(params openapi3.Parameters, param *openapi3.Parameter) bool {
+	for _, p := range params {
+		if p == nil || p.Value == nil || p.Value.In != param.In {
+			continue
+		}
+		if p.Value.Name == param.Name || param.In == openapi3.ParameterInHeader && strings.EqualFold(p.Value.Name, param.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestParseParameters(t *testing.T) {
+	params, err := ParseParameters([]byte(`
+- name: X-Tenant-ID
+  in: header
+  required: true
+  schema: {type: string, format: uuid}
+- name: locale
+  in: query
+  schema: {type: string}
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(params) != 2 || params[0].Name != "X-Tenant-ID" || !params[0].Required || params[1].In != "query" {
+		t.Errorf("Unexpected parameters: %+v", params)
+	}
+
+	if _, err := ParseParameters([]byte("- {name: locale}")); err == nil {
+		t.Error("Expected an error for a parameter without in")
+	}
+}
+
+func TestCommonParameters(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: x-request-id, in: header, required: true, schema: {type: string}}
+      responses: {"200": {description: ok}}
+    post:
+      responses: {"200": {description: ok}}
+  /orders:
+    parameters:
+      - {name: X-Tenant-ID, in: header, schema: {type: string}}
+    get:
+      responses: {"200": {description: ok}}
+components:
+  parameters:
+    XTenantID:
+      {name: X-Tenant-ID, in: header, description: other, schema: {type: string}}
+`)
+
+	transformer := CommonParameters(HeaderParameters("X-Request-ID", "X-Tenant-ID"))
+	if err := transformer.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := func(params openapi3.Parameters) []string {
+		var names []string
+		for _, p := range params {
+			if p.Ref != "" {
+				names = append(names, p.Ref)
+			} else {
+				names = append(names, p.Value.Name)
+			}
+		}
+		return names
+	}
+
+	if got := refs(doc.Paths.Find("/users").Get.Parameters); len(got) != 2 || got[0] != "x-request-id" || got[1] != "#/components/parameters/XTenantID2" {
+		t.Errorf("Expected the declared request ID to be kept, got %v", got)
+	}
+	if got := refs(doc.Paths.Find("/users").Post.Parameters); len(got) != 2 || got[0] != "#/components/parameters/XRequestID" {
+		t.Errorf("Expected both headers to be added, got %v", got)
+	}
+	if got := refs(doc.Paths.Find("/orders").Get.Parameters); len(got) != 1 || got[0] != "#/components/parameters/XRequestID" {
+		t.Errorf("Expected the path-level tenant header to be kept, got %v", got)
+	}
+	if got := doc.Components.Parameters["XTenantID"].Value.Description; got != "other" {
+		t.Error("Expected the existing component to be kept")
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package merger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExecPlugin returns a transformer backed by an external executable.
+// The document is written as JSON to the command's stdin and the
+// transformed document (JSON or YAML) is read back from its stdout, so
+// plugins can be written in any language. The input source is passed in
+// the SWAGGER_MERGER_SOURCE environment variable. The command is killed
+// when the merge's context is done.
+func ExecPlugin(command string, args ...string) Transformer {
+	return execPlugin{command: command, args: args}
+}
+
+// execPlugin is the ContextTransformer returned by ExecPlugin
+type execPlugin struct {
+	command string
+	args    []string
+}
+
+func (p execPlugin) Name() string {
+	return "plugin:" + strings.Join(append([]string{p.command}, p.args...), " ")
+}
+
+func (p execPlugin) Transform(doc *openapi3.T, source string) error {
+	return p.TransformContext(context.Background(), doc, source)
+}
+
+func (p execPlugin) TransformContext(ctx context.Context, doc *openapi3.T, source string) error {
+	in, err := doc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "SWAGGER_MERGER_SOURCE="+source)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("plugin %s interrupted: %v", p.command, context.Cause(ctx))
+		}
+		return fmt.Errorf("plugin %s failed: %v: %s", p.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	loader := openapi3.NewLoader()
+	out, err := loader.LoadFromData(stdout.Bytes())
+	if err != nil {
+		return fmt.Errorf("plugin %s returned an invalid document: %v", p.command, err)
+	}
+
+	*doc = *out
+	return nil
+}
@@ -0,0 +1,66 @@
+package merger
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestExecPlugin(t *testing.T) {
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("sed not available")
+	}
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.1",
+		Info:    &openapi3.Info{Title: "Original", Version: "1.0.0"},
+		Paths:   openapi3.NewPaths(),
+	}
+
+	plugin := ExecPlugin("sed", "s/Original/Renamed/")
+	if err := plugin.Transform(doc, "a.yaml"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if doc.Info.Title != "Renamed" {
+		t.Errorf("Expected title 'Renamed', got '%s'", doc.Info.Title)
+	}
+
+	// Failing commands surface as errors
+	if err := ExecPlugin("false").Transform(doc, "a.yaml"); err == nil {
+		t.Error("Expected error from failing plugin")
+	}
+}
+
+func TestExecPluginContext(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	file, err := createTempSwaggerFile(testSpec)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	// A hung plugin is killed at the merge deadline
+	pipeline := &Pipeline{}
+	pipeline.Add(StageMerged, ExecPlugin("sleep", "10"))
+	m := New(Config{InputPaths: []string{file}, OutputPath: filepath.Join(t.TempDir(), "merged.yaml"), Pipeline: pipeline})
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond, errors.New("merge timeout of 200ms exceeded"))
+	defer cancel()
+	start := time.Now()
+	err = m.MergeContext(ctx)
+	if err == nil || !strings.Contains(err.Error(), "merge timeout of 200ms exceeded") {
+		t.Errorf("Expected the plugin to be interrupted, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the plugin to be killed at the deadline, took %v", elapsed)
+	}
+}
@@ -0,0 +1,296 @@
+package merger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// untaggedGroup names the group of operations without tags in previews
+const untaggedGroup = "(untagged)"
+
+// PreviewNode is an entry of the tree browsing a merged document: a tag,
+// an operation, one of its parameters, bodies or responses, or a schema
+type PreviewNode struct {
+	// Label names the entry, such as "GET /users" or "email*" for a
+	// required property
+	Label string
+	// Detail summarizes it, such as the summary of an operation or the
+	// type of a schema
+	Detail string
+
+	children func() []*PreviewNode
+	expanded []*PreviewNode
+	loaded   bool
+}
+
+// Expandable reports whether the node has children
+func (n *PreviewNode) Expandable() bool {
+	return n.children != nil && len(n.Children()) > 0
+}
+
+// Children returns the entries of the node. Schemas are expanded when
+// asked for, so recursive schemas can be browsed as deep as needed.
+func (n *PreviewNode) Children() []*PreviewNode {
+	if !n.loaded && n.children != nil {
+		n.expanded = n.children()
+		n.loaded = true
+	}
+	return n.expanded
+}
+
+// String returns the label and detail of the node
+func (n *PreviewNode) String() string {
+	if n.Detail == "" {
+		return n.Label
+	}
+	return n.Label + "  " + n.Detail
+}
+
+// PreviewTree returns the tree browsing doc: its operations grouped by
+// tag, in the order of the document's tags, followed by its component
+// schemas
+func PreviewTree(doc *openapi3.T) *PreviewNode {
+	title := "OpenAPI"
+	if doc.Info != nil && doc.Info.Title != "" {
+		title = doc.Info.Title
+	}
+	root := &PreviewNode{Label: title}
+	if doc.Info != nil && doc.Info.Version != "" {
+		root.Detail = "v" + doc.Info.Version
+	}
+
+	var tags []string
+	descriptions := make(map[string]string)
+	for _, tag := range doc.Tags {
+		tags = append(tags, tag.Name)
+		descriptions[tag.Name] = firstLine(tag.Description)
+	}
+	groups := make(map[string][]*PreviewNode)
+	if doc.Paths != nil {
+		paths := doc.Paths.Map()
+		for _, path := range sortedKeys(paths) {
+			ops := paths[path].Operations()
+			for _, method := range sortedKeys(ops) {
+				op := ops[method]
+				opTags := op.Tags
+				if len(opTags) == 0 {
+					opTags = []string{untaggedGroup}
+				}
+				for _, tag := range opTags {
+					if !slices.Contains(tags, tag) && tag != untaggedGroup {
+						tags = append(tags, tag)
+					}
+					groups[tag] = append(groups[tag], operationNode(method, path, paths[path], op))
+				}
+			}
+		}
+	}
+	if len(groups[untaggedGroup]) > 0 {
+		tags = append(tags, untaggedGroup)
+	}
+
+	var sections []*PreviewNode
+	for _, tag := range tags {
+		ops := groups[tag]
+		detail := fmt.Sprintf("(%d operations)", len(ops))
+		if len(ops) == 1 {
+			detail = "(1 operation)"
+		}
+		if descriptions[tag] != "" {
+			detail += " " + descriptions[tag]
+		}
+		sections = append(sections, &PreviewNode{Label: tag, Detail: detail, children: nodes(ops)})
+	}
+	if doc.Components != nil && len(doc.Components.Schemas) > 0 {
+		schemas := doc.Components.Schemas
+		sections = append(sections, &PreviewNode{
+			Label:  "schemas",
+			Detail: fmt.Sprintf("(%d)", len(schemas)),
+			children: func() []*PreviewNode {
+				var children []*PreviewNode
+				for _, name := range sortedKeys(schemas) {
+					children = append(children, schemaNode(name, schemas[name]))
+				}
+				return children
+			},
+		})
+	}
+	root.children = nodes(sections)
+	return root
+}
+
+// nodes returns a children function returning fixed nodes
+func nodes(children []*PreviewNode) func() []*PreviewNode {
+	return func() []*PreviewNode { return children }
+}
+
+// operationNode returns the node of an operation, with its parameters,
+// request body and responses
+func operationNode(method, path string, item *openapi3.PathItem, op *openapi3.Operation) *PreviewNode {
+	detail := op.Summary
+	if detail == "" {
+		detail = firstLine(op.Description)
+	}
+	if op.Deprecated {
+		detail = strings.TrimSpace("(deprecated) " + detail)
+	}
+	return &PreviewNode{
+		Label:  method + " " + path,
+		Detail: detail,
+		children: func() []*PreviewNode {
+			var children []*PreviewNode
+			params := append(slices.Clone(item.Parameters), op.Parameters...)
+			if len(params) > 0 {
+				var paramNodes []*PreviewNode
+				for _, param := range params {
+					if param == nil || param.Value == nil {
+						continue
+					}
+					p := param.Value
+					label := p.Name
+					if p.Required {
+						label += "*"
+					}
+					node := schemaNode(label, p.Schema)
+					node.Detail = strings.TrimSpace(fmt.Sprintf("in %s %s", p.In, node.Detail))
+					paramNodes = append(paramNodes, node)
+				}
+				children = append(children, &PreviewNode{Label: "parameters", children: nodes(paramNodes)})
+			}
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				body := op.RequestBody.Value
+				node := contentNode("request body", body.Content)
+				if body.Required {
+					node.Label += "*"
+				}
+				children = append(children, node)
+			}
+			if op.Responses != nil {
+				responses := op.Responses.Map()
+				var responseNodes []*PreviewNode
+				for _, status := range sortedKeys(responses) {
+					if responses[status].Value == nil {
+						continue
+					}
+					response := responses[status].Value
+					node := contentNode(status, response.Content)
+					if response.Description != nil {
+						node.Detail = firstLine(*response.Description)
+					}
+					responseNodes = append(responseNodes, node)
+				}
+				children = append(children, &PreviewNode{Label: "responses", children: nodes(responseNodes)})
+			}
+			return children
+		},
+	}
+}
+
+// contentNode returns a node with the schema of every media type of a
+// body
+func contentNode(label string, content openapi3.Content) *PreviewNode {
+	return &PreviewNode{
+		Label: label,
+		children: func() []*PreviewNode {
+			var children []*PreviewNode
+			for _, mediaType := range sortedKeys(content) {
+				if content[mediaType] != nil {
+					children = append(children, schemaNode(mediaType, content[mediaType].Schema))
+				}
+			}
+			return children
+		},
+	}
+}
+
+// schemaNode returns the node of a schema, whose children are its
+// properties, items and composed schemas
+func schemaNode(label string, ref *openapi3.SchemaRef) *PreviewNode {
+	node := &PreviewNode{Label: label, Detail: schemaSummary(ref)}
+	if ref == nil || ref.Value == nil {
+		return node
+	}
+	s := ref.Value
+	node.children = func() []*PreviewNode {
+		var children []*PreviewNode
+		for _, name := range sortedKeys(s.Properties) {
+			label := name
+			if slices.Contains(s.Required, name) {
+				label += "*"
+			}
+			children = append(children, schemaNode(label, s.Properties[name]))
+		}
+		if s.Items != nil {
+			children = append(children, schemaNode("items", s.Items))
+		}
+		if s.AdditionalProperties.Schema != nil {
+			children = append(children, schemaNode("additionalProperties", s.AdditionalProperties.Schema))
+		}
+		for _, composed := range []struct {
+			keyword string
+			refs    openapi3.SchemaRefs
+		}{{"allOf", s.AllOf}, {"oneOf", s.OneOf}, {"anyOf", s.AnyOf}} {
+			for i, member := range composed.refs {
+				children = append(children, schemaNode(fmt.Sprintf("%s[%d]", composed.keyword, i), member))
+			}
+		}
+		if s.Not != nil {
+			children = append(children, schemaNode("not", s.Not))
+		}
+		return children
+	}
+	return node
+}
+
+// schemaSummary describes a schema in one line: the schema it refers
+// to, its type and format, and its enum values
+func schemaSummary(ref *openapi3.SchemaRef) string {
+	if ref == nil || ref.Value == nil {
+		return ""
+	}
+	s := ref.Value
+	var parts []string
+	if ref.Ref != "" {
+		parts = append(parts, "→ "+ref.Ref[strings.LastIndex(ref.Ref, "/")+1:])
+	}
+	switch {
+	case s.Type != nil && s.Type.Is("array") && s.Items != nil:
+		item := schemaSummary(s.Items)
+		if item == "" {
+			item = "any"
+		}
+		parts = append(parts, "array of "+item)
+	case s.Type != nil && len(s.Type.Slice()) > 0:
+		types := strings.Join(s.Type.Slice(), "|")
+		if s.Format != "" {
+			types += " (" + s.Format + ")"
+		}
+		parts = append(parts, types)
+	case len(s.AllOf) > 0:
+		parts = append(parts, "allOf")
+	case len(s.OneOf) > 0:
+		parts = append(parts, "oneOf")
+	case len(s.AnyOf) > 0:
+		parts = append(parts, "anyOf")
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		parts = append(parts, "enum: "+strings.Join(values, ", "))
+	}
+	if ref.Ref == "" && s.Deprecated {
+		parts = append(parts, "(deprecated)")
+	}
+	return strings.Join(parts, " ")
+}
+
+// firstLine returns the first line of a description
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
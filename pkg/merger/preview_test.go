@@ -0,0 +1,85 @@
+package merger
+
+import (
+	"testing"
+)
+
+func TestPreviewTree(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Shop, version: "2.1"}
+tags:
+  - {name: orders, description: "Order management\nand more"}
+paths:
+  /orders:
+    get:
+      tags: [orders]
+      summary: List orders
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, format: int32}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: '#/components/schemas/Order'}}
+  /health:
+    get:
+      responses:
+        "200": {description: ok}
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id]
+      properties:
+        id: {type: string}
+        status: {type: string, enum: [open, paid]}
+        parent: {$ref: '#/components/schemas/Order'}
+`)
+
+	root := PreviewTree(doc)
+	if root.String() != "Shop  v2.1" {
+		t.Errorf("Expected the title and version, got %q", root)
+	}
+	sections := root.Children()
+	var labels []string
+	for _, section := range sections {
+		labels = append(labels, section.String())
+	}
+	expected := []string{"orders  (1 operation) Order management", "(untagged)  (1 operation)", "schemas  (1)"}
+	if len(labels) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, labels)
+	}
+	for i := range expected {
+		if labels[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], labels[i])
+		}
+	}
+
+	op := sections[0].Children()[0]
+	if op.String() != "GET /orders  List orders" {
+		t.Errorf("Expected the operation, got %q", op)
+	}
+	params, responses := op.Children()[0], op.Children()[1]
+	if got := params.Children()[0].String(); got != "limit  in query integer (int32)" {
+		t.Errorf("Expected the limit parameter, got %q", got)
+	}
+	body := responses.Children()[0].Children()[0]
+	if body.Detail != "array of → Order object" {
+		t.Errorf("Expected an array of orders, got %q", body.Detail)
+	}
+
+	// Recursive schemas expand as deep as asked
+	order := sections[2].Children()[0]
+	parent := order.Children()[1]
+	if parent.Label != "parent" || parent.Children()[1].Label != "parent" {
+		t.Errorf("Expected the recursive parent property, got %q", parent)
+	}
+	if got := order.Children()[2].String(); got != "status  string enum: open, paid" {
+		t.Errorf("Expected the status enum, got %q", got)
+	}
+	if order.Children()[0].Label != "id*" || order.Children()[0].Expandable() {
+		t.Errorf("Expected the required id leaf, got %q", order.Children()[0])
+	}
+}
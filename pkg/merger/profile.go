@@ -0,0 +1,162 @@
+package merger
+
+import (
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// ProfilePhase is a step of the merge timed when Config.Profile is set
+type ProfilePhase string
+
+const (
+	// PhaseFetch reads inputs from disk, URLs, git or object storage
+	PhaseFetch ProfilePhase = "fetch"
+	// PhaseParse detects the version of inputs and decodes them
+	PhaseParse ProfilePhase = "parse"
+	// PhaseConvert converts Swagger 2.0 inputs, caches converted documents
+	// and runs the input transformers
+	PhaseConvert ProfilePhase = "convert"
+	// PhaseMerge merges the inputs and runs the merged transformers
+	PhaseMerge ProfilePhase = "merge"
+	// PhaseMarshal encodes the outputs
+	PhaseMarshal ProfilePhase = "marshal"
+	// PhaseWrite writes the outputs and their checksums
+	PhaseWrite ProfilePhase = "write"
+)
+
+// ProfilePhases lists the phases in the order a merge runs them
+var ProfilePhases = []ProfilePhase{PhaseFetch, PhaseParse, PhaseConvert, PhaseMerge, PhaseMarshal, PhaseWrite}
+
+// Profile holds the timings and memory use of a merge
+type Profile struct {
+	// Phases is the time spent in each phase. The time of inputs loaded
+	// in parallel adds up, so fetch, parse and convert can exceed Total.
+	Phases map[ProfilePhase]time.Duration
+	// Total is the duration of the merge
+	Total time.Duration
+	// PeakHeap is the largest heap size seen during the merge, in bytes
+	PeakHeap uint64
+	// Allocated is the number of bytes allocated during the merge
+	Allocated uint64
+}
+
+// profileInterval is how often the heap size is sampled
+const profileInterval = 5 * time.Millisecond
+
+// heapMetrics are the runtime metrics read by the profiler
+var heapMetrics = []string{"/memory/classes/heap/objects:bytes", "/gc/heap/allocs:bytes"}
+
+// profiler times the phases of a running merge and samples its heap
+type profiler struct {
+	start     time.Time
+	allocated uint64
+	mu        sync.Mutex
+	phases    map[ProfilePhase]time.Duration
+	peakHeap  uint64
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// startProfiler starts timing a merge
+func startProfiler() *profiler {
+	p := &profiler{
+		start:  time.Now(),
+		phases: make(map[ProfilePhase]time.Duration),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	p.peakHeap, p.allocated = readHeap()
+	go p.sample()
+	return p
+}
+
+// sample records the heap size until the profiler is stopped
+func (p *profiler) sample() {
+	defer close(p.done)
+	ticker := time.NewTicker(profileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.recordHeap()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// recordHeap updates the peak heap size with the current one
+func (p *profiler) recordHeap() {
+	heap, _ := readHeap()
+	p.mu.Lock()
+	p.peakHeap = max(p.peakHeap, heap)
+	p.mu.Unlock()
+}
+
+// finish stops the profiler and returns the profile of the merge
+func (p *profiler) finish() *Profile {
+	close(p.stop)
+	<-p.done
+	p.recordHeap()
+	_, allocated := readHeap()
+	return &Profile{
+		Phases:    p.phases,
+		Total:     time.Since(p.start),
+		PeakHeap:  p.peakHeap,
+		Allocated: allocated - p.allocated,
+	}
+}
+
+// readHeap returns the size of the heap and the bytes allocated so far
+func readHeap() (heap, allocated uint64) {
+	samples := make([]metrics.Sample, len(heapMetrics))
+	for i, name := range heapMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		heap = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		allocated = samples[1].Value.Uint64()
+	}
+	return heap, allocated
+}
+
+// startProfile starts profiling a merge when Config.Profile is set and
+// returns the function recording its profile
+func (m *Merger) startProfile() func() {
+	m.profile = nil
+	if !m.config.Profile {
+		return func() {}
+	}
+	m.profiler = startProfiler()
+	return func() {
+		m.profile = m.profiler.finish()
+		m.profiler = nil
+	}
+}
+
+// phase starts timing a phase of the merge and returns the function
+// ending it. It does nothing unless the merge is profiled.
+func (m *Merger) phase(phase ProfilePhase) func() {
+	p := m.profiler
+	if p == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		p.mu.Lock()
+		p.phases[phase] += elapsed
+		p.mu.Unlock()
+		p.recordHeap()
+	}
+}
+
+// Profile returns the timings and memory use of the last Merge when
+// Config.Profile is set, or nil
+func (m *Merger) Profile() *Profile {
+	return m.profile
+}
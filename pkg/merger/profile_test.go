@@ -0,0 +1,53 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	file, err := createTempSwaggerFile(`swagger: "2.0"
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        200: {description: ok}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	output := filepath.Join(t.TempDir(), "merged.yaml")
+	m := New(Config{InputPaths: []string{file}, OutputPath: output, Profile: true})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	profile := m.Profile()
+	if profile == nil {
+		t.Fatal("Expected a profile")
+	}
+	for _, phase := range ProfilePhases {
+		if _, ok := profile.Phases[phase]; !ok {
+			t.Errorf("Expected the %s phase to be timed, got %v", phase, profile.Phases)
+		}
+		if profile.Phases[phase] > profile.Total {
+			t.Errorf("Expected the %s phase within the total of %v, got %v", phase, profile.Total, profile.Phases[phase])
+		}
+	}
+	if profile.PeakHeap == 0 || profile.Allocated == 0 {
+		t.Errorf("Expected memory use, got peak %d and allocated %d", profile.PeakHeap, profile.Allocated)
+	}
+
+	// Merges are only profiled when asked
+	m = New(Config{InputPaths: []string{file}, OutputPath: output})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if m.Profile() != nil {
+		t.Errorf("Expected no profile, got %+v", m.Profile())
+	}
+}
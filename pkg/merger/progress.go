@@ -0,0 +1,52 @@
+package merger
+
+// ProgressStage identifies the step a merge is currently performing
+type ProgressStage string
+
+const (
+	ProgressFetching   ProgressStage = "fetching"
+	ProgressParsing    ProgressStage = "parsing"
+	ProgressConverting ProgressStage = "converting"
+	ProgressMerging    ProgressStage = "merging"
+	ProgressWriting    ProgressStage = "writing"
+)
+
+// ProgressEvent describes a step of the merge. Per-file stages set Source
+// and Current (1-based); Total is the number of input files.
+type ProgressEvent struct {
+	Stage   ProgressStage
+	Source  string
+	Current int
+	Total   int
+}
+
+// ProgressFunc receives progress events. It is called synchronously from
+// the merge, never concurrently, so it should return quickly. Inputs
+// loaded in parallel report their stages interleaved.
+type ProgressFunc func(ProgressEvent)
+
+// ProgressChannel returns a ProgressFunc that sends events to ch. Events
+// are dropped when ch is full so a slow reader never stalls the merge.
+func ProgressChannel(ch chan<- ProgressEvent) ProgressFunc {
+	return func(event ProgressEvent) {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// progress reports a progress event if a callback is configured
+func (m *Merger) progress(stage ProgressStage, source string, current int) {
+	if m.config.Progress == nil {
+		return
+	}
+	m.progressMu.Lock()
+	defer m.progressMu.Unlock()
+	m.config.Progress(ProgressEvent{
+		Stage:   stage,
+		Source:  source,
+		Current: current,
+		Total:   len(m.inputs),
+	})
+}
@@ -0,0 +1,45 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgressEvents(t *testing.T) {
+	file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: Test API
+  version: 1.0.0
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	var stages []ProgressStage
+	m := New(Config{
+		InputPaths: []string{file},
+		OutputPath: filepath.Join(t.TempDir(), "merged.yaml"),
+		Progress: func(event ProgressEvent) {
+			if event.Total != 1 {
+				t.Errorf("Expected total 1, got %d", event.Total)
+			}
+			stages = append(stages, event.Stage)
+		},
+	})
+
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []ProgressStage{ProgressFetching, ProgressParsing, ProgressConverting, ProgressMerging, ProgressWriting}
+	if len(stages) != len(expected) {
+		t.Fatalf("Expected stages %v, got %v", expected, stages)
+	}
+	for i := range expected {
+		if stages[i] != expected[i] {
+			t.Errorf("Expected stage %s at %d, got %s", expected[i], i, stages[i])
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// defaultPromoteTemplate names promoted schemas, e.g. GetUserResponse
+	defaultPromoteTemplate = "{{.OperationID}}{{.Kind}}"
+	// defaultPromoteMinProperties is the smallest inline object promoted
+	defaultPromoteMinProperties = 3
+)
+
+// PromoteOptions configure PromoteInlineSchemas
+type PromoteOptions struct {
+	// NameTemplate is a text/template naming promoted schemas, with the
+	// fields OperationID (PascalCase), Method, Path, Status, MediaType and
+	// Kind (Request or Response) (default: {{.OperationID}}{{.Kind}}).
+	// Names already taken get the response status, then a number appended.
+	NameTemplate string
+	// MinProperties is the number of properties from which an inline object
+	// schema is promoted (default: 3)
+	MinProperties int
+}
+
+// promoteName holds the fields available to PromoteOptions.NameTemplate
+type promoteName struct {
+	OperationID string
+	Method      string
+	Path        string
+	Status      string
+	MediaType   string
+	Kind        string
+}
+
+// PromoteInlineSchemas returns a transformer that moves large inline
+// request and response body schemas into components.schemas, replacing
+// them with references
+func PromoteInlineSchemas(opts PromoteOptions) (Transformer, error) {
+	if opts.NameTemplate == "" {
+		opts.NameTemplate = defaultPromoteTemplate
+	}
+	if opts.MinProperties <= 0 {
+		opts.MinProperties = defaultPromoteMinProperties
+	}
+	tmpl, err := template.New("name").Parse(opts.NameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema name template: %v", err)
+	}
+
+	return NewTransformer("promote-schemas", func(doc *openapi3.T, source string) error {
+		p := &promoter{doc: doc, opts: opts, tmpl: tmpl, names: make(map[*openapi3.Schema]string)}
+		return p.run()
+	}), nil
+}
+
+// promoter promotes the inline schemas of one document
+type promoter struct {
+	doc   *openapi3.T
+	opts  PromoteOptions
+	tmpl  *template.Template
+	names map[*openapi3.Schema]string
+}
+
+func (p *promoter) run() error {
+	if p.doc.Components == nil {
+		p.doc.Components = &openapi3.Components{}
+	}
+	if p.doc.Components.Schemas == nil {
+		p.doc.Components.Schemas = openapi3.Schemas{}
+	}
+
+	paths := p.doc.Paths.Map()
+	for _, path := range sortedKeys(paths) {
+		ops := paths[path].Operations()
+		for _, method := range sortedKeys(ops) {
+			op := ops[method]
+			name := promoteName{OperationID: operationName(op, method, path), Method: method, Path: path}
+
+			if op.RequestBody != nil && op.RequestBody.Ref == "" && op.RequestBody.Value != nil {
+				name.Kind = "Request"
+				if err := p.promoteContent(op.RequestBody.Value.Content, name); err != nil {
+					return err
+				}
+			}
+
+			if op.Responses == nil {
+				continue
+			}
+			responses := op.Responses.Map()
+			for _, status := range sortedKeys(responses) {
+				resp := responses[status]
+				if resp.Ref != "" || resp.Value == nil {
+					continue
+				}
+				name.Kind, name.Status = "Response", status
+				if err := p.promoteContent(resp.Value.Content, name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// promoteContent promotes the schemas of a request or response body
+func (p *promoter) promoteContent(content openapi3.Content, name promoteName) error {
+	for _, mediaType := range sortedKeys(content) {
+		media := content[mediaType]
+		if media == nil || media.Schema == nil || media.Schema.Ref != "" || !p.large(media.Schema.Value) {
+			continue
+		}
+		name.MediaType = mediaType
+		ref, err := p.promote(media.Schema.Value, name)
+		if err != nil {
+			return err
+		}
+		media.Schema = ref
+	}
+	return nil
+}
+
+// large reports whether an inline schema is worth promoting
+func (p *promoter) large(schema *openapi3.Schema) bool {
+	return schema != nil && len(schema.Properties) >= p.opts.MinProperties
+}
+
+// promote adds schema to the components and returns a reference to it
+func (p *promoter) promote(schema *openapi3.Schema, name promoteName) (*openapi3.SchemaRef, error) {
+	if existing, ok := p.names[schema]; ok {
+		return openapi3.NewSchemaRef("#/components/schemas/"+existing, schema), nil
+	}
+
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, name); err != nil {
+		return nil, fmt.Errorf("failed to name schema of %s %s: %v", name.Method, name.Path, err)
+	}
+	base := buf.String()
+	if base == "" {
+		return nil, fmt.Errorf("empty schema name for %s %s", name.Method, name.Path)
+	}
+
+	schemas := p.doc.Components.Schemas
+	candidate := base
+	if _, taken := schemas[candidate]; taken && name.Status != "" {
+		candidate = strings.TrimSuffix(base, name.Kind) + name.Status + name.Kind
+	}
+	for i := 2; schemas[candidate] != nil; i++ {
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+
+	schemas[candidate] = openapi3.NewSchemaRef("", schema)
+	p.names[schema] = candidate
+	return openapi3.NewSchemaRef("#/components/schemas/"+candidate, schema), nil
+}
+
+// operationName returns the operationId of an operation in PascalCase, or
+// a name derived from its method and path
+func operationName(op *openapi3.Operation, method, path string) string {
+	id := op.OperationID
+	if id == "" {
+		id = strings.ToLower(method) + " " + path
+	}
+	return pascalCase(id)
+}
+
+// pascalCase joins the words of s, separated by anything but ASCII letters
+// and digits, capitalizing each. Component names only allow ASCII, so
+// accented letters split words too.
+func pascalCase(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !isASCIIAlnum(r) {
+			upper = true
+			continue
+		}
+		if upper && 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isASCIIAlnum reports whether r is an ASCII letter or digit
+func isASCIIAlnum(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package merger
+
+import (
+	"testing"
+)
+
+const promoteSpec = `openapi: "3.0.1"
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users/{id}:
+    get:
+      operationId: get_user
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties: {id: {type: string}, name: {type: string}, email: {type: string}}
+        "404":
+          description: Not found
+          content:
+            application/json:
+              schema:
+                type: object
+                properties: {code: {type: integer}, message: {type: string}, details: {type: string}}
+    put:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties: {name: {type: string}, email: {type: string}, phone: {type: string}}
+      responses:
+        "204":
+          description: Updated
+          content:
+            application/json:
+              schema:
+                type: object
+                properties: {ok: {type: boolean}}
+`
+
+func TestPromoteInlineSchemas(t *testing.T) {
+	doc := loadSpec(t, promoteSpec)
+
+	promote, err := PromoteInlineSchemas(PromoteOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := promote.Transform(doc, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	item := doc.Paths.Value("/users/{id}")
+	if ref := item.Get.Responses.Status(200).Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/GetUserResponse" {
+		t.Errorf("Unexpected 200 schema ref: %q", ref)
+	}
+	if ref := item.Get.Responses.Status(404).Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/GetUser404Response" {
+		t.Errorf("Unexpected 404 schema ref: %q", ref)
+	}
+	if ref := item.Put.RequestBody.Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/PutUsersIdRequest" {
+		t.Errorf("Unexpected request schema ref: %q", ref)
+	}
+	if ref := item.Put.Responses.Status(204).Value.Content["application/json"].Schema.Ref; ref != "" {
+		t.Errorf("Expected small schema to stay inline, got %q", ref)
+	}
+	if len(doc.Components.Schemas) != 3 {
+		t.Errorf("Expected 3 promoted schemas, got %v", schemaNames(doc))
+	}
+
+	if _, err := PromoteInlineSchemas(PromoteOptions{NameTemplate: "{{.Bad"}); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
+
+func TestPascalCase(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"get_user", "GetUser"},
+		{"put /users/{id}", "PutUsersId"},
+		{"I'm a teapot", "IMATeapot"},
+		{"créerUtilisateur", "CrErUtilisateur"},
+		{"获取用户", ""},
+		{"v2 list", "V2List"},
+	}
+	for _, tt := range tests {
+		if got := pascalCase(tt.in); got != tt.want {
+			t.Errorf("pascalCase(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
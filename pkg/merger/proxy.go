@@ -0,0 +1,253 @@
+package merger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// ProxyConfig configures a validating reverse proxy
+type ProxyConfig struct {
+	// Backends maps path prefixes to the URL of the backend serving them.
+	// Prefixes match whole path segments, so /users serves /users/1 but not
+	// /users-admin. The longest matching prefix wins; "/" catches every
+	// request.
+	Backends map[string]string
+	// OnViolation is called for every request or response not conforming
+	// to the spec. Violating exchanges are still forwarded.
+	OnViolation func(ProxyViolation)
+}
+
+// ProxyViolation is a request or response of a proxied exchange not
+// conforming to the spec
+type ProxyViolation struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Status is the response status, or 0 for request violations
+	Status int `json:"status,omitempty"`
+	// Pointer locates the mismatch in the body ("" for the body itself or
+	// other parts of the exchange)
+	Pointer string `json:"pointer,omitempty"`
+	Message string `json:"message"`
+}
+
+func (v ProxyViolation) String() string {
+	where := v.Method + " " + v.Path
+	if v.Status != 0 {
+		where += fmt.Sprintf(" response %d", v.Status)
+	} else {
+		where += " request"
+	}
+	if v.Pointer != "" {
+		where += " at " + v.Pointer
+	}
+	return where + ": " + v.Message
+}
+
+// proxyBackend is a backend of the proxy with its path prefix
+type proxyBackend struct {
+	prefix string
+	proxy  *httputil.ReverseProxy
+}
+
+// validatingProxy forwards requests to backends, validating the requests
+// and responses against a spec
+type validatingProxy struct {
+	router      routers.Router
+	backends    []proxyBackend
+	onViolation func(ProxyViolation)
+}
+
+// proxyExchangeKey is the context key of the validation input of a
+// proxied request, used to validate its response
+type proxyExchangeKey struct{}
+
+// NewValidatingProxy returns a reverse proxy forwarding requests to the
+// configured backends while validating requests and responses against
+// doc, turning the merged spec into a live contract check. Security
+// requirements are not checked, backends authenticate requests.
+func NewValidatingProxy(doc *openapi3.T, config ProxyConfig) (http.Handler, error) {
+	// Route on paths only: the servers of the spec are not the proxy's
+	routed := *doc
+	routed.Servers = nil
+	router, err := legacy.NewRouter(&routed, openapi3.DisableExamplesValidation())
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec: %v", err)
+	}
+
+	p := &validatingProxy{router: router, onViolation: config.OnViolation}
+	if p.onViolation == nil {
+		p.onViolation = func(ProxyViolation) {}
+	}
+	for prefix, backend := range config.Backends {
+		target, err := url.Parse(backend)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return nil, fmt.Errorf("invalid backend URL %q for %s", backend, prefix)
+		}
+		p.backends = append(p.backends, proxyBackend{prefix: prefix, proxy: &httputil.ReverseProxy{
+			Rewrite: func(r *httputil.ProxyRequest) {
+				r.SetURL(target)
+				r.SetXForwarded()
+				// Let the transport negotiate compression, so responses
+				// are decoded for validation
+				r.Out.Header.Del("Accept-Encoding")
+			},
+			ModifyResponse: p.validateResponse,
+		}})
+	}
+	if len(p.backends) == 0 {
+		return nil, fmt.Errorf("no backend configured")
+	}
+	sort.Slice(p.backends, func(i, j int) bool {
+		return len(p.backends[i].prefix) > len(p.backends[j].prefix)
+	})
+	return p, nil
+}
+
+// matchesPathPrefix reports whether path is prefix or below it, so /users
+// matches /users/1 but not /users-admin
+func matchesPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+func (p *validatingProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var backend *proxyBackend
+	for i := range p.backends {
+		if matchesPathPrefix(r.URL.Path, p.backends[i].prefix) {
+			backend = &p.backends[i]
+			break
+		}
+	}
+	if backend == nil {
+		http.Error(w, fmt.Sprintf("no backend for %s", r.URL.Path), http.StatusBadGateway)
+		return
+	}
+
+	violation := ProxyViolation{Method: r.Method, Path: r.URL.Path}
+	route, pathParams, err := p.router.FindRoute(r)
+	if err != nil {
+		violation.Message = "no operation of the spec matches the request"
+		p.onViolation(violation)
+		backend.proxy.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := readBody(&r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+		for _, v := range proxyViolations(violation, "", err) {
+			p.onViolation(v)
+		}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	backend.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), proxyExchangeKey{}, input)))
+}
+
+// validateResponse validates the response of a backend against the
+// operation of its request
+func (p *validatingProxy) validateResponse(resp *http.Response) error {
+	input, ok := resp.Request.Context().Value(proxyExchangeKey{}).(*openapi3filter.RequestValidationInput)
+	if !ok {
+		return nil
+	}
+	body, err := readBody(&resp.Body)
+	if err != nil {
+		return err
+	}
+	err = openapi3filter.ValidateResponse(resp.Request.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options:                input.Options,
+	})
+	if err != nil {
+		violation := ProxyViolation{Method: input.Request.Method, Path: input.Request.URL.Path, Status: resp.StatusCode}
+		for _, v := range proxyViolations(violation, "", err) {
+			p.onViolation(v)
+		}
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// readBody reads a request or response body, replacing it by a reader of
+// the content so it can be read again
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+// proxyViolations returns a violation based on v for every validation
+// error in err, with messages prefixed by where
+func proxyViolations(v ProxyViolation, where string, err error) []ProxyViolation {
+	prefixed := func(message string) string {
+		if where == "" {
+			return message
+		}
+		return where + ": " + message
+	}
+
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var violations []ProxyViolation
+		for _, err := range e {
+			violations = append(violations, proxyViolations(v, where, err)...)
+		}
+		return violations
+	case *openapi3filter.RequestError:
+		where = "request body"
+		if e.Parameter != nil {
+			where = fmt.Sprintf("parameter %q in %s", e.Parameter.Name, e.Parameter.In)
+		} else if e.RequestBody == nil {
+			where = "request"
+		}
+		if e.Err != nil {
+			return proxyViolations(v, where, e.Err)
+		}
+		v.Message = prefixed(e.Reason)
+	case *openapi3filter.ResponseError:
+		if e.Err != nil {
+			return proxyViolations(v, e.Reason, e.Err)
+		}
+		v.Message = e.Reason
+	case *openapi3.SchemaError:
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			v.Pointer = "/" + strings.Join(pointer, "/")
+		}
+		v.Message = prefixed(e.Reason)
+	default:
+		v.Message = prefixed(err.Error())
+	}
+	return []ProxyViolation{v}
+}
@@ -0,0 +1,110 @@
+package merger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidatingProxy(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /users/{id}:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [name]
+                properties:
+                  name: {type: string}
+`)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/users/2" {
+			io.WriteString(w, `{"name": 2}`)
+			return
+		}
+		io.WriteString(w, `{"name": "ada"}`)
+	}))
+	defer backend.Close()
+
+	var violations []string
+	proxy, err := NewValidatingProxy(doc, ProxyConfig{
+		Backends:    map[string]string{"/": backend.URL},
+		OnViolation: func(v ProxyViolation) { violations = append(violations, v.String()) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/abc", "/orders"} {
+		recorder := httptest.NewRecorder()
+		proxy.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "name") {
+			t.Errorf("GET %s: expected the backend response, got %d %s", path, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	expected := []string{
+		"GET /users/2 response 200 at /name: response body doesn't match schema: value must be a string",
+		`GET /users/abc request: parameter "id" in path: value abc: an invalid integer: invalid syntax`,
+		"GET /orders request: no operation of the spec matches the request",
+	}
+	if got := strings.Join(violations, "\n"); got != strings.Join(expected, "\n") {
+		t.Errorf("Expected violations:\n%s\ngot:\n%s", strings.Join(expected, "\n"), got)
+	}
+
+	if _, err := NewValidatingProxy(doc, ProxyConfig{}); err == nil {
+		t.Error("Expected an error without backends")
+	}
+}
+
+func TestValidatingProxyPrefixes(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths: {}
+`)
+	backend := func(name string) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, name)
+		}))
+		t.Cleanup(server.Close)
+		return server.URL
+	}
+	proxy, err := NewValidatingProxy(doc, ProxyConfig{Backends: map[string]string{
+		"/users":  backend("users"),
+		"/users-": backend("dash"),
+		"/":       backend("gateway"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]string{
+		"/users":         "users",
+		"/users/1":       "users",
+		"/users-admin/x": "gateway",
+		"/users-/x":      "dash",
+		"/usersx":        "gateway",
+		"/orders":        "gateway",
+	} {
+		recorder := httptest.NewRecorder()
+		proxy.ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if got := recorder.Body.String(); got != expected {
+			t.Errorf("GET %s: expected the %s backend, got %q", path, expected, got)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package merger
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter spaces out requests to each host so that at most burst
+// requests are sent at once and the rest follow at a fixed interval
+type hostLimiter struct {
+	interval time.Duration
+	burst    int
+	mu       sync.Mutex
+	// next holds, per host, the time the request after the reserved ones
+	// would be sent if the burst were exhausted
+	next map[string]time.Time
+}
+
+// newHostLimiter returns a limiter allowing rate requests per second to
+// each host, or nil when rate is not positive
+func newHostLimiter(rate float64, burst int) *hostLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &hostLimiter{
+		interval: time.Duration(float64(time.Second) / rate),
+		burst:    burst,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// reserve books the next request slot for host and returns how long the
+// caller must wait before sending it
+func (l *hostLimiter) reserve(host string, now time.Time) time.Duration {
+	host = strings.ToLower(host)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	next := l.next[host]
+	if next.Before(now) {
+		next = now
+	}
+	l.next[host] = next.Add(l.interval)
+	return next.Sub(now) - time.Duration(l.burst-1)*l.interval
+}
+
+// wait blocks until a request to host may be sent or ctx is done
+func (l *hostLimiter) wait(ctx context.Context, host string) (time.Duration, error) {
+	delay := l.reserve(host, time.Now())
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		return delay, context.Cause(ctx)
+	}
+}
+
+// throttle waits for the rate limit of the host of a remote input, when
+// HTTP.RateLimit is set. It fails with the cause of ctx when ctx is done
+// first.
+func (m *Merger) throttle(ctx context.Context, host string) error {
+	if m.limiter == nil {
+		return nil
+	}
+	delay, err := m.limiter.wait(ctx, host)
+	if delay > 0 {
+		m.config.Logger.Debug("rate limiting remote input", "host", host, "delay", delay)
+	}
+	return err
+}
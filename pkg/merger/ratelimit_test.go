@@ -0,0 +1,88 @@
+package merger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterReserve(t *testing.T) {
+	l := newHostLimiter(10, 2)
+	now := time.Now()
+	expected := []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond}
+	for i, want := range expected {
+		if got := max(l.reserve("api.example.com", now), 0); got != want {
+			t.Errorf("Request %d: expected a delay of %v, got %v", i+1, want, got)
+		}
+	}
+	// Hosts are limited independently, whatever their case
+	if got := l.reserve("other.example.com", now); got > 0 {
+		t.Errorf("Expected no delay for another host, got %v", got)
+	}
+	if got := l.reserve("API.example.com", now); got != 300*time.Millisecond {
+		t.Errorf("Expected the host to be matched case-insensitively, got %v", got)
+	}
+	// The burst refills while the host is idle
+	if got := l.reserve("api.example.com", now.Add(time.Second)); got > 0 {
+		t.Errorf("Expected no delay after an idle second, got %v", got)
+	}
+
+	if newHostLimiter(0, 5) != nil {
+		t.Error("Expected no limiter without a rate")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		fmt.Fprintf(w, "openapi: 3.0.0\ninfo: {title: %[1]s, version: \"1.0\"}\npaths:\n  %[1]s: {get: {responses: {\"200\": {description: ok}}}}\n", r.URL.Path)
+	}))
+	defer server.Close()
+
+	var inputs []string
+	for i := 0; i < 4; i++ {
+		inputs = append(inputs, fmt.Sprintf("%s/service%d", server.URL, i))
+	}
+	m := New(Config{InputPaths: inputs, Concurrency: 4, HTTP: HTTPConfig{RateLimit: 20}})
+	start := time.Now()
+	if _, err := m.loadAndMerge(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("Expected 4 requests at 20/s to take at least 150ms, took %v", elapsed)
+	}
+	if len(times) != len(inputs) {
+		t.Fatalf("Expected %d requests, got %d", len(inputs), len(times))
+	}
+
+	// Waiting for a slot counts towards the total fetch timeout
+	m = New(Config{InputPaths: inputs, HTTP: HTTPConfig{RateLimit: 1, TotalTimeout: 200 * time.Millisecond}})
+	if _, err := m.loadAndMerge(); err == nil || !strings.Contains(err.Error(), "total fetch timeout") {
+		t.Errorf("Expected the total fetch timeout to expire while rate limited, got %v", err)
+	}
+
+	// Cancelling the merge while rate limited reports the cancellation,
+	// not the total fetch timeout
+	m = New(Config{InputPaths: inputs, HTTP: HTTPConfig{RateLimit: 1, TotalTimeout: time.Minute}})
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(200*time.Millisecond, func() { cancel(errors.New("merge cancelled")) })
+	err := m.withContext(ctx, func() error {
+		_, err := m.loadAndMerge()
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "merge cancelled") || strings.Contains(err.Error(), "total fetch timeout") {
+		t.Errorf("Expected the cancellation cause, got %v", err)
+	}
+}
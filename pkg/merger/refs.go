@@ -0,0 +1,92 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// escapeRef encodes a JSON pointer token
+func escapeRef(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// renameComponents renames components of a kind ("schemas", "responses",
+// ...) and rewrites every reference to them. A component renamed to a name
+// that already exists is dropped in favor of the existing one.
+func renameComponents(doc *openapi3.T, kind string, renames map[string]string) error {
+	if len(renames) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %v", err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	if components, ok := root["components"].(map[string]any); ok {
+		if named, ok := components[kind].(map[string]any); ok {
+			moved := make(map[string]any)
+			for old, value := range named {
+				if name, ok := renames[old]; ok && name != old {
+					moved[name] = value
+					delete(named, old)
+				}
+			}
+			for name, value := range moved {
+				if _, exists := named[name]; !exists {
+					named[name] = value
+				}
+			}
+		}
+	}
+
+	prefixes := make(map[string]string, len(renames))
+	for old, name := range renames {
+		prefixes["#/components/"+kind+"/"+escapeRef(old)] = "#/components/" + kind + "/" + escapeRef(name)
+	}
+	rewriteRefValues(root, func(ref string) string {
+		for old, name := range prefixes {
+			if ref == old || strings.HasPrefix(ref, old+"/") {
+				return name + strings.TrimPrefix(ref, old)
+			}
+		}
+		return ref
+	})
+
+	data, err = json.Marshal(root)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %v", err)
+	}
+	renamed, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return fmt.Errorf("failed to reload document: %v", err)
+	}
+	*doc = *renamed
+	return nil
+}
+
+// rewriteRefValues replaces every $ref of a generic JSON value with the
+// result of fn
+func rewriteRefValues(v any, fn func(string) string) {
+	switch node := v.(type) {
+	case map[string]any:
+		for key, value := range node {
+			if ref, ok := value.(string); ok && key == "$ref" {
+				node[key] = fn(ref)
+				continue
+			}
+			rewriteRefValues(value, fn)
+		}
+	case []any:
+		for _, value := range node {
+			rewriteRefValues(value, fn)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package merger
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// renameKinds lists the component kinds that can be renamed. Security
+// schemes are referenced by name rather than $ref and are not supported.
+var renameKinds = map[string]bool{
+	"schemas":       true,
+	"responses":     true,
+	"parameters":    true,
+	"requestBodies": true,
+	"headers":       true,
+	"examples":      true,
+	"links":         true,
+	"callbacks":     true,
+}
+
+// Rename renames a component of the inputs matching Source
+type Rename struct {
+	// Source is an input path, its base name, or * for every input
+	Source string
+	// Kind is the component kind (default: schemas)
+	Kind string
+	// From is the name of the component in the input
+	From string
+	// To is the name of the component in the merged spec
+	To string
+}
+
+func (r Rename) String() string {
+	return fmt.Sprintf("%s:%s/%s -> %s", r.Source, r.Kind, r.From, r.To)
+}
+
+// matches reports whether the rename applies to an input
+func (r Rename) matches(source string) bool {
+	return r.Source == "*" || r.Source == source || r.Source == path.Base(filepath.ToSlash(source))
+}
+
+// ParseRenames parses a rename map in YAML or JSON. Each entry maps
+// source:OldName to NewName, either as an object:
+//
+//	users.yaml:Error: UserError
+//	orders.yaml:responses/NotFound: OrderNotFound
+//
+// or as a list of "source:OldName -> NewName" strings. Names without a
+// kind/ prefix are schemas.
+func ParseRenames(data []byte) ([]Rename, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	var pairs [][2]string
+	switch root := node.Content[0]; root.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			pairs = append(pairs, [2]string{root.Content[i].Value, root.Content[i+1].Value})
+		}
+	case yaml.SequenceNode:
+		var lines []string
+		if err := root.Decode(&lines); err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			from, to, ok := strings.Cut(line, "->")
+			if !ok {
+				return nil, fmt.Errorf("invalid rename %q (expected source:OldName -> NewName)", line)
+			}
+			pairs = append(pairs, [2]string{strings.TrimSpace(from), strings.TrimSpace(to)})
+		}
+	default:
+		return nil, fmt.Errorf("expected a map or a list of renames")
+	}
+
+	renames := make([]Rename, 0, len(pairs))
+	for _, pair := range pairs {
+		r, err := parseRename(pair[0], pair[1])
+		if err != nil {
+			return nil, err
+		}
+		renames = append(renames, r)
+	}
+	return renames, nil
+}
+
+// parseRename parses a source:[kind/]OldName key and its new name
+func parseRename(key, to string) (Rename, error) {
+	i := strings.LastIndex(key, ":")
+	if i <= 0 || i == len(key)-1 || to == "" {
+		return Rename{}, fmt.Errorf("invalid rename %q (expected source:OldName -> NewName)", key)
+	}
+	r := Rename{Source: key[:i], Kind: "schemas", From: key[i+1:], To: to}
+	if kind, name, ok := strings.Cut(r.From, "/"); ok {
+		if !renameKinds[kind] {
+			return Rename{}, fmt.Errorf("invalid rename %q: unsupported component kind %s", key, kind)
+		}
+		r.Kind, r.From = kind, name
+	}
+	return r, nil
+}
+
+// RenameComponents returns an input transformer renaming components of
+// the inputs according to renames and rewriting every reference to them,
+// so collisions can be resolved explicitly before merging. Renaming a
+// component onto a name the input already uses is an error.
+func RenameComponents(renames []Rename) Transformer {
+	return NewTransformer("rename", func(doc *openapi3.T, source string) error {
+		byKind := make(map[string]map[string]string)
+		for _, r := range renames {
+			if !r.matches(source) || !hasComponent(doc, r.Kind, r.From) {
+				continue
+			}
+			if hasComponent(doc, r.Kind, r.To) {
+				return fmt.Errorf("cannot rename %s: %s already exists", r, r.To)
+			}
+			if byKind[r.Kind] == nil {
+				byKind[r.Kind] = make(map[string]string)
+			}
+			byKind[r.Kind][r.From] = r.To
+		}
+		for _, kind := range sortedKeys(byKind) {
+			if err := renameComponents(doc, kind, byKind[kind]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// hasComponent reports whether doc defines a component
+func hasComponent(doc *openapi3.T, kind, name string) bool {
+	c := doc.Components
+	if c == nil {
+		return false
+	}
+	var ok bool
+	switch kind {
+	case "schemas":
+		_, ok = c.Schemas[name]
+	case "responses":
+		_, ok = c.Responses[name]
+	case "parameters":
+		_, ok = c.Parameters[name]
+	case "requestBodies":
+		_, ok = c.RequestBodies[name]
+	case "headers":
+		_, ok = c.Headers[name]
+	case "examples":
+		_, ok = c.Examples[name]
+	case "links":
+		_, ok = c.Links[name]
+	case "callbacks":
+		_, ok = c.Callbacks[name]
+	}
+	return ok
+}
+
+// RenameAliases returns a merged-stage transformer keeping the old name of
+// every renamed schema as a deprecated alias of the new one, so consumers
+// of the merged spec can migrate gradually. No alias is added when the
+// merged spec still uses the old name, e.g. for another input's schema.
+func RenameAliases(renames []Rename) Transformer {
+	return NewTransformer("rename-aliases", func(doc *openapi3.T, source string) error {
+		if doc.Components == nil {
+			return nil
+		}
+		for _, r := range renames {
+			if r.Kind != "schemas" || !hasComponent(doc, r.Kind, r.To) || hasComponent(doc, r.Kind, r.From) {
+				continue
+			}
+			alias := &openapi3.Schema{
+				AllOf:       openapi3.SchemaRefs{openapi3.NewSchemaRef("#/components/schemas/"+r.To, doc.Components.Schemas[r.To].Value)},
+				Deprecated:  true,
+				Description: fmt.Sprintf("Deprecated: renamed to %s.", r.To),
+			}
+			doc.Components.Schemas[r.From] = openapi3.NewSchemaRef("", alias)
+		}
+		return nil
+	})
+}
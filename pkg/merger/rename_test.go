@@ -0,0 +1,132 @@
+package merger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestParseRenames(t *testing.T) {
+	for _, data := range []string{
+		"users.yaml:Error: UserError\nhttps://example.com/orders.yaml:responses/NotFound: OrderNotFound\n",
+		"- users.yaml:Error -> UserError\n- https://example.com/orders.yaml:responses/NotFound -> OrderNotFound\n",
+	} {
+		renames, err := ParseRenames([]byte(data))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		want := []Rename{
+			{Source: "users.yaml", Kind: "schemas", From: "Error", To: "UserError"},
+			{Source: "https://example.com/orders.yaml", Kind: "responses", From: "NotFound", To: "OrderNotFound"},
+		}
+		if len(renames) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, renames)
+		}
+		for i := range want {
+			if renames[i] != want[i] {
+				t.Errorf("Expected %v, got %v", want[i], renames[i])
+			}
+		}
+	}
+
+	for _, data := range []string{"Error: UserError", "- users.yaml:Error", "users.yaml:securitySchemes/Key: Other"} {
+		if _, err := ParseRenames([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
+
+func TestRenameComponents(t *testing.T) {
+	load := func() *openapi3.T {
+		return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    get:
+      responses:
+        "404":
+          description: not found
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Error'}
+components:
+  schemas:
+    Error:
+      type: object
+      properties:
+        message: {type: string}
+    Errors:
+      type: array
+      items: {$ref: '#/components/schemas/Error'}
+`)
+	}
+
+	rename := RenameComponents([]Rename{{Source: "users.yaml", Kind: "schemas", From: "Error", To: "UserError"}})
+
+	doc := load()
+	if err := rename.Transform(doc, "specs/users.yaml"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := schemaNames(doc); strings.Join(got, ",") != "Errors,UserError" {
+		t.Errorf("Expected Error to be renamed, got %v", got)
+	}
+	if ref := doc.Components.Schemas["Errors"].Value.Items.Ref; ref != "#/components/schemas/UserError" {
+		t.Errorf("Expected items to reference UserError, got %s", ref)
+	}
+	if ref := doc.Paths.Find("/users").Get.Responses.Status(404).Value.Content.Get("application/json").Schema.Ref; ref != "#/components/schemas/UserError" {
+		t.Errorf("Expected the response to reference UserError, got %s", ref)
+	}
+
+	doc = load()
+	if err := rename.Transform(doc, "orders.yaml"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := schemaNames(doc); strings.Join(got, ",") != "Error,Errors" {
+		t.Errorf("Expected other inputs to be left alone, got %v", got)
+	}
+
+	doc = load()
+	taken := RenameComponents([]Rename{{Source: "*", Kind: "schemas", From: "Error", To: "Errors"}})
+	if err := taken.Transform(doc, "users.yaml"); err == nil {
+		t.Error("Expected renaming onto an existing name to fail")
+	}
+}
+
+func TestRenameAliases(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths: {}
+components:
+  schemas:
+    Error: {type: object}
+    UserError: {type: object}
+    OrderError: {type: object}
+`)
+
+	err := RenameAliases([]Rename{
+		{Source: "users.yaml", Kind: "schemas", From: "UserFailure", To: "UserError"},
+		{Source: "orders.yaml", Kind: "schemas", From: "Error", To: "OrderError"},
+	}).Transform(doc, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	alias := doc.Components.Schemas["UserFailure"]
+	if alias == nil || !alias.Value.Deprecated || len(alias.Value.AllOf) != 1 || alias.Value.AllOf[0].Ref != "#/components/schemas/UserError" {
+		t.Fatalf("Expected a deprecated alias of UserError, got %+v", alias)
+	}
+	if doc.Components.Schemas["Error"].Value.Deprecated {
+		t.Error("Expected the existing Error schema to be kept")
+	}
+
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"UserFailure":{"allOf":[{"$ref":"#/components/schemas/UserError"}],"deprecated":true`) {
+		t.Errorf("Unexpected alias serialization: %s", data)
+	}
+}
@@ -0,0 +1,212 @@
+package merger
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// Collision is a path or schema that an input defines differently from a
+// previous input, submitted to a ConflictResolver before merging
+type Collision struct {
+	// Kind is "path" or "schema"
+	Kind string `json:"kind"`
+	// Name is the path or schema name
+	Name string `json:"name"`
+	// Sources lists the input that defined the name first and the input
+	// that redefines it
+	Sources []string `json:"sources"`
+	// Existing and Incoming are the path items or schemas of both inputs
+	Existing any `json:"existing"`
+	Incoming any `json:"incoming"`
+	// Diffs lists the differences between the two definitions
+	Diffs []SchemaDiff `json:"diffs"`
+}
+
+// Resolution is the decision on a collision. The zero Resolution leaves
+// the collision to the merge, which reports it as a conflict.
+type Resolution struct {
+	// Kind and Name identify the collision in resolution files
+	Kind string `json:"kind" yaml:"kind"`
+	Name string `json:"name" yaml:"name"`
+	// Keep is the input whose definition wins, one of the sources of the
+	// collision or its base name
+	Keep string `json:"keep,omitempty" yaml:"keep,omitempty"`
+	// Rename renames the schema of the redefining input, rewriting its
+	// references, so both definitions are kept
+	Rename string `json:"rename,omitempty" yaml:"rename,omitempty"`
+}
+
+// decided reports whether the resolution settles the collision
+func (r Resolution) decided() bool {
+	return r.Keep != "" || r.Rename != ""
+}
+
+// keeps reports whether the resolution keeps the definition of source
+func (r Resolution) keeps(source string) bool {
+	return r.Keep == source || r.Keep == path.Base(filepath.ToSlash(source))
+}
+
+// ConflictResolver decides collisions before they are merged
+type ConflictResolver func(Collision) (Resolution, error)
+
+// WithConflictResolver submits every path or schema collision to fn
+// before merging it. Collisions fn resolves are not reported as
+// conflicts.
+func WithConflictResolver(fn ConflictResolver) Option {
+	return func(o *mergeOptions) {
+		o.resolver = fn
+	}
+}
+
+// SavedResolutions returns a ConflictResolver applying the decisions of a
+// resolution file, leaving other collisions undecided
+func SavedResolutions(resolutions []Resolution) ConflictResolver {
+	return func(c Collision) (Resolution, error) {
+		for _, r := range resolutions {
+			if r.Kind != c.Kind || r.Name != c.Name {
+				continue
+			}
+			if r.Rename != "" || slices.ContainsFunc(c.Sources, r.keeps) {
+				return r, nil
+			}
+		}
+		return Resolution{}, nil
+	}
+}
+
+// ParseResolutions parses a resolution file, a YAML or JSON list of
+// resolutions:
+//
+//	# resolutions.yaml
+//	- kind: schema
+//	  name: Error
+//	  keep: users.yaml
+//	- kind: path
+//	  name: /health
+//	  keep: orders.yaml
+//	- kind: schema
+//	  name: Order
+//	  rename: LegacyOrder
+func ParseResolutions(data []byte) ([]Resolution, error) {
+	var resolutions []Resolution
+	if err := yaml.Unmarshal(data, &resolutions); err != nil {
+		return nil, err
+	}
+	for _, r := range resolutions {
+		switch {
+		case r.Kind != "path" && r.Kind != "schema":
+			return nil, fmt.Errorf("invalid resolution of %q: kind must be path or schema", r.Name)
+		case r.Name == "":
+			return nil, fmt.Errorf("invalid %s resolution: name is required", r.Kind)
+		case r.Keep == "" && r.Rename == "", r.Keep != "" && r.Rename != "":
+			return nil, fmt.Errorf("invalid resolution of %s %s: expected either keep or rename", r.Kind, r.Name)
+		case r.Kind == "path" && r.Rename != "":
+			return nil, fmt.Errorf("invalid resolution of path %s: paths cannot be renamed", r.Name)
+		}
+	}
+	return resolutions, nil
+}
+
+// MarshalResolutions encodes resolutions as a resolution file
+func MarshalResolutions(resolutions []Resolution) ([]byte, error) {
+	return yaml.Marshal(resolutions)
+}
+
+// resolveCollisions submits the paths and schemas doc defines differently
+// from merged to the resolver, and applies its decisions before doc is
+// merged: the losing definition is dropped, or the schema of doc renamed
+func resolveCollisions(st *mergeState, merged, doc *openapi3.T, source string) error {
+	if st.opts.resolver == nil {
+		return nil
+	}
+
+	if doc.Paths != nil && merged.Paths != nil {
+		for _, name := range sortedKeys(doc.Paths.Map()) {
+			existing, incoming := merged.Paths.Value(name), doc.Paths.Value(name)
+			if existing == nil || equalJSON(existing, incoming) {
+				continue
+			}
+			r, err := st.resolve("path", name, source, existing, incoming)
+			if err != nil {
+				return err
+			}
+			switch {
+			case r.Rename != "":
+				return fmt.Errorf("cannot resolve path %s: paths cannot be renamed", name)
+			case r.keeps(source):
+				merged.Paths.Delete(name)
+			case r.decided():
+				doc.Paths.Delete(name)
+			}
+		}
+	}
+
+	if doc.Components == nil || merged.Components == nil {
+		return nil
+	}
+	// Renaming a schema rewrites the schemas referencing it, which may
+	// then collide, so collisions are searched again after every rename
+	submitted := make(map[string]bool)
+	for renamed := true; renamed; {
+		renamed = false
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			existing, incoming := merged.Components.Schemas[name], doc.Components.Schemas[name]
+			if submitted[name] || existing == nil || equalJSON(existing, incoming) {
+				continue
+			}
+			submitted[name] = true
+			r, err := st.resolve("schema", name, source, existing, incoming)
+			if err != nil {
+				return err
+			}
+			switch {
+			case r.Rename != "":
+				if hasComponent(doc, "schemas", r.Rename) || merged.Components.Schemas[r.Rename] != nil {
+					return fmt.Errorf("cannot rename schema %s of %s: %s already exists", name, source, r.Rename)
+				}
+				if err := renameComponents(doc, "schemas", map[string]string{name: r.Rename}); err != nil {
+					return fmt.Errorf("failed to rename schema %s of %s: %v", name, source, err)
+				}
+				submitted[r.Rename] = true
+				renamed = true
+			case r.keeps(source):
+				delete(merged.Components.Schemas, name)
+			case r.decided():
+				delete(doc.Components.Schemas, name)
+			}
+			if renamed {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// resolve submits a collision to the resolver
+func (st *mergeState) resolve(kind, name, source string, existing, incoming any) (Resolution, error) {
+	c := Collision{
+		Kind:     kind,
+		Name:     name,
+		Sources:  []string{st.owner(kind, name), source},
+		Existing: existing,
+		Incoming: incoming,
+	}
+	diffs, err := diffValues(existing, incoming, nil)
+	if err != nil {
+		return Resolution{}, fmt.Errorf("failed to compare %s %s: %v", kind, name, err)
+	}
+	c.Diffs = diffs
+	r, err := st.opts.resolver(c)
+	if err != nil {
+		return Resolution{}, fmt.Errorf("failed to resolve %s %s: %v", kind, name, err)
+	}
+	if r.Keep != "" && !slices.ContainsFunc(c.Sources, r.keeps) {
+		return Resolution{}, fmt.Errorf("cannot resolve %s %s: %s is not one of %v", kind, name, r.Keep, c.Sources)
+	}
+	return r, nil
+}
@@ -0,0 +1,125 @@
+package merger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestMergeDocumentsConflictResolver(t *testing.T) {
+	healthDoc := func(description string) *openapi3.T {
+		return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /health:
+    get:
+      responses:
+        "200": {description: `+description+`}
+`)
+	}
+	merge := func(resolver ConflictResolver, docs ...*openapi3.T) (*openapi3.T, []Collision, []Conflict) {
+		var collisions []Collision
+		var conflicts []Conflict
+		merged, err := MergeDocuments(docs,
+			WithSourceNames("specs/a.yaml", "specs/b.yaml"),
+			WithConflictResolver(func(c Collision) (Resolution, error) {
+				collisions = append(collisions, c)
+				return resolver(c)
+			}),
+			WithConflictHandler(func(c Conflict) { conflicts = append(conflicts, c) }),
+		)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return merged, collisions, conflicts
+	}
+
+	// Keeping the first input drops the redefinition, without a conflict
+	merged, collisions, conflicts := merge(SavedResolutions([]Resolution{{Kind: "path", Name: "/health", Keep: "a.yaml"}}),
+		healthDoc("up"), healthDoc("ok"))
+	if len(collisions) != 1 || collisions[0].Kind != "path" || !reflect.DeepEqual(collisions[0].Sources, []string{"specs/a.yaml", "specs/b.yaml"}) {
+		t.Fatalf("Expected the /health collision, got %+v", collisions)
+	}
+	if len(collisions[0].Diffs) == 0 {
+		t.Error("Expected the differences of the collision")
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %v", conflicts)
+	}
+	if got := *merged.Paths.Value("/health").Get.Responses.Value("200").Value.Description; got != "up" {
+		t.Errorf("Expected the first /health, got %q", got)
+	}
+
+	// Undecided collisions are merged as usual
+	merged, _, conflicts = merge(SavedResolutions(nil), healthDoc("up"), healthDoc("ok"))
+	if len(conflicts) != 1 {
+		t.Errorf("Expected the conflict, got %v", conflicts)
+	}
+	if got := *merged.Paths.Value("/health").Get.Responses.Value("200").Value.Description; got != "ok" {
+		t.Errorf("Expected the last /health, got %q", got)
+	}
+
+	// Renaming keeps both schemas and rewrites the references of the
+	// input, which makes the referencing Order schemas collide
+	merged, collisions, conflicts = merge(SavedResolutions([]Resolution{
+		{Kind: "schema", Name: "User", Rename: "LegacyUser"},
+		{Kind: "schema", Name: "Order", Keep: "specs/b.yaml"},
+	}), userDoc(t, "/a", "string"), userDoc(t, "/b", "integer"))
+	if len(collisions) != 2 || collisions[0].Name != "User" || collisions[1].Name != "Order" || len(conflicts) != 0 {
+		t.Fatalf("Expected the User and Order collisions only, got %+v and %v", collisions, conflicts)
+	}
+	if ref := merged.Components.Schemas["Order"].Value.Properties["buyer"].Ref; ref != "#/components/schemas/LegacyUser" {
+		t.Errorf("Expected the Order of b.yaml, got a buyer of %s", ref)
+	}
+	schemas := merged.Components.Schemas
+	if !schemas["User"].Value.Properties["id"].Value.Type.Is("string") || schemas["LegacyUser"] == nil {
+		t.Fatalf("Expected User and LegacyUser, got %v", sortedKeys(schemas))
+	}
+	if ref := merged.Paths.Value("/b").Get.Responses.Value("200").Value.Content["application/json"].Schema.Ref; ref != "#/components/schemas/LegacyUser" {
+		t.Errorf("Expected /b to reference LegacyUser, got %s", ref)
+	}
+
+	// Keeping an input that is not part of the collision fails
+	_, err := MergeDocuments([]*openapi3.T{healthDoc("up"), healthDoc("ok")},
+		WithSourceNames("a.yaml", "b.yaml"),
+		WithConflictResolver(func(c Collision) (Resolution, error) {
+			return Resolution{Kind: c.Kind, Name: c.Name, Keep: "c.yaml"}, nil
+		}),
+	)
+	if err == nil {
+		t.Error("Expected an error for an unknown input")
+	}
+}
+
+func TestParseResolutions(t *testing.T) {
+	resolutions, err := ParseResolutions([]byte(`
+- kind: schema
+  name: Error
+  keep: users.yaml
+- {kind: schema, name: Order, rename: LegacyOrder}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalResolutions(resolutions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseResolutions(data)
+	if err != nil || !reflect.DeepEqual(parsed, resolutions) || len(parsed) != 2 {
+		t.Errorf("Expected the resolutions to round-trip, got %+v (%v)", parsed, err)
+	}
+
+	for _, invalid := range []string{
+		`[{kind: operation, name: x, keep: a.yaml}]`,
+		`[{kind: path, name: /x}]`,
+		`[{kind: path, name: /x, rename: /y}]`,
+		`[{kind: schema, name: X, keep: a.yaml, rename: Y}]`,
+	} {
+		if _, err := ParseResolutions([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
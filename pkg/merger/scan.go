@@ -0,0 +1,207 @@
+package merger
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultPatterns select the files merged from directories and archives
+var defaultPatterns = []string{"*.yaml"}
+
+// ScanOptions control how directories and archives are scanned for specs
+type ScanOptions struct {
+	// Patterns select files by base name (default: *.yaml). Patterns
+	// containing a slash match the path relative to the scanned directory
+	// or archive instead; ** matches any number of directories and {a,b}
+	// matches alternatives, e.g. services/**/openapi.{yaml,yml,json}.
+	Patterns []string
+	// IgnoreFile names the files, in gitignore syntax, listing paths
+	// excluded from directory scanning (default: .swaggerignore). Each
+	// file applies to its directory and below; "-" disables them.
+	IgnoreFile string
+	// MaxDepth limits how deep directories are scanned: 1 only scans the
+	// top level, 2 also its subdirectories, and so on. 0 means no limit.
+	MaxDepth int
+	// Symlinks controls how symbolic links are handled (default:
+	// SymlinkFiles)
+	Symlinks SymlinkMode
+}
+
+// SymlinkMode controls how directory scanning handles symbolic links
+type SymlinkMode string
+
+const (
+	// SymlinkFiles follows links to files but not to directories
+	SymlinkFiles SymlinkMode = "files"
+	// SymlinkFollow follows links to files and directories, visiting each
+	// real file and directory once so link loops terminate
+	SymlinkFollow SymlinkMode = "follow"
+	// SymlinkSkip ignores symbolic links
+	SymlinkSkip SymlinkMode = "skip"
+)
+
+// symlinks returns the configured symlink mode or the default one
+func (o ScanOptions) symlinks() SymlinkMode {
+	if o.Symlinks == "" {
+		return SymlinkFiles
+	}
+	return o.Symlinks
+}
+
+// ignoreFile returns the name of the ignore files, or "" if disabled
+func (o ScanOptions) ignoreFile() string {
+	switch o.IgnoreFile {
+	case "":
+		return defaultIgnoreFile
+	case "-":
+		return ""
+	}
+	return o.IgnoreFile
+}
+
+// patterns returns the configured patterns or the default ones
+func (o ScanOptions) patterns() []string {
+	if len(o.Patterns) == 0 {
+		return defaultPatterns
+	}
+	return o.Patterns
+}
+
+// validate checks that every pattern is well-formed
+func (o ScanOptions) validate() error {
+	for _, pattern := range o.patterns() {
+		if err := validGlob(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// match reports whether a slash-separated path, relative to the scanned
+// directory or archive, matches any of the patterns
+func (o ScanOptions) match(rel string) bool {
+	for _, pattern := range o.patterns() {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanDirectory returns the files below dir matching the patterns
+func scanDirectory(dir string, opts ScanOptions) ([]string, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	switch opts.symlinks() {
+	case SymlinkFiles, SymlinkFollow, SymlinkSkip:
+	default:
+		return nil, fmt.Errorf("invalid symlink mode %q (expected files, follow or skip)", opts.Symlinks)
+	}
+
+	s := &dirScanner{
+		root:    dir,
+		opts:    opts,
+		ignore:  &ignorer{name: opts.ignoreFile()},
+		visited: make(map[string]bool),
+	}
+	if err := s.walk(dir, "", 0); err != nil {
+		return nil, err
+	}
+	return s.files, nil
+}
+
+// dirScanner walks a directory input
+type dirScanner struct {
+	root   string
+	opts   ScanOptions
+	ignore *ignorer
+	files  []string
+	// visited holds the real paths of the directories and files seen when
+	// following symlinks, to break loops and skip duplicates
+	visited map[string]bool
+}
+
+// walk scans dir, whose slash-separated path relative to the root is rel
+func (s *dirScanner) walk(dir, rel string, depth int) error {
+	follow := s.opts.symlinks() == SymlinkFollow
+	if follow && s.seen(dir) {
+		return nil
+	}
+	if err := s.ignore.load(s.root, dir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		p := filepath.Join(dir, entry.Name())
+		r := path.Join(rel, entry.Name())
+
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if s.opts.symlinks() == SymlinkSkip {
+				continue
+			}
+			info, err := os.Stat(p)
+			if err != nil {
+				// Dangling link
+				continue
+			}
+			if info.IsDir() && !follow {
+				continue
+			}
+			isDir = info.IsDir()
+		}
+
+		if s.ignore.ignored(r, isDir) {
+			continue
+		}
+		if isDir {
+			if s.opts.MaxDepth > 0 && depth+1 >= s.opts.MaxDepth {
+				continue
+			}
+			if err := s.walk(p, r, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		if s.opts.match(r) && !(follow && s.seen(p)) {
+			s.files = append(s.files, p)
+		}
+	}
+	return nil
+}
+
+// seen reports whether the real path of p was already visited, and marks
+// it visited
+func (s *dirScanner) seen(p string) bool {
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		real = p
+	}
+	if s.visited[real] {
+		return true
+	}
+	s.visited[real] = true
+	return false
+}
+
+// isLocalDirectory checks if a path is a directory on the local filesystem
+func isLocalDirectory(path string) bool {
+	if IsRemoteSource(path) {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,71 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestScanDirectoryMaxDepth(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "one/b.yaml", "one/two/c.yaml", "one/two/three/d.yaml"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(testSpec), 0644)
+	}
+
+	for depth, want := range map[int]int{0: 4, 1: 1, 2: 2, 3: 3, 10: 4} {
+		files, err := scanDirectory(dir, ScanOptions{MaxDepth: depth})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(files) != want {
+			t.Errorf("MaxDepth %d: expected %d files, got %v", depth, want, files)
+		}
+	}
+}
+
+func TestScanDirectorySymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+
+	shared := t.TempDir()
+	os.WriteFile(filepath.Join(shared, "shared.yaml"), []byte(testSpec), 0644)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "users.yaml"), []byte(testSpec), 0644)
+	os.Symlink(filepath.Join(dir, "users.yaml"), filepath.Join(dir, "alias.yaml"))
+	os.Symlink(shared, filepath.Join(dir, "shared"))
+	os.Symlink(dir, filepath.Join(dir, "loop"))
+	os.Symlink(filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "dangling.yaml"))
+
+	tests := []struct {
+		mode SymlinkMode
+		want []string
+	}{
+		{"", []string{"alias.yaml", "users.yaml"}},
+		{SymlinkSkip, []string{"users.yaml"}},
+		{SymlinkFollow, []string{"alias.yaml", "shared/shared.yaml"}},
+	}
+	for _, tt := range tests {
+		files, err := scanDirectory(dir, ScanOptions{Symlinks: tt.mode})
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.mode, err)
+		}
+		var rel []string
+		for _, f := range files {
+			r, _ := filepath.Rel(dir, f)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		if !reflect.DeepEqual(rel, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.mode, tt.want, rel)
+		}
+	}
+
+	if _, err := scanDirectory(dir, ScanOptions{Symlinks: "always"}); err == nil {
+		t.Error("Expected error for invalid symlink mode")
+	}
+}
@@ -0,0 +1,281 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ParseSecurityScheme parses a YAML or JSON OpenAPI security scheme object
+func ParseSecurityScheme(data []byte) (*openapi3.SecurityScheme, error) {
+	jsonBytes, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	scheme := &openapi3.SecurityScheme{}
+	if err := json.Unmarshal(jsonBytes, scheme); err != nil {
+		return nil, fmt.Errorf("expected a security scheme: %v", err)
+	}
+	if err := scheme.Validate(openapi3.NewLoader().Context); err != nil {
+		return nil, fmt.Errorf("invalid security scheme: %v", err)
+	}
+	return scheme, nil
+}
+
+// GlobalSecurity returns a transformer applying one security scheme to the
+// whole merged document, as gateways often enforce auth uniformly: scheme
+// is registered as components.securitySchemes[name] and the document-level
+// security requirement becomes name with scopes. A nil scheme uses the
+// definition the document already has, or an HTTP bearer scheme for JWTs
+// if there is none. Operations declaring their own security keep it.
+func GlobalSecurity(name string, scheme *openapi3.SecurityScheme, scopes ...string) Transformer {
+	return NewTransformer("global-security", func(doc *openapi3.T, source string) error {
+		if doc.Components == nil {
+			doc.Components = &openapi3.Components{}
+		}
+		if doc.Components.SecuritySchemes == nil {
+			doc.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+		}
+		if scheme != nil {
+			doc.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: scheme}
+		} else if doc.Components.SecuritySchemes[name] == nil {
+			doc.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme()}
+		}
+
+		if scopes == nil {
+			scopes = []string{}
+		}
+		doc.Security = openapi3.SecurityRequirements{openapi3.SecurityRequirement{name: scopes}}
+		return nil
+	})
+}
+
+// mergeSecuritySchemes copies the security schemes of src into dst. OAuth2
+// schemes defined by several inputs are merged: their flows are combined
+// and the scopes of each flow unioned, failing when the inputs disagree on
+// the URLs of a flow. Other schemes are overwritten like components.
+func mergeSecuritySchemes(st *mergeState, dst, src openapi3.SecuritySchemes, source string) error {
+	for _, name := range sortedKeys(src) {
+		scheme := src[name]
+		existing, ok := dst[name]
+		if ok && !equalJSON(existing, scheme) {
+			if isOAuth2(existing) && isOAuth2(scheme) {
+				flows, err := unionFlows(existing.Value.Flows, scheme.Value.Flows)
+				if err != nil {
+					return fmt.Errorf("cannot merge OAuth2 scheme %s from %s with %s: %v", name, source, st.owner("securityScheme", name), err)
+				}
+				union := *existing.Value
+				union.Flows = flows
+				scheme = &openapi3.SecuritySchemeRef{Value: &union}
+			} else {
+				st.conflict("securityScheme", name, source)
+			}
+		}
+		dst[name] = scheme
+		st.own("securityScheme", name, source)
+	}
+	return nil
+}
+
+// isOAuth2 reports whether a scheme is an inline OAuth2 scheme
+func isOAuth2(scheme *openapi3.SecuritySchemeRef) bool {
+	return scheme != nil && scheme.Ref == "" && scheme.Value != nil && scheme.Value.Type == "oauth2"
+}
+
+// unionFlows combines the flows of two OAuth2 schemes
+func unionFlows(a, b *openapi3.OAuthFlows) (*openapi3.OAuthFlows, error) {
+	if a == nil {
+		return b, nil
+	}
+	if b == nil {
+		return a, nil
+	}
+	union := *a
+	for _, flow := range []struct {
+		name string
+		dst  **openapi3.OAuthFlow
+		a, b *openapi3.OAuthFlow
+	}{
+		{"implicit", &union.Implicit, a.Implicit, b.Implicit},
+		{"password", &union.Password, a.Password, b.Password},
+		{"clientCredentials", &union.ClientCredentials, a.ClientCredentials, b.ClientCredentials},
+		{"authorizationCode", &union.AuthorizationCode, a.AuthorizationCode, b.AuthorizationCode},
+	} {
+		merged, err := unionFlow(flow.a, flow.b)
+		if err != nil {
+			return nil, fmt.Errorf("%s flow: %v", flow.name, err)
+		}
+		*flow.dst = merged
+	}
+	return &union, nil
+}
+
+// unionFlow combines two definitions of the same OAuth2 flow, unioning
+// their scopes
+func unionFlow(a, b *openapi3.OAuthFlow) (*openapi3.OAuthFlow, error) {
+	if a == nil {
+		return b, nil
+	}
+	if b == nil {
+		return a, nil
+	}
+	union := *a
+	for _, u := range []struct {
+		name string
+		dst  *string
+		b    string
+	}{
+		{"authorizationUrl", &union.AuthorizationURL, b.AuthorizationURL},
+		{"tokenUrl", &union.TokenURL, b.TokenURL},
+		{"refreshUrl", &union.RefreshURL, b.RefreshURL},
+	} {
+		switch {
+		case *u.dst == "":
+			*u.dst = u.b
+		case u.b != "" && u.b != *u.dst:
+			return nil, fmt.Errorf("conflicting %s %s and %s", u.name, *u.dst, u.b)
+		}
+	}
+	union.Scopes = make(openapi3.StringMap, len(a.Scopes)+len(b.Scopes))
+	for scope, description := range b.Scopes {
+		union.Scopes[scope] = description
+	}
+	for scope, description := range a.Scopes {
+		union.Scopes[scope] = description
+	}
+	return &union, nil
+}
+
+// NormalizeSecuritySchemes returns an input transformer renaming security
+// schemes that are the same scheme under different names, such as
+// BearerAuth, bearer and jwt, to one canonical name. aliases maps each
+// canonical name to its aliases, matched case-insensitively like the
+// canonical name itself. Security requirements of the document and its
+// operations are rewritten; when an input defines both an alias and the
+// canonical scheme, the canonical definition is kept.
+func NormalizeSecuritySchemes(aliases map[string][]string) Transformer {
+	canonical := make(map[string]string)
+	for name, names := range aliases {
+		canonical[strings.ToLower(name)] = name
+		for _, alias := range names {
+			canonical[strings.ToLower(alias)] = name
+		}
+	}
+	rename := func(name string) string {
+		if c, ok := canonical[strings.ToLower(name)]; ok {
+			return c
+		}
+		return name
+	}
+
+	return NewTransformer("normalize-security", func(doc *openapi3.T, source string) error {
+		if doc.Components != nil && len(doc.Components.SecuritySchemes) > 0 {
+			schemes := make(openapi3.SecuritySchemes, len(doc.Components.SecuritySchemes))
+			for _, name := range sortedKeys(doc.Components.SecuritySchemes) {
+				target := rename(name)
+				if _, ok := schemes[target]; ok && name != target {
+					continue
+				}
+				schemes[target] = doc.Components.SecuritySchemes[name]
+			}
+			doc.Components.SecuritySchemes = schemes
+		}
+
+		doc.Security = renameRequirements(doc.Security, rename)
+		if doc.Paths == nil {
+			return nil
+		}
+		for _, item := range doc.Paths.Map() {
+			for _, op := range item.Operations() {
+				if op.Security != nil {
+					requirements := renameRequirements(*op.Security, rename)
+					op.Security = &requirements
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// renameRequirements renames the schemes of security requirements,
+// unioning the scopes of schemes renamed to the same name
+func renameRequirements(requirements openapi3.SecurityRequirements, rename func(string) string) openapi3.SecurityRequirements {
+	if requirements == nil {
+		return nil
+	}
+	renamed := make(openapi3.SecurityRequirements, len(requirements))
+	for i, requirement := range requirements {
+		r := make(openapi3.SecurityRequirement, len(requirement))
+		for name, scopes := range requirement {
+			target := rename(name)
+			for _, scope := range scopes {
+				if !slices.Contains(r[target], scope) {
+					r[target] = append(r[target], scope)
+				}
+			}
+			if r[target] == nil {
+				r[target] = []string{}
+			}
+		}
+		renamed[i] = r
+	}
+	return renamed
+}
+
+// PruneSecuritySchemes returns a transformer removing the security schemes
+// that neither the document-level security nor any operation, including
+// callback operations, requires
+func PruneSecuritySchemes() Transformer {
+	return NewTransformer("prune-security", func(doc *openapi3.T, source string) error {
+		if doc.Components == nil || len(doc.Components.SecuritySchemes) == 0 {
+			return nil
+		}
+		used := make(map[string]bool)
+		addRequirements(used, doc.Security)
+		if doc.Paths != nil {
+			for _, item := range doc.Paths.Map() {
+				addPathItemRequirements(used, item, make(map[*openapi3.PathItem]bool))
+			}
+		}
+		for name := range doc.Components.SecuritySchemes {
+			if !used[name] {
+				delete(doc.Components.SecuritySchemes, name)
+			}
+		}
+		return nil
+	})
+}
+
+// addPathItemRequirements marks the schemes required by the operations of
+// a path item and of their callbacks; seen guards against callback cycles
+func addPathItemRequirements(used map[string]bool, item *openapi3.PathItem, seen map[*openapi3.PathItem]bool) {
+	if item == nil || seen[item] {
+		return
+	}
+	seen[item] = true
+	for _, op := range item.Operations() {
+		if op.Security != nil {
+			addRequirements(used, *op.Security)
+		}
+		for _, callback := range op.Callbacks {
+			if callback == nil || callback.Value == nil {
+				continue
+			}
+			for _, cbItem := range callback.Value.Map() {
+				addPathItemRequirements(used, cbItem, seen)
+			}
+		}
+	}
+}
+
+// addRequirements marks the schemes of security requirements as used
+func addRequirements(used map[string]bool, requirements openapi3.SecurityRequirements) {
+	for _, requirement := range requirements {
+		for name := range requirement {
+			used[name] = true
+		}
+	}
+}
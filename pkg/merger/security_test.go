@@ -0,0 +1,198 @@
+package merger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestParseSecurityScheme(t *testing.T) {
+	scheme, err := ParseSecurityScheme([]byte("type: apiKey\nin: header\nname: X-API-Key\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if scheme.Type != "apiKey" || scheme.In != "header" || scheme.Name != "X-API-Key" {
+		t.Errorf("Unexpected scheme: %+v", scheme)
+	}
+
+	if _, err := ParseSecurityScheme([]byte("type: apiKey\n")); err == nil {
+		t.Error("Expected an error for an apiKey scheme without a name")
+	}
+}
+
+func TestGlobalSecurity(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+security:
+  - legacy: []
+paths: {}
+components:
+  securitySchemes:
+    legacy: {type: http, scheme: basic}
+`)
+
+	if err := GlobalSecurity("bearerAuth", nil).Transform(doc, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if scheme := doc.Components.SecuritySchemes["bearerAuth"]; scheme == nil || scheme.Value.Scheme != "bearer" {
+		t.Errorf("Expected the bearer scheme to be registered, got %+v", scheme)
+	}
+	if len(doc.Security) != 1 || doc.Security[0]["bearerAuth"] == nil || len(doc.Security[0]) != 1 {
+		t.Errorf("Expected bearerAuth as the document requirement, got %v", doc.Security)
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+
+	if err := GlobalSecurity("legacy", nil, "read").Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Components.SecuritySchemes["legacy"].Value.Scheme; got != "basic" {
+		t.Errorf("Expected the existing legacy scheme to be kept, got %s", got)
+	}
+
+	apiKey := openapi3.NewSecurityScheme().WithType("apiKey").WithIn("header").WithName("X-API-Key")
+	if err := GlobalSecurity("legacy", apiKey).Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Components.SecuritySchemes["legacy"].Value.Type; got != "apiKey" {
+		t.Errorf("Expected the given scheme to replace the existing one, got %s", got)
+	}
+}
+
+func TestMergeDocumentsOAuth2Scopes(t *testing.T) {
+	load := func(flows string) *openapi3.T {
+		return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths: {}
+components:
+  securitySchemes:
+    oauth:
+      type: oauth2
+      flows:`+flows)
+	}
+
+	users := load(`
+        authorizationCode:
+          authorizationUrl: https://auth.example.com/authorize
+          tokenUrl: https://auth.example.com/token
+          scopes: {users:read: Read users}
+`)
+	orders := load(`
+        authorizationCode:
+          authorizationUrl: https://auth.example.com/authorize
+          tokenUrl: https://auth.example.com/token
+          scopes: {orders:read: Read orders, users:read: Other description}
+        clientCredentials:
+          tokenUrl: https://auth.example.com/token
+          scopes: {orders:sync: Sync orders}
+`)
+
+	merged, err := MergeDocuments([]*openapi3.T{users, orders})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	flows := merged.Components.SecuritySchemes["oauth"].Value.Flows
+	scopes := flows.AuthorizationCode.Scopes
+	if len(scopes) != 2 || scopes["users:read"] != "Read users" || scopes["orders:read"] != "Read orders" {
+		t.Errorf("Expected the scopes to be unioned, got %v", scopes)
+	}
+	if flows.ClientCredentials == nil || flows.ClientCredentials.Scopes["orders:sync"] == "" {
+		t.Errorf("Expected the client credentials flow to be added, got %+v", flows.ClientCredentials)
+	}
+
+	other := load(`
+        authorizationCode:
+          authorizationUrl: https://auth.example.com/authorize
+          tokenUrl: https://login.example.com/token
+          scopes: {}
+`)
+	_, err = MergeDocuments([]*openapi3.T{load(`
+        authorizationCode:
+          authorizationUrl: https://auth.example.com/authorize
+          tokenUrl: https://auth.example.com/token
+          scopes: {}
+`), other}, WithSourceNames("users.yaml", "orders.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "tokenUrl") {
+		t.Errorf("Expected a conflicting token URL error, got %v", err)
+	}
+}
+
+func TestNormalizeSecuritySchemes(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+security:
+  - jwt: []
+paths:
+  /users:
+    get:
+      security:
+        - BearerAuth: []
+          apiKey: []
+      responses: {"200": {description: ok}}
+components:
+  securitySchemes:
+    BearerAuth: {type: http, scheme: bearer}
+    jwt: {type: http, scheme: bearer, bearerFormat: JWT}
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+`)
+
+	transformer := NormalizeSecuritySchemes(map[string][]string{"bearerAuth": {"bearer", "jwt"}})
+	if err := transformer.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	schemes := doc.Components.SecuritySchemes
+	if len(schemes) != 2 || schemes["bearerAuth"] == nil || schemes["apiKey"] == nil {
+		t.Fatalf("Expected bearerAuth and apiKey, got %v", sortedKeys(schemes))
+	}
+	if doc.Security[0]["bearerAuth"] == nil || len(doc.Security[0]) != 1 {
+		t.Errorf("Expected the document requirement to be renamed, got %v", doc.Security)
+	}
+	op := doc.Paths.Find("/users").Get
+	if r := (*op.Security)[0]; r["bearerAuth"] == nil || r["apiKey"] == nil || len(r) != 2 {
+		t.Errorf("Expected the operation requirement to be renamed, got %v", r)
+	}
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Errorf("Expected a valid document, got %v", err)
+	}
+}
+
+func TestPruneSecuritySchemes(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+security:
+  - global: []
+paths:
+  /users:
+    get:
+      security:
+        - users: []
+      callbacks:
+        onEvent:
+          '{$request.body#/url}':
+            post:
+              security:
+                - callback: []
+              responses: {"200": {description: ok}}
+      responses: {"200": {description: ok}}
+components:
+  securitySchemes:
+    global: {type: http, scheme: bearer}
+    users: {type: http, scheme: basic}
+    callback: {type: apiKey, in: header, name: X-Callback-Key}
+    unused: {type: apiKey, in: header, name: X-API-Key}
+`)
+
+	if err := PruneSecuritySchemes().Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(sortedKeys(doc.Components.SecuritySchemes), ","); got != "callback,global,users" {
+		t.Errorf("Expected only the unused scheme to be pruned, got %s", got)
+	}
+}
@@ -0,0 +1,505 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// VersionBump is a semantic version increment
+type VersionBump int
+
+const (
+	// BumpNone leaves the version unchanged
+	BumpNone VersionBump = iota
+	// BumpPatch is for changes not affecting the API, such as
+	// descriptions and examples
+	BumpPatch
+	// BumpMinor is for additive changes, such as new operations and
+	// optional parameters
+	BumpMinor
+	// BumpMajor is for breaking changes, such as removed operations and
+	// new required parameters
+	BumpMajor
+)
+
+func (b VersionBump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// VersionBumpMode controls whether merges compare the merged spec with the
+// previous output to suggest a semantic version bump
+type VersionBumpMode string
+
+const (
+	// VersionBumpSuggest computes the suggestion, see
+	// Merger.VersionSuggestion
+	VersionBumpSuggest VersionBumpMode = "suggest"
+	// VersionBumpApply also sets info.version of the merged spec to the
+	// suggested version
+	VersionBumpApply VersionBumpMode = "apply"
+)
+
+// validate checks that the mode is known
+func (m VersionBumpMode) validate() error {
+	switch m {
+	case "", VersionBumpSuggest, VersionBumpApply:
+		return nil
+	}
+	return fmt.Errorf("invalid version bump mode %q (expected suggest or apply)", m)
+}
+
+// SpecChange is a difference between two versions of a spec
+type SpecChange struct {
+	Bump VersionBump `json:"bump"`
+	// Location is the path, operation or body changed, e.g. "GET /users
+	// response 200 application/json"
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (c SpecChange) String() string {
+	if c.Location == "" {
+		return fmt.Sprintf("%s: %s", c.Bump, c.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", c.Bump, c.Location, c.Message)
+}
+
+// VersionSuggestion is the version bump suggested by comparing a merged
+// spec with the previous output
+type VersionSuggestion struct {
+	Bump VersionBump
+	// From is the version of the previous output, To the suggested one or
+	// "" when From is not a semantic version
+	From, To string
+	Changes  []SpecChange
+}
+
+// SuggestBump returns the largest bump of changes
+func SuggestBump(changes []SpecChange) VersionBump {
+	bump := BumpNone
+	for _, c := range changes {
+		bump = max(bump, c.Bump)
+	}
+	return bump
+}
+
+// BumpVersion increments a semantic version such as 1.2.3 or v1.2.3.
+// Pre-release and build suffixes are dropped.
+func BumpVersion(version string, bump VersionBump) (string, error) {
+	prefix := ""
+	core := version
+	if rest, ok := strings.CutPrefix(version, "v"); ok {
+		prefix, core = "v", rest
+	}
+	core, _, _ = strings.Cut(core, "+")
+	core, _, _ = strings.Cut(core, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("version %q is not a semantic version", version)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("version %q is not a semantic version", version)
+		}
+		numbers[i] = n
+	}
+	switch bump {
+	case BumpMajor:
+		numbers = [3]int{numbers[0] + 1, 0, 0}
+	case BumpMinor:
+		numbers = [3]int{numbers[0], numbers[1] + 1, 0}
+	case BumpPatch:
+		numbers[2]++
+	case BumpNone:
+		return version, nil
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, numbers[0], numbers[1], numbers[2]), nil
+}
+
+// DiffSpecs compares two versions of a spec, classifying every change of
+// paths, operations, parameters, bodies, responses and their schemas as
+// breaking (major) or additive (minor). When the specs differ otherwise,
+// apart from info.version, a single patch change is returned.
+func DiffSpecs(old, new *openapi3.T) []SpecChange {
+	d := &specDiff{visited: make(map[[2]*openapi3.Schema]bool)}
+	d.paths(old.Paths, new.Paths)
+	if len(d.changes) == 0 && !sameIgnoringVersion(old, new) {
+		d.add(BumpPatch, "", "documentation or metadata changed")
+	}
+	return d.changes
+}
+
+// specDiff collects the changes between two specs
+type specDiff struct {
+	changes []SpecChange
+	// visited holds the schema pairs being compared, so recursive schemas
+	// terminate
+	visited map[[2]*openapi3.Schema]bool
+}
+
+// add records a change
+func (d *specDiff) add(bump VersionBump, location, format string, args ...any) {
+	d.changes = append(d.changes, SpecChange{Bump: bump, Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+// paths compares the paths and operations of two specs
+func (d *specDiff) paths(old, new *openapi3.Paths) {
+	oldPaths, newPaths := old.Map(), new.Map()
+	for _, path := range sortedKeys(oldPaths) {
+		oldItem := oldPaths[path]
+		newItem, ok := newPaths[path]
+		if !ok {
+			d.add(BumpMajor, path, "path removed")
+			continue
+		}
+		oldOps, newOps := oldItem.Operations(), newItem.Operations()
+		for _, method := range sortedKeys(oldOps) {
+			location := strings.ToUpper(method) + " " + path
+			newOp, ok := newOps[method]
+			if !ok {
+				d.add(BumpMajor, location, "operation removed")
+				continue
+			}
+			d.operation(location, oldItem, oldOps[method], newItem, newOp)
+		}
+		for _, method := range sortedKeys(newOps) {
+			if _, ok := oldOps[method]; !ok {
+				d.add(BumpMinor, strings.ToUpper(method)+" "+path, "operation added")
+			}
+		}
+	}
+	for _, path := range sortedKeys(newPaths) {
+		if _, ok := oldPaths[path]; !ok {
+			d.add(BumpMinor, path, "path added")
+		}
+	}
+}
+
+// operation compares the parameters, request body and responses of an
+// operation
+func (d *specDiff) operation(location string, oldItem *openapi3.PathItem, old *openapi3.Operation, newItem *openapi3.PathItem, new *openapi3.Operation) {
+	oldParams, newParams := operationParameters(oldItem, old), operationParameters(newItem, new)
+	for _, key := range sortedKeys(oldParams) {
+		oldParam := oldParams[key]
+		newParam, ok := newParams[key]
+		if !ok {
+			d.add(BumpMajor, location, "parameter %s removed", key)
+			continue
+		}
+		if newParam.Required && !oldParam.Required {
+			d.add(BumpMajor, location, "parameter %s became required", key)
+		}
+		d.schema(location+" parameter "+key, "", oldParam.Schema, newParam.Schema, true)
+	}
+	for _, key := range sortedKeys(newParams) {
+		if _, ok := oldParams[key]; ok {
+			continue
+		}
+		if newParams[key].Required {
+			d.add(BumpMajor, location, "required parameter %s added", key)
+		} else {
+			d.add(BumpMinor, location, "optional parameter %s added", key)
+		}
+	}
+
+	oldBody, newBody := requestBody(old), requestBody(new)
+	switch {
+	case oldBody == nil && newBody != nil && newBody.Required:
+		d.add(BumpMajor, location, "required request body added")
+	case oldBody == nil && newBody != nil:
+		d.add(BumpMinor, location, "optional request body added")
+	case oldBody != nil && newBody == nil:
+		d.add(BumpMajor, location, "request body removed")
+	case oldBody != nil:
+		if newBody.Required && !oldBody.Required {
+			d.add(BumpMajor, location, "request body became required")
+		}
+		d.content(location+" request body", oldBody.Content, newBody.Content, true)
+	}
+
+	oldResponses, newResponses := operationResponses(old), operationResponses(new)
+	for _, status := range sortedKeys(oldResponses) {
+		newResp, ok := newResponses[status]
+		if !ok {
+			d.add(BumpMajor, location, "response %s removed", status)
+			continue
+		}
+		d.content(location+" response "+status, oldResponses[status].Content, newResp.Content, false)
+	}
+	for _, status := range sortedKeys(newResponses) {
+		if _, ok := oldResponses[status]; !ok {
+			d.add(BumpMinor, location, "response %s added", status)
+		}
+	}
+}
+
+// content compares the media types of a body
+func (d *specDiff) content(location string, old, new openapi3.Content, request bool) {
+	for _, mediaType := range sortedKeys(old) {
+		newMedia, ok := new[mediaType]
+		if !ok {
+			d.add(BumpMajor, location, "media type %s removed", mediaType)
+			continue
+		}
+		if old[mediaType] != nil && newMedia != nil {
+			d.schema(location+" "+mediaType, "", old[mediaType].Schema, newMedia.Schema, request)
+		}
+	}
+	for _, mediaType := range sortedKeys(new) {
+		if _, ok := old[mediaType]; !ok {
+			d.add(BumpMinor, location, "media type %s added", mediaType)
+		}
+	}
+}
+
+// schema compares the schemas of a parameter or body. field is the
+// dotted path of the compared property, "" for the schema itself. Request
+// schemas break clients when they accept less, response schemas when
+// they promise less.
+func (d *specDiff) schema(location, field string, oldRef, newRef *openapi3.SchemaRef, request bool) {
+	if oldRef == nil || newRef == nil || oldRef.Value == nil || newRef.Value == nil {
+		return
+	}
+	old, new := oldRef.Value, newRef.Value
+	pair := [2]*openapi3.Schema{old, new}
+	if d.visited[pair] {
+		return
+	}
+	d.visited[pair] = true
+	defer delete(d.visited, pair)
+
+	what := func(s string) string {
+		if field == "" {
+			return s
+		}
+		return s + " of " + field
+	}
+	if oldType, newType := typeName(old.Type), typeName(new.Type); oldType != newType {
+		d.add(BumpMajor, location, "%s changed from %s to %s", what("type"), oldType, newType)
+		return
+	}
+	if old.Format != new.Format {
+		d.add(BumpMajor, location, "%s changed from %q to %q", what("format"), old.Format, new.Format)
+	}
+	if old.Nullable != new.Nullable && old.Nullable == request {
+		d.add(BumpMajor, location, "%s changed", what("nullability"))
+	}
+	d.enum(location, what("enum"), old.Enum, new.Enum, request)
+
+	for _, name := range sortedKeys(old.Properties) {
+		prop := joinField(field, name)
+		newProp, ok := new.Properties[name]
+		if !ok {
+			d.add(BumpMajor, location, "property %s removed", prop)
+			continue
+		}
+		switch {
+		case request && slices.Contains(new.Required, name) && !slices.Contains(old.Required, name):
+			d.add(BumpMajor, location, "property %s became required", prop)
+		case !request && slices.Contains(old.Required, name) && !slices.Contains(new.Required, name):
+			d.add(BumpMajor, location, "property %s became optional", prop)
+		}
+		d.schema(location, prop, old.Properties[name], newProp, request)
+	}
+	for _, name := range sortedKeys(new.Properties) {
+		if _, ok := old.Properties[name]; ok {
+			continue
+		}
+		if request && slices.Contains(new.Required, name) {
+			d.add(BumpMajor, location, "required property %s added", joinField(field, name))
+		} else {
+			d.add(BumpMinor, location, "property %s added", joinField(field, name))
+		}
+	}
+
+	d.schema(location, field+"[]", old.Items, new.Items, request)
+	d.schema(location, joinField(field, "*"), old.AdditionalProperties.Schema, new.AdditionalProperties.Schema, request)
+	for _, composition := range []struct {
+		name     string
+		old, new openapi3.SchemaRefs
+	}{{"allOf", old.AllOf, new.AllOf}, {"oneOf", old.OneOf, new.OneOf}, {"anyOf", old.AnyOf, new.AnyOf}} {
+		if len(composition.old) != len(composition.new) {
+			d.add(BumpMajor, location, "%s changed", what(composition.name))
+			continue
+		}
+		for i := range composition.old {
+			d.schema(location, field, composition.old[i], composition.new[i], request)
+		}
+	}
+}
+
+// enum compares enum values: requests break when accepting fewer values,
+// responses when returning new ones
+func (d *specDiff) enum(location, what string, old, new []any, request bool) {
+	if len(old) == 0 && len(new) == 0 {
+		return
+	}
+	removed, added := enumDifference(old, new), enumDifference(new, old)
+	switch {
+	case len(old) == 0 && request, len(new) == 0 && !request:
+		d.add(BumpMajor, location, "%s changed", what)
+	case len(old) == 0 || len(new) == 0:
+		d.add(BumpMinor, location, "%s changed", what)
+	case len(removed) > 0 && request:
+		d.add(BumpMajor, location, "%s values removed: %s", what, strings.Join(removed, ", "))
+	case len(added) > 0 && !request:
+		d.add(BumpMajor, location, "%s values added: %s", what, strings.Join(added, ", "))
+	case len(removed) > 0 || len(added) > 0:
+		d.add(BumpMinor, location, "%s values changed", what)
+	}
+}
+
+// enumDifference returns the values of a missing from b
+func enumDifference(a, b []any) []string {
+	var values []string
+	for _, v := range a {
+		if !slices.ContainsFunc(b, func(w any) bool { return reflect.DeepEqual(v, w) }) {
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+	return values
+}
+
+// operationParameters returns the parameters of an operation, including
+// those of its path, by "in.name"
+func operationParameters(item *openapi3.PathItem, op *openapi3.Operation) map[string]*openapi3.Parameter {
+	params := make(map[string]*openapi3.Parameter)
+	for _, refs := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+		for _, ref := range refs {
+			if ref != nil && ref.Value != nil {
+				params[ref.Value.In+"."+ref.Value.Name] = ref.Value
+			}
+		}
+	}
+	return params
+}
+
+// requestBody returns the request body of an operation, or nil
+func requestBody(op *openapi3.Operation) *openapi3.RequestBody {
+	if op.RequestBody == nil {
+		return nil
+	}
+	return op.RequestBody.Value
+}
+
+// operationResponses returns the responses of an operation by status
+func operationResponses(op *openapi3.Operation) map[string]*openapi3.Response {
+	responses := make(map[string]*openapi3.Response)
+	if op.Responses != nil {
+		for status, ref := range op.Responses.Map() {
+			if ref != nil && ref.Value != nil {
+				responses[status] = ref.Value
+			}
+		}
+	}
+	return responses
+}
+
+// typeName returns the type of a schema, "any" when unset
+func typeName(types *openapi3.Types) string {
+	if types == nil || len(*types) == 0 {
+		return "any"
+	}
+	return strings.Join(*types, "|")
+}
+
+// joinField appends a property name to a dotted field path
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
+
+// sameIgnoringVersion reports whether two specs serialize identically,
+// ignoring info.version
+func sameIgnoringVersion(a, b *openapi3.T) bool {
+	encode := func(doc *openapi3.T) []byte {
+		copied := *doc
+		if doc.Info != nil {
+			info := *doc.Info
+			info.Version = ""
+			copied.Info = &info
+		}
+		data, _ := json.Marshal(&copied)
+		return data
+	}
+	return string(encode(a)) == string(encode(b))
+}
+
+// suggestVersion compares the merged spec with the previous content of
+// OutputPath, applying the suggested version with VersionBumpApply. Merges
+// without a previous output suggest nothing.
+func (m *Merger) suggestVersion(merged *openapi3.T) error {
+	m.versionSuggestion = nil
+	if m.config.VersionBump == "" {
+		return nil
+	}
+	if err := m.config.VersionBump.validate(); err != nil {
+		return err
+	}
+
+	output := m.config.OutputPath
+	if !IsRemoteSource(output) && !isObjectStorageSource(output) {
+		if _, err := os.Stat(output); os.IsNotExist(err) {
+			m.config.Logger.Debug("no previous output to compare versions with", "path", output)
+			return nil
+		}
+	}
+	data, err := m.readDataFromPath(output)
+	if err == nil && isCompressedOutput(output) {
+		data, err = gunzipData(data)
+	}
+	if err != nil {
+		m.config.Logger.Warn("cannot read previous output, skipping version bump", "path", output, "error", err)
+		return nil
+	}
+	previous, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		m.config.Logger.Warn("cannot parse previous output, skipping version bump", "path", output, "error", err)
+		return nil
+	}
+
+	changes := DiffSpecs(previous, merged)
+	suggestion := &VersionSuggestion{Bump: SuggestBump(changes), Changes: changes}
+	if previous.Info != nil {
+		suggestion.From = previous.Info.Version
+	}
+	m.versionSuggestion = suggestion
+	if suggestion.To, err = BumpVersion(suggestion.From, suggestion.Bump); err != nil {
+		m.config.Logger.Warn("cannot bump the previous version", "path", output, "error", err)
+		return nil
+	}
+	m.config.Logger.Info("version bump", "bump", suggestion.Bump.String(), "from", suggestion.From, "to", suggestion.To, "changes", len(changes))
+
+	if m.config.VersionBump == VersionBumpApply {
+		if merged.Info == nil {
+			merged.Info = &openapi3.Info{}
+		}
+		merged.Info.Version = suggestion.To
+	}
+	return nil
+}
+
+// VersionSuggestion returns the version bump suggested by the last merge,
+// or nil when VersionBump is not set or there was no previous output
+func (m *Merger) VersionSuggestion() *VersionSuggestion {
+	return m.versionSuggestion
+}
@@ -0,0 +1,156 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// semverDoc returns the users API at version 1.2.3, with extra YAML
+// appended to the GET /users operation
+func semverDoc(t *testing.T, description, extra string) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Users, version: 1.2.3, description: `+description+`}
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id: {type: string}
+                  status: {type: string, enum: [active, disabled]}
+`+extra)
+}
+
+func TestDiffSpecs(t *testing.T) {
+	old := semverDoc(t, "Users API", "")
+	tests := []struct {
+		name    string
+		new     *openapi3.T
+		bump    VersionBump
+		changes string
+	}{
+		{"unchanged", semverDoc(t, "Users API", ""), BumpNone, ""},
+		{"description", semverDoc(t, "The users API", ""), BumpPatch, "patch: documentation or metadata changed"},
+		{"operation added", semverDoc(t, "Users API", `    post:
+      responses: {"201": {description: created}}
+`), BumpMinor, "minor: POST /users: operation added"},
+		{"path added", semverDoc(t, "Users API", `  /users/{id}:
+    get:
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {"200": {description: ok}}
+`), BumpMinor, "minor: /users/{id}: path added"},
+	}
+	for _, tt := range tests {
+		changes := DiffSpecs(old, tt.new)
+		var lines []string
+		for _, c := range changes {
+			lines = append(lines, c.String())
+		}
+		if got := strings.Join(lines, "\n"); got != tt.changes || SuggestBump(changes) != tt.bump {
+			t.Errorf("%s: expected %s:\n%s\ngot %s:\n%s", tt.name, tt.bump, tt.changes, SuggestBump(changes), got)
+		}
+	}
+
+	// Breaking changes to the operation itself
+	changed := semverDoc(t, "Users API", "")
+	op := changed.Paths.Value("/users").Get
+	op.Parameters[0].Value.Required = true
+	schema := op.Responses.Value("200").Value.Content["application/json"].Schema.Value
+	delete(schema.Properties, "id")
+	schema.Properties["status"].Value.Enum = append(schema.Properties["status"].Value.Enum, "deleted")
+	schema.Properties["email"] = openapi3.NewStringSchema().NewRef()
+
+	var lines []string
+	for _, c := range DiffSpecs(old, changed) {
+		lines = append(lines, c.String())
+	}
+	expected := strings.Join([]string{
+		"major: GET /users: parameter query.limit became required",
+		"major: GET /users response 200 application/json: property id removed",
+		"major: GET /users response 200 application/json: enum of status values added: deleted",
+		"minor: GET /users response 200 application/json: property email added",
+	}, "\n")
+	if got := strings.Join(lines, "\n"); got != expected {
+		t.Errorf("Expected changes:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		bump     VersionBump
+		expected string
+	}{
+		{"1.2.3", BumpMajor, "2.0.0"},
+		{"1.2.3", BumpMinor, "1.3.0"},
+		{"v1.2.3", BumpPatch, "v1.2.4"},
+		{"1.2.3-beta.1", BumpPatch, "1.2.4"},
+		{"1.2.3", BumpNone, "1.2.3"},
+	}
+	for _, tt := range tests {
+		if got, err := BumpVersion(tt.version, tt.bump); err != nil || got != tt.expected {
+			t.Errorf("BumpVersion(%s, %s) = %s, %v, expected %s", tt.version, tt.bump, got, err, tt.expected)
+		}
+	}
+	if _, err := BumpVersion("2024-01", BumpMinor); err == nil {
+		t.Error("Expected an error for a non-semantic version")
+	}
+}
+
+func TestMergeVersionBump(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.yaml")
+	output := filepath.Join(dir, "merged.yaml")
+	write := func(extra string) {
+		t.Helper()
+		spec := `openapi: 3.0.0
+info: {title: Users, version: 1.0.0}
+paths:
+  /users:
+    get:
+      responses: {"200": {description: ok}}
+` + extra
+		if err := os.WriteFile(input, []byte(spec), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("")
+	m := New(Config{InputPaths: []string{input}, OutputPath: output, VersionBump: VersionBumpApply})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if m.VersionSuggestion() != nil {
+		t.Errorf("Expected no suggestion without a previous output, got %+v", m.VersionSuggestion())
+	}
+
+	write(`  /orders:
+    get:
+      responses: {"200": {description: ok}}
+`)
+	m = New(Config{InputPaths: []string{input}, OutputPath: output, VersionBump: VersionBumpApply})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	suggestion := m.VersionSuggestion()
+	if suggestion == nil || suggestion.Bump != BumpMinor || suggestion.From != "1.0.0" || suggestion.To != "1.1.0" {
+		t.Fatalf("Expected a minor bump from 1.0.0 to 1.1.0, got %+v", suggestion)
+	}
+	if version := m.Document().Info.Version; version != "1.1.0" {
+		t.Errorf("Expected the applied version 1.1.0, got %s", version)
+	}
+}
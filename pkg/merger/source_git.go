@@ -0,0 +1,111 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// isGitSource reports whether path is a git+https:// or git+ssh:// input
+func isGitSource(path string) bool {
+	return strings.HasPrefix(path, "git+")
+}
+
+// gitSource is a file inside a git repository
+type gitSource struct {
+	Repo string
+	Ref  string
+	Path string
+}
+
+// parseGitSource parses inputs such as
+// git+ssh://git@host/repo.git?ref=main&path=api/openapi.yaml
+func parseGitSource(source string) (*gitSource, error) {
+	u, err := url.Parse(strings.TrimPrefix(source, "git+"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid git source %s: %v", source, err)
+	}
+
+	query := u.Query()
+	s := &gitSource{Ref: query.Get("ref"), Path: strings.TrimPrefix(query.Get("path"), "/")}
+	if s.Path == "" {
+		return nil, fmt.Errorf("git source %s has no path parameter", source)
+	}
+	if s.Ref == "" {
+		s.Ref = "HEAD"
+	}
+	if err := checkRefName(s.Ref); err != nil {
+		return nil, fmt.Errorf("invalid git source %s: %v", source, err)
+	}
+
+	u.RawQuery = ""
+	u.Fragment = ""
+	s.Repo = u.String()
+	if strings.HasPrefix(s.Repo, "-") {
+		return nil, fmt.Errorf("invalid git source %s: repository must not start with '-'", source)
+	}
+	return s, nil
+}
+
+// checkRefName rejects refs git would not accept as branch, tag or commit
+// names (see git check-ref-format), including refs shaped like options or
+// refspecs, since they are passed on the git command line
+func checkRefName(ref string) error {
+	switch {
+	case ref == "", ref == "@":
+		return fmt.Errorf("invalid ref %q", ref)
+	case strings.HasPrefix(ref, "-"), strings.HasPrefix(ref, "+"):
+		return fmt.Errorf("invalid ref %q: must not start with %q", ref, ref[:1])
+	case strings.HasPrefix(ref, "/"), strings.HasSuffix(ref, "/"), strings.HasSuffix(ref, "."), strings.HasSuffix(ref, ".lock"):
+		return fmt.Errorf("invalid ref %q", ref)
+	case strings.Contains(ref, ".."), strings.Contains(ref, "//"), strings.Contains(ref, "@{"), strings.Contains(ref, "/."):
+		return fmt.Errorf("invalid ref %q", ref)
+	case strings.ContainsAny(ref, " ~^:?*[\\"):
+		return fmt.Errorf("invalid ref %q: contains a forbidden character", ref)
+	}
+	for _, r := range ref {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("invalid ref %q: contains a control character", ref)
+		}
+	}
+	return nil
+}
+
+// readGitSource fetches a single file from a git repository. Only the
+// requested ref is fetched, shallowly, into a temporary bare repository;
+// credentials come from the usual git configuration (SSH keys, credential
+// helpers).
+func readGitSource(ctx context.Context, source string) ([]byte, error) {
+	s, err := parseGitSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "swagger-merger-git-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) ([]byte, error) {
+		return runGit(ctx, dir, args...)
+	}
+	if _, err := git("init", "--quiet", "--bare"); err != nil {
+		return nil, err
+	}
+	if _, err := git("fetch", "--quiet", "--depth", "1", "--", s.Repo, s.Ref); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from %s: %v", s.Ref, s.Repo, err)
+	}
+	data, err := git("show", "FETCH_HEAD:"+s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %v", s.Path, s.Ref, err)
+	}
+	return data, nil
+}
+
+// runGit runs a git command in dir and returns its stdout
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	return runToolContext(ctx, nil, "git", append([]string{"-C", dir}, args...)...)
+}
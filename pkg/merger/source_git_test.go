@@ -0,0 +1,84 @@
+package merger
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	s, err := parseGitSource("git+ssh://git@github.com/org/repo.git?ref=main&path=api/openapi.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if s.Repo != "ssh://git@github.com/org/repo.git" || s.Ref != "main" || s.Path != "api/openapi.yaml" {
+		t.Errorf("Unexpected git source: %+v", s)
+	}
+
+	if _, err := parseGitSource("git+https://github.com/org/repo.git"); err == nil {
+		t.Error("Expected error for missing path")
+	}
+
+	for _, source := range []string{
+		"git+https://host/repo.git?ref=--upload-pack=touch%20/tmp/pwned&path=a.yaml",
+		"git+https://host/repo.git?ref=-c&path=a.yaml",
+		"git+https://host/repo.git?ref=main:refs/heads/x&path=a.yaml",
+		"git+https://host/repo.git?ref=%2Bmain&path=a.yaml",
+		"git+https://host/repo.git?ref=a..b&path=a.yaml",
+		"git+-oProxyCommand=touch%20pwned?path=a.yaml",
+	} {
+		if _, err := parseGitSource(source); err == nil {
+			t.Errorf("Expected an error for %s", source)
+		}
+	}
+	for _, ref := range []string{"main", "v1.2.3", "release/2024-01", "HEAD", "0123abcd"} {
+		if err := checkRefName(ref); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", ref, err)
+		}
+	}
+}
+
+func TestReadGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "api", "openapi.yaml"), []byte(testSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "spec"},
+	} {
+		if _, err := runGit(context.Background(), repo, args...); err != nil {
+			t.Fatalf("Failed to set up repository: %v", err)
+		}
+	}
+
+	data, err := readGitSource(context.Background(), "git+file://"+repo+"?ref=main&path=api/openapi.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if string(data) != testSpec {
+		t.Errorf("Unexpected content: %s", data)
+	}
+
+	// Option-shaped refs never reach git
+	marker := filepath.Join(t.TempDir(), "pwned")
+	source := "git+file://" + repo + "?ref=--upload-pack=touch%20" + marker + "&path=api/openapi.yaml"
+	if _, err := readGitSource(context.Background(), source); err == nil {
+		t.Error("Expected an error for an option-shaped ref")
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("Expected the injected command not to run")
+	}
+}
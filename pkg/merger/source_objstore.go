@@ -0,0 +1,164 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Object storage inputs are read through the provider CLIs, so the
+// standard credential chains (environment, profiles, instance metadata,
+// workload identity) apply without extra configuration:
+//
+//	s3://bucket/key                    aws s3 cp
+//	gs://bucket/object                 gcloud storage cat
+//	azblob://account/container/blob    az storage blob download
+
+// isObjectStorageSource reports whether path is an object storage URI
+func isObjectStorageSource(path string) bool {
+	return strings.HasPrefix(path, "s3://") ||
+		strings.HasPrefix(path, "gs://") ||
+		strings.HasPrefix(path, "azblob://")
+}
+
+// readObjectStorageSource downloads an object storage input
+func readObjectStorageSource(ctx context.Context, source string) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object storage URI %s: %v", source, err)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("invalid object storage URI %s: bucket and object are required", source)
+	}
+
+	switch u.Scheme {
+	case "s3":
+		return runToolContext(ctx, nil, "aws", "s3", "cp", "--only-show-errors", source, "-")
+	case "gs":
+		return runToolContext(ctx, nil, "gcloud", "storage", "cat", source)
+	case "azblob":
+		container, blob, found := strings.Cut(key, "/")
+		if !found || blob == "" {
+			return nil, fmt.Errorf("invalid Azure Blob URI %s (format: azblob://account/container/blob)", source)
+		}
+		return downloadAzureBlob(ctx, u.Host, container, blob)
+	}
+	return nil, fmt.Errorf("unsupported object storage scheme %s", u.Scheme)
+}
+
+// downloadAzureBlob downloads a blob through a temporary file, since the
+// az CLI cannot stream blobs to stdout
+func downloadAzureBlob(ctx context.Context, account, container, blob string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "swagger-merger-azblob-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "blob")
+	if _, err := runToolContext(ctx, nil, "az", "storage", "blob", "download",
+		"--auth-mode", "login",
+		"--account-name", account,
+		"--container-name", container,
+		"--name", blob,
+		"--file", file,
+		"--only-show-errors",
+	); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(file)
+}
+
+// ObjectMetadata holds the HTTP metadata stored with uploaded objects
+type ObjectMetadata struct {
+	ContentType     string
+	ContentEncoding string
+	CacheControl    string
+}
+
+// writeObjectStorage uploads data to an object storage URI
+func writeObjectStorage(ctx context.Context, target string, data []byte, meta ObjectMetadata) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid object storage URI %s: %v", target, err)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return fmt.Errorf("invalid object storage URI %s: bucket and object are required", target)
+	}
+
+	switch u.Scheme {
+	case "s3":
+		args := []string{"s3", "cp", "--only-show-errors", "-", target}
+		if meta.ContentType != "" {
+			args = append(args, "--content-type", meta.ContentType)
+		}
+		if meta.ContentEncoding != "" {
+			args = append(args, "--content-encoding", meta.ContentEncoding)
+		}
+		if meta.CacheControl != "" {
+			args = append(args, "--cache-control", meta.CacheControl)
+		}
+		_, err = runToolContext(ctx, data, "aws", args...)
+		return err
+	case "gs":
+		args := []string{"storage", "cp", "-", target}
+		if meta.ContentType != "" {
+			args = append(args, "--content-type="+meta.ContentType)
+		}
+		if meta.ContentEncoding != "" {
+			args = append(args, "--content-encoding="+meta.ContentEncoding)
+		}
+		if meta.CacheControl != "" {
+			args = append(args, "--cache-control="+meta.CacheControl)
+		}
+		_, err = runToolContext(ctx, data, "gcloud", args...)
+		return err
+	case "azblob":
+		container, blob, found := strings.Cut(key, "/")
+		if !found || blob == "" {
+			return fmt.Errorf("invalid Azure Blob URI %s (format: azblob://account/container/blob)", target)
+		}
+		return uploadAzureBlob(ctx, u.Host, container, blob, data, meta)
+	}
+	return fmt.Errorf("unsupported object storage scheme %s", u.Scheme)
+}
+
+// uploadAzureBlob uploads a blob through a temporary file
+func uploadAzureBlob(ctx context.Context, account, container, blob string, data []byte, meta ObjectMetadata) error {
+	dir, err := os.MkdirTemp("", "swagger-merger-azblob-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "blob")
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return err
+	}
+
+	args := []string{"storage", "blob", "upload",
+		"--auth-mode", "login",
+		"--account-name", account,
+		"--container-name", container,
+		"--name", blob,
+		"--file", file,
+		"--overwrite",
+		"--only-show-errors",
+	}
+	if meta.ContentType != "" {
+		args = append(args, "--content-type", meta.ContentType)
+	}
+	if meta.ContentEncoding != "" {
+		args = append(args, "--content-encoding", meta.ContentEncoding)
+	}
+	if meta.CacheControl != "" {
+		args = append(args, "--content-cache-control", meta.CacheControl)
+	}
+	_, err = runToolContext(ctx, nil, "az", args...)
+	return err
+}
@@ -0,0 +1,95 @@
+package merger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTool installs an executable script named name on PATH that prints
+// its arguments followed by output
+func fakeTool(t *testing.T, name, output string) {
+	t.Helper()
+	fakeToolScript(t, name, "echo \"$@\"\ncat <<'EOF'\n"+output+"\nEOF\n")
+}
+
+// fakeToolScript puts a shell script named name first on PATH
+func fakeToolScript(t *testing.T, name, body string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts not supported")
+	}
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" + body
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestReadObjectStorageSource(t *testing.T) {
+	fakeTool(t, "aws", "spec")
+	fakeTool(t, "gcloud", "spec")
+
+	data, err := readObjectStorageSource(context.Background(), "s3://specs/users/openapi.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(string(data), "s3 cp --only-show-errors s3://specs/users/openapi.yaml -") {
+		t.Errorf("Unexpected aws invocation: %s", data)
+	}
+
+	data, err = readObjectStorageSource(context.Background(), "gs://specs/orders.yaml")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(string(data), "storage cat gs://specs/orders.yaml") {
+		t.Errorf("Unexpected gcloud invocation: %s", data)
+	}
+
+	for _, source := range []string{"s3://bucket-only", "azblob://account/container"} {
+		if _, err := readObjectStorageSource(context.Background(), source); err == nil {
+			t.Errorf("Expected error for %s", source)
+		}
+	}
+}
+
+func TestMergeToObjectStorage(t *testing.T) {
+	dir := t.TempDir()
+	// Record stdin and arguments of the upload
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat > " + filepath.Join(dir, "body") + "\n"
+	awsDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(awsDir, "aws"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", awsDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	file, err := createTempSwaggerFile(testSpec)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	m := New(Config{
+		InputPaths:         []string{file},
+		OutputPath:         "s3://docs-site/openapi.yaml",
+		OutputCacheControl: "max-age=300",
+	})
+	if err := m.Merge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if !strings.Contains(string(args), "s3://docs-site/openapi.yaml --content-type application/yaml --cache-control max-age=300") {
+		t.Errorf("Unexpected aws invocation: %s", args)
+	}
+
+	body, _ := os.ReadFile(filepath.Join(dir, "body"))
+	if !strings.Contains(string(body), "Remote API") {
+		t.Errorf("Expected merged document to be uploaded, got %s", body)
+	}
+}
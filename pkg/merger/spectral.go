@@ -0,0 +1,523 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// spectralRuleset is the supported subset of a Spectral ruleset. extends
+// is ignored: the built-in rules, named after their Spectral equivalents,
+// are always registered.
+type spectralRuleset struct {
+	Rules map[string]yaml.Node `yaml:"rules"`
+}
+
+// spectralRule is a custom rule of a Spectral ruleset
+type spectralRule struct {
+	Description string          `yaml:"description"`
+	Message     string          `yaml:"message"`
+	Severity    yaml.Node       `yaml:"severity"`
+	Given       yaml.Node       `yaml:"given"`
+	Then        yaml.Node       `yaml:"then"`
+	Recommended *bool           `yaml:"recommended"`
+	given       []string        `yaml:"-"`
+	then        []spectralCheck `yaml:"-"`
+}
+
+// spectralCheck is a then entry of a Spectral rule
+type spectralCheck struct {
+	Field           string         `yaml:"field"`
+	Function        string         `yaml:"function"`
+	FunctionOptions map[string]any `yaml:"functionOptions"`
+}
+
+// AddSpectralRuleset loads the rules of a Spectral ruleset (YAML or JSON)
+// into the linter. A rule set to a severity or to false overrides the
+// built-in rule of that name. Custom rules are supported with given paths
+// made of $, .name, ..name, .*, [*], [n] and ['name'] segments, and the
+// truthy, falsy, defined, undefined, pattern, enumeration, length and
+// casing functions. Overrides of unknown rules and custom rules using
+// anything else are skipped; their names are returned.
+func (l *Linter) AddSpectralRuleset(data []byte) ([]string, error) {
+	var ruleset spectralRuleset
+	if err := yaml.Unmarshal(data, &ruleset); err != nil {
+		return nil, fmt.Errorf("failed to parse Spectral ruleset: %v", err)
+	}
+
+	var skipped []string
+	config := LintConfig{Rules: make(map[string]Severity)}
+	for _, name := range sortedKeys(ruleset.Rules) {
+		node := ruleset.Rules[name]
+		if node.Kind == yaml.ScalarNode {
+			severity, err := spectralSeverity(&node)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %v", name, err)
+			}
+			if l.rule(name) == nil {
+				skipped = append(skipped, name)
+			} else if node.Value != "true" {
+				// true keeps the default severity of the rule
+				config.Rules[name] = severity
+			}
+			continue
+		}
+
+		var rule spectralRule
+		if err := node.Decode(&rule); err != nil {
+			return nil, fmt.Errorf("rule %s: %v", name, err)
+		}
+		if rule.Given.IsZero() && rule.Then.IsZero() {
+			// Only overrides the severity of a built-in rule
+			if l.rule(name) == nil {
+				skipped = append(skipped, name)
+				continue
+			}
+			severity, err := spectralSeverity(&rule.Severity)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %v", name, err)
+			}
+			config.Rules[name] = severity
+			continue
+		}
+
+		lintRule, err := rule.compile(name)
+		if err != nil {
+			skipped = append(skipped, name)
+			continue
+		}
+		l.Add(lintRule)
+	}
+
+	if err := l.Configure(config); err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+// rule returns the registered rule called name
+func (l *Linter) rule(name string) *LintRule {
+	for i := range l.rules {
+		if l.rules[i].Name == name {
+			return &l.rules[i]
+		}
+	}
+	return nil
+}
+
+// spectralSeverity converts a Spectral severity: error, warn, info, hint,
+// off, their numeric forms 0 to 3, or true and false enabling and disabling
+// a rule. Hints are reported as info, an unset severity is warn.
+func spectralSeverity(node *yaml.Node) (Severity, error) {
+	if node.IsZero() {
+		return SeverityWarn, nil
+	}
+	switch node.Value {
+	case "error", "0":
+		return SeverityError, nil
+	case "warn", "1", "true":
+		return SeverityWarn, nil
+	case "info", "2", "hint", "3":
+		return SeverityInfo, nil
+	case "off", "-1", "false":
+		return SeverityOff, nil
+	}
+	return "", fmt.Errorf("invalid severity %q", node.Value)
+}
+
+// compile turns a Spectral rule into a lint rule, failing on what is not
+// supported
+func (r *spectralRule) compile(name string) (LintRule, error) {
+	severity, err := spectralSeverity(&r.Severity)
+	if err != nil {
+		return LintRule{}, err
+	}
+	if r.Recommended != nil && !*r.Recommended {
+		severity = SeverityOff
+	}
+	if err := decodeOneOrMany(&r.Given, &r.given); err != nil {
+		return LintRule{}, fmt.Errorf("given: %v", err)
+	}
+	if err := decodeOneOrMany(&r.Then, &r.then); err != nil {
+		return LintRule{}, fmt.Errorf("then: %v", err)
+	}
+	for _, given := range r.given {
+		if _, err := parseJSONPath(given); err != nil {
+			return LintRule{}, err
+		}
+	}
+	for _, check := range r.then {
+		if _, err := check.test(nil, false); err != nil {
+			return LintRule{}, err
+		}
+	}
+
+	rule := *r
+	return LintRule{
+		Name:        name,
+		Description: r.Description,
+		Severity:    severity,
+		Check: func(doc *openapi3.T) []LintFinding {
+			return rule.check(doc)
+		},
+	}, nil
+}
+
+// decodeOneOrMany decodes a YAML value that is either one item or a list
+func decodeOneOrMany[T any](node *yaml.Node, items *[]T) error {
+	if node.Kind == yaml.SequenceNode {
+		return node.Decode(items)
+	}
+	var item T
+	if err := node.Decode(&item); err != nil {
+		return err
+	}
+	*items = []T{item}
+	return nil
+}
+
+// check runs the rule against the generic JSON form of doc
+func (r *spectralRule) check(doc *openapi3.T) []LintFinding {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil
+	}
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil
+	}
+
+	var findings []LintFinding
+	for _, given := range r.given {
+		segments, _ := parseJSONPath(given)
+		for _, node := range evalJSONPath(root, segments) {
+			for _, check := range r.then {
+				target, location, defined := node.value, node.path, true
+				switch {
+				case check.Field == "@key":
+					if len(node.path) == 0 {
+						continue
+					}
+					target = node.path[len(node.path)-1]
+				case check.Field != "":
+					for _, key := range strings.Split(check.Field, ".") {
+						object, _ := target.(map[string]any)
+						target, defined = object[key]
+						location = append(location[:len(location):len(location)], key)
+						if !defined {
+							break
+						}
+					}
+				}
+
+				message, _ := check.test(target, defined)
+				if message == "" {
+					continue
+				}
+				findings = append(findings, r.finding(location, target, message))
+			}
+		}
+	}
+	return findings
+}
+
+// finding builds the finding of a failed check, expanding the {{error}},
+// {{description}}, {{path}}, {{property}} and {{value}} placeholders of
+// the rule message
+func (r *spectralRule) finding(path []string, value any, message string) LintFinding {
+	location := strings.Join(path, ".")
+	if r.Message != "" {
+		property := ""
+		if len(path) > 0 {
+			property = path[len(path)-1]
+		}
+		message = strings.NewReplacer(
+			"{{error}}", message,
+			"{{description}}", r.Description,
+			"{{path}}", location,
+			"{{property}}", property,
+			"{{value}}", fmt.Sprint(value),
+		).Replace(r.Message)
+	}
+	finding := LintFinding{Location: location, Message: message}
+	switch {
+	case len(path) > 1 && path[0] == "paths":
+		finding.Path = path[1]
+	case len(path) > 2 && path[0] == "components" && path[1] == "schemas":
+		finding.Schema = path[2]
+	}
+	return finding
+}
+
+// test applies the check function to a value, returning a message when
+// the value fails it. Undefined values only fail truthy and defined.
+func (c spectralCheck) test(value any, defined bool) (string, error) {
+	option := func(name string) (any, bool) {
+		v, ok := c.FunctionOptions[name]
+		return v, ok
+	}
+
+	switch c.Function {
+	case "truthy":
+		if !defined || !truthy(value) {
+			return "property must be truthy", nil
+		}
+	case "falsy":
+		if defined && truthy(value) {
+			return "property must be falsy", nil
+		}
+	case "defined":
+		if !defined {
+			return "property must be defined", nil
+		}
+	case "undefined":
+		if defined {
+			return "property must be undefined", nil
+		}
+	case "pattern":
+		for _, name := range []string{"match", "notMatch"} {
+			pattern, ok := option(name)
+			if !ok {
+				continue
+			}
+			re, err := spectralRegexp(fmt.Sprint(pattern))
+			if err != nil {
+				return "", err
+			}
+			s, isString := value.(string)
+			if !defined || !isString {
+				continue
+			}
+			if matched := re.MatchString(s); matched != (name == "match") {
+				if name == "match" {
+					return fmt.Sprintf("%q must match the pattern %q", s, pattern), nil
+				}
+				return fmt.Sprintf("%q must not match the pattern %q", s, pattern), nil
+			}
+		}
+	case "enumeration":
+		values, _ := option("values")
+		list, _ := values.([]any)
+		if !defined {
+			return "", nil
+		}
+		for _, v := range list {
+			if reflect.DeepEqual(v, value) || fmt.Sprint(v) == fmt.Sprint(value) {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("%v must be one of %v", value, list), nil
+	case "length":
+		if !defined {
+			return "", nil
+		}
+		var length float64
+		switch v := value.(type) {
+		case string:
+			length = float64(len([]rune(v)))
+		case []any:
+			length = float64(len(v))
+		case map[string]any:
+			length = float64(len(v))
+		case float64:
+			length = v
+		default:
+			return "", nil
+		}
+		if min, ok := option("min"); ok && length < toFloat(min) {
+			return fmt.Sprintf("length must be at least %v", min), nil
+		}
+		if max, ok := option("max"); ok && length > toFloat(max) {
+			return fmt.Sprintf("length must be at most %v", max), nil
+		}
+	case "casing":
+		casing, _ := option("type")
+		re, ok := casingPatterns[fmt.Sprint(casing)]
+		if !ok {
+			return "", fmt.Errorf("unsupported casing %v", casing)
+		}
+		if s, isString := value.(string); defined && isString && !re.MatchString(s) {
+			return fmt.Sprintf("%q must be %v case", s, casing), nil
+		}
+	default:
+		return "", fmt.Errorf("unsupported function %q", c.Function)
+	}
+	return "", nil
+}
+
+// truthy reports whether a JSON value is truthy in JavaScript
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	}
+	return true
+}
+
+// toFloat converts a YAML number option
+func toFloat(v any) float64 {
+	f, _ := strconv.ParseFloat(fmt.Sprint(v), 64)
+	return f
+}
+
+// spectralRegexp compiles a Spectral pattern, either a plain regular
+// expression or a /regex/flags literal
+func spectralRegexp(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "/") {
+		if end := strings.LastIndex(pattern, "/"); end > 0 {
+			flags := pattern[end+1:]
+			pattern = pattern[1:end]
+			if strings.Contains(flags, "i") {
+				pattern = "(?i)" + pattern
+			}
+		}
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported pattern %q: %v", pattern, err)
+	}
+	return re, nil
+}
+
+// casingPatterns matches the casing types of the Spectral casing function
+var casingPatterns = map[string]*regexp.Regexp{
+	"flat":   regexp.MustCompile(`^[a-z][a-z0-9]*$`),
+	"camel":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`),
+	"pascal": regexp.MustCompile(`^[A-Z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`),
+	"kebab":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:-[a-z0-9]+)*$`),
+	"cobol":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(?:-[A-Z0-9]+)*$`),
+	"snake":  regexp.MustCompile(`^[a-z][a-z0-9]*(?:_[a-z0-9]+)*$`),
+	"macro":  regexp.MustCompile(`^[A-Z][A-Z0-9]*(?:_[A-Z0-9]+)*$`),
+}
+
+// jsonPathSegment is a step of a JSONPath: a key, "*" for every child, or
+// a recursive descent to a key
+type jsonPathSegment struct {
+	key       string
+	recursive bool
+}
+
+// parseJSONPath parses the supported subset of JSONPath
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("unsupported given %q: must start with $", path)
+	}
+	var segments []jsonPathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			key, remaining := cutJSONPathName(rest[2:])
+			if key == "" {
+				return nil, fmt.Errorf("unsupported given %q", path)
+			}
+			segments = append(segments, jsonPathSegment{key: key, recursive: true})
+			rest = remaining
+		case strings.HasPrefix(rest, "."):
+			key, remaining := cutJSONPathName(rest[1:])
+			if key == "" {
+				return nil, fmt.Errorf("unsupported given %q", path)
+			}
+			segments = append(segments, jsonPathSegment{key: key})
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unsupported given %q", path)
+			}
+			key := rest[1:end]
+			if unquoted, err := strconv.Unquote(strings.ReplaceAll(key, "'", `"`)); err == nil {
+				key = unquoted
+			} else if key != "*" {
+				if _, err := strconv.Atoi(key); err != nil {
+					return nil, fmt.Errorf("unsupported given %q: filters are not supported", path)
+				}
+			}
+			segments = append(segments, jsonPathSegment{key: key})
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unsupported given %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// cutJSONPathName splits the name at the start of a dotted JSONPath
+// segment from the rest
+func cutJSONPathName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// jsonPathNode is a value matched by a JSONPath, with its location
+type jsonPathNode struct {
+	path  []string
+	value any
+}
+
+// evalJSONPath returns the nodes of root matched by segments, in order
+func evalJSONPath(root any, segments []jsonPathSegment) []jsonPathNode {
+	nodes := []jsonPathNode{{value: root}}
+	for _, segment := range segments {
+		var next []jsonPathNode
+		for _, node := range nodes {
+			if segment.recursive {
+				next = append(next, descendants(node, segment.key)...)
+			} else {
+				next = append(next, children(node, segment.key)...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// children returns the child of node called key, or all its children for *
+func children(node jsonPathNode, key string) []jsonPathNode {
+	child := func(k string, v any) jsonPathNode {
+		return jsonPathNode{path: append(node.path[:len(node.path):len(node.path)], k), value: v}
+	}
+	var result []jsonPathNode
+	switch v := node.value.(type) {
+	case map[string]any:
+		if key == "*" {
+			for _, k := range sortedKeys(v) {
+				result = append(result, child(k, v[k]))
+			}
+		} else if value, ok := v[key]; ok {
+			result = append(result, child(key, value))
+		}
+	case []any:
+		for i, value := range v {
+			if k := strconv.Itoa(i); key == "*" || key == k {
+				result = append(result, child(k, value))
+			}
+		}
+	}
+	return result
+}
+
+// descendants returns the children called key of node and of every node
+// below it
+func descendants(node jsonPathNode, key string) []jsonPathNode {
+	result := children(node, key)
+	for _, child := range children(node, "*") {
+		result = append(result, descendants(child, key)...)
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package merger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddSpectralRuleset(t *testing.T) {
+	linter, err := NewLinter(LintConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	skipped, err := linter.AddSpectralRuleset([]byte(`
+extends: spectral:oas
+rules:
+  operation-operationId: warn
+  operation-summary: off
+  no-empty-schema:
+    severity: 0
+  info-contact: error
+  operation-description:
+    description: Operations must have a description
+    message: "{{description}}: {{path}}"
+    severity: error
+    given: $.paths[*][*]
+    then:
+      field: description
+      function: truthy
+  schema-names-pascal:
+    given: $.components.schemas[*]
+    then:
+      field: '@key'
+      function: casing
+      functionOptions: {type: pascal}
+  no-x-internal:
+    given: "$..x-internal"
+    severity: info
+    then:
+      function: falsy
+  path-no-trailing-slash:
+    given: "$.paths[*]"
+    then:
+      - field: '@key'
+        function: pattern
+        functionOptions: {notMatch: '/$'}
+  filtered:
+    given: "$.paths[?(@.get)]"
+    then: {function: truthy}
+  custom-function:
+    given: $.info
+    then: {function: myFunction}
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if expected := []string{"custom-function", "filtered", "info-contact"}; !reflect.DeepEqual(skipped, expected) {
+		t.Errorf("Expected skipped rules %v, got %v", expected, skipped)
+	}
+
+	var got []string
+	for _, finding := range linter.Lint(lintDoc(t)) {
+		got = append(got, string(finding.Severity)+" "+finding.Rule+" "+finding.Location+": "+finding.Message)
+	}
+	expected := []string{
+		"error no-empty-schema components.schemas.Anything: schema is empty",
+		"error operation-success-response paths./health.get: operation has no 2xx response",
+		"error operation-description paths./health.get.description: Operations must have a description: paths./health.get.description",
+		"error operation-description paths./users.get.description: Operations must have a description: paths./users.get.description",
+		"warn operation-operationId paths./users.post: operation has no operationId",
+		"error operation-description paths./users.post.description: Operations must have a description: paths./users.post.description",
+		"error no-empty-schema paths./users.post.requestBody.content.application/json.schema: schema is empty",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected findings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestSpectralFunctions(t *testing.T) {
+	tests := []struct {
+		check   spectralCheck
+		value   any
+		defined bool
+		fails   bool
+	}{
+		{spectralCheck{Function: "truthy"}, "", true, true},
+		{spectralCheck{Function: "truthy"}, nil, false, true},
+		{spectralCheck{Function: "falsy"}, false, true, false},
+		{spectralCheck{Function: "defined"}, nil, false, true},
+		{spectralCheck{Function: "undefined"}, "x", true, true},
+		{spectralCheck{Function: "pattern", FunctionOptions: map[string]any{"match": "/^v[0-9]+$/i"}}, "V2", true, false},
+		{spectralCheck{Function: "pattern", FunctionOptions: map[string]any{"match": "^v[0-9]+$"}}, "beta", true, true},
+		{spectralCheck{Function: "pattern", FunctionOptions: map[string]any{"match": "^v"}}, nil, false, false},
+		{spectralCheck{Function: "enumeration", FunctionOptions: map[string]any{"values": []any{"a", "b"}}}, "c", true, true},
+		{spectralCheck{Function: "length", FunctionOptions: map[string]any{"max": 3}}, "abcd", true, true},
+		{spectralCheck{Function: "length", FunctionOptions: map[string]any{"min": 1}}, []any{"a"}, true, false},
+		{spectralCheck{Function: "casing", FunctionOptions: map[string]any{"type": "kebab"}}, "user-orders", true, false},
+		{spectralCheck{Function: "casing", FunctionOptions: map[string]any{"type": "camel"}}, "UserId", true, true},
+	}
+	for _, tt := range tests {
+		message, err := tt.check.test(tt.value, tt.defined)
+		if err != nil {
+			t.Errorf("%s(%v): unexpected error %v", tt.check.Function, tt.value, err)
+		}
+		if fails := message != ""; fails != tt.fails {
+			t.Errorf("%s %v (%v): expected failing %v, got %q", tt.check.Function, tt.check.FunctionOptions, tt.value, tt.fails, message)
+		}
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	segments, err := parseJSONPath(`$.paths[*]['get']..schema[0]`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []jsonPathSegment{{key: "paths"}, {key: "*"}, {key: "get"}, {key: "schema", recursive: true}, {key: "0"}}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Errorf("Expected %v, got %v", expected, segments)
+	}
+
+	for _, path := range []string{"paths", "$.paths[?(@.get)]", "$.."} {
+		if _, err := parseJSONPath(path); err == nil {
+			t.Errorf("Expected an error for %s", path)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SplitBy selects how a merged document is split into several documents
+type SplitBy string
+
+const (
+	// SplitByTag writes one document per operation tag; operations with
+	// several tags appear in each of their documents
+	SplitByTag SplitBy = "tag"
+	// SplitByPathSegment writes one document per first path segment, e.g.
+	// /orders and /orders/{id} go to the orders document
+	SplitByPathSegment SplitBy = "path-segment"
+)
+
+const (
+	// untaggedPart holds the operations without tags when splitting by tag
+	untaggedPart = "untagged"
+	// rootPart holds the operations of / when splitting by path segment
+	rootPart = "root"
+)
+
+// splitKeys returns the parts an operation belongs to
+func (by SplitBy) splitKeys(path string, op *openapi3.Operation) ([]string, error) {
+	switch by {
+	case SplitByTag:
+		if len(op.Tags) == 0 {
+			return []string{untaggedPart}, nil
+		}
+		return op.Tags, nil
+	case SplitByPathSegment:
+		segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		if segment == "" {
+			return []string{rootPart}, nil
+		}
+		return []string{segment}, nil
+	}
+	return nil, fmt.Errorf("invalid split mode %q", by)
+}
+
+// SplitDocument splits a document into parts keyed by tag or first path
+// segment. Each part keeps the info, servers and security of
+// the document, and only the components and tags its operations use.
+func SplitDocument(doc *openapi3.T, by SplitBy) (map[string]*openapi3.T, error) {
+	parts := make(map[string]*openapi3.T)
+	for _, path := range doc.Paths.InMatchingOrder() {
+		item := doc.Paths.Value(path)
+		for method, op := range item.Operations() {
+			keys, err := by.splitKeys(path, op)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range keys {
+				part, ok := parts[key]
+				if !ok {
+					part = newPart(doc, key)
+					parts[key] = part
+				}
+				partItem := part.Paths.Value(path)
+				if partItem == nil {
+					partItem = &openapi3.PathItem{
+						Extensions:  item.Extensions,
+						Ref:         item.Ref,
+						Summary:     item.Summary,
+						Description: item.Description,
+						Servers:     item.Servers,
+						Parameters:  item.Parameters,
+					}
+					part.Paths.Set(path, partItem)
+				}
+				partItem.SetOperation(method, op)
+			}
+		}
+	}
+
+	for _, part := range parts {
+		if err := pruneComponents(part, doc.Components); err != nil {
+			return nil, err
+		}
+		part.Tags = usedTags(part, doc.Tags)
+	}
+	return parts, nil
+}
+
+// newPart returns an empty part of doc
+func newPart(doc *openapi3.T, key string) *openapi3.T {
+	part := &openapi3.T{
+		Extensions:   doc.Extensions,
+		OpenAPI:      doc.OpenAPI,
+		Servers:      doc.Servers,
+		Security:     doc.Security,
+		ExternalDocs: doc.ExternalDocs,
+		Paths:        openapi3.NewPaths(),
+	}
+	if doc.Info != nil {
+		info := *doc.Info
+		info.Title = strings.TrimSpace(info.Title + " - " + key)
+		part.Info = &info
+	}
+	return part
+}
+
+// usedTags returns the tags referenced by the operations of doc, keeping
+// their definitions from tags
+func usedTags(doc *openapi3.T, tags openapi3.Tags) openapi3.Tags {
+	used := make(map[string]bool)
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			for _, tag := range op.Tags {
+				used[tag] = true
+			}
+		}
+	}
+	var result openapi3.Tags
+	for _, tag := range tags {
+		if tag != nil && used[tag.Name] {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// componentRef matches references to components
+var componentRef = regexp.MustCompile(`"\$ref":"#/components/([A-Za-z]+)/([^"]+)"`)
+
+// collectRefs adds the components referenced by v to refs and returns the
+// newly found ones
+func collectRefs(v any, refs map[string]bool) ([]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var found []string
+	for _, match := range componentRef.FindAllStringSubmatch(string(data), -1) {
+		ref := match[1] + "/" + match[2]
+		if !refs[ref] {
+			refs[ref] = true
+			found = append(found, ref)
+		}
+	}
+	return found, nil
+}
+
+// componentValue returns a component by kind and name
+func componentValue(c *openapi3.Components, kind, name string) any {
+	switch kind {
+	case "schemas":
+		return c.Schemas[name]
+	case "responses":
+		return c.Responses[name]
+	case "parameters":
+		return c.Parameters[name]
+	case "requestBodies":
+		return c.RequestBodies[name]
+	case "headers":
+		return c.Headers[name]
+	case "examples":
+		return c.Examples[name]
+	case "links":
+		return c.Links[name]
+	case "callbacks":
+		return c.Callbacks[name]
+	case "securitySchemes":
+		return c.SecuritySchemes[name]
+	}
+	return nil
+}
+
+// pruneComponents sets the components of doc to those of all it
+// references, directly or through other components. Security schemes are
+// kept since security requirements reference them by name.
+func pruneComponents(doc *openapi3.T, all *openapi3.Components) error {
+	if all == nil {
+		return nil
+	}
+
+	refs := make(map[string]bool)
+	queue, err := collectRefs(doc.Paths, refs)
+	if err != nil {
+		return err
+	}
+	for len(queue) > 0 {
+		ref := queue[0]
+		queue = queue[1:]
+		kind, name, _ := strings.Cut(ref, "/")
+		value := componentValue(all, kind, unescapeRef(name))
+		if value == nil {
+			continue
+		}
+		found, err := collectRefs(value, refs)
+		if err != nil {
+			return err
+		}
+		queue = append(queue, found...)
+	}
+
+	c := &openapi3.Components{Extensions: all.Extensions, SecuritySchemes: all.SecuritySchemes}
+	for ref := range refs {
+		kind, name, _ := strings.Cut(ref, "/")
+		name = unescapeRef(name)
+		switch kind {
+		case "schemas":
+			if v, ok := all.Schemas[name]; ok {
+				if c.Schemas == nil {
+					c.Schemas = openapi3.Schemas{}
+				}
+				c.Schemas[name] = v
+			}
+		case "responses":
+			if v, ok := all.Responses[name]; ok {
+				if c.Responses == nil {
+					c.Responses = openapi3.ResponseBodies{}
+				}
+				c.Responses[name] = v
+			}
+		case "parameters":
+			if v, ok := all.Parameters[name]; ok {
+				if c.Parameters == nil {
+					c.Parameters = openapi3.ParametersMap{}
+				}
+				c.Parameters[name] = v
+			}
+		case "requestBodies":
+			if v, ok := all.RequestBodies[name]; ok {
+				if c.RequestBodies == nil {
+					c.RequestBodies = openapi3.RequestBodies{}
+				}
+				c.RequestBodies[name] = v
+			}
+		case "headers":
+			if v, ok := all.Headers[name]; ok {
+				if c.Headers == nil {
+					c.Headers = openapi3.Headers{}
+				}
+				c.Headers[name] = v
+			}
+		case "examples":
+			if v, ok := all.Examples[name]; ok {
+				if c.Examples == nil {
+					c.Examples = openapi3.Examples{}
+				}
+				c.Examples[name] = v
+			}
+		case "links":
+			if v, ok := all.Links[name]; ok {
+				if c.Links == nil {
+					c.Links = openapi3.Links{}
+				}
+				c.Links[name] = v
+			}
+		case "callbacks":
+			if v, ok := all.Callbacks[name]; ok {
+				if c.Callbacks == nil {
+					c.Callbacks = openapi3.Callbacks{}
+				}
+				c.Callbacks[name] = v
+			}
+		}
+	}
+	doc.Components = c
+	return nil
+}
+
+// unescapeRef decodes a JSON pointer token
+func unescapeRef(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// unsafeFileChars matches characters replaced in part file names
+var unsafeFileChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// partFileName returns a file name for a part
+func partFileName(key string) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(strings.ToLower(key), "-"), "-.")
+	if name == "" {
+		name = rootPart
+	}
+	return name
+}
+
+// splitIndexEntry describes a part in the split index
+type splitIndexEntry struct {
+	Name  string `json:"name" yaml:"name"`
+	URL   string `json:"url" yaml:"url"`
+	Paths int    `json:"paths" yaml:"paths"`
+}
+
+// WriteSplit splits the document produced by the last merge and writes
+// each part to dir, along with an index listing the parts. The index can be
+// used as an index+ input.
+func (m *Merger) WriteSplit(dir string, by SplitBy, format OutputFormat) error {
+	if m.merged == nil {
+		return fmt.Errorf("nothing to split, merge first")
+	}
+	if format != FormatYAML && format != FormatJSON {
+		return fmt.Errorf("invalid format %q (expected yaml or json)", format)
+	}
+
+	parts, err := SplitDocument(m.merged, by)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %v", dir, err)
+	}
+
+	keys := make([]string, 0, len(parts))
+	for key := range parts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	index := struct {
+		Title string            `json:"title,omitempty" yaml:"title,omitempty"`
+		Specs []splitIndexEntry `json:"specs" yaml:"specs"`
+	}{}
+	if m.merged.Info != nil {
+		index.Title = m.merged.Info.Title
+	}
+
+	// The index file name is reserved, so a part named index gets a suffix
+	used := map[string]bool{"index": true}
+	for _, key := range keys {
+		name := partFileName(key)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d", partFileName(key), i)
+		}
+		used[name] = true
+		file := name + "." + string(format)
+
+		data, err := m.encode(parts[key], format)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0644); err != nil {
+			return fmt.Errorf("error writing file: %v", err)
+		}
+		index.Specs = append(index.Specs, splitIndexEntry{Name: key, URL: file, Paths: parts[key].Paths.Len()})
+	}
+
+	data, err := m.encode(index, format)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index."+string(format)), data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %v", err)
+	}
+	m.config.Logger.Debug("wrote split output", "dir", dir, "by", by, "parts", len(parts))
+	return nil
+}
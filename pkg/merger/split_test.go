@@ -0,0 +1,183 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const splitSpec = `openapi: "3.0.1"
+info:
+  title: Shop API
+  version: 1.0.0
+tags:
+  - name: Users
+  - name: Orders
+paths:
+  /users:
+    get:
+      tags: [Users]
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+  /orders:
+    get:
+      tags: [Orders, Users]
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Order"
+    post:
+      responses:
+        "201":
+          description: Created
+  /health:
+    get:
+      responses:
+        "200":
+          description: OK
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        address:
+          $ref: "#/components/schemas/Address"
+    Address:
+      type: object
+    Order:
+      type: object
+      properties:
+        customer:
+          $ref: "#/components/schemas/Customer"
+    Customer:
+      type: object
+    Unused:
+      type: object
+`
+
+func loadSplitSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, splitSpec)
+}
+
+func schemaNames(doc *openapi3.T) []string {
+	var names []string
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSplitDocumentByTag(t *testing.T) {
+	parts, err := SplitDocument(loadSplitSpec(t), SplitByTag)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(parts) != 3 {
+		t.Fatalf("Expected Users, Orders and untagged parts, got %d", len(parts))
+	}
+
+	users := parts["Users"]
+	if users.Paths.Len() != 2 || users.Paths.Value("/orders").Post != nil {
+		t.Errorf("Unexpected Users paths: %v", users.Paths.Map())
+	}
+	if got := schemaNames(users); len(got) != 4 {
+		t.Errorf("Expected all referenced schemas in Users, got %v", got)
+	}
+	if users.Info.Title != "Shop API - Users" || len(users.Tags) != 2 {
+		t.Errorf("Unexpected Users info/tags: %s %v", users.Info.Title, users.Tags)
+	}
+
+	orders := parts["Orders"]
+	if got := schemaNames(orders); len(got) != 2 || got[0] != "Customer" || got[1] != "Order" {
+		t.Errorf("Expected Order and Customer schemas, got %v", got)
+	}
+
+	untagged := parts[untaggedPart]
+	if untagged.Paths.Len() != 2 || untagged.Paths.Value("/orders").Get != nil || len(untagged.Components.Schemas) != 0 {
+		t.Errorf("Unexpected untagged part: %v", untagged.Paths.Map())
+	}
+}
+
+func TestWriteSplit(t *testing.T) {
+	dir := t.TempDir()
+	m := New(Config{})
+	m.merged = loadSplitSpec(t)
+
+	if err := m.WriteSplit(dir, SplitByTag, FormatYAML); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, file := range []string{"index.yaml", "users.yaml", "orders.yaml", "untagged.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+		}
+	}
+
+	// The index can be merged back as an index input
+	sources, err := New(Config{}).expandInputs([]string{"index+" + filepath.Join(dir, "index.yaml")})
+	if err != nil || len(sources) != 3 {
+		t.Errorf("Expected index to list 3 parts, got %v (%v)", sources, err)
+	}
+}
+
+func TestWriteSplitIndexTag(t *testing.T) {
+	dir := t.TempDir()
+	m := New(Config{})
+	m.merged = loadSplitSpec(t)
+	m.merged.Paths.Value("/users").Get.Tags = []string{"Index"}
+
+	if err := m.WriteSplit(dir, SplitByTag, FormatYAML); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	part, err := openapi3.NewLoader().LoadFromFile(filepath.Join(dir, "index-2.yaml"))
+	if err != nil {
+		t.Fatalf("Expected the Index part in index-2.yaml: %v", err)
+	}
+	if part.Paths.Value("/users") == nil {
+		t.Errorf("Expected /users in the Index part, got %v", part.Paths.Map())
+	}
+	sources, err := New(Config{}).expandInputs([]string{"index+" + filepath.Join(dir, "index.yaml")})
+	if err != nil || len(sources) != 4 {
+		t.Errorf("Expected the index to list 4 parts, got %v (%v)", sources, err)
+	}
+}
+
+func TestSplitDocumentByPathSegment(t *testing.T) {
+	doc := loadSplitSpec(t)
+	doc.Paths.Set("/orders/{id}", &openapi3.PathItem{Delete: &openapi3.Operation{Responses: openapi3.NewResponses()}})
+	doc.Paths.Set("/", &openapi3.PathItem{Get: &openapi3.Operation{Responses: openapi3.NewResponses()}})
+
+	parts, err := SplitDocument(doc, SplitByPathSegment)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var keys []string
+	for key := range parts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) != 4 || keys[0] != "health" || keys[1] != "orders" || keys[2] != rootPart || keys[3] != "users" {
+		t.Fatalf("Unexpected parts: %v", keys)
+	}
+
+	orders := parts["orders"]
+	if orders.Paths.Len() != 2 || orders.Paths.Value("/orders").Post == nil {
+		t.Errorf("Expected both /orders paths with all operations, got %v", orders.Paths.Map())
+	}
+	if got := schemaNames(orders); len(got) != 2 {
+		t.Errorf("Expected only Order and Customer schemas, got %v", got)
+	}
+}
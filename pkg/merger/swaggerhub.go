@@ -0,0 +1,168 @@
+package merger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultSwaggerHubURL is the registry API of the hosted SwaggerHub
+const defaultSwaggerHubURL = "https://api.swaggerhub.com"
+
+// SwaggerHubConfig configures access to the SwaggerHub registry API
+type SwaggerHubConfig struct {
+	// APIKey authenticates requests; required for private APIs
+	APIKey string
+	// URL is the registry API base URL, for SwaggerHub On-Premise.
+	// Defaults to https://api.swaggerhub.com.
+	URL string
+}
+
+// isSwaggerHubSource reports whether path is a swaggerhub:// input
+func isSwaggerHubSource(path string) bool {
+	return strings.HasPrefix(path, "swaggerhub://")
+}
+
+// swaggerHubAPI identifies an API definition on SwaggerHub
+type swaggerHubAPI struct {
+	Owner   string
+	API     string
+	Version string
+}
+
+// parseSwaggerHubURI parses swaggerhub://owner/api[/version]
+func parseSwaggerHubURI(uri string) (*swaggerHubAPI, error) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(uri, "swaggerhub://"), "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid SwaggerHub URI %s (format: swaggerhub://owner/api[/version])", uri)
+	}
+
+	api := &swaggerHubAPI{Owner: parts[0], API: parts[1]}
+	if len(parts) == 3 {
+		api.Version = parts[2]
+	}
+	return api, nil
+}
+
+// baseURL returns the registry API URL of the API
+func (c SwaggerHubConfig) baseURL(api *swaggerHubAPI) string {
+	base := c.URL
+	if base == "" {
+		base = defaultSwaggerHubURL
+	}
+	return fmt.Sprintf("%s/apis/%s/%s", strings.TrimSuffix(base, "/"), url.PathEscape(api.Owner), url.PathEscape(api.API))
+}
+
+// headers returns the authentication headers for registry requests
+func (c SwaggerHubConfig) headers() map[string]string {
+	if c.APIKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": c.APIKey}
+}
+
+// readSwaggerHubSource downloads an API definition from SwaggerHub. Without
+// a version, the API's default version is used.
+func (m *Merger) readSwaggerHubSource(source string) ([]byte, error) {
+	api, err := parseSwaggerHubURI(source)
+	if err != nil {
+		return nil, err
+	}
+
+	hub := m.config.SwaggerHub
+	base := hub.baseURL(api)
+
+	if api.Version == "" {
+		data, err := m.fetchURLWithHeaders(base+"/settings/default", hub.headers())
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up default version of %s/%s: %v", api.Owner, api.API, err)
+		}
+		var settings struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(data, &settings); err != nil || settings.Version == "" {
+			return nil, fmt.Errorf("no default version set for %s/%s", api.Owner, api.API)
+		}
+		api.Version = settings.Version
+	}
+
+	return m.fetchURLWithHeaders(base+"/"+url.PathEscape(api.Version)+"/swagger.yaml", hub.headers())
+}
+
+// PublishToSwaggerHub uploads the document produced by the last merge to
+// SwaggerHub. target has the form swaggerhub://owner/api/version and may
+// set ?private=false to publish a public API (private by default).
+func (m *Merger) PublishToSwaggerHub(target string) error {
+	return m.PublishToSwaggerHubContext(context.Background(), target)
+}
+
+// PublishToSwaggerHubContext is PublishToSwaggerHub with the upload
+// cancelled once ctx is done
+func (m *Merger) PublishToSwaggerHubContext(ctx context.Context, target string) error {
+	return m.withContext(ctx, func() error { return m.publishToSwaggerHub(target) })
+}
+
+// publishToSwaggerHub posts the merged document to the registry API
+func (m *Merger) publishToSwaggerHub(target string) error {
+	if m.merged == nil {
+		return fmt.Errorf("nothing to publish, merge first")
+	}
+
+	api, err := parseSwaggerHubURI(strings.SplitN(target, "?", 2)[0])
+	if err != nil {
+		return err
+	}
+	if api.Version == "" {
+		return fmt.Errorf("SwaggerHub publish target %s needs a version", target)
+	}
+
+	private := "true"
+	if _, query, found := strings.Cut(target, "?"); found {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return fmt.Errorf("invalid SwaggerHub publish target %s: %v", target, err)
+		}
+		if values.Get("private") == "false" {
+			private = "false"
+		}
+	}
+
+	data, err := m.encode(m.merged, FormatYAML)
+	if err != nil {
+		return err
+	}
+
+	client, err := m.httpClient()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{"version": {api.Version}, "isPrivate": {private}, "force": {"true"}}
+	req, err := http.NewRequestWithContext(m.context(), http.MethodPost, m.config.SwaggerHub.baseURL(api)+"?"+params.Encode(), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid SwaggerHub URL: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+	for name, value := range m.config.SwaggerHub.headers() {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to SwaggerHub: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("SwaggerHub publish failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	m.config.Logger.Debug("published to SwaggerHub", "owner", api.Owner, "api", api.API, "version", api.Version)
+	return nil
+}
@@ -0,0 +1,81 @@
+package merger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestReadSwaggerHubSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "hub-key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/apis/acme/users/settings/default":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"version":"1.2.0"}`))
+		case "/apis/acme/users/1.2.0/swagger.yaml":
+			w.Write([]byte(testSpec))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	m := New(Config{SwaggerHub: SwaggerHubConfig{APIKey: "hub-key", URL: server.URL}})
+
+	for _, source := range []string{"swaggerhub://acme/users/1.2.0", "swaggerhub://acme/users"} {
+		data, err := m.readDataFromPath(source)
+		if err != nil {
+			t.Fatalf("Expected no error for %s, got %v", source, err)
+		}
+		if string(data) != testSpec {
+			t.Errorf("Unexpected content for %s: %s", source, data)
+		}
+	}
+
+	if _, err := parseSwaggerHubURI("swaggerhub://acme"); err == nil {
+		t.Error("Expected error for URI without API name")
+	}
+}
+
+func TestPublishToSwaggerHub(t *testing.T) {
+	var published string
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/apis/acme/platform" || r.Header.Get("Authorization") != "hub-key" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		published = string(body)
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	m := New(Config{SwaggerHub: SwaggerHubConfig{APIKey: "hub-key", URL: server.URL}})
+	if err := m.PublishToSwaggerHub("swaggerhub://acme/platform/2.0.0"); err == nil {
+		t.Error("Expected error before merging")
+	}
+
+	m.merged = &openapi3.T{OpenAPI: "3.0.1", Info: &openapi3.Info{Title: "Platform API", Version: "2.0.0"}}
+	if err := m.PublishToSwaggerHub("swaggerhub://acme/platform/2.0.0?private=false"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(published, "Platform API") {
+		t.Errorf("Expected merged document to be uploaded, got %s", published)
+	}
+
+	if query.Get("version") != "2.0.0" || query.Get("isPrivate") != "false" {
+		t.Errorf("Unexpected publish parameters: %v", query)
+	}
+}
@@ -0,0 +1,237 @@
+package merger
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseTagOrder parses a YAML or JSON list of tag names, the curated tag
+// sequence passed to OrderTags
+func ParseTagOrder(data []byte) ([]string, error) {
+	var order []string
+	if err := yaml.Unmarshal(data, &order); err != nil {
+		return nil, fmt.Errorf("expected a list of tag names: %v", err)
+	}
+	for i, name := range order {
+		if name == "" {
+			return nil, fmt.Errorf("tag %d has no name", i+1)
+		}
+	}
+	return order, nil
+}
+
+// SortTags returns a transformer sorting the tags of the merged document
+// by name, which orders the operation groups of documentation UIs
+func SortTags() Transformer {
+	return NewTransformer("sort-tags", func(doc *openapi3.T, source string) error {
+		sort.SliceStable(doc.Tags, func(i, j int) bool {
+			return doc.Tags[i].Name < doc.Tags[j].Name
+		})
+		return nil
+	})
+}
+
+// OrderTags returns a transformer pinning the tags of the merged document
+// to a curated sequence. The listed tags come first, in order, followed by
+// the other tags as merged. Listed tags that operations use but no input
+// declares are declared, so they are ordered too.
+func OrderTags(order []string) Transformer {
+	return NewTransformer("tag-order", func(doc *openapi3.T, source string) error {
+		used := make(map[string]bool)
+		if doc.Paths != nil {
+			for _, item := range doc.Paths.Map() {
+				for _, op := range item.Operations() {
+					for _, name := range op.Tags {
+						used[name] = true
+					}
+				}
+			}
+		}
+
+		position := make(map[string]int, len(order))
+		for i, name := range order {
+			if _, ok := position[name]; ok {
+				continue
+			}
+			position[name] = i
+			if used[name] && doc.Tags.Get(name) == nil {
+				doc.Tags = append(doc.Tags, &openapi3.Tag{Name: name})
+			}
+		}
+
+		sort.SliceStable(doc.Tags, func(i, j int) bool {
+			pi, iPinned := position[doc.Tags[i].Name]
+			pj, jPinned := position[doc.Tags[j].Name]
+			if iPinned && jPinned {
+				return pi < pj
+			}
+			return iPinned && !jPinned
+		})
+		return nil
+	})
+}
+
+// tagGroupsKey is the Redoc extension grouping tags in the navigation
+const tagGroupsKey = "x-tagGroups"
+
+// otherTagsGroup holds the tags no group lists, which Redoc would hide
+const otherTagsGroup = "Other"
+
+// TagGroup is an entry of the x-tagGroups extension
+type TagGroup struct {
+	Name string   `json:"name" yaml:"name"`
+	Tags []string `json:"tags" yaml:"tags"`
+}
+
+// ParseTagGroups parses a YAML or JSON list of tag groups, in the format
+// of x-tagGroups
+func ParseTagGroups(data []byte) ([]TagGroup, error) {
+	var groups []TagGroup
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("expected a list of tag groups: %v", err)
+	}
+	for i, group := range groups {
+		if group.Name == "" {
+			return nil, fmt.Errorf("tag group %d has no name", i+1)
+		}
+	}
+	return groups, nil
+}
+
+// TagGroups returns a transformer setting the Redoc x-tagGroups extension
+// of the merged document to groups. Tags of the document that no group
+// lists are added to an "Other" group, as Redoc hides ungrouped tags.
+func TagGroups(groups []TagGroup) Transformer {
+	return NewTransformer("tag-groups", func(doc *openapi3.T, source string) error {
+		setTagGroups(doc, groups)
+		return nil
+	})
+}
+
+// TagGroupsBySource returns the transformers generating x-tagGroups with
+// one group per input service, named after the info.title of the input
+// (or its file name), listing the tags the input declares or uses. The
+// input transformer records the tags of every input, the merged one writes
+// the groups; merges reset the recorded tags when they start.
+func TagGroupsBySource() (input, merged Transformer) {
+	var (
+		mu     sync.Mutex
+		groups []TagGroup
+	)
+	record := func(doc *openapi3.T, source string) error {
+		name := path.Base(source)
+		if doc.Info != nil && doc.Info.Title != "" {
+			name = doc.Info.Title
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		i := 0
+		for i < len(groups) && groups[i].Name != name {
+			i++
+		}
+		if i == len(groups) {
+			groups = append(groups, TagGroup{Name: name})
+		}
+		groups[i].Tags = appendNew(groups[i].Tags, documentTags(doc)...)
+		return nil
+	}
+	input = statefulTransformer{
+		transformerFunc: transformerFunc{name: "tag-groups", fn: record},
+		reset: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			groups = nil
+		},
+	}
+	merged = NewTransformer("tag-groups", func(doc *openapi3.T, source string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		setTagGroups(doc, groups)
+		groups = nil
+		return nil
+	})
+	return input, merged
+}
+
+// setTagGroups sets the x-tagGroups extension of doc, grouping the tags
+// of doc that groups leave out under otherTagsGroup. Documents without
+// tags are left alone.
+func setTagGroups(doc *openapi3.T, groups []TagGroup) {
+	grouped := make(map[string]bool)
+	result := make([]TagGroup, 0, len(groups)+1)
+	for _, group := range groups {
+		if len(group.Tags) == 0 {
+			continue
+		}
+		result = append(result, TagGroup{Name: group.Name, Tags: appendNew(nil, group.Tags...)})
+		for _, tag := range group.Tags {
+			grouped[tag] = true
+		}
+	}
+
+	var other []string
+	for _, tag := range documentTags(doc) {
+		if !grouped[tag] {
+			other = append(other, tag)
+		}
+	}
+	if len(other) > 0 {
+		i := 0
+		for i < len(result) && !strings.EqualFold(result[i].Name, otherTagsGroup) {
+			i++
+		}
+		if i == len(result) {
+			result = append(result, TagGroup{Name: otherTagsGroup})
+		}
+		result[i].Tags = appendNew(result[i].Tags, other...)
+	}
+	if len(result) == 0 {
+		return
+	}
+
+	if doc.Extensions == nil {
+		doc.Extensions = make(map[string]any)
+	}
+	doc.Extensions[tagGroupsKey] = result
+}
+
+// documentTags returns the declared tags of doc, in order, followed by the
+// undeclared tags its operations use, sorted
+func documentTags(doc *openapi3.T) []string {
+	var tags []string
+	for _, tag := range doc.Tags {
+		tags = appendNew(tags, tag.Name)
+	}
+	var undeclared []string
+	if doc.Paths != nil {
+		for _, item := range doc.Paths.Map() {
+			for _, op := range item.Operations() {
+				for _, name := range op.Tags {
+					if doc.Tags.Get(name) == nil {
+						undeclared = appendNew(undeclared, name)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(undeclared)
+	return append(tags, undeclared...)
+}
+
+// appendNew appends the values missing from list
+func appendNew(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,135 @@
+package merger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// tagsDoc returns a document declaring the users, orders and billing tags,
+// with an operation using the undeclared admin tag
+func tagsDoc(t *testing.T) *openapi3.T {
+	t.Helper()
+	return loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+tags:
+  - name: users
+  - name: orders
+  - name: billing
+paths:
+  /admin:
+    get:
+      tags: [admin]
+      responses: {"200": {description: ok}}
+`)
+}
+
+// tagNames returns the names of the tags of a document
+func tagNames(doc *openapi3.T) []string {
+	var names []string
+	for _, tag := range doc.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func TestSortTags(t *testing.T) {
+	doc := tagsDoc(t)
+	if err := SortTags().Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := tagNames(doc), []string{"billing", "orders", "users"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestOrderTags(t *testing.T) {
+	doc := tagsDoc(t)
+	if err := OrderTags([]string{"admin", "orders", "missing"}).Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got, expected := tagNames(doc), []string{"admin", "orders", "users", "billing"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseTagOrder(t *testing.T) {
+	order, err := ParseTagOrder([]byte("- users\n- orders\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(order, []string{"users", "orders"}) {
+		t.Errorf("Unexpected order: %v", order)
+	}
+
+	if _, err := ParseTagOrder([]byte("users: 1")); err == nil {
+		t.Error("Expected an error for a map")
+	}
+}
+
+func TestTagGroupsBySource(t *testing.T) {
+	users := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Users, version: "1.0"}
+tags: [{name: users}]
+paths:
+  /users:
+    get:
+      tags: [users, profiles]
+      responses: {"200": {description: ok}}
+`)
+	orders := tagsDoc(t)
+	orders.Info.Title = ""
+
+	input, merged := TagGroupsBySource()
+	if err := input.Transform(users, "specs/users.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if err := input.Transform(orders, "specs/orders.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := MergeDocuments([]*openapi3.T{users, orders})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := merged.Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TagGroup{
+		{Name: "Users", Tags: []string{"users", "profiles"}},
+		{Name: "orders.yaml", Tags: []string{"users", "orders", "billing", "admin"}},
+	}
+	if got := doc.Extensions["x-tagGroups"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestTagGroups(t *testing.T) {
+	groups, err := ParseTagGroups([]byte(`
+- name: Commerce
+  tags: [orders, billing]
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	doc := tagsDoc(t)
+	if err := TagGroups(groups).Transform(doc, ""); err != nil {
+		t.Fatal(err)
+	}
+	expected := []TagGroup{
+		{Name: "Commerce", Tags: []string{"orders", "billing"}},
+		{Name: "Other", Tags: []string{"users", "admin"}},
+	}
+	if got := doc.Extensions["x-tagGroups"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if _, err := ParseTagGroups([]byte("- tags: [users]")); err == nil {
+		t.Error("Expected an error for a group without a name")
+	}
+}
@@ -0,0 +1,103 @@
+package merger
+
+import "time"
+
+// Telemetry receives the merger's traces and metrics. It follows the
+// OpenTelemetry model of spans, histograms and counters, so services
+// embedding the merger can forward them to an OpenTelemetry tracer and
+// meter with a small adapter, without the library depending on the SDK.
+//
+// The merger records these spans, each with a duration histogram named
+// after it with a ".duration" suffix:
+//   - swagger_merger.merge: a whole merge, parent of the other spans
+//   - swagger_merger.fetch: reading an input (attribute source)
+//   - swagger_merger.convert: parsing and converting an input to OpenAPI
+//     3 (attribute source)
+//
+// and the counter swagger_merger.conflicts (attribute kind).
+type Telemetry interface {
+	// StartSpan starts a span, a child of parent unless parent is nil
+	StartSpan(parent Span, name string, attrs ...Attribute) Span
+	// RecordDuration adds a sample to the duration histogram name
+	RecordDuration(name string, d time.Duration, attrs ...Attribute)
+	// AddCount adds n to the counter name
+	AddCount(name string, n int64, attrs ...Attribute)
+}
+
+// Span is an operation traced by Telemetry
+type Span interface {
+	// End ends the span, with the error of the operation or nil
+	End(err error)
+}
+
+// Attribute is a key/value pair describing a span or metric sample. Values
+// are strings or ints.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// MultiTelemetry returns a Telemetry forwarding to every telemetry
+func MultiTelemetry(telemetry ...Telemetry) Telemetry {
+	return multiTelemetry(telemetry)
+}
+
+// multiTelemetry forwards to several telemetries
+type multiTelemetry []Telemetry
+
+// multiSpan holds the spans of a multiTelemetry, by index
+type multiSpan []Span
+
+func (t multiTelemetry) StartSpan(parent Span, name string, attrs ...Attribute) Span {
+	spans := make(multiSpan, len(t))
+	for i, telemetry := range t {
+		var p Span
+		if parent, ok := parent.(multiSpan); ok {
+			p = parent[i]
+		}
+		spans[i] = telemetry.StartSpan(p, name, attrs...)
+	}
+	return spans
+}
+
+func (t multiTelemetry) RecordDuration(name string, d time.Duration, attrs ...Attribute) {
+	for _, telemetry := range t {
+		telemetry.RecordDuration(name, d, attrs...)
+	}
+}
+
+func (t multiTelemetry) AddCount(name string, n int64, attrs ...Attribute) {
+	for _, telemetry := range t {
+		telemetry.AddCount(name, n, attrs...)
+	}
+}
+
+func (s multiSpan) End(err error) {
+	for _, span := range s {
+		span.End(err)
+	}
+}
+
+// nopTelemetry discards all traces and metrics
+type nopTelemetry struct{}
+
+func (nopTelemetry) StartSpan(Span, string, ...Attribute) Span          { return nopSpan{} }
+func (nopTelemetry) RecordDuration(string, time.Duration, ...Attribute) {}
+func (nopTelemetry) AddCount(string, int64, ...Attribute)               {}
+
+// nopSpan is the span of nopTelemetry
+type nopSpan struct{}
+
+func (nopSpan) End(error) {}
+
+// trace starts the span swagger_merger.<name>, a child of the current
+// merge span, and returns it with a function ending it and recording its
+// duration
+func (m *Merger) trace(name string, attrs ...Attribute) (Span, func(err error)) {
+	start := time.Now()
+	span := m.config.Telemetry.StartSpan(m.span, "swagger_merger."+name, attrs...)
+	return span, func(err error) {
+		m.config.Telemetry.RecordDuration("swagger_merger."+name+".duration", time.Since(start), attrs...)
+		span.End(err)
+	}
+}
@@ -0,0 +1,109 @@
+package merger
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingTelemetry records span names, with their parent, and metrics
+type recordingTelemetry struct {
+	mu       sync.Mutex
+	spans    []string
+	ended    int
+	metrics  map[string]int
+	counters map[string]int64
+}
+
+// recordingSpan is a span of recordingTelemetry
+type recordingSpan struct {
+	t    *recordingTelemetry
+	name string
+}
+
+func (s *recordingSpan) End(error) {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	s.t.ended++
+}
+
+func (t *recordingTelemetry) StartSpan(parent Span, name string, attrs ...Attribute) Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry := name
+	if parent != nil {
+		entry = parent.(*recordingSpan).name + " > " + name
+	}
+	t.spans = append(t.spans, entry)
+	return &recordingSpan{t: t, name: name}
+}
+
+func (t *recordingTelemetry) RecordDuration(name string, d time.Duration, attrs ...Attribute) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.metrics[name]++
+}
+
+func (t *recordingTelemetry) AddCount(name string, n int64, attrs ...Attribute) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, attr := range attrs {
+		name += " " + attr.Key + "=" + attr.Value.(string)
+	}
+	t.counters[name] += n
+}
+
+func TestTelemetry(t *testing.T) {
+	var files []string
+	for _, title := range []string{"Users", "Orders"} {
+		file, err := createTempSwaggerFile(`openapi: "3.0.1"
+info:
+  title: ` + title + `
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Error: {type: object, description: ` + title + `}`)
+		if err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		defer os.Remove(file)
+		files = append(files, file)
+	}
+
+	telemetry := &recordingTelemetry{metrics: make(map[string]int), counters: make(map[string]int64)}
+	m := New(Config{InputPaths: files, Telemetry: telemetry})
+	if _, err := m.loadAndMerge(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sort.Strings(telemetry.spans)
+	expected := strings.Join([]string{
+		"swagger_merger.merge",
+		"swagger_merger.merge > swagger_merger.convert",
+		"swagger_merger.merge > swagger_merger.convert",
+		"swagger_merger.merge > swagger_merger.fetch",
+		"swagger_merger.merge > swagger_merger.fetch",
+	}, "\n")
+	if got := strings.Join(telemetry.spans, "\n"); got != expected {
+		t.Errorf("Expected spans:\n%s\ngot:\n%s", expected, got)
+	}
+	if telemetry.ended != len(telemetry.spans) {
+		t.Errorf("Expected all %d spans to end, %d did", len(telemetry.spans), telemetry.ended)
+	}
+	for name, count := range map[string]int{
+		"swagger_merger.merge.duration":   1,
+		"swagger_merger.fetch.duration":   2,
+		"swagger_merger.convert.duration": 2,
+	} {
+		if telemetry.metrics[name] != count {
+			t.Errorf("Expected %d samples of %s, got %d", count, name, telemetry.metrics[name])
+		}
+	}
+	if n := telemetry.counters["swagger_merger.conflicts kind=schema"]; n != 1 {
+		t.Errorf("Expected 1 schema conflict counted, got %d", n)
+	}
+}
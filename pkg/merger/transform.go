@@ -0,0 +1,188 @@
+package merger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Stage identifies the point of the merge at which a transformer runs
+type Stage string
+
+const (
+	// StageInput runs on every input document after conversion to OpenAPI 3.0
+	StageInput Stage = "input"
+	// StageMerged runs once on the merged document
+	StageMerged Stage = "merged"
+)
+
+// Transformer modifies a document as part of a Pipeline
+type Transformer interface {
+	// Name identifies the transformer so it can be found and removed
+	Name() string
+	// Transform modifies doc in place. source is the input the document was
+	// read from, or empty for the merged document.
+	Transform(doc *openapi3.T, source string) error
+}
+
+// ContextTransformer is a Transformer that can be cancelled, such as one
+// running an external command. Merges call TransformContext with their
+// context instead of Transform.
+type ContextTransformer interface {
+	Transformer
+	TransformContext(ctx context.Context, doc *openapi3.T, source string) error
+}
+
+// Resetter is implemented by transformers keeping state across the
+// documents of a merge, such as the input transformer of APIVersions.
+// Merges reset their pipeline before loading inputs, so a merge failing
+// halfway leaves nothing behind for the next one.
+type Resetter interface {
+	Reset()
+}
+
+// transformerFunc adapts a plain function to the Transformer interface
+type transformerFunc struct {
+	name string
+	fn   func(doc *openapi3.T, source string) error
+}
+
+func (t transformerFunc) Name() string { return t.name }
+
+func (t transformerFunc) Transform(doc *openapi3.T, source string) error {
+	return t.fn(doc, source)
+}
+
+// statefulTransformer is a transformerFunc whose state is cleared by reset
+type statefulTransformer struct {
+	transformerFunc
+	reset func()
+}
+
+func (t statefulTransformer) Reset() { t.reset() }
+
+// NewTransformer creates a named Transformer from a function
+func NewTransformer(name string, fn func(doc *openapi3.T, source string) error) Transformer {
+	return transformerFunc{name: name, fn: fn}
+}
+
+// Pipeline holds the ordered transformers run over the input documents and
+// the merged result
+type Pipeline struct {
+	Input  []Transformer
+	Merged []Transformer
+}
+
+// DefaultPipeline returns the built-in pipeline: every input document is
+// stamped as OpenAPI 3.0.1 and gets its servers replaced by servers.
+func DefaultPipeline(servers []Server) *Pipeline {
+	return &Pipeline{
+		Input: []Transformer{
+			VersionStamp("3.0.1"),
+			ServerOverride(servers),
+		},
+	}
+}
+
+// Add appends a transformer to the given stage. It panics for a stage
+// other than StageInput and StageMerged, which would never run.
+func (p *Pipeline) Add(stage Stage, t Transformer) {
+	switch stage {
+	case StageInput:
+		p.Input = append(p.Input, t)
+	case StageMerged:
+		p.Merged = append(p.Merged, t)
+	default:
+		panic(fmt.Sprintf("merger: unknown pipeline stage %q for transformer %s", stage, t.Name()))
+	}
+}
+
+// Reset clears the state of the transformers implementing Resetter
+func (p *Pipeline) Reset() {
+	if p == nil {
+		return
+	}
+	for _, ts := range [][]Transformer{p.Input, p.Merged} {
+		for _, t := range ts {
+			if r, ok := t.(Resetter); ok {
+				r.Reset()
+			}
+		}
+	}
+}
+
+// Remove drops every transformer with the given name from both stages
+func (p *Pipeline) Remove(name string) {
+	p.Input = removeTransformer(p.Input, name)
+	p.Merged = removeTransformer(p.Merged, name)
+}
+
+// removeTransformer returns ts without the transformers called name
+func removeTransformer(ts []Transformer, name string) []Transformer {
+	kept := ts[:0]
+	for _, t := range ts {
+		if t.Name() != name {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// Run applies the transformers of a stage to doc in order
+func (p *Pipeline) Run(stage Stage, doc *openapi3.T, source string) error {
+	return p.RunContext(context.Background(), stage, doc, source)
+}
+
+// RunContext is Run passing ctx to the transformers implementing
+// ContextTransformer
+func (p *Pipeline) RunContext(ctx context.Context, stage Stage, doc *openapi3.T, source string) error {
+	if p == nil {
+		return nil
+	}
+
+	var ts []Transformer
+	switch stage {
+	case StageInput:
+		ts = p.Input
+	case StageMerged:
+		ts = p.Merged
+	default:
+		return fmt.Errorf("unknown pipeline stage %q", stage)
+	}
+
+	for _, t := range ts {
+		var err error
+		if ct, ok := t.(ContextTransformer); ok {
+			err = ct.TransformContext(ctx, doc, source)
+		} else {
+			err = t.Transform(doc, source)
+		}
+		if err != nil {
+			return fmt.Errorf("transformer %s failed: %v", t.Name(), err)
+		}
+	}
+	return nil
+}
+
+// VersionStamp returns a transformer that sets the openapi version field
+func VersionStamp(version string) Transformer {
+	return NewTransformer("version-stamp", func(doc *openapi3.T, source string) error {
+		doc.OpenAPI = version
+		return nil
+	})
+}
+
+// ServerOverride returns a transformer that replaces the document servers
+func ServerOverride(servers []Server) Transformer {
+	return NewTransformer("server-override", func(doc *openapi3.T, source string) error {
+		doc.Servers = make(openapi3.Servers, len(servers))
+		for i, server := range servers {
+			doc.Servers[i] = &openapi3.Server{
+				URL:         server.URL,
+				Description: server.Description,
+			}
+		}
+		return nil
+	})
+}
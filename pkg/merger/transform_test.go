@@ -0,0 +1,70 @@
+package merger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestDefaultPipeline(t *testing.T) {
+	pipeline := DefaultPipeline([]Server{{URL: "https://api.example.com", Description: "Example"}})
+
+	doc := &openapi3.T{OpenAPI: "3.0.3"}
+	if err := pipeline.Run(StageInput, doc, "a.yaml"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if doc.OpenAPI != "3.0.1" {
+		t.Errorf("Expected version '3.0.1', got '%s'", doc.OpenAPI)
+	}
+
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != "https://api.example.com" {
+		t.Errorf("Expected configured server, got %v", doc.Servers)
+	}
+}
+
+func TestPipelineRemoveAndAdd(t *testing.T) {
+	pipeline := DefaultPipeline(DefaultServers())
+	pipeline.Remove("server-override")
+	pipeline.Add(StageMerged, NewTransformer("retitle", func(doc *openapi3.T, source string) error {
+		doc.Info.Title = "Merged API"
+		return nil
+	}))
+
+	file, err := createTempSwaggerFile(`openapi: "3.0.3"
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://original.example.com
+paths: {}`)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(file)
+
+	m := New(Config{InputPaths: []string{file}, Pipeline: pipeline})
+	merged, err := m.loadAndMerge()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(merged.Servers) != 1 || merged.Servers[0].URL != "https://original.example.com" {
+		t.Errorf("Expected original servers to be kept, got %v", merged.Servers)
+	}
+
+	if merged.Info.Title != "Merged API" {
+		t.Errorf("Expected title 'Merged API', got '%s'", merged.Info.Title)
+	}
+
+	// Unknown stages are programming errors rather than silently ignored
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), `"output"`) {
+			t.Errorf("Expected a panic naming the unknown stage, got %v", r)
+		}
+	}()
+	pipeline.Add(Stage("output"), NewTransformer("noop", func(doc *openapi3.T, source string) error { return nil }))
+}
@@ -0,0 +1,217 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// unbundledRoot is the name of the root document of an unbundled output
+const unbundledRoot = "openapi"
+
+// unsafeRefFileChars matches characters replaced in unbundled file names
+var unsafeRefFileChars = regexp.MustCompile(`[^A-Za-z0-9._{}-]+`)
+
+// pathFile returns the slash-separated file of a path item without its
+// extension, relative to the output directory, e.g. /users/{id} is
+// paths/users_{id}
+func pathFile(p string) string {
+	name := strings.ReplaceAll(strings.Trim(p, "/"), "/", "_")
+	name = unsafeRefFileChars.ReplaceAllString(name, "_")
+	if name == "" {
+		name = rootPart
+	}
+	return "paths/" + name
+}
+
+// componentFile returns the slash-separated file of a component without
+// its extension, relative to the output directory, e.g.
+// components/schemas/User
+func componentFile(kind, name string) string {
+	return "components/" + kind + "/" + unsafeRefFileChars.ReplaceAllString(name, "_")
+}
+
+// unbundleLayout assigns the files of an unbundled document. Names mapping
+// to the same file, such as /users/{id} and /users_{id}, get a numeric
+// suffix as in WriteSplit.
+type unbundleLayout struct {
+	format OutputFormat
+	// used holds the assigned files, lowercased for case-insensitive file
+	// systems
+	used map[string]bool
+	// paths and components map path templates and "kind/name" keys to
+	// their files
+	paths      map[string]string
+	components map[string]string
+}
+
+// newUnbundleLayout assigns files to the path items and components of a
+// document decoded as JSON, in sorted order so suffixes are stable
+func newUnbundleLayout(root map[string]any, format OutputFormat) *unbundleLayout {
+	l := &unbundleLayout{
+		format:     format,
+		used:       make(map[string]bool),
+		paths:      make(map[string]string),
+		components: make(map[string]string),
+	}
+	if paths, ok := root["paths"].(map[string]any); ok {
+		for _, p := range sortedKeys(paths) {
+			if !strings.HasPrefix(p, "x-") {
+				l.paths[p] = l.assign(pathFile(p))
+			}
+		}
+	}
+	if components, ok := root["components"].(map[string]any); ok {
+		for _, kind := range sortedKeys(components) {
+			named, ok := components[kind].(map[string]any)
+			if !ok || kind == "securitySchemes" || strings.HasPrefix(kind, "x-") {
+				continue
+			}
+			for _, name := range sortedKeys(named) {
+				l.components[kind+"/"+name] = l.assign(componentFile(kind, name))
+			}
+		}
+	}
+	return l
+}
+
+// assign returns an unused file for base with the extension of the format
+func (l *unbundleLayout) assign(base string) string {
+	name := base
+	for i := 2; l.used[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	l.used[strings.ToLower(name)] = true
+	return name + "." + string(l.format)
+}
+
+// componentFile returns the file of a component, or the file it would
+// have for components missing from the document
+func (l *unbundleLayout) componentFile(kind, name string) string {
+	if file, ok := l.components[kind+"/"+name]; ok {
+		return file
+	}
+	return componentFile(kind, name) + "." + string(l.format)
+}
+
+// relativeRef rewrites a local #/components/... reference found in the
+// file at from into a reference relative to that file; other references
+// are returned unchanged
+func (l *unbundleLayout) relativeRef(ref, from string) string {
+	pointer, ok := strings.CutPrefix(ref, "#/components/")
+	if !ok {
+		return ref
+	}
+	parts := strings.SplitN(pointer, "/", 3)
+	if len(parts) < 2 || parts[0] == "securitySchemes" {
+		return ref
+	}
+
+	target := l.componentFile(parts[0], unescapeRef(parts[1]))
+	rel, err := filepath.Rel(path.Dir(from), target)
+	if err != nil {
+		return ref
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+	if len(parts) == 3 {
+		rel += "#/" + parts[2]
+	}
+	return rel
+}
+
+// rewriteRefs rewrites the references of a generic JSON value written to
+// the file at from
+func (l *unbundleLayout) rewriteRefs(v any, from string) {
+	rewriteRefValues(v, func(ref string) string {
+		return l.relativeRef(ref, from)
+	})
+}
+
+// UnbundleDocument splits a document into a root document and separate
+// files for every path item and component, linked by relative $refs. It
+// returns the contents keyed by slash-separated file name; the root is
+// openapi.yaml (or openapi.json). Security schemes stay in the root since
+// security requirements reference them by name.
+func UnbundleDocument(doc *openapi3.T, format OutputFormat) (map[string]any, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %v", err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %v", err)
+	}
+
+	files := make(map[string]any)
+	layout := newUnbundleLayout(root, format)
+
+	if paths, ok := root["paths"].(map[string]any); ok {
+		for p, item := range paths {
+			if strings.HasPrefix(p, "x-") {
+				continue
+			}
+			file := layout.paths[p]
+			layout.rewriteRefs(item, file)
+			files[file] = item
+			paths[p] = map[string]any{"$ref": "./" + file}
+		}
+	}
+
+	if components, ok := root["components"].(map[string]any); ok {
+		for kind, values := range components {
+			named, ok := values.(map[string]any)
+			if !ok || kind == "securitySchemes" || strings.HasPrefix(kind, "x-") {
+				continue
+			}
+			for name, value := range named {
+				file := layout.components[kind+"/"+name]
+				layout.rewriteRefs(value, file)
+				files[file] = value
+				named[name] = map[string]any{"$ref": "./" + file}
+			}
+		}
+	}
+
+	files[unbundledRoot+"."+string(format)] = root
+	return files, nil
+}
+
+// WriteUnbundled writes the document produced by the last merge to dir as
+// a root document plus one file per path item and component
+func (m *Merger) WriteUnbundled(dir string, format OutputFormat) error {
+	if m.merged == nil {
+		return fmt.Errorf("nothing to unbundle, merge first")
+	}
+	if format != FormatYAML && format != FormatJSON {
+		return fmt.Errorf("invalid format %q (expected yaml or json)", format)
+	}
+
+	files, err := UnbundleDocument(m.merged, format)
+	if err != nil {
+		return err
+	}
+	for name, content := range files {
+		data, err := m.encode(content, format)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return fmt.Errorf("error creating %s: %v", filepath.Dir(file), err)
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return fmt.Errorf("error writing file: %v", err)
+		}
+	}
+	m.config.Logger.Debug("wrote unbundled output", "dir", dir, "files", len(files))
+	return nil
+}
@@ -0,0 +1,116 @@
+package merger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestWriteUnbundled(t *testing.T) {
+	for _, format := range []OutputFormat{FormatYAML, FormatJSON} {
+		dir := t.TempDir()
+		m := New(Config{})
+		m.merged = loadSplitSpec(t)
+
+		if err := m.WriteUnbundled(dir, format); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		for _, file := range []string{"paths/users", "paths/orders", "components/schemas/User", "components/schemas/Address"} {
+			if _, err := os.Stat(filepath.Join(dir, file+"."+string(format))); err != nil {
+				t.Errorf("Expected %s.%s to be written: %v", file, format, err)
+			}
+		}
+
+		// The unbundled files load back into an equivalent document
+		loader := openapi3.NewLoader()
+		loader.IsExternalRefsAllowed = true
+		doc, err := loader.LoadFromFile(filepath.Join(dir, "openapi."+string(format)))
+		if err != nil {
+			t.Fatalf("Failed to load unbundled %s output: %v", format, err)
+		}
+		if err := doc.Validate(context.Background()); err != nil {
+			t.Errorf("Unbundled %s output is invalid: %v", format, err)
+		}
+		schema := doc.Paths.Value("/users").Get.Responses.Status(200).Value.Content["application/json"].Schema.Value
+		if schema == nil || schema.Properties["address"].Value == nil {
+			t.Errorf("Expected User schema with resolved address in %s output", format)
+		}
+	}
+}
+
+func TestUnbundleCollidingNames(t *testing.T) {
+	doc := loadSpec(t, `openapi: "3.0.1"
+info: {title: API, version: "1.0"}
+paths:
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          description: by id
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User Name"}
+  /users_{id}:
+    get:
+      responses:
+        "200":
+          description: by underscore
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User_Name"}
+components:
+  schemas:
+    User Name: {type: string}
+    User_Name: {type: integer}
+`)
+	files, err := UnbundleDocument(doc, FormatYAML)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, file := range []string{"paths/users_{id}.yaml", "paths/users_{id}-2.yaml", "components/schemas/User_Name.yaml", "components/schemas/User_Name-2.yaml"} {
+		if files[file] == nil {
+			t.Errorf("Expected %s, got %v", file, sortedKeys(files))
+		}
+	}
+
+	dir := t.TempDir()
+	m := New(Config{})
+	m.merged = doc
+	if err := m.WriteUnbundled(dir, FormatYAML); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	unbundled, err := loader.LoadFromFile(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load unbundled output: %v", err)
+	}
+	for path, expected := range map[string]string{"/users/{id}": "string", "/users_{id}": "integer"} {
+		response := unbundled.Paths.Value(path).Get.Responses.Status(200).Value
+		if schema := response.Content["application/json"].Schema.Value; schema == nil || !schema.Type.Is(expected) {
+			t.Errorf("Expected %s to keep its %s schema, got %+v", path, expected, schema)
+		}
+	}
+}
+
+func TestRelativeRef(t *testing.T) {
+	tests := []struct {
+		ref, from, want string
+	}{
+		{"#/components/schemas/User", "paths/users.yaml", "../components/schemas/User.yaml"},
+		{"#/components/schemas/Address", "components/schemas/User.yaml", "./Address.yaml"},
+		{"#/components/responses/Error", "components/schemas/User.yaml", "../responses/Error.yaml"},
+		{"#/components/schemas/User/properties/id", "paths/users.yaml", "../components/schemas/User.yaml#/properties/id"},
+		{"https://example.com/common.yaml#/Error", "paths/users.yaml", "https://example.com/common.yaml#/Error"},
+	}
+	layout := &unbundleLayout{format: FormatYAML}
+	for _, tt := range tests {
+		if got := layout.relativeRef(tt.ref, tt.from); got != tt.want {
+			t.Errorf("relativeRef(%q, %q) = %q, want %q", tt.ref, tt.from, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package merger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExampleIssue is an example of a request or response body that does not
+// conform to the body schema
+type ExampleIssue struct {
+	// Source is the input that contributed the operation, when known
+	Source string `json:"source,omitempty"`
+	Path   string `json:"path"`
+	Method string `json:"method"`
+	// Status is the response status, or "" for the request body
+	Status    string `json:"status,omitempty"`
+	MediaType string `json:"mediaType"`
+	// Example is the name of the example in examples, or "" for example
+	Example string `json:"example,omitempty"`
+	// Pointer locates the mismatch in the example ("" for the example
+	// itself)
+	Pointer string `json:"pointer,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i ExampleIssue) String() string {
+	where := i.Method + " " + i.Path
+	if i.Status != "" {
+		where += " response " + i.Status
+	} else {
+		where += " request"
+	}
+	where += " " + i.MediaType
+	if i.Example != "" {
+		where += " example " + i.Example
+	}
+	if i.Pointer != "" {
+		where += " at " + i.Pointer
+	}
+	if i.Source != "" {
+		where += " (" + i.Source + ")"
+	}
+	return where + ": " + i.Message
+}
+
+// ValidateExamples checks that the examples of the request and response
+// bodies of doc conform to their schemas, returning the mismatches
+func ValidateExamples(doc *openapi3.T) []ExampleIssue {
+	var issues []ExampleIssue
+	forEachBody(doc, func(body bodyContent) {
+		media := body.media
+		if media.Schema == nil || media.Schema.Value == nil {
+			return
+		}
+		issue := ExampleIssue{
+			Path:      body.path,
+			Method:    strings.ToUpper(body.method),
+			Status:    body.status,
+			MediaType: body.mediaType,
+		}
+		if media.Example != nil {
+			issues = append(issues, exampleIssues(issue, media.Schema.Value, media.Example, body.status == "")...)
+		}
+		for _, name := range sortedKeys(media.Examples) {
+			example := media.Examples[name]
+			if example == nil || example.Value == nil || example.Value.Value == nil {
+				continue
+			}
+			issue.Example = name
+			issues = append(issues, exampleIssues(issue, media.Schema.Value, example.Value.Value, body.status == "")...)
+		}
+	})
+	return issues
+}
+
+// exampleIssues validates an example against a schema, returning an issue
+// based on issue for every mismatch
+func exampleIssues(issue ExampleIssue, schema *openapi3.Schema, value any, request bool) []ExampleIssue {
+	direction := openapi3.VisitAsResponse()
+	if request {
+		direction = openapi3.VisitAsRequest()
+	}
+	err := schema.VisitJSON(value, direction, openapi3.MultiErrors())
+	if err == nil {
+		return nil
+	}
+
+	var errs []error
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		errs = multi
+	} else {
+		errs = []error{err}
+	}
+
+	var issues []ExampleIssue
+	for _, err := range errs {
+		i := issue
+		var schemaErr *openapi3.SchemaError
+		if errors.As(err, &schemaErr) {
+			if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+				i.Pointer = "/" + strings.Join(pointer, "/")
+			}
+			i.Message = schemaErr.Reason
+		} else {
+			i.Message = err.Error()
+		}
+		issues = append(issues, i)
+	}
+	return issues
+}
+
+// ValidateExamples checks the examples of the last merged spec against
+// their schemas, reporting mismatches with the input that contributed
+// the operation
+func (m *Merger) ValidateExamples() ([]ExampleIssue, error) {
+	if m.merged == nil {
+		return nil, fmt.Errorf("nothing to validate, merge first")
+	}
+	issues := ValidateExamples(m.merged)
+	for i := range issues {
+		issues[i].Source = m.pathSources[issues[i].Path]
+	}
+	return issues, nil
+}
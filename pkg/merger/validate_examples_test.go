@@ -0,0 +1,110 @@
+package merger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateExamples(t *testing.T) {
+	doc := loadSpec(t, `
+openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /users:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/User'}
+            example: {name: Ann, id: "1"}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+              examples:
+                stale:
+                  value: {name: 42, id: "1"}
+                valid:
+                  value: {name: Ann, id: "1"}
+components:
+  schemas:
+    User:
+      type: object
+      required: [name]
+      properties:
+        id: {type: string, readOnly: true}
+        name: {type: string}
+`)
+
+	issues := ValidateExamples(doc)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", issues)
+	}
+	if i := issues[0]; i.Method != "POST" || i.Status != "" || i.Example != "" {
+		t.Errorf("Expected the readOnly id in the request example to be reported first, got %v", i)
+	}
+	if i := issues[1]; i.Status != "200" || i.Example != "stale" || i.Pointer != "/name" {
+		t.Errorf("Expected the stale response example to be reported, got %v", i)
+	}
+}
+
+func TestMergerValidateExamples(t *testing.T) {
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.yaml")
+	orders := filepath.Join(dir, "orders.yaml")
+	writeFile := func(path, spec string) {
+		if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(users, `
+openapi: 3.0.0
+info: {title: Users, version: "1.0"}
+paths:
+  /users:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: array, items: {type: string}}
+              example: [ann]
+`)
+	writeFile(orders, `
+openapi: 3.0.0
+info: {title: Orders, version: "1.0"}
+paths:
+  /orders:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: integer}
+              example: many
+`)
+
+	m := New(Config{InputPaths: []string{users, orders}, OutputPath: filepath.Join(dir, "merged.yaml")})
+	if _, err := m.ValidateExamples(); err == nil {
+		t.Error("Expected an error before merging")
+	}
+	if err := m.Merge(); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := m.ValidateExamples()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Path != "/orders" || issues[0].Source != orders {
+		t.Fatalf("Expected one issue from orders.yaml, got %v", issues)
+	}
+	if s := issues[0].String(); !strings.HasPrefix(s, "GET /orders response 200 application/json ("+orders+"): ") {
+		t.Errorf("Unexpected issue text: %s", s)
+	}
+}
@@ -0,0 +1,254 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultYAMLIndent is the indentation of YAML outputs when not configured
+const defaultYAMLIndent = 4
+
+// YAMLStyle controls how YAML outputs are emitted, to match the layout of
+// hand-maintained specs and keep diffs against them small
+type YAMLStyle struct {
+	// Indent is the number of spaces per nesting level, from 2 to 9
+	// (default 4)
+	Indent int
+	// LineWidth wraps single-line strings longer than this width at
+	// spaces; 0 never wraps
+	LineWidth int
+	// Quote is the quoting style of string values
+	Quote QuoteStyle
+}
+
+// QuoteStyle is the quoting style of YAML string values. Multi-line
+// strings keep the literal block style, and mapping keys are only quoted
+// when they need to be.
+type QuoteStyle string
+
+const (
+	// QuoteMinimal quotes only the strings that would otherwise be read
+	// as another type, such as "1.0" or "true" (default)
+	QuoteMinimal QuoteStyle = "minimal"
+	// QuoteSingle single-quotes every string value
+	QuoteSingle QuoteStyle = "single"
+	// QuoteDouble double-quotes every string value
+	QuoteDouble QuoteStyle = "double"
+)
+
+// validate checks that the style is supported
+func (s YAMLStyle) validate() error {
+	if s.Indent != 0 && (s.Indent < 2 || s.Indent > 9) {
+		return fmt.Errorf("invalid YAML indent %d (expected 2 to 9)", s.Indent)
+	}
+	if s.LineWidth < 0 {
+		return fmt.Errorf("invalid YAML line width %d", s.LineWidth)
+	}
+	switch s.Quote {
+	case "", QuoteMinimal, QuoteSingle, QuoteDouble:
+		return nil
+	}
+	return fmt.Errorf("invalid quote style %q (expected minimal, single or double)", s.Quote)
+}
+
+// indent returns the configured indentation or the default one
+func (s YAMLStyle) indent() int {
+	if s.Indent == 0 {
+		return defaultYAMLIndent
+	}
+	return s.Indent
+}
+
+// encodeYAML serializes a value as YAML in a style
+func encodeYAML(v any, style YAMLStyle) ([]byte, error) {
+	if err := style.validate(); err != nil {
+		return nil, err
+	}
+	if style == (YAMLStyle{}) {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling to YAML: %v", err)
+		}
+		return data, nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, fmt.Errorf("error marshaling to YAML: %v", err)
+	}
+	return encodeYAMLNode(&node, style)
+}
+
+// encodeYAMLNode serializes a YAML node in a style
+func encodeYAMLNode(node *yaml.Node, style YAMLStyle) ([]byte, error) {
+	if err := style.validate(); err != nil {
+		return nil, err
+	}
+	switch style.Quote {
+	case QuoteSingle:
+		quoteStrings(node, yaml.SingleQuotedStyle)
+	case QuoteDouble:
+		quoteStrings(node, yaml.DoubleQuotedStyle)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(style.indent())
+	if err := enc.Encode(node); err != nil {
+		return nil, fmt.Errorf("error marshaling to YAML: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("error marshaling to YAML: %v", err)
+	}
+	if style.LineWidth > 0 {
+		return wrapYAML(buf.Bytes(), style.LineWidth, style.indent()), nil
+	}
+	return buf.Bytes(), nil
+}
+
+// quoteStrings sets the style of the single-line string values below a
+// node
+func quoteStrings(node *yaml.Node, style yaml.Style) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			quoteStrings(child, style)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			quoteStrings(node.Content[i], style)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" && !strings.Contains(node.Value, "\n") {
+			node.Style = style
+		}
+	}
+}
+
+// wrapYAML folds single-line scalar values longer than width at spaces
+// onto indented continuation lines, which YAML reads back as the same
+// string. The document is returned unwrapped if it would not read back the
+// same.
+func wrapYAML(data []byte, width, indent int) []byte {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return data
+	}
+	// Lines holding a wrappable scalar, by line number, with its column
+	columns := make(map[int]int)
+	collectWrappable(&doc, columns)
+
+	lines := strings.Split(string(data), "\n")
+	var out strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			out.WriteByte('\n')
+		}
+		column, ok := columns[i+1]
+		if !ok || len(line) <= width {
+			out.WriteString(line)
+			continue
+		}
+		out.WriteString(wrapScalarLine(line, column-1, width, contentColumn(line)+indent))
+	}
+	wrapped := []byte(out.String())
+
+	var before, after any
+	if yaml.Unmarshal(data, &before) != nil || yaml.Unmarshal(wrapped, &after) != nil || !reflect.DeepEqual(before, after) {
+		return data
+	}
+	return wrapped
+}
+
+// collectWrappable records the line and column of the plain and quoted
+// scalar values below a node
+func collectWrappable(node *yaml.Node, columns map[int]int) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			collectWrappable(child, columns)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			// Breaking a line with a comment could fold the comment in
+			if node.Content[i-1].LineComment == "" {
+				collectWrappable(node.Content[i], columns)
+			}
+		}
+	case yaml.ScalarNode:
+		if node.LineComment != "" {
+			return
+		}
+		switch node.Style {
+		case 0, yaml.SingleQuotedStyle, yaml.DoubleQuotedStyle:
+			columns[node.Line] = node.Column
+		}
+	}
+}
+
+// contentColumn returns the column of the first key or value of a line,
+// after its indentation and sequence dashes
+func contentColumn(line string) int {
+	column := 0
+	for column < len(line) {
+		switch {
+		case line[column] == ' ':
+			column++
+		case strings.HasPrefix(line[column:], "- "):
+			column += 2
+		default:
+			return column
+		}
+	}
+	return column
+}
+
+// wrapScalarLine breaks the scalar starting at start of a line at single
+// spaces, so every line fits in width where possible
+func wrapScalarLine(line string, start, width, indent int) string {
+	var b strings.Builder
+	b.WriteString(line[:start])
+	rest := line[start:]
+	column := start
+	prefix := strings.Repeat(" ", indent)
+	for {
+		if column+len(rest) <= width {
+			b.WriteString(rest)
+			return b.String()
+		}
+		at := breakPoint(rest, width-column)
+		if at < 0 {
+			b.WriteString(rest)
+			return b.String()
+		}
+		b.WriteString(rest[:at])
+		b.WriteString("\n" + prefix)
+		rest = rest[at+1:]
+		column = indent
+	}
+}
+
+// breakPoint returns the index of the last space of s before limit that
+// can be folded, the first one after it if there is none, or -1. Only
+// single spaces between other characters fold back into a space, and
+// escaped spaces of double-quoted strings do not fold.
+func breakPoint(s string, limit int) int {
+	foldable := func(i int) bool {
+		return s[i] == ' ' && i > 0 && i < len(s)-1 && s[i-1] != ' ' && s[i+1] != ' ' && s[i-1] != '\\'
+	}
+	for i := min(limit, len(s)-1); i > 0; i-- {
+		if foldable(i) {
+			return i
+		}
+	}
+	for i := max(limit+1, 1); i < len(s)-1; i++ {
+		if foldable(i) {
+			return i
+		}
+	}
+	return -1
+}
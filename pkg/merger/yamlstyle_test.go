@@ -0,0 +1,73 @@
+package merger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestEncodeYAMLStyle(t *testing.T) {
+	value := map[string]any{
+		"info": map[string]any{
+			"title":   "Users",
+			"version": "1.0",
+		},
+		"tags": []any{"users"},
+	}
+
+	tests := []struct {
+		style YAMLStyle
+		want  string
+	}{
+		{YAMLStyle{}, "info:\n    title: Users\n    version: \"1.0\"\ntags:\n    - users\n"},
+		{YAMLStyle{Indent: 2}, "info:\n  title: Users\n  version: \"1.0\"\ntags:\n  - users\n"},
+		{YAMLStyle{Indent: 2, Quote: QuoteSingle}, "info:\n  title: 'Users'\n  version: '1.0'\ntags:\n  - 'users'\n"},
+		{YAMLStyle{Indent: 2, Quote: QuoteDouble}, "info:\n  title: \"Users\"\n  version: \"1.0\"\ntags:\n  - \"users\"\n"},
+	}
+	for _, test := range tests {
+		data, err := encodeYAML(value, test.style)
+		if err != nil {
+			t.Fatalf("%+v: expected no error, got %v", test.style, err)
+		}
+		if string(data) != test.want {
+			t.Errorf("%+v: expected:\n%s\ngot:\n%s", test.style, test.want, data)
+		}
+	}
+
+	for _, style := range []YAMLStyle{{Indent: 1}, {Indent: 10}, {LineWidth: -1}, {Quote: "backtick"}} {
+		if _, err := encodeYAML(value, style); err == nil {
+			t.Errorf("%+v: expected an error", style)
+		}
+	}
+}
+
+func TestEncodeYAMLLineWidth(t *testing.T) {
+	long := strings.Repeat("word ", 30) + "end"
+	value := map[string]any{
+		"description": long,
+		"quoted":      "1.0 " + long,
+		"servers":     []any{map[string]any{"description": long}},
+		"multi":       "first line\nsecond line\n",
+		"unbreakable": strings.Repeat("x", 100),
+	}
+
+	data, err := encodeYAML(value, YAMLStyle{Indent: 2, LineWidth: 40})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(line) > 40 && !strings.Contains(line, "xxx") {
+			t.Errorf("Expected lines of at most 40 characters, got %q", line)
+		}
+	}
+
+	var got map[string]any
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Expected valid YAML, got %v:\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, value) {
+		t.Errorf("Expected wrapped YAML to read back the same, got %v", got)
+	}
+}